@@ -2,6 +2,7 @@ package bfv
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"math/big"
@@ -108,6 +109,7 @@ func TestBFV(t *testing.T) {
 			testEvaluatorRotate,
 			testEvaluatorKeySwitch,
 			testMarshaller,
+			testChecked,
 		} {
 			testSet(tc, t)
 			runtime.GC()
@@ -1013,6 +1015,31 @@ func testMarshaller(tc *testContext, t *testing.T) {
 
 	})
 
+	t.Run(testString("Marshaller/Parameters/Fingerprint", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
+
+		fp := tc.params.Fingerprint()
+		require.NotEqual(t, tc.params.Parameters.Fingerprint(), fp)
+
+		ciphertext := NewCiphertextRandom(tc.prng, tc.params, 1)
+		data, err := rlwe.Seal(KindCiphertext, fp, ciphertext)
+		require.NoError(t, err)
+		require.NoError(t, rlwe.Open(data, KindCiphertext, fp, new(Ciphertext)))
+
+		if tc.params.T() == tc.params.Q()[0] {
+			t.Skip("T is Q[0] and cannot be changed")
+		}
+
+		T := uint64(65537)
+		if tc.params.T() == T {
+			T = 786433
+		}
+
+		paramsOtherT, err := NewParameters(tc.params.Parameters, T)
+		require.NoError(t, err)
+		require.NotEqual(t, fp, paramsOtherT.Fingerprint())
+		require.True(t, errors.Is(rlwe.Open(data, KindCiphertext, paramsOtherT.Fingerprint(), new(Ciphertext)), rlwe.ErrEnvelopeParameters))
+	})
+
 	t.Run(testString("Marshaller/Ciphertext", tc.params, tc.params.MaxLevel()), func(t *testing.T) {
 
 		ciphertextWant := NewCiphertextRandom(tc.prng, tc.params, 2)
@@ -1029,3 +1056,74 @@ func testMarshaller(tc *testContext, t *testing.T) {
 		}
 	})
 }
+
+func testChecked(tc *testContext, t *testing.T) {
+
+	encoder := NewCheckedEncoder(tc.params, tc.encoder)
+	encryptor := NewCheckedEncryptor(tc.params, tc.encryptorSk)
+	decryptor := NewCheckedDecryptor(tc.params, tc.decryptor)
+	eval := NewCheckedEvaluator(tc.params, tc.evaluator)
+
+	lvl := tc.params.MaxLevel()
+
+	values, _, ciphertext := newTestVectorsRingQLvl(lvl, tc, tc.encryptorSk, t)
+
+	t.Run(testString("Checked/Encoder", tc.params, lvl), func(t *testing.T) {
+		_, err := encoder.EncodeNew(make([]uint64, tc.params.N()+1), lvl)
+		require.True(t, errors.Is(err, rlwe.ErrInvalidOperand))
+
+		_, err = encoder.EncodeNew([]float64{1}, lvl)
+		require.True(t, errors.Is(err, rlwe.ErrInvalidOperand))
+
+		require.True(t, errors.Is(encoder.DecodeUint(NewPlaintextLvl(tc.params, lvl), make([]uint64, 1)), rlwe.ErrInvalidOperand))
+
+		plaintext, err := encoder.EncodeNew(values.Coeffs[0], lvl)
+		require.NoError(t, err)
+
+		ct, err := encryptor.EncryptNew(plaintext)
+		require.NoError(t, err)
+
+		plaintext, err = decryptor.DecryptNew(ct)
+		require.NoError(t, err)
+
+		coeffs := make([]uint64, tc.params.N())
+		require.NoError(t, encoder.DecodeUint(plaintext, coeffs))
+		require.True(t, utils.EqualSliceUint64(values.Coeffs[0], coeffs))
+	})
+
+	t.Run(testString("Checked/InvalidOperands", tc.params, lvl), func(t *testing.T) {
+		var err error
+		require.NotPanics(t, func() { err = eval.Add(ciphertext, (*Plaintext)(nil), ciphertext) })
+		require.True(t, errors.Is(err, rlwe.ErrInvalidOperand))
+
+		require.True(t, errors.Is(eval.Mul(ciphertext, ciphertext, NewCiphertextLvl(tc.params, 1, lvl)), rlwe.ErrDegree))
+		require.True(t, errors.Is(eval.RescaleTo(lvl+1, ciphertext, ciphertext), rlwe.ErrLevel))
+		require.True(t, errors.Is(decryptor.Decrypt(nil, NewPlaintextLvl(tc.params, lvl)), rlwe.ErrInvalidOperand))
+
+		if lvl > 0 {
+			require.True(t, errors.Is(eval.Neg(ciphertext, NewCiphertextLvl(tc.params, 1, lvl-1)), rlwe.ErrLevel))
+		}
+	})
+
+	t.Run(testString("Checked/MissingKeys", tc.params, lvl), func(t *testing.T) {
+		var err error
+		require.NotPanics(t, func() { err = eval.RotateColumns(ciphertext, 1, ciphertext) })
+		require.True(t, errors.Is(err, rlwe.ErrMissingRotationKey))
+
+		require.True(t, errors.Is(eval.RotateRows(ciphertext, ciphertext), rlwe.ErrMissingRotationKey))
+		require.True(t, errors.Is(eval.InnerSum(ciphertext, ciphertext), rlwe.ErrMissingRotationKey))
+
+		ct2 := NewCiphertextLvl(tc.params, 2, lvl)
+		require.NoError(t, eval.Mul(ciphertext, ciphertext, ct2))
+		require.True(t, errors.Is(eval.WithKey(rlwe.EvaluationKey{}).Relinearize(ct2, ciphertext), rlwe.ErrMissingRelinearizationKey))
+	})
+
+	t.Run(testString("Checked/RotateColumns", tc.params, lvl), func(t *testing.T) {
+		rtks := tc.kgen.GenRotationKeysForRotations([]int{1}, false, tc.sk)
+		evalRot := eval.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rtks})
+
+		receiver := NewCiphertextLvl(tc.params, 1, lvl)
+		require.NoError(t, evalRot.RotateColumns(ciphertext, 1, receiver))
+		verifyTestVectors(tc, tc.decryptor, &ring.Poly{Coeffs: [][]uint64{utils.RotateUint64Slots(values.Coeffs[0], 1)}}, receiver, t)
+	})
+}
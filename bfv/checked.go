@@ -0,0 +1,511 @@
+package bfv
+
+import (
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// CheckedEvaluator wraps an Evaluator and returns an *rlwe.OperationError instead of panicking on malformed
+// operands, such as a missing key or operands of invalid degrees or levels.
+// The returned errors wrap one of the errors of the rlwe package and can be tested with errors.Is.
+type CheckedEvaluator struct {
+	params Parameters
+	eval   Evaluator
+}
+
+// NewCheckedEvaluator returns a CheckedEvaluator wrapping eval, an Evaluator for the given parameters.
+func NewCheckedEvaluator(params Parameters, eval Evaluator) *CheckedEvaluator {
+	return &CheckedEvaluator{params: params, eval: eval}
+}
+
+// Unchecked returns the wrapped Evaluator.
+func (eval *CheckedEvaluator) Unchecked() Evaluator {
+	return eval.eval
+}
+
+// ShallowCopy returns a CheckedEvaluator wrapping a shallow copy of the wrapped Evaluator.
+func (eval *CheckedEvaluator) ShallowCopy() *CheckedEvaluator {
+	return &CheckedEvaluator{params: eval.params, eval: eval.eval.ShallowCopy()}
+}
+
+// WithKey returns a CheckedEvaluator wrapping the wrapped Evaluator with the new EvaluationKey.
+func (eval *CheckedEvaluator) WithKey(evaluationKey rlwe.EvaluationKey) *CheckedEvaluator {
+	return &CheckedEvaluator{params: eval.params, eval: eval.eval.WithKey(evaluationKey)}
+}
+
+// Run calls f with the wrapped Evaluator and returns its panic, if any, as an *rlwe.OperationError
+// for the operation op. It gives access to the methods that have no checked counterpart.
+func (eval *CheckedEvaluator) Run(op string, f func(eval Evaluator)) (err error) {
+	defer rlwe.Recover(op, &err)
+	f(eval.eval)
+	return
+}
+
+// Add is as Evaluator.Add, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Add(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Add", &err)
+	if err = eval.checkBinary("Add", ctIn, op1, ctOut); err != nil {
+		return
+	}
+	eval.eval.Add(ctIn, op1, ctOut)
+	return
+}
+
+// Sub is as Evaluator.Sub, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Sub(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Sub", &err)
+	if err = eval.checkBinary("Sub", ctIn, op1, ctOut); err != nil {
+		return
+	}
+	eval.eval.Sub(ctIn, op1, ctOut)
+	return
+}
+
+// Neg is as Evaluator.Neg, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Neg(ctIn *Ciphertext, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Neg", &err)
+	if err = eval.checkUnary("Neg", ctIn, ctOut); err != nil {
+		return
+	}
+	eval.eval.Neg(ctIn, ctOut)
+	return
+}
+
+// AddScalar is as Evaluator.AddScalar, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) AddScalar(ctIn *Ciphertext, scalar uint64, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("AddScalar", &err)
+	if err = eval.checkUnary("AddScalar", ctIn, ctOut); err != nil {
+		return
+	}
+	eval.eval.AddScalar(ctIn, scalar, ctOut)
+	return
+}
+
+// MulScalar is as Evaluator.MulScalar, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) MulScalar(ctIn *Ciphertext, scalar uint64, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("MulScalar", &err)
+	if err = eval.checkUnary("MulScalar", ctIn, ctOut); err != nil {
+		return
+	}
+	eval.eval.MulScalar(ctIn, scalar, ctOut)
+	return
+}
+
+// Mul is as Evaluator.Mul, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Mul(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Mul", &err)
+	if err = eval.checkOperands("Mul", ctIn, op1, ctOut); err != nil {
+		return
+	}
+	if degree := ctIn.Degree() + op1.Degree(); ctOut.Degree() < degree {
+		return rlwe.NewOperationError("Mul", rlwe.ErrDegree, "the output has degree %d but must have degree at least %d", ctOut.Degree(), degree)
+	}
+	eval.eval.Mul(ctIn, op1, ctOut)
+	return
+}
+
+// Relinearize is as Evaluator.Relinearize, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Relinearize(ctIn *Ciphertext, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Relinearize", &err)
+	if err = checkCiphertext(eval.params, "Relinearize", ctIn, 2, 0xFF); err != nil {
+		return
+	}
+	if err = checkCiphertext(eval.params, "Relinearize", ctOut, 1, 0xFF); err != nil {
+		return
+	}
+	if err = eval.eval.GetRLWEEvaluator().CheckRelinearizationKey("Relinearize", ctIn.Level(), ctIn.Degree()); err != nil {
+		return
+	}
+	eval.eval.Relinearize(ctIn, ctOut)
+	return
+}
+
+// RescaleTo is as Evaluator.RescaleTo, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) RescaleTo(level int, ctIn, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("RescaleTo", &err)
+	if err = checkCiphertext(eval.params, "RescaleTo", ctIn, 1, 1); err != nil {
+		return
+	}
+	if err = checkCiphertext(eval.params, "RescaleTo", ctOut, 1, 1); err != nil {
+		return
+	}
+	if level < 0 || level > ctIn.Level() || ctOut.Level() < level {
+		return rlwe.NewOperationError("RescaleTo", rlwe.ErrLevel, "cannot rescale from level %d to level %d in a ciphertext at level %d", ctIn.Level(), level, ctOut.Level())
+	}
+	eval.eval.RescaleTo(level, ctIn, ctOut)
+	return
+}
+
+// SwitchKeys is as Evaluator.SwitchKeys, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) SwitchKeys(ctIn *Ciphertext, switchingKey *rlwe.SwitchingKey, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("SwitchKeys", &err)
+	if err = checkCiphertext(eval.params, "SwitchKeys", ctIn, 1, 1); err != nil {
+		return
+	}
+	if err = checkCiphertext(eval.params, "SwitchKeys", ctOut, 1, 1); err != nil {
+		return
+	}
+	if switchingKey == nil || len(switchingKey.Value) == 0 || len(switchingKey.Value[0]) == 0 {
+		return rlwe.NewOperationError("SwitchKeys", rlwe.ErrInvalidOperand, "switching key is nil or empty")
+	}
+	if switchingKey.LevelQ() < ctIn.Level() {
+		return rlwe.NewOperationError("SwitchKeys", rlwe.ErrLevel, "the input is at level %d but the key is at level %d", ctIn.Level(), switchingKey.LevelQ())
+	}
+	eval.eval.SwitchKeys(ctIn, switchingKey, ctOut)
+	return
+}
+
+// RotateColumns is as Evaluator.RotateColumns, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) RotateColumns(ctIn *Ciphertext, k int, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("RotateColumns", &err)
+	if err = eval.checkRotation("RotateColumns", ctIn, ctOut, eval.params.GaloisElementForColumnRotationBy(k)); err != nil {
+		return
+	}
+	eval.eval.RotateColumns(ctIn, k, ctOut)
+	return
+}
+
+// RotateRows is as Evaluator.RotateRows, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) RotateRows(ctIn *Ciphertext, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("RotateRows", &err)
+	if err = eval.checkRotation("RotateRows", ctIn, ctOut, eval.params.GaloisElementForRowRotation()); err != nil {
+		return
+	}
+	eval.eval.RotateRows(ctIn, ctOut)
+	return
+}
+
+// InnerSum is as Evaluator.InnerSum, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) InnerSum(ctIn *Ciphertext, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("InnerSum", &err)
+	if err = eval.checkRotation("InnerSum", ctIn, ctOut, eval.params.GaloisElementsForRowInnerSum()...); err != nil {
+		return
+	}
+	eval.eval.InnerSum(ctIn, ctOut)
+	return
+}
+
+// EvaluatePoly is as Evaluator.EvaluatePoly, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) EvaluatePoly(input interface{}, pol *Polynomial) (ctOut *Ciphertext, err error) {
+	defer rlwe.Recover("EvaluatePoly", &err)
+	if ct, isCiphertext := input.(*Ciphertext); isCiphertext {
+		if err = checkCiphertext(eval.params, "EvaluatePoly", ct, 1, 1); err != nil {
+			return
+		}
+	}
+	if pol == nil {
+		return nil, rlwe.NewOperationError("EvaluatePoly", rlwe.ErrInvalidOperand, "polynomial is nil")
+	}
+	if err = eval.eval.GetRLWEEvaluator().CheckRelinearizationKey("EvaluatePoly", 0, 2); err != nil {
+		return
+	}
+	return eval.eval.EvaluatePoly(input, pol)
+}
+
+// checkUnary checks the input and output ciphertexts of an operation, which must be at the same level.
+func (eval *CheckedEvaluator) checkUnary(op string, ctIn, ctOut *Ciphertext) (err error) {
+	if err = checkCiphertext(eval.params, op, ctIn, 0, 0xFF); err != nil {
+		return
+	}
+	if err = checkCiphertext(eval.params, op, ctOut, 0, 0xFF); err != nil {
+		return
+	}
+	if ctIn.Level() != ctOut.Level() {
+		return rlwe.NewOperationError(op, rlwe.ErrLevel, "the input is at level %d but the output is at level %d", ctIn.Level(), ctOut.Level())
+	}
+	if ctOut.Degree() < ctIn.Degree() {
+		return rlwe.NewOperationError(op, rlwe.ErrDegree, "the output has degree %d but must have degree at least %d", ctOut.Degree(), ctIn.Degree())
+	}
+	return
+}
+
+// checkOperands checks the operands of a binary operation, which must all be at the same level except for a *PlaintextRingT.
+func (eval *CheckedEvaluator) checkOperands(op string, ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	if err = eval.checkUnary(op, ctIn, ctOut); err != nil {
+		return
+	}
+	if err = checkOperand(eval.params, op, op1); err != nil {
+		return
+	}
+	if _, isPtRingT := op1.(*PlaintextRingT); !isPtRingT && op1.Level() != ctIn.Level() {
+		return rlwe.NewOperationError(op, rlwe.ErrLevel, "the input is at level %d but the operand is at level %d", ctIn.Level(), op1.Level())
+	}
+	return
+}
+
+// checkBinary checks the operands of an addition or a subtraction.
+func (eval *CheckedEvaluator) checkBinary(op string, ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	if err = eval.checkOperands(op, ctIn, op1, ctOut); err != nil {
+		return
+	}
+	if ctOut.Degree() < op1.Degree() {
+		return rlwe.NewOperationError(op, rlwe.ErrDegree, "the output has degree %d but must have degree at least %d", ctOut.Degree(), op1.Degree())
+	}
+	return
+}
+
+// checkRotation checks the operands of a rotation and the rotation keys of the given Galois elements.
+func (eval *CheckedEvaluator) checkRotation(op string, ctIn, ctOut *Ciphertext, galEls ...uint64) (err error) {
+	if err = checkCiphertext(eval.params, op, ctIn, 1, 1); err != nil {
+		return
+	}
+	if err = checkCiphertext(eval.params, op, ctOut, 1, 1); err != nil {
+		return
+	}
+	return eval.eval.GetRLWEEvaluator().CheckRotationKeys(op, ctIn.Level(), galEls...)
+}
+
+// CheckedEncoder wraps an Encoder and returns an *rlwe.OperationError instead of panicking on malformed operands.
+type CheckedEncoder struct {
+	params Parameters
+	ecd    Encoder
+}
+
+// NewCheckedEncoder returns a CheckedEncoder wrapping ecd, an Encoder for the given parameters.
+func NewCheckedEncoder(params Parameters, ecd Encoder) *CheckedEncoder {
+	return &CheckedEncoder{params: params, ecd: ecd}
+}
+
+// Unchecked returns the wrapped Encoder.
+func (ecd *CheckedEncoder) Unchecked() Encoder {
+	return ecd.ecd
+}
+
+// ShallowCopy returns a CheckedEncoder wrapping a shallow copy of the wrapped Encoder.
+func (ecd *CheckedEncoder) ShallowCopy() *CheckedEncoder {
+	return &CheckedEncoder{params: ecd.params, ecd: ecd.ecd.ShallowCopy()}
+}
+
+// Encode is as Encoder.Encode, but returns an error instead of panicking.
+func (ecd *CheckedEncoder) Encode(coeffs interface{}, pt *Plaintext) (err error) {
+	defer rlwe.Recover("Encode", &err)
+	if err = ecd.checkCoeffs("Encode", coeffs); err != nil {
+		return
+	}
+	if pt == nil {
+		return rlwe.NewOperationError("Encode", rlwe.ErrInvalidOperand, "plaintext is nil")
+	}
+	if err = rlwe.CheckPlaintext(ecd.params.Parameters, "Encode", pt.Plaintext); err != nil {
+		return
+	}
+	ecd.ecd.Encode(coeffs, pt)
+	return
+}
+
+// EncodeNew is as Encoder.EncodeNew, but returns an error instead of panicking.
+func (ecd *CheckedEncoder) EncodeNew(coeffs interface{}, level int) (pt *Plaintext, err error) {
+	defer rlwe.Recover("EncodeNew", &err)
+	if err = ecd.checkCoeffs("EncodeNew", coeffs); err != nil {
+		return
+	}
+	if level < 0 || level > ecd.params.MaxLevel() {
+		return nil, rlwe.NewOperationError("EncodeNew", rlwe.ErrLevel, "level=%d is not in [0, %d]", level, ecd.params.MaxLevel())
+	}
+	return ecd.ecd.EncodeNew(coeffs, level), nil
+}
+
+// EncodeMul is as Encoder.EncodeMul, but returns an error instead of panicking.
+func (ecd *CheckedEncoder) EncodeMul(coeffs interface{}, pt *PlaintextMul) (err error) {
+	defer rlwe.Recover("EncodeMul", &err)
+	if err = ecd.checkCoeffs("EncodeMul", coeffs); err != nil {
+		return
+	}
+	if err = checkOperand(ecd.params, "EncodeMul", pt); err != nil {
+		return
+	}
+	ecd.ecd.EncodeMul(coeffs, pt)
+	return
+}
+
+// DecodeUint is as Encoder.DecodeUint, but returns an error instead of panicking.
+func (ecd *CheckedEncoder) DecodeUint(pt interface{}, coeffs []uint64) (err error) {
+	defer rlwe.Recover("DecodeUint", &err)
+	if err = ecd.checkDecode("DecodeUint", pt, len(coeffs)); err != nil {
+		return
+	}
+	ecd.ecd.DecodeUint(pt, coeffs)
+	return
+}
+
+// DecodeInt is as Encoder.DecodeInt, but returns an error instead of panicking.
+func (ecd *CheckedEncoder) DecodeInt(pt interface{}, coeffs []int64) (err error) {
+	defer rlwe.Recover("DecodeInt", &err)
+	if err = ecd.checkDecode("DecodeInt", pt, len(coeffs)); err != nil {
+		return
+	}
+	ecd.ecd.DecodeInt(pt, coeffs)
+	return
+}
+
+// checkCoeffs checks that coeffs is a []uint64 or a []int64 of at most N elements.
+func (ecd *CheckedEncoder) checkCoeffs(op string, coeffs interface{}) error {
+
+	var lenCoeffs int
+	switch coeffs := coeffs.(type) {
+	case []uint64:
+		lenCoeffs = len(coeffs)
+	case []int64:
+		lenCoeffs = len(coeffs)
+	default:
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "coeffs must be a []uint64 or a []int64 but is %T", coeffs)
+	}
+
+	if lenCoeffs > ecd.params.N() {
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "#coeffs=%d is larger than N=%d", lenCoeffs, ecd.params.N())
+	}
+
+	return nil
+}
+
+// checkDecode checks that pt is a valid plaintext and that the receiver has N elements.
+func (ecd *CheckedEncoder) checkDecode(op string, pt interface{}, lenCoeffs int) error {
+
+	operand, isOperand := pt.(Operand)
+	if !isOperand {
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "pt must be a *Plaintext, *PlaintextMul or *PlaintextRingT but is %T", pt)
+	}
+
+	if _, isCiphertext := pt.(*Ciphertext); isCiphertext {
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "pt must be a *Plaintext, *PlaintextMul or *PlaintextRingT but is %T", pt)
+	}
+
+	if err := checkOperand(ecd.params, op, operand); err != nil {
+		return err
+	}
+
+	if lenCoeffs < ecd.params.N() {
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "#coeffs=%d is smaller than N=%d", lenCoeffs, ecd.params.N())
+	}
+
+	return nil
+}
+
+// CheckedEncryptor wraps an Encryptor and returns an *rlwe.OperationError instead of panicking on malformed operands.
+type CheckedEncryptor struct {
+	params Parameters
+	enc    Encryptor
+}
+
+// NewCheckedEncryptor returns a CheckedEncryptor wrapping enc, an Encryptor for the given parameters.
+func NewCheckedEncryptor(params Parameters, enc Encryptor) *CheckedEncryptor {
+	return &CheckedEncryptor{params: params, enc: enc}
+}
+
+// Unchecked returns the wrapped Encryptor.
+func (enc *CheckedEncryptor) Unchecked() Encryptor {
+	return enc.enc
+}
+
+// ShallowCopy returns a CheckedEncryptor wrapping a shallow copy of the wrapped Encryptor.
+func (enc *CheckedEncryptor) ShallowCopy() *CheckedEncryptor {
+	return &CheckedEncryptor{params: enc.params, enc: enc.enc.ShallowCopy()}
+}
+
+// Encrypt is as Encryptor.Encrypt, but returns an error instead of panicking.
+func (enc *CheckedEncryptor) Encrypt(plaintext *Plaintext, ciphertext *Ciphertext) (err error) {
+	defer rlwe.Recover("Encrypt", &err)
+	if err = checkOperand(enc.params, "Encrypt", plaintext); err != nil {
+		return
+	}
+	if err = checkCiphertext(enc.params, "Encrypt", ciphertext, 1, 1); err != nil {
+		return
+	}
+	enc.enc.Encrypt(plaintext, ciphertext)
+	return
+}
+
+// EncryptNew is as Encryptor.EncryptNew, but returns an error instead of panicking.
+func (enc *CheckedEncryptor) EncryptNew(plaintext *Plaintext) (ciphertext *Ciphertext, err error) {
+	defer rlwe.Recover("EncryptNew", &err)
+	if err = checkOperand(enc.params, "EncryptNew", plaintext); err != nil {
+		return
+	}
+	return enc.enc.EncryptNew(plaintext), nil
+}
+
+// EncryptZero is as Encryptor.EncryptZero, but returns an error instead of panicking.
+func (enc *CheckedEncryptor) EncryptZero(ciphertext *Ciphertext) (err error) {
+	defer rlwe.Recover("EncryptZero", &err)
+	if err = checkCiphertext(enc.params, "EncryptZero", ciphertext, 1, 1); err != nil {
+		return
+	}
+	enc.enc.EncryptZero(ciphertext)
+	return
+}
+
+// CheckedDecryptor wraps a Decryptor and returns an *rlwe.OperationError instead of panicking on malformed operands.
+type CheckedDecryptor struct {
+	params Parameters
+	dec    Decryptor
+}
+
+// NewCheckedDecryptor returns a CheckedDecryptor wrapping dec, a Decryptor for the given parameters.
+func NewCheckedDecryptor(params Parameters, dec Decryptor) *CheckedDecryptor {
+	return &CheckedDecryptor{params: params, dec: dec}
+}
+
+// Unchecked returns the wrapped Decryptor.
+func (dec *CheckedDecryptor) Unchecked() Decryptor {
+	return dec.dec
+}
+
+// ShallowCopy returns a CheckedDecryptor wrapping a shallow copy of the wrapped Decryptor.
+func (dec *CheckedDecryptor) ShallowCopy() *CheckedDecryptor {
+	return &CheckedDecryptor{params: dec.params, dec: dec.dec.ShallowCopy()}
+}
+
+// Decrypt is as Decryptor.Decrypt, but returns an error instead of panicking.
+func (dec *CheckedDecryptor) Decrypt(ciphertext *Ciphertext, plaintext *Plaintext) (err error) {
+	defer rlwe.Recover("Decrypt", &err)
+	if err = checkCiphertext(dec.params, "Decrypt", ciphertext, 0, 0xFF); err != nil {
+		return
+	}
+	if err = checkOperand(dec.params, "Decrypt", plaintext); err != nil {
+		return
+	}
+	dec.dec.Decrypt(ciphertext, plaintext)
+	return
+}
+
+// DecryptNew is as Decryptor.DecryptNew, but returns an error instead of panicking.
+func (dec *CheckedDecryptor) DecryptNew(ciphertext *Ciphertext) (plaintext *Plaintext, err error) {
+	defer rlwe.Recover("DecryptNew", &err)
+	if err = checkCiphertext(dec.params, "DecryptNew", ciphertext, 0, 0xFF); err != nil {
+		return
+	}
+	return dec.dec.DecryptNew(ciphertext), nil
+}
+
+// checkCiphertext is as rlwe.CheckCiphertext for a *Ciphertext.
+func checkCiphertext(params Parameters, op string, ct *Ciphertext, minDegree, maxDegree int) error {
+	if ct == nil {
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "ciphertext is nil")
+	}
+	return rlwe.CheckCiphertext(params.Parameters, op, ct.Ciphertext, minDegree, maxDegree)
+}
+
+// checkOperand checks an Operand that is either a *Ciphertext, a *Plaintext, a *PlaintextMul or a *PlaintextRingT.
+func checkOperand(params Parameters, op string, operand Operand) error {
+
+	var pt *rlwe.Plaintext
+	switch operand := operand.(type) {
+	case *Ciphertext:
+		return checkCiphertext(params, op, operand, 0, 0xFF)
+	case *Plaintext:
+		if operand != nil {
+			pt = operand.Plaintext
+		}
+	case *PlaintextMul:
+		if operand != nil {
+			pt = operand.Plaintext
+		}
+	case *PlaintextRingT:
+		if operand != nil {
+			pt = operand.Plaintext
+		}
+	case nil:
+	default:
+		return rlwe.CheckCiphertext(params.Parameters, op, operand.El(), 0, 0xFF)
+	}
+
+	return rlwe.CheckPlaintext(params.Parameters, op, pt)
+}
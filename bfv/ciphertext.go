@@ -1,6 +1,8 @@
 package bfv
 
 import (
+	"io"
+
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/utils"
 )
@@ -35,6 +37,9 @@ func (ct *Ciphertext) CopyNew() *Ciphertext {
 	return &Ciphertext{ct.Ciphertext.CopyNew()}
 }
 
+// KindCiphertext is the kind of the envelopes of bfv.Ciphertext, see rlwe.Seal.
+const KindCiphertext rlwe.ObjectKind = "bfv.Ciphertext"
+
 // MarshalBinary encodes a Ciphertext in a byte slice.
 func (ct *Ciphertext) MarshalBinary() (data []byte, err error) {
 	return ct.Ciphertext.MarshalBinary()
@@ -46,6 +51,17 @@ func (ct *Ciphertext) UnmarshalBinary(data []byte) (err error) {
 	return ct.Ciphertext.UnmarshalBinary(data)
 }
 
+// WriteTo writes the target Ciphertext on w, see rlwe.Ciphertext.WriteTo.
+func (ct *Ciphertext) WriteTo(w io.Writer) (n int64, err error) {
+	return ct.Ciphertext.WriteTo(w)
+}
+
+// ReadFrom reads a Ciphertext from r on the target Ciphertext, see rlwe.Ciphertext.ReadFrom.
+func (ct *Ciphertext) ReadFrom(r io.Reader) (n int64, err error) {
+	ct.Ciphertext = new(rlwe.Ciphertext)
+	return ct.Ciphertext.ReadFrom(r)
+}
+
 // GetDataLen returns the length in bytes of the target Ciphertext.
 func (ct *Ciphertext) GetDataLen(WithMetaData bool) (dataLen int) {
 	return ct.Ciphertext.GetDataLen(WithMetaData)
@@ -54,6 +54,7 @@ type Evaluator interface {
 	ShallowCopy() Evaluator
 	WithKey(rlwe.EvaluationKey) Evaluator
 
+	GetRLWEEvaluator() *rlwe.Evaluator
 	BuffQ() [][]*ring.Poly
 	BuffQMul() [][]*ring.Poly
 	BuffPt() *Plaintext
@@ -307,7 +308,7 @@ func (eval *evaluator) Rescale(ctIn, ctOut *Ciphertext) {
 func (eval *evaluator) RescaleTo(level int, ctIn, ctOut *Ciphertext) {
 
 	if ctIn.Level() < level || ctOut.Level() < ctIn.Level()-level {
-		panic("cannot RescaleTo: (ctIn.Level() || ctOut.Level()) < level")
+		panic(rlwe.NewOperationError("RescaleTo", rlwe.ErrLevel, "(ctIn.Level() || ctOut.Level()) < level"))
 	}
 
 	eval.ringQ.DivRoundByLastModulusManyLvl(ctIn.Level(), ctIn.Level()-level, ctIn.Value[0], eval.buffQ[0][0], ctOut.Value[0])
@@ -658,7 +659,7 @@ func (eval *evaluator) RotateRowsNew(ctIn *Ciphertext) (ctOut *Ciphertext) {
 // The resulting vector will be of the form [sum, sum, .., sum, sum].
 func (eval *evaluator) InnerSum(ctIn *Ciphertext, ctOut *Ciphertext) {
 	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot InnerSum: input and output must be of degree 1")
+		panic(rlwe.NewOperationError("InnerSum", rlwe.ErrDegree, "input and output must be of degree 1"))
 	}
 	cTmp := NewCiphertextLvl(eval.params, 1, ctIn.Level())
 	ctOut.Copy(ctIn.El())
@@ -699,6 +700,11 @@ func (eval *evaluator) BuffQ() [][]*ring.Poly {
 	return eval.buffQ
 }
 
+// GetRLWEEvaluator returns the underlying *rlwe.Evaluator.
+func (eval *evaluator) GetRLWEEvaluator() *rlwe.Evaluator {
+	return eval.Evaluator
+}
+
 // BuffQMul returns the internal evaluator buffQMul buffer.
 func (eval *evaluator) BuffQMul() [][]*ring.Poly {
 	return eval.buffQMul
@@ -728,19 +734,19 @@ func (eval *evaluator) getRingQElem(level int, op Operand) *rlwe.Ciphertext {
 // getElemAndCheckBinary unwraps the elements from the operands and checks that the receiver has sufficiently large degree.
 func (eval *evaluator) getElemAndCheckBinary(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext, opOutMinDegree int, ensureRingQ bool) (el0, el1, elOut *rlwe.Ciphertext) {
 	if ctIn == nil || op1 == nil || ctOut == nil {
-		panic("cannot getElemAndCheckBinary: ctIn, op1 or ctOut cannot be nil")
+		panic(rlwe.NewOperationError("getElemAndCheckBinary", rlwe.ErrInvalidOperand, "ctIn, op1 or ctOut cannot be nil"))
 	}
 
 	if ctOut.Degree() < opOutMinDegree {
-		panic("cannot getElemAndCheckBinary: ctOut.Degree() degree is too small")
+		panic(rlwe.NewOperationError("getElemAndCheckBinary", rlwe.ErrDegree, "ctOut.Degree() degree is too small"))
 	}
 
 	if ctIn.Level() != ctOut.Level() {
-		panic("cannot getElemAndCheckBinary: ctIn and ctOut must be at the same level")
+		panic(rlwe.NewOperationError("getElemAndCheckBinary", rlwe.ErrLevel, "ctIn and ctOut must be at the same level"))
 	}
 
 	if _, isPtRingT := op1.(*PlaintextRingT); !isPtRingT && ctIn.Level() != op1.Level() {
-		panic("cannot getElemAndCheckBinary: ctIn & op1 of type *bfv.Plaintext or *bfv.PlaintextMul must be at the same level")
+		panic(rlwe.NewOperationError("getElemAndCheckBinary", rlwe.ErrLevel, "ctIn & op1 of type *bfv.Plaintext or *bfv.PlaintextMul must be at the same level"))
 	}
 
 	level := ctIn.Level()
@@ -755,15 +761,15 @@ func (eval *evaluator) getElemAndCheckBinary(ctIn *Ciphertext, op1 Operand, ctOu
 func (eval *evaluator) getElemAndCheckUnary(ctIn, ctOut *Ciphertext, opOutMinDegree int) (el0, elOut *rlwe.Ciphertext) {
 
 	if ctIn == nil || ctOut == nil {
-		panic("cannot getElemAndCheckUnary: ctIn or ctOut cannot be nil")
+		panic(rlwe.NewOperationError("getElemAndCheckUnary", rlwe.ErrInvalidOperand, "ctIn or ctOut cannot be nil"))
 	}
 
 	if ctIn.Level() != ctOut.Level() {
-		panic("cannot getElemAndCheckUnary: ctIn.Level() is not equal to ctOut.Level()")
+		panic(rlwe.NewOperationError("getElemAndCheckUnary", rlwe.ErrLevel, "ctIn.Level() is not equal to ctOut.Level()"))
 	}
 
 	if ctOut.Degree() < opOutMinDegree {
-		panic("cannot getElemAndCheckUnary: ctOut.Degree() is too small")
+		panic(rlwe.NewOperationError("getElemAndCheckUnary", rlwe.ErrDegree, "ctOut.Degree() is too small"))
 	}
 
 	return ctIn.El(), ctOut.El()
@@ -1,12 +1,21 @@
 package bfv
 
-import "github.com/tuneinsight/lattigo/v3/rlwe"
+import (
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
 
 // NewKeyGenerator creates a rlwe.KeyGenerator instance from the BFV parameters.
 func NewKeyGenerator(params Parameters) rlwe.KeyGenerator {
 	return rlwe.NewKeyGenerator(params.Parameters)
 }
 
+// NewKeyGeneratorWithPRNG creates a rlwe.KeyGenerator instance from the BFV parameters drawing its randomness from prng.
+// See rlwe.NewKeyGeneratorWithPRNG.
+func NewKeyGeneratorWithPRNG(params Parameters, prng utils.PRNG) rlwe.KeyGenerator {
+	return rlwe.NewKeyGeneratorWithPRNG(params.Parameters, prng)
+}
+
 // NewSecretKey returns an allocated BFV secret key with zero values.
 func NewSecretKey(params Parameters) (sk *rlwe.SecretKey) {
 	return rlwe.NewSecretKey(params.Parameters)
@@ -10,6 +10,7 @@ import (
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/utils"
+	"golang.org/x/crypto/blake2b"
 )
 
 var (
@@ -268,6 +269,17 @@ func (p Parameters) MarshalBinarySize() int {
 	return p.Parameters.MarshalBinarySize() + 8
 }
 
+// Fingerprint returns the BLAKE2b-256 hash of the binary encoding of the parameters. Unlike the fingerprint
+// of the embedded rlwe.Parameters, it also depends on the plaintext modulus T, so that the envelopes
+// of the objects generated under other bfv parameters are rejected by rlwe.Open.
+func (p Parameters) Fingerprint() (fp rlwe.Fingerprint) {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return blake2b.Sum256(data)
+}
+
 // MarshalJSON returns a JSON representation of this parameter set. See `Marshal` from the `encoding/json` package.
 func (p Parameters) MarshalJSON() ([]byte, error) {
 	return json.Marshal(ParametersLiteral{
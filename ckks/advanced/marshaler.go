@@ -3,6 +3,15 @@ package advanced
 import (
 	"encoding/binary"
 	"math"
+
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// The kinds of the envelopes of the advanced literals, see rlwe.Seal. These are not bound to a
+// parameter set and are sealed with the zero rlwe.Fingerprint.
+const (
+	KindEncodingMatrixLiteral rlwe.ObjectKind = "advanced.EncodingMatrixLiteral"
+	KindEvalModLiteral        rlwe.ObjectKind = "advanced.EvalModLiteral"
 )
 
 // MarshalBinary encode the target EncodingMatrixParameters on a slice of bytes.
@@ -3,6 +3,7 @@ package bootstrapping
 import (
 	"github.com/tuneinsight/lattigo/v3/ckks"
 	"github.com/tuneinsight/lattigo/v3/ckks/advanced"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
@@ -14,6 +15,10 @@ type Parameters struct {
 	EphemeralSecretWeight   int // Hamming weight of the ephemeral secret. If 0, no ephemeral secret is used during the bootstrapping.
 }
 
+// KindParameters is the kind of the envelopes of bootstrapping.Parameters, see rlwe.Seal.
+// These are not bound to a parameter set and are sealed with the zero rlwe.Fingerprint.
+const KindParameters rlwe.ObjectKind = "bootstrapping.Parameters"
+
 // MarshalBinary encode the target Parameters on a slice of bytes.
 func (p *Parameters) MarshalBinary() (data []byte, err error) {
 	data = []byte{}
@@ -1,6 +1,8 @@
 package bootstrapping
 
 import (
+	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
 	"runtime"
@@ -10,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
@@ -41,6 +44,41 @@ func TestBootstrapParametersMarshalling(t *testing.T) {
 	assert.Equal(t, bootstrapParams, *bootstrapParamsNew)
 }
 
+func TestEvaluationKeysStream(t *testing.T) {
+
+	params, err := ckks.NewParametersFromLiteral(ckks.PN12QP109)
+	require.NoError(t, err)
+
+	kgen := ckks.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+
+	evk := EvaluationKeys{
+		EvaluationKey: rlwe.EvaluationKey{
+			Rlk:  kgen.GenRelinearizationKey(sk, 1),
+			Rtks: kgen.GenRotationKeysForRotations([]int{1, 2}, true, sk),
+		},
+		SwkStD: kgen.GenSwitchingKey(sk, kgen.GenSecretKey()),
+	}
+
+	buf := new(bytes.Buffer)
+	n, err := evk.WriteTo(buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(buf.Len()), n)
+
+	// trailing data after the keys is left unread
+	buf.WriteByte(0xFF)
+
+	evkTest := new(EvaluationKeys)
+	nTest, err := evkTest.ReadFrom(bufio.NewReader(buf))
+	require.NoError(t, err)
+	require.Equal(t, n, nTest)
+
+	require.True(t, evk.Rlk.Equals(evkTest.Rlk))
+	require.True(t, evk.Rtks.(*rlwe.RotationKeySet).Equals(evkTest.Rtks.(*rlwe.RotationKeySet)))
+	require.Nil(t, evkTest.SwkDtS)
+	require.True(t, evk.SwkStD.Equals(evkTest.SwkStD))
+}
+
 func TestBootstrap(t *testing.T) {
 
 	if runtime.GOARCH == "wasm" {
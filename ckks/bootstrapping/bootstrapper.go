@@ -2,6 +2,7 @@ package bootstrapping
 
 import (
 	"fmt"
+	"io"
 	"math"
 
 	"github.com/tuneinsight/lattigo/v3/ckks"
@@ -41,6 +42,95 @@ type EvaluationKeys struct {
 	SwkStD *rlwe.SwitchingKey
 }
 
+// WriteTo writes the target EvaluationKeys on w, one polynomial at a time. The layout is a byte flagging
+// the non-nil keys, followed by Rlk, Rtks with the layout of rlwe.WriteRotationKeys, SwkDtS and SwkStD.
+// It implements the io.WriterTo interface.
+func (evk *EvaluationKeys) WriteTo(w io.Writer) (n int64, err error) {
+
+	var flags uint8
+	for i, nonNil := range []bool{evk.Rlk != nil, evk.HasRotationKeys(), evk.SwkDtS != nil, evk.SwkStD != nil} {
+		if nonNil {
+			flags |= 1 << uint(i)
+		}
+	}
+
+	var inc int
+	if inc, err = w.Write([]byte{flags}); err != nil {
+		return n + int64(inc), err
+	}
+	n += int64(inc)
+
+	var inc64 int64
+	for i := 0; i < 4; i++ {
+
+		if flags>>uint(i)&1 == 0 {
+			continue
+		}
+
+		switch i {
+		case 0:
+			inc64, err = evk.Rlk.WriteTo(w)
+		case 1:
+			inc64, err = rlwe.WriteRotationKeys(w, evk.Rtks)
+		case 2:
+			inc64, err = evk.SwkDtS.WriteTo(w)
+		case 3:
+			inc64, err = evk.SwkStD.WriteTo(w)
+		}
+
+		if n += inc64; err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// ReadFrom reads EvaluationKeys written by WriteTo from r on the target EvaluationKeys.
+// The rotation keys are read on a *rlwe.RotationKeySet. It implements the io.ReaderFrom interface.
+func (evk *EvaluationKeys) ReadFrom(r io.Reader) (n int64, err error) {
+
+	flags := []byte{0}
+
+	var inc int
+	if inc, err = io.ReadFull(r, flags); err != nil {
+		return n + int64(inc), err
+	}
+	n += int64(inc)
+
+	evk.Rlk, evk.Rtks, evk.SwkDtS, evk.SwkStD = nil, nil, nil, nil
+
+	var inc64 int64
+	for i := 0; i < 4; i++ {
+
+		if flags[0]>>uint(i)&1 == 0 {
+			continue
+		}
+
+		switch i {
+		case 0:
+			evk.Rlk = new(rlwe.RelinearizationKey)
+			inc64, err = evk.Rlk.ReadFrom(r)
+		case 1:
+			rtks := new(rlwe.RotationKeySet)
+			inc64, err = rtks.ReadFrom(r)
+			evk.Rtks = rtks
+		case 2:
+			evk.SwkDtS = new(rlwe.SwitchingKey)
+			inc64, err = evk.SwkDtS.ReadFrom(r)
+		case 3:
+			evk.SwkStD = new(rlwe.SwitchingKey)
+			inc64, err = evk.SwkStD.ReadFrom(r)
+		}
+
+		if n += inc64; err != nil {
+			return
+		}
+	}
+
+	return
+}
+
 // NewBootstrapper creates a new Bootstrapper.
 func NewBootstrapper(params ckks.Parameters, btpParams Parameters, btpKeys EvaluationKeys) (btp *Bootstrapper, err error) {
 
@@ -133,7 +223,7 @@ func (bb *bootstrapperBase) CheckKeys(btpKeys EvaluationKeys) (err error) {
 		return fmt.Errorf("relinearization key is nil")
 	}
 
-	if btpKeys.Rtks == nil {
+	if !btpKeys.HasRotationKeys() {
 		return fmt.Errorf("rotation key is nil")
 	}
 
@@ -149,16 +239,22 @@ func (bb *bootstrapperBase) CheckKeys(btpKeys EvaluationKeys) (err error) {
 	rotKeyIndex = append(rotKeyIndex, bb.CoeffsToSlotsParameters.Rotations()...)
 	rotKeyIndex = append(rotKeyIndex, bb.SlotsToCoeffsParameters.Rotations()...)
 
+	// the presence of the keys is checked on the galois elements so that a lazy set does not load its keys
+	generated := make(map[uint64]bool)
+	for _, galEl := range btpKeys.Rtks.GaloisElements() {
+		generated[galEl] = true
+	}
+
 	rotMissing := []int{}
 	for _, i := range rotKeyIndex {
 		galEl := bb.params.GaloisElementForColumnRotationBy(int(i))
-		if _, generated := btpKeys.Rtks.Keys[galEl]; !generated {
+		if !generated[galEl] {
 			rotMissing = append(rotMissing, i)
 		}
 	}
 
 	for _, galEl := range bb.params.GaloisElementsForTrace(bb.params.LogSlots()) {
-		if _, generated := btpKeys.Rtks.Keys[galEl]; !generated {
+		if !generated[galEl] {
 			rotMissing = append(rotMissing, int(galEl))
 		}
 	}
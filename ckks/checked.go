@@ -0,0 +1,653 @@
+package ckks
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// CheckedEvaluator wraps an Evaluator and returns an *rlwe.OperationError instead of panicking on malformed
+// operands, such as a missing key or operands of invalid degrees or levels.
+// The returned errors wrap one of the errors of the rlwe package and can be tested with errors.Is.
+type CheckedEvaluator struct {
+	params Parameters
+	eval   Evaluator
+}
+
+// NewCheckedEvaluator returns a CheckedEvaluator wrapping eval, an Evaluator for the given parameters.
+func NewCheckedEvaluator(params Parameters, eval Evaluator) *CheckedEvaluator {
+	return &CheckedEvaluator{params: params, eval: eval}
+}
+
+// Unchecked returns the wrapped Evaluator.
+func (eval *CheckedEvaluator) Unchecked() Evaluator {
+	return eval.eval
+}
+
+// ShallowCopy returns a CheckedEvaluator wrapping a shallow copy of the wrapped Evaluator.
+func (eval *CheckedEvaluator) ShallowCopy() *CheckedEvaluator {
+	return &CheckedEvaluator{params: eval.params, eval: eval.eval.ShallowCopy()}
+}
+
+// WithKey returns a CheckedEvaluator wrapping the wrapped Evaluator with the new EvaluationKey.
+func (eval *CheckedEvaluator) WithKey(evaluationKey rlwe.EvaluationKey) *CheckedEvaluator {
+	return &CheckedEvaluator{params: eval.params, eval: eval.eval.WithKey(evaluationKey)}
+}
+
+// Run calls f with the wrapped Evaluator and returns its panic, if any, as an *rlwe.OperationError
+// for the operation op. It gives access to the methods that have no checked counterpart.
+func (eval *CheckedEvaluator) Run(op string, f func(eval Evaluator)) (err error) {
+	defer rlwe.Recover(op, &err)
+	f(eval.eval)
+	return
+}
+
+// Add is as Evaluator.Add, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Add(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Add", &err)
+	if err = eval.checkBinary("Add", ctIn, op1, ctOut); err != nil {
+		return
+	}
+	eval.eval.Add(ctIn, op1, ctOut)
+	return
+}
+
+// Sub is as Evaluator.Sub, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Sub(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Sub", &err)
+	if err = eval.checkBinary("Sub", ctIn, op1, ctOut); err != nil {
+		return
+	}
+	eval.eval.Sub(ctIn, op1, ctOut)
+	return
+}
+
+// Neg is as Evaluator.Neg, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Neg(ctIn *Ciphertext, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Neg", &err)
+	if err = eval.checkUnary("Neg", ctIn, ctOut, 0xFF); err != nil {
+		return
+	}
+	if ctOut.Degree() != ctIn.Degree() {
+		return rlwe.NewOperationError("Neg", rlwe.ErrDegree, "the input has degree %d but the output has degree %d", ctIn.Degree(), ctOut.Degree())
+	}
+	eval.eval.Neg(ctIn, ctOut)
+	return
+}
+
+// AddConst is as Evaluator.AddConst, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) AddConst(ctIn *Ciphertext, constant interface{}, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("AddConst", &err)
+	if err = eval.checkUnary("AddConst", ctIn, ctOut, 0xFF); err != nil {
+		return
+	}
+	eval.eval.AddConst(ctIn, constant, ctOut)
+	return
+}
+
+// MultByConst is as Evaluator.MultByConst, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) MultByConst(ctIn *Ciphertext, constant interface{}, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("MultByConst", &err)
+	if err = eval.checkUnary("MultByConst", ctIn, ctOut, 0xFF); err != nil {
+		return
+	}
+	eval.eval.MultByConst(ctIn, constant, ctOut)
+	return
+}
+
+// MultByi is as Evaluator.MultByi, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) MultByi(ctIn *Ciphertext, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("MultByi", &err)
+	if err = eval.checkStandardRing("MultByi"); err != nil {
+		return
+	}
+	if err = eval.checkUnary("MultByi", ctIn, ctOut, 0xFF); err != nil {
+		return
+	}
+	eval.eval.MultByi(ctIn, ctOut)
+	return
+}
+
+// DivByi is as Evaluator.DivByi, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) DivByi(ctIn *Ciphertext, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("DivByi", &err)
+	if err = eval.checkStandardRing("DivByi"); err != nil {
+		return
+	}
+	if err = eval.checkUnary("DivByi", ctIn, ctOut, 0xFF); err != nil {
+		return
+	}
+	eval.eval.DivByi(ctIn, ctOut)
+	return
+}
+
+// Mul is as Evaluator.Mul, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Mul(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Mul", &err)
+	if err = eval.checkMul("Mul", ctIn, op1, ctOut, false); err != nil {
+		return
+	}
+	eval.eval.Mul(ctIn, op1, ctOut)
+	return
+}
+
+// MulRelin is as Evaluator.MulRelin, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) MulRelin(ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("MulRelin", &err)
+	if err = eval.checkMul("MulRelin", ctIn, op1, ctOut, true); err != nil {
+		return
+	}
+	eval.eval.MulRelin(ctIn, op1, ctOut)
+	return
+}
+
+// Relinearize is as Evaluator.Relinearize, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Relinearize(ctIn *Ciphertext, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Relinearize", &err)
+	if err = checkCiphertext(eval.params, "Relinearize", ctIn, 2, 0xFF); err != nil {
+		return
+	}
+	if err = checkCiphertext(eval.params, "Relinearize", ctOut, 1, 0xFF); err != nil {
+		return
+	}
+	if err = eval.eval.GetRLWEEvaluator().CheckRelinearizationKey("Relinearize", utils.MinInt(ctIn.Level(), ctOut.Level()), ctIn.Degree()); err != nil {
+		return
+	}
+	eval.eval.Relinearize(ctIn, ctOut)
+	return
+}
+
+// Rescale is as Evaluator.Rescale, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Rescale(ctIn *Ciphertext, minScale float64, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Rescale", &err)
+	if err = eval.checkUnary("Rescale", ctIn, ctOut, 0xFF); err != nil {
+		return
+	}
+	if err = eval.eval.Rescale(ctIn, minScale, ctOut); err != nil {
+		return rlwe.NewOperationError("Rescale", rlwe.ErrLevel, "%s", err)
+	}
+	return
+}
+
+// DropLevel is as Evaluator.DropLevel, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) DropLevel(ctIn *Ciphertext, levels int) (err error) {
+	defer rlwe.Recover("DropLevel", &err)
+	if err = checkCiphertext(eval.params, "DropLevel", ctIn, 0, 0xFF); err != nil {
+		return
+	}
+	if levels < 0 || levels > ctIn.Level() {
+		return rlwe.NewOperationError("DropLevel", rlwe.ErrLevel, "cannot drop %d levels of a ciphertext at level %d", levels, ctIn.Level())
+	}
+	eval.eval.DropLevel(ctIn, levels)
+	return
+}
+
+// Rotate is as Evaluator.Rotate, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Rotate(ctIn *Ciphertext, k int, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Rotate", &err)
+	if err = eval.checkUnary("Rotate", ctIn, ctOut, 1); err != nil {
+		return
+	}
+	level := utils.MinInt(ctIn.Level(), ctOut.Level())
+	if err = eval.eval.GetRLWEEvaluator().CheckRotationKeys("Rotate", level, eval.params.GaloisElementForColumnRotationBy(k)); err != nil {
+		return
+	}
+	eval.eval.Rotate(ctIn, k, ctOut)
+	return
+}
+
+// RotateHoisted is as Evaluator.RotateHoisted, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) RotateHoisted(ctIn *Ciphertext, rotations []int, ctOut map[int]*Ciphertext) (err error) {
+	defer rlwe.Recover("RotateHoisted", &err)
+	if err = checkCiphertext(eval.params, "RotateHoisted", ctIn, 1, 1); err != nil {
+		return
+	}
+	for _, k := range rotations {
+		if err = checkCiphertext(eval.params, "RotateHoisted", ctOut[k], 1, 1); err != nil {
+			return
+		}
+	}
+	if err = eval.checkHoistedKeys("RotateHoisted", ctIn.Level(), rotations); err != nil {
+		return
+	}
+	eval.eval.RotateHoisted(ctIn, rotations, ctOut)
+	return
+}
+
+// Conjugate is as Evaluator.Conjugate, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Conjugate(ctIn *Ciphertext, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Conjugate", &err)
+	if err = eval.checkStandardRing("Conjugate"); err != nil {
+		return
+	}
+	if err = eval.checkUnary("Conjugate", ctIn, ctOut, 1); err != nil {
+		return
+	}
+	level := utils.MinInt(ctIn.Level(), ctOut.Level())
+	if err = eval.eval.GetRLWEEvaluator().CheckRotationKeys("Conjugate", level, eval.params.GaloisElementForRowRotation()); err != nil {
+		return
+	}
+	eval.eval.Conjugate(ctIn, ctOut)
+	return
+}
+
+// InnerSum is as Evaluator.InnerSum, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) InnerSum(ctIn *Ciphertext, batch, n int, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("InnerSum", &err)
+	if err = eval.checkUnary("InnerSum", ctIn, ctOut, 1); err != nil {
+		return
+	}
+	if n < 1 {
+		return rlwe.NewOperationError("InnerSum", rlwe.ErrInvalidOperand, "n=%d must be positive", n)
+	}
+	if err = eval.checkHoistedKeys("InnerSum", ctIn.Level(), eval.params.RotationsForInnerSum(batch, n)); err != nil {
+		return
+	}
+	eval.eval.InnerSum(ctIn, batch, n, ctOut)
+	return
+}
+
+// Trace is as Evaluator.Trace, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Trace(ctIn *Ciphertext, logSlots int, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("Trace", &err)
+	if err = eval.checkUnary("Trace", ctIn, ctOut, 1); err != nil {
+		return
+	}
+	if logSlots < 0 || logSlots > eval.params.LogN()-1 {
+		return rlwe.NewOperationError("Trace", rlwe.ErrInvalidOperand, "logSlots=%d is not in [0, %d]", logSlots, eval.params.LogN()-1)
+	}
+	level := utils.MinInt(ctIn.Level(), ctOut.Level())
+	if err = eval.eval.GetRLWEEvaluator().CheckRotationKeys("Trace", level, eval.params.GaloisElementsForTrace(logSlots)...); err != nil {
+		return
+	}
+	eval.eval.Trace(ctIn, logSlots, ctOut)
+	return
+}
+
+// LinearTransform is as Evaluator.LinearTransform, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) LinearTransform(ctIn *Ciphertext, linearTransform interface{}, ctOut []*Ciphertext) (err error) {
+	defer rlwe.Recover("LinearTransform", &err)
+
+	if err = checkCiphertext(eval.params, "LinearTransform", ctIn, 1, 1); err != nil {
+		return
+	}
+
+	var LTs []LinearTransform
+	switch LT := linearTransform.(type) {
+	case LinearTransform:
+		LTs = []LinearTransform{LT}
+	case []LinearTransform:
+		LTs = LT
+	default:
+		return rlwe.NewOperationError("LinearTransform", rlwe.ErrInvalidOperand, "linearTransform must be a LinearTransform or a []LinearTransform but is %T", linearTransform)
+	}
+
+	if len(ctOut) < len(LTs) {
+		return rlwe.NewOperationError("LinearTransform", rlwe.ErrInvalidOperand, "%d output ciphertexts for %d linear transforms", len(ctOut), len(LTs))
+	}
+
+	for i := range LTs {
+		if err = checkCiphertext(eval.params, "LinearTransform", ctOut[i], 1, 1); err != nil {
+			return
+		}
+		if err = eval.checkHoistedKeys("LinearTransform", utils.MinInt(ctIn.Level(), LTs[i].Level), LTs[i].Rotations()); err != nil {
+			return
+		}
+	}
+
+	eval.eval.LinearTransform(ctIn, linearTransform, ctOut)
+	return
+}
+
+// SwitchKeys is as Evaluator.SwitchKeys, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) SwitchKeys(ctIn *Ciphertext, switchingKey *rlwe.SwitchingKey, ctOut *Ciphertext) (err error) {
+	defer rlwe.Recover("SwitchKeys", &err)
+	if err = eval.checkUnary("SwitchKeys", ctIn, ctOut, 1); err != nil {
+		return
+	}
+	if switchingKey == nil || len(switchingKey.Value) == 0 || len(switchingKey.Value[0]) == 0 {
+		return rlwe.NewOperationError("SwitchKeys", rlwe.ErrInvalidOperand, "switching key is nil or empty")
+	}
+	if level := utils.MinInt(ctIn.Level(), ctOut.Level()); switchingKey.LevelQ() < level {
+		return rlwe.NewOperationError("SwitchKeys", rlwe.ErrLevel, "the input is at level %d but the key is at level %d", level, switchingKey.LevelQ())
+	}
+	eval.eval.SwitchKeys(ctIn, switchingKey, ctOut)
+	return
+}
+
+// EvaluatePoly is as Evaluator.EvaluatePoly, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) EvaluatePoly(input interface{}, pol *Polynomial, targetScale float64) (ctOut *Ciphertext, err error) {
+	defer rlwe.Recover("EvaluatePoly", &err)
+	if ct, isCiphertext := input.(*Ciphertext); isCiphertext {
+		if err = checkCiphertext(eval.params, "EvaluatePoly", ct, 1, 1); err != nil {
+			return
+		}
+	}
+	if pol == nil {
+		return nil, rlwe.NewOperationError("EvaluatePoly", rlwe.ErrInvalidOperand, "polynomial is nil")
+	}
+	if err = eval.eval.GetRLWEEvaluator().CheckRelinearizationKey("EvaluatePoly", 0, 2); err != nil {
+		return
+	}
+	return eval.eval.EvaluatePoly(input, pol, targetScale)
+}
+
+// checkStandardRing checks that the ring type of the parameters is ring.Standard.
+func (eval *CheckedEvaluator) checkStandardRing(op string) error {
+	if eval.params.RingType() != ring.Standard {
+		return rlwe.NewOperationError(op, rlwe.ErrRingType, "method is not supported when params.RingType() == %s", eval.params.RingType())
+	}
+	return nil
+}
+
+// checkUnary checks the input and output ciphertexts of an operation.
+func (eval *CheckedEvaluator) checkUnary(op string, ctIn, ctOut *Ciphertext, maxDegree int) (err error) {
+	if err = checkCiphertext(eval.params, op, ctIn, 0, maxDegree); err != nil {
+		return
+	}
+	return checkCiphertext(eval.params, op, ctOut, 0, maxDegree)
+}
+
+// checkBinary checks the operands of an addition or a subtraction.
+func (eval *CheckedEvaluator) checkBinary(op string, ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext) (err error) {
+	if err = eval.checkUnary(op, ctIn, ctOut, 0xFF); err != nil {
+		return
+	}
+	if err = checkOperand(eval.params, op, op1); err != nil {
+		return
+	}
+	if degree := utils.MaxInt(ctIn.Degree(), op1.Degree()); ctOut.Degree() < degree {
+		return rlwe.NewOperationError(op, rlwe.ErrDegree, "the output has degree %d but must have degree at least %d", ctOut.Degree(), degree)
+	}
+	return
+}
+
+// checkMul checks the operands of a multiplication and, if relin is true, the relinearization key.
+func (eval *CheckedEvaluator) checkMul(op string, ctIn *Ciphertext, op1 Operand, ctOut *Ciphertext, relin bool) (err error) {
+	if err = eval.checkUnary(op, ctIn, ctOut, 2); err != nil {
+		return
+	}
+	if err = checkOperand(eval.params, op, op1); err != nil {
+		return
+	}
+
+	degree := ctIn.Degree() + op1.Degree()
+	if degree > 2 {
+		return rlwe.NewOperationError(op, rlwe.ErrDegree, "the sum of the input degrees is %d but cannot be larger than 2", degree)
+	}
+
+	if minDegree := utils.MaxInt(ctIn.Degree(), op1.Degree()); ctOut.Degree() < minDegree {
+		return rlwe.NewOperationError(op, rlwe.ErrDegree, "the output has degree %d but must have degree at least %d", ctOut.Degree(), minDegree)
+	}
+
+	if relin && degree == 2 {
+		level := utils.MinInt(utils.MinInt(ctIn.Level(), op1.Level()), ctOut.Level())
+		return eval.eval.GetRLWEEvaluator().CheckRelinearizationKey(op, level, degree)
+	}
+
+	return
+}
+
+// checkHoistedKeys checks that the rotation keys of the given rotations are available. Hoisted operations
+// decompose the input with the full modulus P and thus also require the keys to be generated at the maximum level of P.
+func (eval *CheckedEvaluator) checkHoistedKeys(op string, level int, rotations []int) (err error) {
+
+	rlweEval := eval.eval.GetRLWEEvaluator()
+
+	for _, k := range rotations {
+
+		galEl := eval.params.GaloisElementForColumnRotationBy(k)
+
+		if err = rlweEval.CheckRotationKeys(op, level, galEl); err != nil {
+			return
+		}
+
+		if galEl == 1 {
+			continue
+		}
+
+		if rtk, _ := rlweEval.Rtks.GetRotationKey(galEl); rtk.LevelP() != eval.params.PCount()-1 {
+			return rlwe.NewOperationError(op, rlwe.ErrLevel, "hoisted rotations require keys at levelP=%d but the key of galEl %d is at levelP=%d", eval.params.PCount()-1, galEl, rtk.LevelP())
+		}
+	}
+
+	return
+}
+
+// CheckedEncoder wraps an Encoder and returns an *rlwe.OperationError instead of panicking on malformed operands.
+type CheckedEncoder struct {
+	params Parameters
+	ecd    Encoder
+}
+
+// NewCheckedEncoder returns a CheckedEncoder wrapping ecd, an Encoder for the given parameters.
+func NewCheckedEncoder(params Parameters, ecd Encoder) *CheckedEncoder {
+	return &CheckedEncoder{params: params, ecd: ecd}
+}
+
+// Unchecked returns the wrapped Encoder.
+func (ecd *CheckedEncoder) Unchecked() Encoder {
+	return ecd.ecd
+}
+
+// ShallowCopy returns a CheckedEncoder wrapping a shallow copy of the wrapped Encoder.
+func (ecd *CheckedEncoder) ShallowCopy() *CheckedEncoder {
+	return &CheckedEncoder{params: ecd.params, ecd: ecd.ecd.ShallowCopy()}
+}
+
+// Encode is as Encoder.Encode, but returns an error instead of panicking.
+func (ecd *CheckedEncoder) Encode(values interface{}, plaintext *Plaintext, logSlots int) (err error) {
+	defer rlwe.Recover("Encode", &err)
+	if err = ecd.checkSlots("Encode", values, logSlots); err != nil {
+		return
+	}
+	if err = checkPlaintext(ecd.params, "Encode", plaintext); err != nil {
+		return
+	}
+	ecd.ecd.Encode(values, plaintext, logSlots)
+	return
+}
+
+// EncodeNew is as Encoder.EncodeNew, but returns an error instead of panicking.
+func (ecd *CheckedEncoder) EncodeNew(values interface{}, level int, scale float64, logSlots int) (plaintext *Plaintext, err error) {
+	defer rlwe.Recover("EncodeNew", &err)
+	if err = ecd.checkSlots("EncodeNew", values, logSlots); err != nil {
+		return
+	}
+	if level < 0 || level > ecd.params.MaxLevel() {
+		return nil, rlwe.NewOperationError("EncodeNew", rlwe.ErrLevel, "level=%d is not in [0, %d]", level, ecd.params.MaxLevel())
+	}
+	return ecd.ecd.EncodeNew(values, level, scale, logSlots), nil
+}
+
+// Decode is as Encoder.Decode, but returns an error instead of panicking.
+func (ecd *CheckedEncoder) Decode(plaintext *Plaintext, logSlots int) (res []complex128, err error) {
+	defer rlwe.Recover("Decode", &err)
+	if err = ecd.checkLogSlots("Decode", logSlots); err != nil {
+		return
+	}
+	if err = checkPlaintext(ecd.params, "Decode", plaintext); err != nil {
+		return
+	}
+	return ecd.ecd.Decode(plaintext, logSlots), nil
+}
+
+// EncodeCoeffs is as Encoder.EncodeCoeffs, but returns an error instead of panicking.
+func (ecd *CheckedEncoder) EncodeCoeffs(values []float64, plaintext *Plaintext) (err error) {
+	defer rlwe.Recover("EncodeCoeffs", &err)
+	if len(values) > ecd.params.N() {
+		return rlwe.NewOperationError("EncodeCoeffs", rlwe.ErrInvalidOperand, "#values=%d is larger than N=%d", len(values), ecd.params.N())
+	}
+	if err = checkPlaintext(ecd.params, "EncodeCoeffs", plaintext); err != nil {
+		return
+	}
+	ecd.ecd.EncodeCoeffs(values, plaintext)
+	return
+}
+
+// DecodeCoeffs is as Encoder.DecodeCoeffs, but returns an error instead of panicking.
+func (ecd *CheckedEncoder) DecodeCoeffs(plaintext *Plaintext) (res []float64, err error) {
+	defer rlwe.Recover("DecodeCoeffs", &err)
+	if err = checkPlaintext(ecd.params, "DecodeCoeffs", plaintext); err != nil {
+		return
+	}
+	return ecd.ecd.DecodeCoeffs(plaintext), nil
+}
+
+// checkLogSlots checks that logSlots is in [minLogSlots, MaxLogSlots].
+func (ecd *CheckedEncoder) checkLogSlots(op string, logSlots int) error {
+	if logSlots < minLogSlots || logSlots > ecd.params.MaxLogSlots() {
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "logSlots=%d is not in [%d, %d]", logSlots, minLogSlots, ecd.params.MaxLogSlots())
+	}
+	return nil
+}
+
+// checkSlots checks logSlots and that values is a []complex128 or a []float64 of at most 2^logSlots elements.
+func (ecd *CheckedEncoder) checkSlots(op string, values interface{}, logSlots int) (err error) {
+
+	if err = ecd.checkLogSlots(op, logSlots); err != nil {
+		return
+	}
+
+	var lenValues int
+	switch values := values.(type) {
+	case []complex128:
+		lenValues = len(values)
+	case []float64:
+		lenValues = len(values)
+	default:
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "values must be a []complex128 or a []float64 but is %T", values)
+	}
+
+	if lenValues > 1<<logSlots {
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "#values=%d is larger than the number of slots %d", lenValues, 1<<logSlots)
+	}
+
+	return
+}
+
+// CheckedEncryptor wraps an Encryptor and returns an *rlwe.OperationError instead of panicking on malformed operands.
+type CheckedEncryptor struct {
+	params Parameters
+	enc    Encryptor
+}
+
+// NewCheckedEncryptor returns a CheckedEncryptor wrapping enc, an Encryptor for the given parameters.
+func NewCheckedEncryptor(params Parameters, enc Encryptor) *CheckedEncryptor {
+	return &CheckedEncryptor{params: params, enc: enc}
+}
+
+// Unchecked returns the wrapped Encryptor.
+func (enc *CheckedEncryptor) Unchecked() Encryptor {
+	return enc.enc
+}
+
+// ShallowCopy returns a CheckedEncryptor wrapping a shallow copy of the wrapped Encryptor.
+func (enc *CheckedEncryptor) ShallowCopy() *CheckedEncryptor {
+	return &CheckedEncryptor{params: enc.params, enc: enc.enc.ShallowCopy()}
+}
+
+// Encrypt is as Encryptor.Encrypt, but returns an error instead of panicking.
+func (enc *CheckedEncryptor) Encrypt(plaintext *Plaintext, ciphertext *Ciphertext) (err error) {
+	defer rlwe.Recover("Encrypt", &err)
+	if err = checkPlaintext(enc.params, "Encrypt", plaintext); err != nil {
+		return
+	}
+	if err = checkCiphertext(enc.params, "Encrypt", ciphertext, 1, 1); err != nil {
+		return
+	}
+	enc.enc.Encrypt(plaintext, ciphertext)
+	return
+}
+
+// EncryptNew is as Encryptor.EncryptNew, but returns an error instead of panicking.
+func (enc *CheckedEncryptor) EncryptNew(plaintext *Plaintext) (ciphertext *Ciphertext, err error) {
+	defer rlwe.Recover("EncryptNew", &err)
+	if err = checkPlaintext(enc.params, "EncryptNew", plaintext); err != nil {
+		return
+	}
+	return enc.enc.EncryptNew(plaintext), nil
+}
+
+// EncryptZero is as Encryptor.EncryptZero, but returns an error instead of panicking.
+func (enc *CheckedEncryptor) EncryptZero(ciphertext *Ciphertext) (err error) {
+	defer rlwe.Recover("EncryptZero", &err)
+	if err = checkCiphertext(enc.params, "EncryptZero", ciphertext, 1, 1); err != nil {
+		return
+	}
+	enc.enc.EncryptZero(ciphertext)
+	return
+}
+
+// CheckedDecryptor wraps a Decryptor and returns an *rlwe.OperationError instead of panicking on malformed operands.
+type CheckedDecryptor struct {
+	params Parameters
+	dec    Decryptor
+}
+
+// NewCheckedDecryptor returns a CheckedDecryptor wrapping dec, a Decryptor for the given parameters.
+func NewCheckedDecryptor(params Parameters, dec Decryptor) *CheckedDecryptor {
+	return &CheckedDecryptor{params: params, dec: dec}
+}
+
+// Unchecked returns the wrapped Decryptor.
+func (dec *CheckedDecryptor) Unchecked() Decryptor {
+	return dec.dec
+}
+
+// ShallowCopy returns a CheckedDecryptor wrapping a shallow copy of the wrapped Decryptor.
+func (dec *CheckedDecryptor) ShallowCopy() *CheckedDecryptor {
+	return &CheckedDecryptor{params: dec.params, dec: dec.dec.ShallowCopy()}
+}
+
+// Decrypt is as Decryptor.Decrypt, but returns an error instead of panicking.
+func (dec *CheckedDecryptor) Decrypt(ciphertext *Ciphertext, plaintext *Plaintext) (err error) {
+	defer rlwe.Recover("Decrypt", &err)
+	if err = checkCiphertext(dec.params, "Decrypt", ciphertext, 0, 0xFF); err != nil {
+		return
+	}
+	if err = checkPlaintext(dec.params, "Decrypt", plaintext); err != nil {
+		return
+	}
+	dec.dec.Decrypt(ciphertext, plaintext)
+	return
+}
+
+// DecryptNew is as Decryptor.DecryptNew, but returns an error instead of panicking.
+func (dec *CheckedDecryptor) DecryptNew(ciphertext *Ciphertext) (plaintext *Plaintext, err error) {
+	defer rlwe.Recover("DecryptNew", &err)
+	if err = checkCiphertext(dec.params, "DecryptNew", ciphertext, 0, 0xFF); err != nil {
+		return
+	}
+	return dec.dec.DecryptNew(ciphertext), nil
+}
+
+// checkCiphertext is as rlwe.CheckCiphertext for a *Ciphertext.
+func checkCiphertext(params Parameters, op string, ct *Ciphertext, minDegree, maxDegree int) error {
+	if ct == nil {
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "ciphertext is nil")
+	}
+	return rlwe.CheckCiphertext(params.Parameters, op, ct.Ciphertext, minDegree, maxDegree)
+}
+
+// checkPlaintext is as rlwe.CheckPlaintext for a *Plaintext.
+func checkPlaintext(params Parameters, op string, pt *Plaintext) error {
+	if pt == nil {
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "plaintext is nil")
+	}
+	return rlwe.CheckPlaintext(params.Parameters, op, pt.Plaintext)
+}
+
+// checkOperand checks an Operand that is either a *Ciphertext or a *Plaintext.
+func checkOperand(params Parameters, op string, operand Operand) error {
+	switch operand := operand.(type) {
+	case *Ciphertext:
+		return checkCiphertext(params, op, operand, 0, 0xFF)
+	case *Plaintext:
+		return checkPlaintext(params, op, operand)
+	case nil:
+		return rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "operand is nil")
+	default:
+		return rlwe.CheckCiphertext(params.Parameters, op, operand.El(), 0, 0xFF)
+	}
+}
@@ -3,6 +3,7 @@ package ckks
 import (
 	"encoding/binary"
 	"errors"
+	"io"
 	"math"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
@@ -79,6 +80,9 @@ func (ct *Ciphertext) GetDataLen(WithMetaData bool) (dataLen int) {
 	return dataLen
 }
 
+// KindCiphertext is the kind of the envelopes of ckks.Ciphertext, see rlwe.Seal.
+const KindCiphertext rlwe.ObjectKind = "ckks.Ciphertext"
+
 // MarshalBinary encodes a Ciphertext on a byte slice. The total size
 // in byte is 4 + 8* N * numberModuliQ * (degree + 1).
 func (ct *Ciphertext) MarshalBinary() (data []byte, err error) {
@@ -95,6 +99,38 @@ func (ct *Ciphertext) MarshalBinary() (data []byte, err error) {
 	return append(dataScale, dataCt...), nil
 }
 
+// WriteTo writes the target Ciphertext on w with the layout of MarshalBinary, see rlwe.Ciphertext.WriteTo.
+func (ct *Ciphertext) WriteTo(w io.Writer) (n int64, err error) {
+
+	dataScale := make([]byte, 8)
+	binary.LittleEndian.PutUint64(dataScale, math.Float64bits(ct.Scale))
+
+	var inc int
+	if inc, err = w.Write(dataScale); err != nil {
+		return int64(inc), err
+	}
+
+	n, err = ct.Ciphertext.WriteTo(w)
+	return n + int64(inc), err
+}
+
+// ReadFrom reads a Ciphertext from r on the target Ciphertext, see rlwe.Ciphertext.ReadFrom.
+func (ct *Ciphertext) ReadFrom(r io.Reader) (n int64, err error) {
+
+	dataScale := make([]byte, 8)
+
+	var inc int
+	if inc, err = io.ReadFull(r, dataScale); err != nil {
+		return int64(inc), err
+	}
+
+	ct.Scale = math.Float64frombits(binary.LittleEndian.Uint64(dataScale))
+	ct.Ciphertext = new(rlwe.Ciphertext)
+
+	n, err = ct.Ciphertext.ReadFrom(r)
+	return n + int64(inc), err
+}
+
 // UnmarshalBinary decodes a previously marshaled Ciphertext on the target Ciphertext.
 func (ct *Ciphertext) UnmarshalBinary(data []byte) (err error) {
 	if len(data) < 10 { // cf. ct.GetDataLen()
@@ -1,10 +1,13 @@
 package ckks
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"math"
+	"math/bits"
 	"math/cmplx"
 	"runtime"
 	"testing"
@@ -116,6 +119,10 @@ func TestCKKS(t *testing.T) {
 			// testLinearTransform,
 			// testMarshaller,
 			testEncryptor,
+			testChecked,
+			testSecurity,
+			testFingerprint,
+			testHoistedTruncatedKeys,
 		} {
 			testSet(tc, t)
 			runtime.GC()
@@ -1392,6 +1399,171 @@ func testLinearTransform(tc *testContext, t *testing.T) {
 	})
 }
 
+// testHoistedTruncatedKeys tests the hoisted rotations with rotation keys generated with fewer P primes than the parameters.
+func testHoistedTruncatedKeys(tc *testContext, t *testing.T) {
+
+	logQ := make([]int, tc.params.QCount())
+	for i, qi := range tc.params.Q() {
+		logQ[i] = bits.Len64(qi)
+	}
+
+	// bit-size of the largest P prime of the parameters, as LogP is the total bit-size of P
+	logP := 0
+	for _, pi := range tc.params.P() {
+		logP = utils.MaxInt(logP, bits.Len64(pi))
+	}
+
+	params, err := NewParametersFromLiteral(ParametersLiteral{
+		LogN:         tc.params.LogN(),
+		LogQ:         logQ,
+		LogP:         []int{logP, logP},
+		RingType:     tc.params.RingType(),
+		LogSlots:     tc.params.LogSlots(),
+		DefaultScale: tc.params.DefaultScale(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, params.PCount())
+
+	if tc, err = genTestParams(params); err != nil {
+		t.Fatal(err)
+	}
+
+	levelP := 0
+
+	genRotationKeys := func(rotations []int, levelP int) *rlwe.RotationKeySet {
+		galEls := make([]uint64, len(rotations))
+		for i, k := range rotations {
+			galEls[i] = params.GaloisElementForColumnRotationBy(k)
+		}
+		return tc.kgen.GenRotationKeysLvl(galEls, tc.sk, params.MaxLevel(), levelP)
+	}
+
+	t.Run(GetTestName(params, "HoistedTruncatedKeys/RotateHoisted"), func(t *testing.T) {
+
+		rots := []int{1, -1, 4, -4, 63, -63}
+
+		eval := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: genRotationKeys(rots, levelP)})
+
+		values, _, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		ciphertexts := eval.RotateHoistedNew(ciphertext, rots)
+
+		for _, n := range rots {
+			verifyTestVectors(params, tc.encoder, tc.decryptor, utils.RotateComplex128Slice(values, n), ciphertexts[n], params.LogSlots(), 0, t)
+		}
+	})
+
+	t.Run(GetTestName(params, "HoistedTruncatedKeys/LinearTransform"), func(t *testing.T) {
+
+		diagMatrix := make(map[int][]complex128)
+		for _, k := range []int{-15, -1, 0, 1, 4} {
+			diagMatrix[k] = make([]complex128, params.Slots())
+			for i := range diagMatrix[k] {
+				diagMatrix[k][i] = complex(1, 0)
+			}
+		}
+
+		for _, BSGSRatio := range []float64{0, 1} {
+
+			var linTransf LinearTransform
+			if BSGSRatio == 0 {
+				linTransf = GenLinearTransform(tc.encoder, diagMatrix, params.MaxLevel(), params.DefaultScale(), params.LogSlots())
+			} else {
+				linTransf = GenLinearTransformBSGS(tc.encoder, diagMatrix, params.MaxLevel(), params.DefaultScale(), BSGSRatio, params.LogSlots())
+			}
+
+			eval := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: genRotationKeys(linTransf.Rotations(), levelP)})
+
+			values, _, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+			eval.LinearTransform(ciphertext, linTransf, []*Ciphertext{ciphertext})
+
+			want := make([]complex128, len(values))
+			for k := range diagMatrix {
+				rotated := utils.RotateComplex128Slice(values, k)
+				for i := range want {
+					want[i] += rotated[i]
+				}
+			}
+
+			verifyTestVectors(params, tc.encoder, tc.decryptor, want, ciphertext, params.LogSlots(), 0, t)
+		}
+	})
+
+	t.Run(GetTestName(params, "HoistedTruncatedKeys/InnerSum"), func(t *testing.T) {
+
+		batch, n := 2, 5
+
+		for _, log := range []bool{false, true} {
+
+			var rotations []int
+			if log {
+				rotations = params.RotationsForInnerSumLog(batch, n)
+			} else {
+				rotations = params.RotationsForInnerSum(batch, n)
+			}
+
+			eval := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: genRotationKeys(rotations, levelP)})
+
+			values, _, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+			if log {
+				eval.InnerSumLog(ciphertext, batch, n, ciphertext)
+			} else {
+				eval.InnerSum(ciphertext, batch, n, ciphertext)
+			}
+
+			want := make([]complex128, len(values))
+			for i := 0; i < n; i++ {
+				rotated := utils.RotateComplex128Slice(values, i*batch)
+				for j := range want {
+					want[j] += rotated[j]
+				}
+			}
+
+			verifyTestVectors(params, tc.encoder, tc.decryptor, want, ciphertext, params.LogSlots(), 0, t)
+		}
+	})
+
+	t.Run(GetTestName(params, "HoistedTruncatedKeys/MixedLevelP"), func(t *testing.T) {
+
+		rtks := genRotationKeys([]int{1}, levelP)
+		for galEl, swk := range genRotationKeys([]int{2}, params.PCount()-1).Keys {
+			rtks.Keys[galEl] = swk
+		}
+
+		eval := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rtks})
+
+		_, _, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		require.Panics(t, func() { eval.RotateHoistedNew(ciphertext, []int{1, 2}) })
+	})
+}
+
+func testFingerprint(testctx *testContext, t *testing.T) {
+
+	t.Run(GetTestName(testctx.params, "Envelope/Fingerprint"), func(t *testing.T) {
+
+		fp := testctx.params.Fingerprint()
+		require.NotEqual(t, testctx.params.Parameters.Fingerprint(), fp)
+
+		paramsOtherSlots, err := NewParameters(testctx.params.Parameters, testctx.params.LogSlots()-1, testctx.params.DefaultScale())
+		require.NoError(t, err)
+		require.NotEqual(t, fp, paramsOtherSlots.Fingerprint())
+
+		paramsOtherScale, err := NewParameters(testctx.params.Parameters, testctx.params.LogSlots(), 2*testctx.params.DefaultScale())
+		require.NoError(t, err)
+		require.NotEqual(t, fp, paramsOtherScale.Fingerprint())
+
+		ciphertext := NewCiphertextRandom(testctx.prng, testctx.params, 1, testctx.params.MaxLevel(), testctx.params.DefaultScale())
+		data, err := rlwe.Seal(KindCiphertext, fp, ciphertext)
+		require.NoError(t, err)
+		require.NoError(t, rlwe.Open(data, KindCiphertext, fp, new(Ciphertext)))
+		require.True(t, errors.Is(rlwe.Open(data, KindCiphertext, paramsOtherSlots.Fingerprint(), new(Ciphertext)), rlwe.ErrEnvelopeParameters))
+		require.True(t, errors.Is(rlwe.Open(data, KindCiphertext, paramsOtherScale.Fingerprint(), new(Ciphertext)), rlwe.ErrEnvelopeParameters))
+	})
+}
+
 func testMarshaller(testctx *testContext, t *testing.T) {
 
 	t.Run(GetTestName(testctx.params, "Marshaller/Parameters/Binary"), func(t *testing.T) {
@@ -1465,6 +1637,30 @@ func testMarshaller(testctx *testContext, t *testing.T) {
 			}
 		})
 
+		t.Run(GetTestName(testctx.params, "Stream"), func(t *testing.T) {
+
+			ciphertextWant := NewCiphertextRandom(testctx.prng, testctx.params, 2, testctx.params.MaxLevel(), testctx.params.DefaultScale())
+
+			marshalledCiphertext, err := ciphertextWant.MarshalBinary()
+			require.NoError(t, err)
+
+			buf := new(bytes.Buffer)
+			n, err := ciphertextWant.WriteTo(buf)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(marshalledCiphertext)), n)
+			require.Equal(t, marshalledCiphertext, buf.Bytes())
+
+			ciphertextTest := new(Ciphertext)
+			n, err = ciphertextTest.ReadFrom(buf)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(marshalledCiphertext)), n)
+			require.Equal(t, ciphertextWant.Scale, ciphertextTest.Scale)
+
+			for i := range ciphertextWant.Value {
+				require.True(t, testctx.ringQ.EqualLvl(ciphertextWant.Level(), ciphertextWant.Value[i], ciphertextTest.Value[i]))
+			}
+		})
+
 		t.Run(GetTestName(testctx.params, "Minimal"), func(t *testing.T) {
 
 			ciphertext := NewCiphertextRandom(testctx.prng, testctx.params, 0, testctx.params.MaxLevel(), testctx.params.DefaultScale())
@@ -1483,3 +1679,152 @@ func testMarshaller(testctx *testContext, t *testing.T) {
 		})
 	})
 }
+
+func testChecked(tc *testContext, t *testing.T) {
+
+	encoder := NewCheckedEncoder(tc.params, tc.encoder)
+	encryptor := NewCheckedEncryptor(tc.params, tc.encryptorSk)
+	decryptor := NewCheckedDecryptor(tc.params, tc.decryptor)
+	eval := NewCheckedEvaluator(tc.params, tc.evaluator)
+
+	values, _, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+	t.Run(GetTestName(tc.params, "Checked/Encoder"), func(t *testing.T) {
+		_, err := encoder.EncodeNew(values, tc.params.MaxLevel(), tc.params.DefaultScale(), tc.params.MaxLogSlots()+1)
+		require.True(t, errors.Is(err, rlwe.ErrInvalidOperand))
+
+		_, err = encoder.EncodeNew([]int{1}, tc.params.MaxLevel(), tc.params.DefaultScale(), tc.params.LogSlots())
+		require.True(t, errors.Is(err, rlwe.ErrInvalidOperand))
+
+		_, err = encoder.Decode(nil, tc.params.LogSlots())
+		require.True(t, errors.Is(err, rlwe.ErrInvalidOperand))
+
+		plaintext, err := encoder.EncodeNew(values, tc.params.MaxLevel(), tc.params.DefaultScale(), tc.params.LogSlots())
+		require.NoError(t, err)
+		require.NoError(t, encryptor.Encrypt(plaintext, NewCiphertext(tc.params, 1, plaintext.Level(), plaintext.Scale)))
+	})
+
+	t.Run(GetTestName(tc.params, "Checked/InvalidOperands"), func(t *testing.T) {
+		var err error
+		require.NotPanics(t, func() { err = eval.Add(ciphertext, (*Ciphertext)(nil), ciphertext) })
+		require.True(t, errors.Is(err, rlwe.ErrInvalidOperand))
+
+		require.True(t, errors.Is(eval.Mul(ciphertext, NewCiphertext(tc.params, 2, ciphertext.Level(), ciphertext.Scale), ciphertext), rlwe.ErrDegree))
+		require.True(t, errors.Is(eval.DropLevel(ciphertext, ciphertext.Level()+1), rlwe.ErrLevel))
+		require.True(t, errors.Is(encryptor.EncryptZero(nil), rlwe.ErrInvalidOperand))
+
+		_, err = decryptor.DecryptNew(&Ciphertext{Ciphertext: &rlwe.Ciphertext{}})
+		require.True(t, errors.Is(err, rlwe.ErrInvalidOperand))
+
+		// a ciphertext with a truncated limb other than the first one
+		ctTruncated := NewCiphertext(tc.params, 1, tc.params.MaxLevel(), tc.params.DefaultScale())
+		ctTruncated.Value[1].Coeffs[ctTruncated.Level()] = ctTruncated.Value[1].Coeffs[ctTruncated.Level()][:10]
+		require.NotPanics(t, func() { err = eval.Add(ctTruncated, ctTruncated, ctTruncated) })
+		require.True(t, errors.Is(err, rlwe.ErrInvalidOperand))
+	})
+
+	t.Run(GetTestName(tc.params, "Checked/MissingKeys"), func(t *testing.T) {
+		var err error
+		require.NotPanics(t, func() { err = eval.Rotate(ciphertext, 1, ciphertext) })
+		require.True(t, errors.Is(err, rlwe.ErrMissingRotationKey))
+
+		require.True(t, errors.Is(eval.InnerSum(ciphertext, 1, 4, ciphertext), rlwe.ErrMissingRotationKey))
+
+		if tc.params.RingType() == ring.ConjugateInvariant {
+			require.True(t, errors.Is(eval.Conjugate(ciphertext, ciphertext), rlwe.ErrRingType))
+			require.True(t, errors.Is(eval.MultByi(ciphertext, ciphertext), rlwe.ErrRingType))
+		} else {
+			require.True(t, errors.Is(eval.Conjugate(ciphertext, ciphertext), rlwe.ErrMissingRotationKey))
+		}
+
+		if tc.rlk == nil {
+			require.True(t, errors.Is(eval.MulRelin(ciphertext, ciphertext, ciphertext), rlwe.ErrMissingRelinearizationKey))
+		}
+	})
+
+	t.Run(GetTestName(tc.params, "Checked/Rotate"), func(t *testing.T) {
+
+		if tc.params.PCount() == 0 {
+			t.Skip("#Pi is zero")
+		}
+
+		rotations := []int{1, 2}
+		rtks := tc.kgen.GenRotationKeysForRotations(rotations, false, tc.sk)
+		evalRot := eval.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rtks})
+
+		ctOut := NewCiphertext(tc.params, 1, ciphertext.Level(), ciphertext.Scale)
+		require.NoError(t, evalRot.Rotate(ciphertext, 1, ctOut))
+
+		valuesWant := utils.RotateComplex128Slice(values, 1)
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, valuesWant, ctOut, tc.params.LogSlots(), 0, t)
+
+		require.NoError(t, evalRot.InnerSum(ciphertext, 1, 3, ctOut))
+
+		// Hoisted rotations decompose with the full modulus P and thus reject keys with a smaller levelP.
+		if tc.params.PCount() > 1 {
+			galEls := make([]uint64, len(rotations))
+			for i, k := range rotations {
+				galEls[i] = tc.params.GaloisElementForColumnRotationBy(k)
+			}
+			rtks = tc.kgen.GenRotationKeysLvl(galEls, tc.sk, tc.params.MaxLevel(), 0)
+			evalRot = eval.WithKey(rlwe.EvaluationKey{Rtks: rtks})
+			require.NoError(t, evalRot.Rotate(ciphertext, 1, ctOut))
+			require.True(t, errors.Is(evalRot.InnerSum(ciphertext, 1, 3, ctOut), rlwe.ErrLevel))
+		}
+	})
+}
+
+func testSecurity(tc *testContext, t *testing.T) {
+
+	t.Run(GetTestName(tc.params, "Security/Parameters"), func(t *testing.T) {
+		est, err := tc.params.EstimateSecurity()
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, est.Bits(), 125.0)
+	})
+
+	t.Run(GetTestName(tc.params, "Security/Builder"), func(t *testing.T) {
+
+		spec := ParametersSpec{
+			LogN:     tc.params.LogN(),
+			Depth:    tc.params.MaxLevel() / 2,
+			LogScale: int(math.Round(math.Log2(tc.params.DefaultScale()))),
+			RingType: tc.params.RingType(),
+		}
+
+		pl, err := BuildParametersLiteral(spec)
+		require.NoError(t, err)
+
+		params, err := NewParametersFromLiteral(pl)
+		require.NoError(t, err)
+		require.Equal(t, spec.Depth, params.MaxLevel())
+		require.Equal(t, 1, params.PCount())
+		require.Equal(t, math.Exp2(float64(spec.LogScale)), params.DefaultScale())
+
+		est, err := params.EstimateSecurity()
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, est.Bits(), 128.0)
+
+		// insecure choices are refused
+		insecure := spec
+		insecure.Depth = 2 * tc.params.LogQP() / spec.LogScale
+		_, err = BuildParametersLiteral(insecure)
+		require.Error(t, err)
+
+		insecure = spec
+		insecure.Depth = tc.params.MaxLevel()
+		insecure.Security = 256
+		_, err = BuildParametersLiteral(insecure)
+		require.Error(t, err)
+
+		insecure = spec
+		insecure.H = 2
+		_, err = BuildParametersLiteral(insecure)
+		require.Error(t, err)
+
+		// sparse secrets are refused, even with a large weight
+		insecure = spec
+		insecure.H = 1<<(spec.LogN-1) - 1
+		_, err = BuildParametersLiteral(insecure)
+		require.Error(t, err)
+	})
+}
@@ -214,12 +214,13 @@ func (eval *evaluator) GetRLWEEvaluator() *rlwe.Evaluator {
 	return eval.Evaluator
 }
 
-func (eval *evaluator) PermuteNTTIndexesForKey(rtks *rlwe.RotationKeySet) *map[uint64][]uint64 {
+func (eval *evaluator) PermuteNTTIndexesForKey(rtks rlwe.RotationKeyProvider) *map[uint64][]uint64 {
 	if rtks == nil {
 		return &map[uint64][]uint64{}
 	}
-	PermuteNTTIndex := make(map[uint64][]uint64, len(rtks.Keys))
-	for galEl := range rtks.Keys {
+	galEls := rtks.GaloisElements()
+	PermuteNTTIndex := make(map[uint64][]uint64, len(galEls))
+	for _, galEl := range galEls {
 		PermuteNTTIndex[galEl] = eval.params.RingQ().PermuteNTTIndex(galEl)
 	}
 	return &PermuteNTTIndex
@@ -227,7 +228,7 @@ func (eval *evaluator) PermuteNTTIndexesForKey(rtks *rlwe.RotationKeySet) *map[u
 
 func (eval *evaluator) checkBinary(op0, op1, opOut Operand, opOutMinDegree int) {
 	if op0 == nil || op1 == nil || opOut == nil {
-		panic("cannot checkBinary: operands cannot be nil")
+		panic(rlwe.NewOperationError("checkBinary", rlwe.ErrInvalidOperand, "operands cannot be nil"))
 	}
 
 	if op0.Degree()+op1.Degree() == 0 {
@@ -235,7 +236,7 @@ func (eval *evaluator) checkBinary(op0, op1, opOut Operand, opOutMinDegree int)
 	}
 
 	if opOut.Degree() < opOutMinDegree {
-		panic("cannot checkBinary: receiver operand degree is too small")
+		panic(rlwe.NewOperationError("checkBinary", rlwe.ErrDegree, "receiver operand degree is too small"))
 	}
 
 	for _, pol := range op0.El().Value {
@@ -859,7 +860,7 @@ func (eval *evaluator) MultByGaussianIntegerAndAdd(ct0 *Ciphertext, cReal, cImag
 func (eval *evaluator) MultByiNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
 
 	if eval.params.RingType() == ring.ConjugateInvariant {
-		panic("method MultByi is not supported when params.RingType() == ring.ConjugateInvariant")
+		panic(rlwe.NewOperationError("MultByi", rlwe.ErrRingType, "method is not supported when params.RingType() == ring.ConjugateInvariant"))
 	}
 
 	ctOut = NewCiphertext(eval.params, 1, ct0.Level(), ct0.Scale)
@@ -872,7 +873,7 @@ func (eval *evaluator) MultByiNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
 func (eval *evaluator) MultByi(ct0 *Ciphertext, ctOut *Ciphertext) {
 
 	if eval.params.RingType() == ring.ConjugateInvariant {
-		panic("method MultByi is not supported when params.RingType() == ring.ConjugateInvariant")
+		panic(rlwe.NewOperationError("MultByi", rlwe.ErrRingType, "method is not supported when params.RingType() == ring.ConjugateInvariant"))
 	}
 
 	var level = utils.MinInt(ct0.Level(), ctOut.Level())
@@ -911,7 +912,7 @@ func (eval *evaluator) MultByi(ct0 *Ciphertext, ctOut *Ciphertext) {
 func (eval *evaluator) DivByiNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
 
 	if eval.params.RingType() == ring.ConjugateInvariant {
-		panic("method DivByi is not supported when params.RingType() == ring.ConjugateInvariant")
+		panic(rlwe.NewOperationError("DivByi", rlwe.ErrRingType, "method is not supported when params.RingType() == ring.ConjugateInvariant"))
 	}
 
 	ctOut = NewCiphertext(eval.params, 1, ct0.Level(), ct0.Scale)
@@ -924,7 +925,7 @@ func (eval *evaluator) DivByiNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
 func (eval *evaluator) DivByi(ct0 *Ciphertext, ctOut *Ciphertext) {
 
 	if eval.params.RingType() == ring.ConjugateInvariant {
-		panic("method DivByi is not supported when params.RingType() == ring.ConjugateInvariant")
+		panic(rlwe.NewOperationError("DivByi", rlwe.ErrRingType, "method is not supported when params.RingType() == ring.ConjugateInvariant"))
 	}
 
 	var level = utils.MinInt(ct0.Level(), ctOut.Level())
@@ -1144,7 +1145,7 @@ func (eval *evaluator) mulRelin(ctIn *Ciphertext, op1 Operand, relin bool, ctOut
 	level := utils.MinInt(utils.MinInt(ctIn.Level(), op1.Level()), ctOut.Level())
 
 	if ctIn.Degree()+op1.Degree() > 2 {
-		panic("cannot MulRelin: the sum of the input elements' total degree cannot be larger than 2")
+		panic(rlwe.NewOperationError("MulRelin", rlwe.ErrDegree, "the sum of the input elements' total degree cannot be larger than 2"))
 	}
 
 	ctOut.Scale = ctIn.ScalingFactor() * op1.ScalingFactor()
@@ -1247,7 +1248,7 @@ func (eval *evaluator) mulRelinAndAdd(ctIn *Ciphertext, op1 Operand, relin bool,
 	level := utils.MinInt(utils.MinInt(ctIn.Level(), op1.Level()), ctOut.Level())
 
 	if ctIn.Degree()+op1.Degree() > 2 {
-		panic("cannot MulRelinAndAdd: the sum of the input elements' degree cannot be larger than 2")
+		panic(rlwe.NewOperationError("MulRelinAndAdd", rlwe.ErrDegree, "the sum of the input elements' degree cannot be larger than 2"))
 	}
 
 	if ctIn.El() == ctOut.El() || op1.El() == ctOut.El() {
@@ -1369,7 +1370,7 @@ func (eval *evaluator) Rotate(ct0 *Ciphertext, k int, ctOut *Ciphertext) {
 func (eval *evaluator) ConjugateNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
 
 	if eval.params.RingType() == ring.ConjugateInvariant {
-		panic("cannot ConjugateNew: method is not supported when params.RingType() == ring.ConjugateInvariant")
+		panic(rlwe.NewOperationError("ConjugateNew", rlwe.ErrRingType, "method is not supported when params.RingType() == ring.ConjugateInvariant"))
 	}
 
 	ctOut = NewCiphertext(eval.params, ct0.Degree(), ct0.Level(), ct0.Scale)
@@ -1382,7 +1383,7 @@ func (eval *evaluator) ConjugateNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
 func (eval *evaluator) Conjugate(ct0 *Ciphertext, ctOut *Ciphertext) {
 
 	if eval.params.RingType() == ring.ConjugateInvariant {
-		panic("cannot Conjugate: method is not supported when params.RingType() == ring.ConjugateInvariant")
+		panic(rlwe.NewOperationError("Conjugate", rlwe.ErrRingType, "method is not supported when params.RingType() == ring.ConjugateInvariant"))
 	}
 
 	eval.Automorphism(ct0.Ciphertext, eval.params.GaloisElementForRowRotation(), ctOut.Ciphertext)
@@ -1,6 +1,9 @@
 package ckks
 
-import "github.com/tuneinsight/lattigo/v3/rlwe"
+import (
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
 
 // KeyGenerator is an interface for the generation of CKKS keys.
 type KeyGenerator interface {
@@ -36,6 +39,12 @@ func NewKeyGenerator(params Parameters) KeyGenerator {
 	return &keyGenerator{rlwe.NewKeyGenerator(params.Parameters), &params}
 }
 
+// NewKeyGeneratorWithPRNG creates a KeyGenerator instance from the CKKS parameters drawing its randomness from prng.
+// See rlwe.NewKeyGeneratorWithPRNG.
+func NewKeyGeneratorWithPRNG(params Parameters, prng utils.PRNG) KeyGenerator {
+	return &keyGenerator{rlwe.NewKeyGeneratorWithPRNG(params.Parameters, prng), &params}
+}
+
 // NewSecretKey returns an allocated CKKS secret key with zero values.
 func NewSecretKey(params Parameters) (sk *rlwe.SecretKey) {
 	return rlwe.NewSecretKey(params.Parameters)
@@ -4,6 +4,7 @@ import (
 	"runtime"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
 	"github.com/tuneinsight/lattigo/v3/utils"
 )
@@ -54,13 +55,48 @@ func (eval *evaluator) RotateHoistedNew(ctIn *Ciphertext, rotations []int) (ctOu
 // It is much faster than sequential calls to Rotate.
 func (eval *evaluator) RotateHoisted(ctIn *Ciphertext, rotations []int, ctOut map[int]*Ciphertext) {
 	levelQ := ctIn.Level()
-	eval.DecomposeNTT(levelQ, eval.params.PCount()-1, eval.params.PCount(), ctIn.Value[1], eval.BuffDecompQP)
+	levelP := eval.hoistedLevelP("RotateHoisted", levelQ, rotations)
+	eval.DecomposeNTT(levelQ, levelP, levelP+1, ctIn.Value[1], eval.BuffDecompQP)
 	for _, i := range rotations {
 		eval.AutomorphismHoisted(levelQ, ctIn.Ciphertext, eval.BuffDecompQP, eval.params.GaloisElementForColumnRotationBy(i), ctOut[i].Ciphertext)
 		ctOut[i].Scale = ctIn.Scale
 	}
 }
 
+// hoistedLevelP returns the levelP of the rotation keys of the given rotations, at which the hoisted rotations must
+// decompose their input, or params.PCount()-1 if none of these keys is available. It grows the decomposition buffer
+// if the keys have fewer P primes than the parameters. It panics if the keys do not share the same levelP.
+func (eval *evaluator) hoistedLevelP(method string, levelQ int, rotations []int) (levelP int) {
+
+	levelP = eval.params.PCount() - 1
+
+	if eval.Rtks != nil {
+		var found bool
+		for _, k := range rotations {
+
+			galEl := eval.params.GaloisElementForColumnRotationBy(k)
+			if galEl == 1 {
+				continue
+			}
+
+			if rtk, ok := eval.Rtks.GetRotationKey(galEl); ok {
+				if found && rtk.LevelP() != levelP {
+					panic(rlwe.NewOperationError(method, rlwe.ErrLevel, "the rotation keys have different levelP %d and %d", levelP, rtk.LevelP()))
+				}
+				levelP, found = rtk.LevelP(), true
+			}
+		}
+	}
+
+	if levelP > -1 {
+		for len(eval.BuffDecompQP) < eval.params.DecompRNS(levelQ, levelP) {
+			eval.BuffDecompQP = append(eval.BuffDecompQP, eval.params.RingQP().NewPoly())
+		}
+	}
+
+	return
+}
+
 // LinearTransform is a type for linear transformations on ciphertexts.
 // It stores a plaintext matrix diagonalized in diagonal form and
 // can be evaluated on a ciphertext by using the evaluator.LinearTransform method.
@@ -396,7 +432,13 @@ func (eval *evaluator) LinearTransformNew(ctIn *Ciphertext, linearTransform inte
 
 		minLevel := utils.MinInt(maxLevel, ctIn.Level())
 
-		eval.DecomposeNTT(minLevel, eval.params.PCount()-1, eval.params.PCount(), ctIn.Value[1], eval.BuffDecompQP)
+		var rotations []int
+		for _, LT := range LTs {
+			rotations = append(rotations, LT.Rotations()...)
+		}
+
+		levelP := eval.hoistedLevelP("LinearTransform", minLevel, rotations)
+		eval.DecomposeNTT(minLevel, levelP, levelP+1, ctIn.Value[1], eval.BuffDecompQP)
 
 		for i, LT := range LTs {
 			ctOut[i] = NewCiphertext(eval.params, 1, minLevel, ctIn.Scale)
@@ -411,7 +453,8 @@ func (eval *evaluator) LinearTransformNew(ctIn *Ciphertext, linearTransform inte
 	case LinearTransform:
 
 		minLevel := utils.MinInt(LTs.Level, ctIn.Level())
-		eval.DecomposeNTT(minLevel, eval.params.PCount()-1, eval.params.PCount(), ctIn.Value[1], eval.BuffDecompQP)
+		levelP := eval.hoistedLevelP("LinearTransform", minLevel, LTs.Rotations())
+		eval.DecomposeNTT(minLevel, levelP, levelP+1, ctIn.Value[1], eval.BuffDecompQP)
 
 		ctOut = []*Ciphertext{NewCiphertext(eval.params, 1, minLevel, ctIn.Scale)}
 
@@ -440,7 +483,13 @@ func (eval *evaluator) LinearTransform(ctIn *Ciphertext, linearTransform interfa
 
 		minLevel := utils.MinInt(maxLevel, ctIn.Level())
 
-		eval.DecomposeNTT(minLevel, eval.params.PCount()-1, eval.params.PCount(), ctIn.Value[1], eval.BuffDecompQP)
+		var rotations []int
+		for _, LT := range LTs {
+			rotations = append(rotations, LT.Rotations()...)
+		}
+
+		levelP := eval.hoistedLevelP("LinearTransform", minLevel, rotations)
+		eval.DecomposeNTT(minLevel, levelP, levelP+1, ctIn.Value[1], eval.BuffDecompQP)
 
 		for i, LT := range LTs {
 			if LT.N1 == 0 {
@@ -452,7 +501,8 @@ func (eval *evaluator) LinearTransform(ctIn *Ciphertext, linearTransform interfa
 
 	case LinearTransform:
 		minLevel := utils.MinInt(LTs.Level, ctIn.Level())
-		eval.DecomposeNTT(minLevel, eval.params.PCount()-1, eval.params.PCount(), ctIn.Value[1], eval.BuffDecompQP)
+		levelP := eval.hoistedLevelP("LinearTransform", minLevel, LTs.Rotations())
+		eval.DecomposeNTT(minLevel, levelP, levelP+1, ctIn.Value[1], eval.BuffDecompQP)
 		if LTs.N1 == 0 {
 			eval.MultiplyByDiagMatrix(ctIn, LTs, eval.BuffDecompQP, ctOut[0])
 		} else {
@@ -501,11 +551,10 @@ func (eval *evaluator) Average(ctIn *Ciphertext, logBatchSize int, ctOut *Cipher
 func (eval *evaluator) InnerSumLog(ctIn *Ciphertext, batchSize, n int, ctOut *Ciphertext) {
 
 	ringQ := eval.params.RingQ()
-	ringP := eval.params.RingP()
 	ringQP := eval.params.RingQP()
 
 	levelQ := ctIn.Level()
-	levelP := len(ringP.Modulus) - 1
+	levelP := eval.hoistedLevelP("InnerSumLog", levelQ, eval.params.RotationsForInnerSumLog(batchSize, n))
 
 	ctOut.Resize(ctOut.Degree(), levelQ)
 	ctOut.Scale = ctIn.Scale
@@ -624,7 +673,7 @@ func (eval *evaluator) InnerSum(ctIn *Ciphertext, batchSize, n int, ctOut *Ciphe
 	ringQP := eval.params.RingQP()
 
 	levelQ := ctIn.Level()
-	levelP := len(ringP.Modulus) - 1
+	levelP := eval.hoistedLevelP("InnerSum", levelQ, eval.params.RotationsForInnerSum(batchSize, n))
 
 	QiOverF := eval.params.QiOverflowMargin(levelQ) >> 1
 	PiOverF := eval.params.PiOverflowMargin(levelP) >> 1
@@ -737,6 +786,7 @@ func (eval *evaluator) Replicate(ctIn *Ciphertext, batchSize, n int, ctOut *Ciph
 // MultiplyByDiagMatrix multiplies the ciphertext "ctIn" by the plaintext matrix "matrix" and returns the result on the ciphertext
 // "ctOut". Memory buffers for the decomposed ciphertext BuffDecompQP, BuffDecompQP must be provided, those are list of poly of ringQ and ringP
 // respectively, each of size params.DecompRNS(params.QCount()-1, params.PCount()-1)).
+// The decomposition must have been computed at the levelP of the rotation keys of the matrix, which must all share the same levelP.
 // The naive approach is used (single hoisting and no baby-step giant-step), which is faster than MultiplyByDiagMatrixBSGS
 // for matrix of only a few non-zero diagonals but uses more keys.
 func (eval *evaluator) MultiplyByDiagMatrix(ctIn *Ciphertext, matrix LinearTransform, BuffDecompQP []ringqp.Poly, ctOut *Ciphertext) {
@@ -746,7 +796,7 @@ func (eval *evaluator) MultiplyByDiagMatrix(ctIn *Ciphertext, matrix LinearTrans
 	ringQP := eval.params.RingQP()
 
 	levelQ := utils.MinInt(ctOut.Level(), utils.MinInt(ctIn.Level(), matrix.Level))
-	levelP := len(ringP.Modulus) - 1
+	levelP := eval.hoistedLevelP("MultiplyByDiagMatrix", levelQ, matrix.Rotations())
 
 	ctOut.Resize(ctOut.Degree(), levelQ)
 
@@ -780,9 +830,9 @@ func (eval *evaluator) MultiplyByDiagMatrix(ctIn *Ciphertext, matrix LinearTrans
 
 			galEl := eval.params.GaloisElementForColumnRotationBy(k)
 
-			rtk, generated := eval.Rtks.Keys[galEl]
+			rtk, generated := eval.Rtks.GetRotationKey(galEl)
 			if !generated {
-				panic("cannot MultiplyByDiagMatrix: switching key not available")
+				panic(rlwe.NewOperationError("MultiplyByDiagMatrix", rlwe.ErrMissingRotationKey, "switching key not available"))
 			}
 
 			index := eval.PermuteNTTIndex[galEl]
@@ -840,6 +890,7 @@ func (eval *evaluator) MultiplyByDiagMatrix(ctIn *Ciphertext, matrix LinearTrans
 // MultiplyByDiagMatrixBSGS multiplies the ciphertext "ctIn" by the plaintext matrix "matrix" and returns the result on the ciphertext
 // "ctOut". Memory buffers for the decomposed ciphertext BuffDecompQP, BuffDecompQP must be provided, those are list of poly of ringQ and ringP
 // respectively, each of size params.DecompRNS(params.QCount()-1, params.PCount()-1)).
+// The decomposition must have been computed at the levelP of the rotation keys of the matrix, which must all share the same levelP.
 // The BSGS approach is used (double hoisting with baby-step giant-step), which is faster than MultiplyByDiagMatrix
 // for matrix with more than a few non-zero diagonals and uses much less keys.
 func (eval *evaluator) MultiplyByDiagMatrixBSGS(ctIn *Ciphertext, matrix LinearTransform, PoolDecompQP []ringqp.Poly, ctOut *Ciphertext) {
@@ -849,7 +900,7 @@ func (eval *evaluator) MultiplyByDiagMatrixBSGS(ctIn *Ciphertext, matrix LinearT
 	ringQP := eval.params.RingQP()
 
 	levelQ := utils.MinInt(ctOut.Level(), utils.MinInt(ctIn.Level(), matrix.Level))
-	levelP := len(ringP.Modulus) - 1
+	levelP := eval.hoistedLevelP("MultiplyByDiagMatrixBSGS", levelQ, matrix.Rotations())
 
 	ctOut.Resize(ctOut.Degree(), levelQ)
 
@@ -939,9 +990,9 @@ func (eval *evaluator) MultiplyByDiagMatrixBSGS(ctIn *Ciphertext, matrix LinearT
 
 			galEl := eval.params.GaloisElementForColumnRotationBy(j)
 
-			rtk, generated := eval.Rtks.Keys[galEl]
+			rtk, generated := eval.Rtks.GetRotationKey(galEl)
 			if !generated {
-				panic("cannot MultiplyByDiagMatrixBSGS: switching key not available")
+				panic(rlwe.NewOperationError("MultiplyByDiagMatrixBSGS", rlwe.ErrMissingRotationKey, "switching key not available"))
 			}
 
 			rotIndex := eval.PermuteNTTIndex[galEl]
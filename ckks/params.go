@@ -11,6 +11,7 @@ import (
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/utils"
+	"golang.org/x/crypto/blake2b"
 )
 
 var minLogSlots = 0
@@ -582,6 +583,17 @@ func (p Parameters) MarshalBinarySize() int {
 	return p.Parameters.MarshalBinarySize() + 9
 }
 
+// Fingerprint returns the BLAKE2b-256 hash of the binary encoding of the parameters. Unlike the fingerprint
+// of the embedded rlwe.Parameters, it also depends on LogSlots and DefaultScale, so that the envelopes
+// of the objects generated under other ckks parameters are rejected by rlwe.Open.
+func (p Parameters) Fingerprint() (fp rlwe.Fingerprint) {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return blake2b.Sum256(data)
+}
+
 // MarshalJSON returns a JSON representation of this parameter set. See `Marshal` from the `encoding/json` package.
 func (p Parameters) MarshalJSON() ([]byte, error) {
 	return json.Marshal(ParametersLiteral{
@@ -0,0 +1,147 @@
+package ckks
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// ParametersSpec is a high level specification of CKKS parameters, from which BuildParametersLiteral generates
+// the moduli chain of a ParametersLiteral meeting a target security level.
+//
+// Users must set the polynomial degree (in log_2, LogN), the number of rescalings (Depth) and the precision per
+// level (in log_2, LogScale). The other fields are optional and are set to their default values if left unset.
+type ParametersSpec struct {
+	LogN     int
+	Depth    int // number of moduli consumed by rescalings
+	LogScale int // bit-size of the moduli consumed by rescalings and log2 of the default scale
+	LogQ0    int // bit-size of the first modulus, min(LogScale+10, 60) by default
+	LogP     int // bit-size of the special moduli, min(max(LogQ0, LogScale)+1, 61) by default
+	PCount   int // number of special moduli, 1 by default
+	Pow2Base int
+	Security int // target classical bit security, 128 by default
+	Sigma    float64
+	H        int
+	RingType ring.Type
+	LogSlots int
+}
+
+// BuildParametersLiteral generates the moduli chain of the ParametersLiteral specified by spec with rlwe.GenModuli and
+// checks that it meets the target security level of spec. The moduli chain has a first modulus of LogQ0 bits followed by
+// Depth moduli of LogScale bits, and PCount special moduli of LogP bits. The default scale is set to 2^LogScale.
+//
+// It returns an error if the parameters are not secure: their security estimated by rlwe.EstimateSecurity must be at
+// least the target security, and, if the target security is 128, 192 or 256 bits, the parameters must also be within the
+// bounds of the tables of the HE standard at this level when these tables cover them. Since rlwe.EstimateSecurity has
+// no model of the hybrid attacks on sparse secrets, it also returns an error if H is smaller than N/2.
+func BuildParametersLiteral(spec ParametersSpec) (pl ParametersLiteral, err error) {
+
+	if spec.LogN < rlwe.MinLogN || spec.LogN > rlwe.MaxLogN {
+		return pl, fmt.Errorf("ckks.BuildParametersLiteral: invalid LogN: %d is not in [%d, %d]", spec.LogN, rlwe.MinLogN, rlwe.MaxLogN)
+	}
+
+	if spec.Depth < 0 || spec.LogScale <= 0 || spec.LogScale > rlwe.MaxModuliSize {
+		return pl, fmt.Errorf("ckks.BuildParametersLiteral: invalid depth %d or scale 2^%d", spec.Depth, spec.LogScale)
+	}
+
+	if spec.LogQ0 == 0 {
+		spec.LogQ0 = utils.MinInt(spec.LogScale+10, rlwe.MaxModuliSize)
+	}
+
+	if spec.LogP == 0 {
+		spec.LogP = utils.MinInt(utils.MaxInt(spec.LogQ0, spec.LogScale)+1, rlwe.MaxModuliSize+1)
+	}
+
+	if spec.PCount == 0 {
+		spec.PCount = 1
+	}
+
+	if spec.Security == 0 {
+		spec.Security = 128
+	}
+
+	logQ := make([]int, spec.Depth+1)
+	logQ[0] = spec.LogQ0
+	for i := 1; i < len(logQ); i++ {
+		logQ[i] = spec.LogScale
+	}
+
+	logP := make([]int, spec.PCount)
+	for i := range logP {
+		logP[i] = spec.LogP
+	}
+
+	pl = ParametersLiteral{
+		LogN:         spec.LogN,
+		Pow2Base:     spec.Pow2Base,
+		Sigma:        spec.Sigma,
+		H:            spec.H,
+		RingType:     spec.RingType,
+		LogSlots:     spec.LogSlots,
+		DefaultScale: math.Exp2(float64(spec.LogScale)),
+	}
+
+	// Checks the security on the sizes of the moduli before generating them
+	logQP := spec.LogQ0 + spec.Depth*spec.LogScale + spec.PCount*spec.LogP
+	if err = checkSecurity(spec, pl, float64(logQP)); err != nil {
+		return ParametersLiteral{}, err
+	}
+
+	switch spec.RingType {
+	case ring.Standard:
+		pl.Q, pl.P, err = rlwe.GenModuli(spec.LogN, logQ, logP)
+	case ring.ConjugateInvariant:
+		pl.Q, pl.P, err = rlwe.GenModuli(spec.LogN+1, logQ, logP)
+	default:
+		err = fmt.Errorf("invalid ring.Type, must be ring.ConjugateInvariant or ring.Standard")
+	}
+
+	if err != nil {
+		return ParametersLiteral{}, fmt.Errorf("ckks.BuildParametersLiteral: %w", err)
+	}
+
+	params, err := NewParametersFromLiteral(pl)
+	if err != nil {
+		return ParametersLiteral{}, fmt.Errorf("ckks.BuildParametersLiteral: %w", err)
+	}
+
+	if err = checkSecurity(spec, pl, params.SecurityInstance().LogQP); err != nil {
+		return ParametersLiteral{}, err
+	}
+
+	return
+}
+
+// checkSecurity checks that the parameters pl with a modulus of logQP bits meet the target security of spec.
+func checkSecurity(spec ParametersSpec, pl ParametersLiteral, logQP float64) (err error) {
+
+	inst := rlwe.SecurityInstance{LogN: spec.LogN, LogQP: logQP, Sigma: spec.Sigma, Secret: rlwe.UniformTernary}
+
+	if inst.Sigma == 0 {
+		inst.Sigma = rlwe.DefaultSigma
+	}
+
+	if pl.H != 0 && pl.H < 1<<(spec.LogN-1) {
+		return fmt.Errorf("ckks.BuildParametersLiteral: unsupported sparse secret: the security of H=%d < N/2 cannot be estimated", pl.H)
+	}
+
+	est, err := rlwe.EstimateSecurity(inst)
+	if err != nil {
+		return fmt.Errorf("ckks.BuildParametersLiteral: %w", err)
+	}
+
+	if est.Bits() < float64(spec.Security) {
+		return fmt.Errorf("ckks.BuildParametersLiteral: insecure parameters: estimated security of %.1f bits for LogN=%d and LogQP=%.1f is below %d bits",
+			est.Bits(), spec.LogN, logQP, spec.Security)
+	}
+
+	if est.Standard >= 0 && (spec.Security == 128 || spec.Security == 192 || spec.Security == 256) && est.Standard < spec.Security {
+		return fmt.Errorf("ckks.BuildParametersLiteral: insecure parameters: LogQP=%.1f is above the bound of the HE standard for %d-bit security with LogN=%d",
+			logQP, spec.Security, spec.LogN)
+	}
+
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"math/big"
+	"math/bits"
 	"runtime"
 	"testing"
 
@@ -92,6 +93,9 @@ func Test_DBFV(t *testing.T) {
 			testRelinKeyGen,
 			testKeyswitching,
 			testPublicKeySwitching,
+			testPublicSwitchingKeyGen,
+			testThreshold,
+			testSmudging,
 			testRotKeyGenRotRows,
 			testRotKeyGenRotCols,
 			testEncToShares,
@@ -368,6 +372,159 @@ func testPublicKeySwitching(tc *testContext, t *testing.T) {
 	})
 }
 
+func testPublicSwitchingKeyGen(tc *testContext, t *testing.T) {
+
+	t.Run(testString("PublicSwitchingKeyGen", parties, tc.params), func(t *testing.T) {
+
+		coeffs, _, ciphertext := newTestVectors(tc, tc.encryptorPk0, t)
+
+		pskg := make([]*PSKGProtocol, parties)
+		shares := make([]*drlwe.PSKGShare, parties)
+		for i := range pskg {
+			if i == 0 {
+				pskg[i] = NewPSKGProtocol(tc.params)
+			} else {
+				pskg[i] = pskg[0].ShallowCopy()
+			}
+			shares[i] = pskg[i].AllocateShare()
+		}
+
+		// Checks that dbfv.PSKGProtocol complies to the drlwe.PublicSwitchingKeyGenerator interface
+		var _ drlwe.PublicSwitchingKeyGenerator = &pskg[0].PSKGProtocol
+
+		// The parties holding sk0 generate a switching key towards pk1
+		for i := range pskg {
+			pskg[i].GenShare(tc.sk0Shards[i], tc.pk1, shares[i])
+			if i > 0 {
+				pskg[0].AggregateShare(shares[0], shares[i], shares[0])
+			}
+		}
+
+		swk := bfv.NewSwitchingKey(tc.params)
+		pskg[0].GenSwitchingKey(shares[0], swk)
+
+		tc.evaluator.SwitchKeys(ciphertext, swk, ciphertext)
+
+		verifyTestVectors(tc, tc.decryptorSk1, coeffs, ciphertext, t)
+	})
+}
+
+func testThreshold(tc *testContext, t *testing.T) {
+
+	sk0Shards := tc.sk0Shards
+	pk1 := tc.pk1
+	encryptorPk0 := tc.encryptorPk0
+	decryptorSk1 := tc.decryptorSk1
+	threshold := parties - 1
+
+	t.Run(testString("Threshold", parties, tc.params)+fmt.Sprintf("/threshold=%d", threshold), func(t *testing.T) {
+
+		type Party struct {
+			*Thresholdizer
+			*Combiner
+			*PCKSProtocol
+			s     *rlwe.SecretKey
+			tsk   *drlwe.ShamirSecretShare
+			tpk   drlwe.ShamirPublicPoint
+			share *drlwe.PCKSShare
+		}
+
+		shamirPks := make([]drlwe.ShamirPublicPoint, parties)
+		for i := range shamirPks {
+			shamirPks[i] = drlwe.ShamirPublicPoint(i + 1)
+		}
+
+		P := make([]*Party, parties)
+		for i := range P {
+			p := new(Party)
+			p.Thresholdizer = NewThresholdizer(tc.params)
+			p.Combiner = NewCombiner(tc.params, shamirPks[i], shamirPks, threshold)
+			p.PCKSProtocol = NewPCKSProtocol(tc.params, 6.36)
+			p.s = sk0Shards[i]
+			p.tpk = shamirPks[i]
+			p.tsk = p.AllocateThresholdSecretShare()
+			p.share = p.PCKSProtocol.AllocateShare()
+			P[i] = p
+		}
+
+		// Setup: every party re-shares its secret key among all the parties
+		for _, pi := range P {
+			gen, err := pi.GenShamirPolynomial(threshold, pi.s)
+			require.NoError(t, err)
+			share := pi.AllocateThresholdSecretShare()
+			for _, pj := range P {
+				pi.GenShamirSecretShare(pj.tpk, gen, share)
+				pj.AggregateShares(pj.tsk, share, pj.tsk)
+			}
+		}
+
+		coeffs, _, ciphertext := newTestVectors(tc, encryptorPk0, t)
+
+		// Only the last threshold parties are online
+		active := P[parties-threshold:]
+		activePks := make([]drlwe.ShamirPublicPoint, len(active))
+		for i, p := range active {
+			activePks[i] = p.tpk
+		}
+
+		P0 := active[0]
+		for i, p := range active {
+			sk := rlwe.NewSecretKey(tc.params.Parameters)
+			p.GenAdditiveShare(activePks, p.tpk, p.tsk, sk)
+			p.PCKSProtocol.GenShare(sk, pk1, ciphertext.Value[1], p.share)
+			if i > 0 {
+				P0.PCKSProtocol.AggregateShare(p.share, P0.share, P0.share)
+			}
+		}
+
+		ciphertextSwitched := bfv.NewCiphertext(tc.params, 1)
+		P0.KeySwitch(ciphertext, P0.share, ciphertextSwitched)
+
+		verifyTestVectors(tc, decryptorSk1, coeffs, ciphertextSwitched, t)
+	})
+}
+
+func testSmudging(tc *testContext, t *testing.T) {
+
+	sk0Shards := tc.sk0Shards
+	sk1Shards := tc.sk1Shards
+	encryptorPk0 := tc.encryptorPk0
+	decryptorSk1 := tc.decryptorSk1
+
+	t.Run(testString("Smudging", parties, tc.params), func(t *testing.T) {
+
+		for _, qi := range tc.params.Q() {
+			if bits.Len64(qi) < 20 {
+				t.Skip("the smudging noise does not fit the smallest modulus of Q")
+			}
+		}
+
+		coeffs, _, ciphertext := newTestVectors(tc, encryptorPk0, t)
+
+		s, err := drlwe.NewSmudging(tc.params.Parameters, drlwe.SmudgingParameters{LogNoise: 0, Parties: parties, Lambda: 8})
+		require.NoError(t, err)
+		require.Less(t, SmudgingFailureProbability(tc.params, s), -40.0)
+
+		failing := s
+		failing.LogNoise = float64(tc.params.LogQ())
+		require.Equal(t, 0.0, SmudgingFailureProbability(tc.params, failing))
+
+		cks := NewCKSProtocol(tc.params, s.Sigma)
+		shares := make([]*drlwe.CKSShare, parties)
+		for i := range shares {
+			shares[i] = cks.AllocateShare()
+			cks.GenShare(sk0Shards[i], sk1Shards[i], ciphertext.Value[1], shares[i])
+			if i > 0 {
+				cks.AggregateShare(shares[0], shares[i], shares[0])
+			}
+		}
+
+		cks.KeySwitch(ciphertext, shares[0], ciphertext)
+
+		verifyTestVectors(tc, decryptorSk1, coeffs, ciphertext, t)
+	})
+}
+
 func testRotKeyGenRotRows(tc *testContext, t *testing.T) {
 
 	encryptorPk0 := tc.encryptorPk0
@@ -58,3 +58,22 @@ func NewRotKGProtocol(params bfv.Parameters) (rtg *RTGProtocol) {
 func (rtg *RTGProtocol) ShallowCopy() *RTGProtocol {
 	return &RTGProtocol{*rtg.RTGProtocol.ShallowCopy()}
 }
+
+// PSKGProtocol is the structure storing the parameters for the collective generation of a switching key from the
+// collective secret key to the secret key of another collective public key.
+type PSKGProtocol struct {
+	drlwe.PSKGProtocol
+}
+
+// NewPSKGProtocol creates a new PSKGProtocol instance. The switching key it generates re-encrypts the ciphertexts under
+// the key of another party set, without decrypting them, with the SwitchKeys method of the evaluator.
+func NewPSKGProtocol(params bfv.Parameters) *PSKGProtocol {
+	return &PSKGProtocol{*drlwe.NewPSKGProtocol(params.Parameters)}
+}
+
+// ShallowCopy creates a shallow copy of PSKGProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// PSKGProtocol can be used concurrently.
+func (pskg *PSKGProtocol) ShallowCopy() *PSKGProtocol {
+	return &PSKGProtocol{*pskg.PSKGProtocol.ShallowCopy()}
+}
@@ -1,6 +1,8 @@
 package dbfv
 
 import (
+	"math"
+
 	"github.com/tuneinsight/lattigo/v3/bfv"
 	"github.com/tuneinsight/lattigo/v3/drlwe"
 )
@@ -63,3 +65,31 @@ func (pcks *PCKSProtocol) KeySwitch(ctIn *bfv.Ciphertext, combined *drlwe.PCKSSh
 func (pcks *PCKSProtocol) ShallowCopy() *PCKSProtocol {
 	return &PCKSProtocol{*pcks.PCKSProtocol.ShallowCopy(), pcks.maxLevel}
 }
+
+// SmudgingFailureProbability returns the base-2 logarithm of an upper bound on the probability that a ciphertext at
+// the maximum level whose noise is bounded by 2^s.LogNoise fails to decrypt after a protocol using the smudging noise s.
+// It returns 0 if the noise of the input ciphertexts already exceeds the decryption bound Q/(2T) and -Inf if the
+// smudging noise, truncated by the Gaussian sampler, cannot cause a failure.
+func SmudgingFailureProbability(params bfv.Parameters, s drlwe.Smudging) (logP float64) {
+
+	var logDelta float64
+	for _, qi := range params.Q() {
+		logDelta += math.Log2(float64(qi))
+	}
+	logDelta -= math.Log2(float64(params.T()))
+
+	// margin = Q/(2T) - 2^LogNoise
+	if s.LogNoise >= logDelta-1 {
+		return 0
+	}
+	logMargin := logDelta - 1 + math.Log2(1-math.Exp2(s.LogNoise-logDelta+1))
+
+	parties := float64(s.Parties)
+	if logMargin > math.Log2(6*s.Sigma*parties) {
+		return math.Inf(-1)
+	}
+
+	// Gaussian tail bound on the aggregated noise, with a union bound over the N coefficients.
+	ratio := math.Exp2(logMargin) / (s.Sigma * math.Sqrt(parties))
+	return math.Min(0, 1+float64(params.LogN())-ratio*ratio/(2*math.Ln2))
+}
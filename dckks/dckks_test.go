@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math"
 	"runtime"
 	"testing"
 
@@ -108,6 +109,10 @@ func TestDCKKS(t *testing.T) {
 			testRelinKeyGen,
 			testKeyswitching,
 			testPublicKeySwitching,
+			testPublicSwitchingKeyGen,
+			testThreshold,
+			testSmudging,
+			testDP,
 			testRotKeyGenConjugate,
 			testRotKeyGenCols,
 			testE2SProtocol,
@@ -402,6 +407,312 @@ func testPublicKeySwitching(testCtx *testContext, t *testing.T) {
 	})
 }
 
+func testPublicSwitchingKeyGen(testCtx *testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	t.Run(testString("PublicSwitchingKeyGen", parties, params), func(t *testing.T) {
+
+		coeffs, _, ciphertext := newTestVectors(testCtx, testCtx.encryptorPk0, -1, 1)
+
+		pskg := make([]*PSKGProtocol, parties)
+		shares := make([]*drlwe.PSKGShare, parties)
+		for i := range pskg {
+			if i == 0 {
+				pskg[i] = NewPSKGProtocol(params)
+			} else {
+				pskg[i] = pskg[0].ShallowCopy()
+			}
+			shares[i] = pskg[i].AllocateShare()
+		}
+
+		// Checks that dckks.PSKGProtocol complies to the drlwe.PublicSwitchingKeyGenerator interface
+		var _ drlwe.PublicSwitchingKeyGenerator = &pskg[0].PSKGProtocol
+
+		// The parties holding sk0 generate a switching key towards pk1
+		for i := range pskg {
+			pskg[i].GenShare(testCtx.sk0Shards[i], testCtx.pk1, shares[i])
+			if i > 0 {
+				pskg[0].AggregateShare(shares[0], shares[i], shares[0])
+			}
+		}
+
+		swk := ckks.NewSwitchingKey(params)
+		pskg[0].GenSwitchingKey(shares[0], swk)
+
+		// The switching key is an encryption under a public key, whose noise is larger by a factor about
+		// sqrt(N * #Parties) than the one of a switching key generated with both secret keys
+		swkIdeal := ckks.NewKeyGenerator(params).GenSwitchingKey(testCtx.sk0, testCtx.sk1)
+		precIdeal := ckks.GetPrecisionStats(params, testCtx.encoder, testCtx.decryptorSk1, coeffs, testCtx.evaluator.SwitchKeysNew(ciphertext, swkIdeal), params.LogSlots(), 0)
+
+		testCtx.evaluator.SwitchKeys(ciphertext, swk, ciphertext)
+
+		prec := ckks.GetPrecisionStats(params, testCtx.encoder, testCtx.decryptorSk1, coeffs, ciphertext, params.LogSlots(), 0)
+		require.GreaterOrEqual(t, prec.MeanPrecision.Real, precIdeal.MeanPrecision.Real-float64(params.LogN())/2-math.Log2(float64(parties))-2)
+	})
+}
+
+func testThreshold(testCtx *testContext, t *testing.T) {
+
+	encryptorPk0 := testCtx.encryptorPk0
+	decryptorSk1 := testCtx.decryptorSk1
+	sk0Shards := testCtx.sk0Shards
+	pk1 := testCtx.pk1
+	params := testCtx.params
+	threshold := parties - 1
+
+	t.Run(testString("Threshold", parties, params)+fmt.Sprintf("/threshold=%d", threshold), func(t *testing.T) {
+
+		type Party struct {
+			*Thresholdizer
+			*Combiner
+			*PCKSProtocol
+			s     *rlwe.SecretKey
+			tsk   *drlwe.ShamirSecretShare
+			tpk   drlwe.ShamirPublicPoint
+			share *drlwe.PCKSShare
+		}
+
+		coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, -1, 1)
+
+		shamirPks := make([]drlwe.ShamirPublicPoint, parties)
+		for i := range shamirPks {
+			shamirPks[i] = drlwe.ShamirPublicPoint(i + 1)
+		}
+
+		P := make([]*Party, parties)
+		for i := range P {
+			p := new(Party)
+			p.Thresholdizer = NewThresholdizer(params)
+			p.Combiner = NewCombiner(params, shamirPks[i], shamirPks, threshold)
+			p.PCKSProtocol = NewPCKSProtocol(params, 3.2)
+			p.s = sk0Shards[i]
+			p.tpk = shamirPks[i]
+			p.tsk = p.AllocateThresholdSecretShare()
+			p.share = p.PCKSProtocol.AllocateShare(ciphertext.Level())
+			P[i] = p
+		}
+
+		// Setup: every party re-shares its secret key among all the parties
+		for _, pi := range P {
+			gen, err := pi.GenShamirPolynomial(threshold, pi.s)
+			require.NoError(t, err)
+			share := pi.AllocateThresholdSecretShare()
+			for _, pj := range P {
+				pi.GenShamirSecretShare(pj.tpk, gen, share)
+				pj.AggregateShares(pj.tsk, share, pj.tsk)
+			}
+		}
+
+		// Only the last threshold parties are online
+		active := P[parties-threshold:]
+		activePks := make([]drlwe.ShamirPublicPoint, len(active))
+		for i, p := range active {
+			activePks[i] = p.tpk
+		}
+
+		P0 := active[0]
+		for i, p := range active {
+			sk := rlwe.NewSecretKey(params.Parameters)
+			p.GenAdditiveShare(activePks, p.tpk, p.tsk, sk)
+			p.PCKSProtocol.GenShare(sk, pk1, ciphertext.Value[1], p.share)
+			if i > 0 {
+				P0.PCKSProtocol.AggregateShare(p.share, P0.share, P0.share)
+			}
+		}
+
+		ciphertextSwitched := ckks.NewCiphertext(params, 1, ciphertext.Level(), ciphertext.Scale)
+		P0.KeySwitch(ciphertext, P0.share, ciphertextSwitched)
+
+		verifyTestVectors(testCtx, decryptorSk1, coeffs, ciphertextSwitched, t)
+	})
+}
+
+func testSmudging(testCtx *testContext, t *testing.T) {
+
+	encryptorPk0 := testCtx.encryptorPk0
+	sk0Shards := testCtx.sk0Shards
+	sk1Shards := testCtx.sk1Shards
+	params := testCtx.params
+
+	t.Run(testString("Smudging", parties, params), func(t *testing.T) {
+
+		coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, -1, 1)
+
+		s, err := drlwe.NewSmudging(params.Parameters, drlwe.SmudgingParameters{LogNoise: 0, Parties: parties, Lambda: 8})
+		require.NoError(t, err)
+
+		logPrec, logLoss := SmudgingPrecisionLoss(params, ciphertext.Scale, s)
+		require.Greater(t, logLoss, 0.0)
+
+		cks := NewCKSProtocol(params, s.Sigma)
+		shares := make([]*drlwe.CKSShare, parties)
+		for i := range shares {
+			shares[i] = cks.AllocateShare(ciphertext.Level())
+			cks.GenShare(sk0Shards[i], sk1Shards[i], ciphertext.Value[1], shares[i])
+			if i > 0 {
+				cks.AggregateShare(shares[0], shares[i], shares[0])
+			}
+		}
+
+		cks.KeySwitch(ciphertext, shares[0], ciphertext)
+
+		precStats := ckks.GetPrecisionStats(params, testCtx.encoder, testCtx.decryptorSk1, coeffs, ciphertext, params.LogSlots(), 0)
+		require.GreaterOrEqual(t, precStats.MeanPrecision.Real, logPrec)
+		require.GreaterOrEqual(t, precStats.MeanPrecision.Imag, logPrec)
+	})
+}
+
+func testDP(testCtx *testContext, t *testing.T) {
+
+	encryptorPk0 := testCtx.encryptorPk0
+	decryptorSk1 := testCtx.decryptorSk1
+	sk0Shards := testCtx.sk0Shards
+	sk1Shards := testCtx.sk1Shards
+	pk1 := testCtx.pk1
+	params := testCtx.params
+
+	dp := drlwe.DPParameters{Epsilon: 1, Delta: 1e-5, Sensitivity: 1, Parties: parties}
+
+	// checks that the decrypted values have the variance of the mechanism
+	verifyDPNoise := func(noise *drlwe.DPNoise, valuesWant, valuesHave []complex128, t *testing.T) {
+		var variance float64
+		for i := range valuesWant {
+			d := valuesHave[i] - valuesWant[i]
+			variance += real(d) * real(d)
+			if params.RingType() == ring.Standard {
+				variance += imag(d) * imag(d)
+			}
+		}
+		variance /= float64(len(valuesWant))
+		if params.RingType() == ring.Standard {
+			variance /= 2
+		}
+
+		// the Gaussian noise of the conjugate invariant ring has twice the variance of the mechanism and the
+		// Laplace noise of each slot sums the noise of the coefficients
+		want := noise.StdDev() * noise.StdDev() * float64(noise.Parties)
+		switch {
+		case noise.Mechanism == drlwe.LaplaceMechanism:
+			want *= float64(2 * len(valuesWant))
+		case params.RingType() == ring.ConjugateInvariant:
+			want *= 2
+		}
+		require.InDelta(t, 1, variance/want, 0.15)
+	}
+
+	t.Run(testString("DP/CKS", parties, params), func(t *testing.T) {
+
+		coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, -1, 1)
+
+		dp := dp
+		dp.Mechanism = drlwe.GaussianMechanism
+		cks, err := NewDPCKSProtocol(params, 3.2, dp)
+		require.NoError(t, err)
+
+		shares := make([]*drlwe.CKSShare, parties)
+		for i := range shares {
+			shares[i] = cks.AllocateShare(ciphertext.Level())
+			cks.ShallowCopy().GenShare(sk0Shards[i], sk1Shards[i], ciphertext, params.LogSlots(), shares[i])
+			if i > 0 {
+				cks.AggregateShare(shares[0], shares[i], shares[0])
+			}
+		}
+
+		cks.KeySwitch(ciphertext, shares[0], ciphertext)
+
+		verifyDPNoise(cks.Noise(), coeffs, testCtx.encoder.Decode(decryptorSk1.DecryptNew(ciphertext), params.LogSlots()), t)
+	})
+
+	t.Run(testString("DP/LogSlots", parties, params), func(t *testing.T) {
+
+		_, _, ciphertext := newTestVectors(testCtx, encryptorPk0, -1, 1)
+
+		// the number of slots of the shares does not depend on the LogSlots of the parameters
+		paramsFewSlots, err := ckks.NewParameters(params.Parameters, 4, params.DefaultScale())
+		require.NoError(t, err)
+
+		cks, err := NewDPCKSProtocol(paramsFewSlots, 3.2, dp)
+		require.NoError(t, err)
+
+		share := cks.AllocateShare(ciphertext.Level())
+		require.NotPanics(t, func() { cks.GenShare(sk0Shards[0], sk1Shards[0], ciphertext, params.MaxLogSlots(), share) })
+		require.Panics(t, func() { cks.GenShare(sk0Shards[0], sk1Shards[0], ciphertext, params.MaxLogSlots()+1, share) })
+		require.Panics(t, func() { cks.GenShare(sk0Shards[0], sk1Shards[0], ciphertext, -1, share) })
+	})
+
+	for name, mechanism := range map[string]drlwe.DPMechanism{"Gaussian": drlwe.GaussianMechanism, "Laplace": drlwe.LaplaceMechanism} {
+
+		dp := dp
+		dp.Mechanism = mechanism
+		dp.Colluding = 1
+
+		t.Run(testString("DP/PCKS/"+name, parties, params), func(t *testing.T) {
+
+			coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, -1, 1)
+
+			pcks, err := NewDPPCKSProtocol(params, 3.2, dp)
+			require.NoError(t, err)
+
+			shares := make([]*drlwe.PCKSShare, parties)
+			for i := range shares {
+				shares[i] = pcks.AllocateShare(ciphertext.Level())
+				pcks.ShallowCopy().GenShare(sk0Shards[i], pk1, ciphertext, params.LogSlots(), shares[i])
+				if i > 0 {
+					pcks.AggregateShare(shares[0], shares[i], shares[0])
+				}
+			}
+
+			pcks.KeySwitch(ciphertext, shares[0], ciphertext)
+
+			verifyDPNoise(pcks.Noise(), coeffs, testCtx.encoder.Decode(decryptorSk1.DecryptNew(ciphertext), params.LogSlots()), t)
+		})
+	}
+
+	t.Run(testString("DP/E2S", parties, params), func(t *testing.T) {
+
+		var minLevel, logBound int
+		var ok bool
+		if minLevel, logBound, ok = GetMinimumLevelForBootstrapping(128, params.DefaultScale(), parties, params.Q()); ok != true || minLevel+1 > params.MaxLevel() {
+			t.Skip("Not enough levels to ensure correcness and 128 security")
+		}
+
+		coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, -1, 1)
+		testCtx.evaluator.DropLevel(ciphertext, ciphertext.Level()-minLevel-1)
+
+		dp := dp
+		dp.Mechanism = drlwe.GaussianMechanism
+		e2s, err := NewDPE2SProtocol(params, 3.2, dp)
+		require.NoError(t, err)
+
+		secretShares := make([]*rlwe.AdditiveShareBigint, parties)
+		publicShare := e2s.AllocateShare(minLevel)
+		for i := range secretShares {
+			secretShares[i] = NewAdditiveShareBigint(params, params.LogSlots())
+			share := e2s.AllocateShare(minLevel)
+			e2s.GenShare(sk0Shards[i], logBound, params.LogSlots(), ciphertext, secretShares[i], share)
+			e2s.AggregateShare(publicShare, share, publicShare)
+		}
+
+		e2s.GetShare(secretShares[0], publicShare, params.LogSlots(), ciphertext, secretShares[0])
+
+		rec := NewAdditiveShareBigint(params, params.LogSlots())
+		for _, share := range secretShares {
+			for i := range rec.Value {
+				rec.Value[i].Add(rec.Value[i], share.Value[i])
+			}
+		}
+
+		pt := ckks.NewPlaintext(params, ciphertext.Level(), ciphertext.Scale)
+		pt.Value.IsNTT = false
+		testCtx.ringQ.SetCoefficientsBigintLvl(pt.Level(), rec.Value, pt.Value)
+		testCtx.ringQ.NTTLvl(pt.Level(), pt.Value, pt.Value)
+		pt.Value.IsNTT = true
+
+		verifyDPNoise(e2s.Noise(), coeffs, testCtx.encoder.Decode(pt, params.LogSlots()), t)
+	})
+}
+
 func testRotKeyGenConjugate(testCtx *testContext, t *testing.T) {
 
 	encryptorPk0 := testCtx.encryptorPk0
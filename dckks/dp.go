@@ -0,0 +1,208 @@
+package dckks
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// dpSampler adds the share of a party of a differentially private noise on a public share. The noise is sampled on
+// the integer coefficients of the plaintext that are read by the decoding, with a scale such that the differential
+// privacy of the decoded values follows from the differential privacy of the coefficients.
+type dpSampler struct {
+	params ckks.Parameters
+	noise  *drlwe.DPNoise
+	values []*big.Int
+	poly   *ring.Poly
+}
+
+func newDPSampler(params ckks.Parameters, dp drlwe.DPParameters) (dps dpSampler, err error) {
+
+	var noise *drlwe.DPNoise
+	if noise, err = drlwe.NewDPNoise(dp); err != nil {
+		return
+	}
+
+	return dpSampler{
+		params: params,
+		noise:  noise,
+		values: newBigintSlice(params.N()), // 2*MaxSlots (standard ring) or MaxSlots (conjugate invariant ring) coefficients
+		poly:   params.RingQ().NewPoly(),
+	}, nil
+}
+
+func newBigintSlice(n int) (values []*big.Int) {
+	values = make([]*big.Int, n)
+	for i := range values {
+		values[i] = new(big.Int)
+	}
+	return
+}
+
+func (dps *dpSampler) shallowCopy() dpSampler {
+	return dpSampler{
+		params: dps.params,
+		noise:  dps.noise.ShallowCopy(),
+		values: newBigintSlice(len(dps.values)),
+		poly:   dps.params.RingQ().NewPoly(),
+	}
+}
+
+// addNoise samples the noise of the party for 2^logSlots slots at the given scale and adds it on p, which is in the
+// NTT domain.
+//
+// The decoding reads 2*slots coefficients in the standard ring (slots in the conjugate invariant ring), with a gap of
+// MaxSlots/slots, and maps them to the slots with a linear map that multiplies their L2 norm by at least sqrt(slots).
+// A change of L2 norm Sensitivity of the values thus changes the coefficients by at most Sensitivity*scale/sqrt(slots),
+// which is the scaling factor of the sensitivity of the Gaussian noise. The noise of the slots has the standard
+// deviation of the mechanism in the standard ring and twice its variance in the conjugate invariant ring.
+//
+// A change of L1 norm Sensitivity of the values, which bounds their change of L2 norm, changes the n coefficients by
+// at most sqrt(n) times their change of L2 norm, that is Sensitivity*scale*sqrt(n/slots), which is the scaling factor
+// of the sensitivity of the Laplace noise. The Laplace noise of the slots is a sum of the noise of the n coefficients:
+// each slot has a variance 2*slots times the variance of the mechanism.
+// The method panics if logSlots is not in [0, MaxLogSlots].
+func (dps *dpSampler) addNoise(logSlots int, scale float64, p *ring.Poly) {
+
+	if logSlots < 0 || logSlots > dps.params.MaxLogSlots() {
+		panic(fmt.Sprintf("cannot add a differentially private noise: logSlots=%d is not in [0, %d]", logSlots, dps.params.MaxLogSlots()))
+	}
+
+	ringQ := dps.params.RingQ()
+	level := p.Level()
+
+	slots := 1 << logSlots
+	gap := dps.params.MaxSlots() / slots
+
+	values := dps.values[:slots]
+	if dps.params.RingType() == ring.Standard {
+		values = dps.values[:2*slots]
+	}
+
+	switch dps.noise.Mechanism {
+	case drlwe.GaussianMechanism:
+		dps.noise.Read(values, scale/math.Sqrt(float64(slots)))
+	case drlwe.LaplaceMechanism:
+		dps.noise.Read(values, scale*math.Sqrt(float64(len(values))/float64(slots)))
+	}
+
+	poly := dps.poly
+	poly.Resize(level)
+	poly.Zero()
+
+	tmp := new(big.Int)
+	for i, qi := range ringQ.Modulus[:level+1] {
+		bqi := new(big.Int).SetUint64(qi)
+		for j, v := range values {
+			poly.Coeffs[i][j*gap] = tmp.Mod(v, bqi).Uint64()
+		}
+	}
+
+	ringQ.NTTLvl(level, poly, poly)
+	ringQ.AddLvl(level, p, poly, p)
+}
+
+// Noise returns the DPNoise of the protocol.
+func (dps *dpSampler) Noise() *drlwe.DPNoise {
+	return dps.noise
+}
+
+// DPCKSProtocol is a CKSProtocol in which the parties jointly add a differentially private noise on the
+// decrypted values, on top of the smudging noise.
+// The differentially private noise is not bounded: the shares of a DPCKSProtocol cannot be proven with ProveShare,
+// which only proves shares with a smudging noise bounded by 6*sigmaSmudging.
+type DPCKSProtocol struct {
+	CKSProtocol
+	dpSampler
+}
+
+// NewDPCKSProtocol creates a new DPCKSProtocol. It returns an error if the parameters of the
+// differentially private noise are invalid.
+func NewDPCKSProtocol(params ckks.Parameters, sigmaSmudging float64, dp drlwe.DPParameters) (cks *DPCKSProtocol, err error) {
+	var dps dpSampler
+	if dps, err = newDPSampler(params, dp); err != nil {
+		return nil, err
+	}
+	return &DPCKSProtocol{*NewCKSProtocol(params, sigmaSmudging), dps}, nil
+}
+
+// ShallowCopy creates a shallow copy of DPCKSProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// DPCKSProtocol can be used concurrently.
+func (cks *DPCKSProtocol) ShallowCopy() *DPCKSProtocol {
+	return &DPCKSProtocol{*cks.CKSProtocol.ShallowCopy(), cks.dpSampler.shallowCopy()}
+}
+
+// GenShare computes the share of a party in the CKS protocol, including its share of the differentially private
+// noise on 2^logSlots slots at the scale of ct.
+func (cks *DPCKSProtocol) GenShare(skInput, skOutput *rlwe.SecretKey, ct *ckks.Ciphertext, logSlots int, shareOut *drlwe.CKSShare) {
+	cks.CKSProtocol.GenShare(skInput, skOutput, ct.Value[1], shareOut)
+	cks.addNoise(logSlots, ct.Scale, shareOut.Value)
+}
+
+// DPPCKSProtocol is a PCKSProtocol in which the parties jointly add a differentially private noise on the
+// decrypted values, on top of the smudging noise.
+type DPPCKSProtocol struct {
+	PCKSProtocol
+	dpSampler
+}
+
+// NewDPPCKSProtocol creates a new DPPCKSProtocol. It returns an error if the parameters of the
+// differentially private noise are invalid.
+func NewDPPCKSProtocol(params ckks.Parameters, sigmaSmudging float64, dp drlwe.DPParameters) (pcks *DPPCKSProtocol, err error) {
+	var dps dpSampler
+	if dps, err = newDPSampler(params, dp); err != nil {
+		return nil, err
+	}
+	return &DPPCKSProtocol{*NewPCKSProtocol(params, sigmaSmudging), dps}, nil
+}
+
+// ShallowCopy creates a shallow copy of DPPCKSProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// DPPCKSProtocol can be used concurrently.
+func (pcks *DPPCKSProtocol) ShallowCopy() *DPPCKSProtocol {
+	return &DPPCKSProtocol{*pcks.PCKSProtocol.ShallowCopy(), pcks.dpSampler.shallowCopy()}
+}
+
+// GenShare computes the share of a party in the PCKS protocol, including its share of the differentially private
+// noise on 2^logSlots slots at the scale of ct.
+func (pcks *DPPCKSProtocol) GenShare(sk *rlwe.SecretKey, pk *rlwe.PublicKey, ct *ckks.Ciphertext, logSlots int, shareOut *drlwe.PCKSShare) {
+	pcks.PCKSProtocol.GenShare(sk, pk, ct.Value[1], shareOut)
+	pcks.addNoise(logSlots, ct.Scale, shareOut.Value[0])
+}
+
+// DPE2SProtocol is an E2SProtocol in which the parties jointly add a differentially private noise on the
+// secret-shared values.
+type DPE2SProtocol struct {
+	E2SProtocol
+	dpSampler
+}
+
+// NewDPE2SProtocol creates a new DPE2SProtocol. It returns an error if the parameters of the
+// differentially private noise are invalid.
+func NewDPE2SProtocol(params ckks.Parameters, sigmaSmudging float64, dp drlwe.DPParameters) (e2s *DPE2SProtocol, err error) {
+	var dps dpSampler
+	if dps, err = newDPSampler(params, dp); err != nil {
+		return nil, err
+	}
+	return &DPE2SProtocol{*NewE2SProtocol(params, sigmaSmudging), dps}, nil
+}
+
+// ShallowCopy creates a shallow copy of DPE2SProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// DPE2SProtocol can be used concurrently.
+func (e2s *DPE2SProtocol) ShallowCopy() *DPE2SProtocol {
+	return &DPE2SProtocol{*e2s.E2SProtocol.ShallowCopy(), e2s.dpSampler.shallowCopy()}
+}
+
+// GenShare generates the share of a party in the encryption-to-shares protocol, including its share of the
+// differentially private noise at the scale of ct. See E2SProtocol.GenShare for the other inputs.
+func (e2s *DPE2SProtocol) GenShare(sk *rlwe.SecretKey, logBound, logSlots int, ct *ckks.Ciphertext, secretShareOut *rlwe.AdditiveShareBigint, publicShareOut *drlwe.CKSShare) {
+	e2s.E2SProtocol.GenShare(sk, logBound, logSlots, ct.Value[1], secretShareOut, publicShareOut)
+	e2s.addNoise(logSlots, ct.Scale, publicShareOut.Value)
+}
@@ -0,0 +1,42 @@
+package dckks
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+)
+
+// Thresholdizer is the structure storing the parameters for a party in the threshold setup protocol.
+type Thresholdizer struct {
+	drlwe.Thresholdizer
+}
+
+// NewThresholdizer creates a new Thresholdizer instance that will be used to re-share a party's secret-key
+// such that any t out of the N parties can later act on behalf of the collective secret-key.
+func NewThresholdizer(params ckks.Parameters) *Thresholdizer {
+	return &Thresholdizer{*drlwe.NewThresholdizer(params.Parameters)}
+}
+
+// ShallowCopy creates a shallow copy of Thresholdizer in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Thresholdizer can be used concurrently.
+func (thr *Thresholdizer) ShallowCopy() *Thresholdizer {
+	return &Thresholdizer{*thr.Thresholdizer.ShallowCopy()}
+}
+
+// Combiner is the structure storing the parameters for a party to derive its additive share of the
+// collective secret-key from its threshold secret-share, given the set of active parties.
+type Combiner struct {
+	drlwe.Combiner
+}
+
+// NewCombiner creates a new Combiner instance for the party with public point own among the
+// others public points, for the given threshold.
+func NewCombiner(params ckks.Parameters, own drlwe.ShamirPublicPoint, others []drlwe.ShamirPublicPoint, threshold int) *Combiner {
+	return &Combiner{*drlwe.NewCombiner(params.Parameters, own, others, threshold)}
+}
+
+// ShallowCopy creates a shallow copy of Combiner in which all the read-only data-structures are
+// shared with the receiver. The receiver and the returned Combiner can be used concurrently.
+func (cmb *Combiner) ShallowCopy() *Combiner {
+	return &Combiner{*cmb.Combiner.ShallowCopy()}
+}
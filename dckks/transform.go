@@ -4,6 +4,7 @@ import (
 	"math/big"
 
 	"encoding/binary"
+	"fmt"
 
 	"github.com/tuneinsight/lattigo/v3/ckks"
 	"github.com/tuneinsight/lattigo/v3/drlwe"
@@ -78,8 +79,16 @@ func (share *MaskedTransformShare) MarshalBinary() (data []byte, err error) {
 // UnmarshalBinary decodes a marshaled RefreshShare on the target RefreshShare.
 func (share *MaskedTransformShare) UnmarshalBinary(data []byte) error {
 
+	if len(data) < 8 {
+		return fmt.Errorf("%w: data is too short", rlwe.ErrMalformed)
+	}
+
 	e2sDataLen := binary.LittleEndian.Uint64(data[:8])
 
+	if e2sDataLen > uint64(len(data)-8) {
+		return fmt.Errorf("%w: invalid share length %d", rlwe.ErrMalformed, e2sDataLen)
+	}
+
 	if err := share.e2sShare.UnmarshalBinary(data[8 : e2sDataLen+8]); err != nil {
 		return err
 	}
@@ -5,6 +5,7 @@ import (
 	"math/bits"
 
 	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 )
@@ -43,3 +44,12 @@ func NewAdditiveShareBigint(params ckks.Parameters, logSlots int) *rlwe.Additive
 	}
 	return rlwe.NewAdditiveShareBigint(params.Parameters, dslots)
 }
+
+// SmudgingPrecisionLoss returns the estimated precision, in bits, of the slots of the ciphertexts encoded with the given
+// scale that are output by a protocol using the smudging noise s, and the number of bits of precision lost to the smudging
+// noise. The estimate accounts for the expansion of the noise by sqrt(N) in the canonical embedding, hence the LogBudget
+// of s for a target precision of logPrec bits is log2(scale) - logPrec - LogN/2.
+func SmudgingPrecisionLoss(params ckks.Parameters, scale float64, s drlwe.Smudging) (logPrec, logLoss float64) {
+	logNoise := s.LogNoiseAfter()
+	return math.Log2(scale) - logNoise - float64(params.LogN())/2, logNoise - s.LogNoise
+}
@@ -0,0 +1,226 @@
+package drlwe
+
+import (
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// ShareCompressor encodes the CKS and PCKS shares on fewer bits than their MarshalBinary method. It rounds away the
+// LogDrop least-significant bits of each coefficient of the shares seen as an integer modulo Q, which is equivalent to
+// switching the shares to the modulus Q/2^LogDrop, and packs the remaining bits.
+//
+// The decoded shares differ from the encoded ones by an error uniform in [-2^(LogDrop-1), 2^(LogDrop-1)] on each
+// coefficient. For CKS, the aggregation of the shares of n parties adds an error of norm at most n * 2^(LogDrop-1)
+// to the output ciphertext. For PCKS, the error on the second element of the shares is multiplied by the output secret
+// key, hence the error is at most n * 2^(LogDrop-1) * (1 + ||s_out||_1). The LogDrop can be taken close to the bit
+// size of the smudging noise for a small precision cost.
+type ShareCompressor struct {
+	params  rlwe.Parameters
+	LogDrop int
+
+	buff   *ring.Poly
+	coeffs []*big.Int
+}
+
+// NewShareCompressor creates a new ShareCompressor dropping logDrop bits of each coefficient of the shares.
+func NewShareCompressor(params rlwe.Parameters, logDrop int) *ShareCompressor {
+	if logDrop < 0 || logDrop > 0xFF {
+		panic("cannot NewShareCompressor: logDrop must be in [0, 255]")
+	}
+	coeffs := make([]*big.Int, params.N())
+	for i := range coeffs {
+		coeffs[i] = new(big.Int)
+	}
+	return &ShareCompressor{params: params, LogDrop: logDrop, buff: params.RingQ().NewPoly(), coeffs: coeffs}
+}
+
+// ShallowCopy creates a shallow copy of ShareCompressor in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// ShareCompressor can be used concurrently.
+func (c *ShareCompressor) ShallowCopy() *ShareCompressor {
+	return NewShareCompressor(c.params, c.LogDrop)
+}
+
+// LogError returns the base-2 logarithm of the bound on the infinity norm of the error added by
+// the compression of the CKS shares of the given number of parties.
+func (c *ShareCompressor) LogError(parties int) float64 {
+	if c.LogDrop == 0 {
+		return math.Inf(-1)
+	}
+	return float64(c.LogDrop-1) + math.Log2(float64(parties))
+}
+
+// MarshalCKSShare encodes a CKS share on a slice of bytes.
+func (c *ShareCompressor) MarshalCKSShare(share *CKSShare) (data []byte, err error) {
+	return c.marshal([]*ring.Poly{share.Value})
+}
+
+// UnmarshalCKSShare decodes a slice of bytes generated by MarshalCKSShare on the target CKS share.
+func (c *ShareCompressor) UnmarshalCKSShare(data []byte, share *CKSShare) (err error) {
+	polys := make([]*ring.Poly, 1)
+	if err = c.unmarshal(data, polys); err != nil {
+		return
+	}
+	share.Value = polys[0]
+	return
+}
+
+// MarshalPCKSShare encodes a PCKS share on a slice of bytes.
+func (c *ShareCompressor) MarshalPCKSShare(share *PCKSShare) (data []byte, err error) {
+	return c.marshal(share.Value[:])
+}
+
+// UnmarshalPCKSShare decodes a slice of bytes generated by MarshalPCKSShare on the target PCKS share.
+func (c *ShareCompressor) UnmarshalPCKSShare(data []byte, share *PCKSShare) (err error) {
+	return c.unmarshal(data, share.Value[:])
+}
+
+// compressedHeaderLen is the length of the header of an encoding: the level, the NTT flag and LogDrop.
+const compressedHeaderLen = 3
+
+// width returns the number of bits of a compressed coefficient at the given level.
+func (c *ShareCompressor) width(level int) int {
+	// the largest compressed value is round((Q-1)/2^LogDrop) <= ceil(Q/2^LogDrop)
+	q := c.params.RingQ().ModulusAtLevel[level]
+	return new(big.Int).Rsh(new(big.Int).Add(q, new(big.Int).Lsh(big.NewInt(1), uint(c.LogDrop))), uint(c.LogDrop)).BitLen()
+}
+
+func (c *ShareCompressor) marshal(polys []*ring.Poly) (data []byte, err error) {
+
+	ringQ := c.params.RingQ()
+	level := polys[0].Level()
+
+	if level > 0xFF {
+		return nil, errors.New("cannot MarshalBinary: level overflows uint8")
+	}
+
+	width := c.width(level)
+	w := &bitWriter{buf: make([]byte, compressedHeaderLen, compressedHeaderLen+(len(polys)*ringQ.N*width+7)/8)}
+	w.buf[0] = uint8(level)
+	if polys[0].IsNTT {
+		w.buf[1] = 1
+	}
+	w.buf[2] = uint8(c.LogDrop)
+
+	half := new(big.Int)
+	if c.LogDrop > 0 {
+		half.Lsh(big.NewInt(1), uint(c.LogDrop-1))
+	}
+	tmp := new(big.Int)
+
+	for _, p := range polys {
+
+		if p.Level() != level || p.IsNTT != polys[0].IsNTT {
+			return nil, errors.New("cannot MarshalBinary: polynomials must have the same level and domain")
+		}
+
+		ring.CopyValuesLvl(level, p, c.buff)
+		if p.IsNTT {
+			ringQ.InvNTTLvl(level, c.buff, c.buff)
+		}
+
+		ringQ.PolyToBigintLvl(level, c.buff, 1, c.coeffs)
+
+		// round(x / 2^LogDrop)
+		for _, x := range c.coeffs {
+			x.Add(x, half)
+			x.Rsh(x, uint(c.LogDrop))
+			for k := 0; k < width; k += 64 {
+				w.write(tmp.Rsh(x, uint(k)).Uint64(), width-k)
+			}
+		}
+	}
+
+	return w.buf, nil
+}
+
+func (c *ShareCompressor) unmarshal(data []byte, polys []*ring.Poly) (err error) {
+
+	ringQ := c.params.RingQ()
+
+	if len(data) < compressedHeaderLen {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	level := int(data[0])
+	isNTT := data[1] == 1
+
+	if level > c.params.MaxLevel() {
+		return errors.New("cannot UnmarshalBinary: invalid level")
+	}
+
+	if int(data[2]) != c.LogDrop {
+		return errors.New("cannot UnmarshalBinary: the share was compressed with another LogDrop")
+	}
+
+	width := c.width(level)
+	if len(data) != compressedHeaderLen+(len(polys)*ringQ.N*width+7)/8 {
+		return errors.New("cannot UnmarshalBinary: invalid data length")
+	}
+
+	r := &bitReader{buf: data[compressedHeaderLen:]}
+	tmp := new(big.Int)
+
+	for i := range polys {
+
+		// x' = y * 2^LogDrop
+		for _, x := range c.coeffs {
+			x.SetUint64(0)
+			for k := 0; k < width; k += 64 {
+				x.Or(x, tmp.Lsh(tmp.SetUint64(r.read(width-k)), uint(k)))
+			}
+			x.Lsh(x, uint(c.LogDrop))
+		}
+
+		polys[i] = ringQ.NewPolyLvl(level)
+		ringQ.SetCoefficientsBigintLvl(level, c.coeffs, polys[i])
+		if isNTT {
+			ringQ.NTTLvl(level, polys[i], polys[i])
+		}
+		polys[i].IsNTT = isNTT
+	}
+
+	return nil
+}
+
+// bitWriter appends integers of arbitrary bit length to a slice of bytes.
+type bitWriter struct {
+	buf   []byte
+	nbits uint
+}
+
+// write appends the min(n, 64) least-significant bits of v.
+func (w *bitWriter) write(v uint64, n int) {
+	if n > 64 {
+		n = 64
+	}
+	for i := 0; i < n; i++ {
+		if w.nbits&7 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		w.buf[len(w.buf)-1] |= byte((v>>uint(i))&1) << (w.nbits & 7)
+		w.nbits++
+	}
+}
+
+// bitReader reads the integers written by a bitWriter.
+type bitReader struct {
+	buf   []byte
+	nbits uint
+}
+
+// read returns the next min(n, 64) bits.
+func (r *bitReader) read(n int) (v uint64) {
+	if n > 64 {
+		n = 64
+	}
+	for i := 0; i < n; i++ {
+		v |= uint64((r.buf[r.nbits>>3]>>(r.nbits&7))&1) << uint(i)
+		r.nbits++
+	}
+	return
+}
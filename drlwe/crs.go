@@ -10,3 +10,13 @@ import (
 type CRS interface {
 	utils.PRNG
 }
+
+// derivePRNGs returns the independent PRNGs of the given domains derived from prng, see utils.NewDerivedPRNGs.
+// The domains of the protocols are prefixed by "drlwe/" and the name of the protocol.
+func derivePRNGs(prng utils.PRNG, domains ...string) []*utils.KeyedPRNG {
+	prngs, err := utils.NewDerivedPRNGs(prng, domains...)
+	if err != nil {
+		panic(err)
+	}
+	return prngs
+}
@@ -0,0 +1,269 @@
+package drlwe
+
+import (
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// DPMechanism is the distribution of the differentially private noise added on the output of a protocol.
+type DPMechanism int
+
+const (
+	// GaussianMechanism adds a Gaussian noise calibrated on the L2 sensitivity, for (epsilon, delta)-differential privacy.
+	GaussianMechanism DPMechanism = iota
+	// LaplaceMechanism adds a Laplace noise calibrated on the L1 sensitivity, for epsilon-differential privacy.
+	LaplaceMechanism
+)
+
+// DPParameters are the parameters of the differentially private noise that the parties of a protocol jointly add
+// on the decrypted values.
+type DPParameters struct {
+	Mechanism DPMechanism
+	Epsilon   float64
+	// Delta is ignored by the LaplaceMechanism.
+	Delta float64
+	// Sensitivity is the L2 (GaussianMechanism) or L1 (LaplaceMechanism) sensitivity of the vector of decrypted
+	// values, where the real and imaginary parts of a complex value are two distinct coordinates.
+	Sensitivity float64
+	// Parties is the number of parties adding noise.
+	Parties int
+	// Colluding is the number of parties that may collude. The noise is calibrated so that the noise of the
+	// Parties-Colluding honest parties is enough for the privacy guarantee.
+	Colluding int
+}
+
+// DPNoise samples the share of a party of the differentially private noise.
+// The noise is sampled on integers with exact samplers that only use integer arithmetic, since the noise sampled with
+// floating-point arithmetic leaks the noised values through its low-order bits (Mironov, CCS 2012).
+type DPNoise struct {
+	DPParameters
+	Scale float64
+
+	prng utils.PRNG
+}
+
+// NewDPNoise calibrates the differentially private noise for the given parameters. The GaussianMechanism uses the
+// analytic calibration of Balle and Wang (ICML 2018) and the LaplaceMechanism the scale Sensitivity/Epsilon.
+func NewDPNoise(dp DPParameters) (n *DPNoise, err error) {
+
+	if dp.Epsilon <= 0 || dp.Sensitivity <= 0 {
+		return nil, errors.New("cannot NewDPNoise: Epsilon and Sensitivity must be positive")
+	}
+
+	if dp.Parties < 1 || dp.Colluding < 0 || dp.Colluding >= dp.Parties {
+		return nil, errors.New("cannot NewDPNoise: Colluding must be in [0, Parties)")
+	}
+
+	n = &DPNoise{DPParameters: dp}
+
+	switch dp.Mechanism {
+	case GaussianMechanism:
+		if dp.Delta <= 0 || dp.Delta >= 1 {
+			return nil, errors.New("cannot NewDPNoise: Delta must be in (0, 1)")
+		}
+		n.Scale = analyticGaussianSigma(dp.Epsilon, dp.Delta, dp.Sensitivity)
+	case LaplaceMechanism:
+		n.Scale = dp.Sensitivity / dp.Epsilon
+	default:
+		return nil, errors.New("cannot NewDPNoise: invalid Mechanism")
+	}
+
+	if n.prng, err = utils.NewPRNG(); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// ShallowCopy creates a shallow copy of DPNoise with a new PRNG. The receiver and the returned
+// DPNoise can be used concurrently.
+func (n *DPNoise) ShallowCopy() *DPNoise {
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	return &DPNoise{DPParameters: n.DPParameters, Scale: n.Scale, prng: prng}
+}
+
+// StdDev returns the standard deviation of the noise of a single party, for a unit scaling factor.
+func (n *DPNoise) StdDev() float64 {
+	if n.Mechanism == LaplaceMechanism {
+		return n.Scale * math.Sqrt2
+	}
+	return n.Scale / math.Sqrt(float64(n.Parties-n.Colluding))
+}
+
+// Read samples the share of the party of the noise for each coordinate and writes it on values, for a vector of
+// integers of which the sensitivity is Sensitivity*scale. For the GaussianMechanism, each share is a discrete Gaussian
+// of variance (Scale*scale)^2/(Parties-Colluding), so that the sum of the shares of any Parties-Colluding parties is,
+// up to a negligible statistical distance, a discrete Gaussian of standard deviation Scale*scale. For the
+// LaplaceMechanism, which is not divisible on the integers, each share is a discrete Laplace of scale Scale*scale, so
+// that the share of any single honest party gives the privacy guarantee.
+func (n *DPNoise) Read(values []*big.Int, scale float64) {
+
+	if !(scale > 0) || math.IsInf(scale, 0) {
+		panic("cannot Read: scale must be positive and finite")
+	}
+
+	s := new(big.Rat).SetFloat64(n.Scale * scale)
+
+	switch n.Mechanism {
+	case GaussianMechanism:
+		sigma2 := new(big.Rat).Mul(s, s)
+		sigma2.Quo(sigma2, new(big.Rat).SetInt64(int64(n.Parties-n.Colluding)))
+		for i := range values {
+			n.discreteGaussian(sigma2, values[i])
+		}
+	case LaplaceMechanism:
+		for i := range values {
+			n.discreteLaplace(s.Num(), s.Denom(), values[i])
+		}
+	}
+}
+
+// uniform returns a uniform integer in [0, max).
+func (n *DPNoise) uniform(max *big.Int) (u *big.Int) {
+
+	buff := make([]byte, (max.BitLen()+7)/8)
+	mask := byte(0xff >> uint(len(buff)*8-max.BitLen()))
+
+	u = new(big.Int)
+	for {
+		if _, err := n.prng.Read(buff); err != nil {
+			panic(err)
+		}
+		buff[0] &= mask
+		if u.SetBytes(buff).Cmp(max) < 0 {
+			return
+		}
+	}
+}
+
+// bernoulli returns true with probability num/den, for 0 <= num <= den.
+func (n *DPNoise) bernoulli(num, den *big.Int) bool {
+	return n.uniform(den).Cmp(num) < 0
+}
+
+// bernoulliExp returns true with probability exp(-num/den), for num/den >= 0
+// (Canonne, Kamath and Steinke, NeurIPS 2020, Algorithm 1).
+func (n *DPNoise) bernoulliExp(num, den *big.Int) bool {
+
+	// exp(-gamma) = exp(-1)^floor(gamma) * exp(-(gamma - floor(gamma)))
+	floor, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	one := big.NewInt(1)
+	for i := new(big.Int); i.Cmp(floor) < 0; i.Add(i, one) {
+		if !n.bernoulliExpUnit(one, one) {
+			return false
+		}
+	}
+
+	return n.bernoulliExpUnit(rem, den)
+}
+
+// bernoulliExpUnit returns true with probability exp(-num/den), for num/den in [0, 1]: it returns true if the first k
+// for which a Bernoulli(num/(den*k)) fails is odd.
+func (n *DPNoise) bernoulliExpUnit(num, den *big.Int) bool {
+	one := big.NewInt(1)
+	k := new(big.Int).Set(one)
+	for n.bernoulli(num, new(big.Int).Mul(den, k)) {
+		k.Add(k, one)
+	}
+	return k.Bit(0) == 1
+}
+
+// discreteLaplace samples y on out with probability proportional to exp(-|y| * den/num)
+// (Canonne, Kamath and Steinke, NeurIPS 2020, Algorithm 2).
+func (n *DPNoise) discreteLaplace(num, den *big.Int, out *big.Int) {
+
+	one := big.NewInt(1)
+	for {
+		u := n.uniform(num)
+		if !n.bernoulliExp(u, num) {
+			continue
+		}
+
+		v := new(big.Int)
+		for n.bernoulliExpUnit(one, one) {
+			v.Add(v, one)
+		}
+
+		// y = floor((u + num * v)/den)
+		out.Mul(num, v)
+		out.Add(out, u)
+		out.Quo(out, den)
+
+		if n.bernoulli(one, big.NewInt(2)) {
+			if out.Sign() == 0 {
+				continue
+			}
+			out.Neg(out)
+		}
+
+		return
+	}
+}
+
+// discreteGaussian samples y on out with probability proportional to exp(-y^2/(2*sigma2))
+// (Canonne, Kamath and Steinke, NeurIPS 2020, Algorithm 3).
+func (n *DPNoise) discreteGaussian(sigma2 *big.Rat, out *big.Int) {
+
+	// t = floor(sigma) + 1
+	t := new(big.Int).Quo(sigma2.Num(), sigma2.Denom())
+	t.Sqrt(t)
+	t.Add(t, big.NewInt(1))
+
+	// sigma2/t
+	center := new(big.Rat).Quo(sigma2, new(big.Rat).SetInt(t))
+	twoSigma2 := new(big.Rat).Add(sigma2, sigma2)
+
+	y := new(big.Rat)
+	for {
+		n.discreteLaplace(t, big.NewInt(1), out)
+
+		// accepts with probability exp(-(|y| - sigma2/t)^2/(2*sigma2))
+		y.SetInt(out)
+		y.Abs(y)
+		y.Sub(y, center)
+		y.Mul(y, y)
+		y.Quo(y, twoSigma2)
+
+		if n.bernoulliExp(y.Num(), y.Denom()) {
+			return
+		}
+	}
+}
+
+// analyticGaussianSigma returns the smallest sigma such that the Gaussian mechanism with
+// standard deviation sigma is (epsilon, delta)-differentially private.
+func analyticGaussianSigma(epsilon, delta, sensitivity float64) float64 {
+
+	phi := func(x float64) float64 {
+		return 0.5 * math.Erfc(-x/math.Sqrt2)
+	}
+
+	// privacy loss of the mechanism, which is decreasing in sigma
+	deltaOf := func(sigma float64) float64 {
+		a := sensitivity / (2 * sigma)
+		b := epsilon * sigma / sensitivity
+		return phi(a-b) - math.Exp(epsilon)*phi(-a-b)
+	}
+
+	lo, hi := 0.0, sensitivity
+	for deltaOf(hi) > delta {
+		lo, hi = hi, 2*hi
+	}
+
+	for i := 0; i < 64; i++ {
+		mid := (lo + hi) / 2
+		if deltaOf(mid) > delta {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return hi
+}
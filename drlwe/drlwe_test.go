@@ -1,7 +1,9 @@
 package drlwe
 
 import (
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"math"
@@ -13,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
 	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
@@ -99,7 +102,16 @@ func TestDRLWE(t *testing.T) {
 			testPublicKeySwitching,
 			testRelinKeyGen,
 			testRotKeyGen,
+			testPublicSwitchingKeyGen,
+			testShareCompression,
+			testThreshold,
+			testSmudging,
+			testDP,
+			testProofs,
 			testMarshalling,
+			testValidate,
+			testDeterministic,
+			testSealedEphemeralKey,
 		} {
 			testSet(textCtx, t)
 			runtime.GC()
@@ -276,105 +288,109 @@ func testRelinKeyGen(testCtx testContext, t *testing.T) {
 	ringQ := params.RingQ()
 	ringP := params.RingP()
 	ringQP := params.RingQP()
-	levelQ, levelP := params.QCount()-1, params.PCount()-1
 
-	decompPw2 := params.DecompPw2(levelQ, levelP)
+	for _, levels := range testKeyLevels(params) {
 
-	t.Run(testString(params, "RelinKeyGen"), func(t *testing.T) {
+		levelQ, levelP := levels[0], levels[1]
 
-		rkg := make([]*RKGProtocol, nbParties)
+		decompPw2 := params.DecompPw2(levelQ, levelP)
 
-		for i := range rkg {
-			if i == 0 {
-				rkg[i] = NewRKGProtocol(params)
-			} else {
-				rkg[i] = rkg[0].ShallowCopy()
+		t.Run(testString(params, fmt.Sprintf("RelinKeyGen/levelQ=%d/levelP=%d", levelQ, levelP)), func(t *testing.T) {
+
+			rkg := make([]*RKGProtocol, nbParties)
+
+			for i := range rkg {
+				if i == 0 {
+					rkg[i] = NewRKGProtocol(params)
+				} else {
+					rkg[i] = rkg[0].ShallowCopy()
+				}
 			}
-		}
 
-		var _ RelinearizationKeyGenerator = rkg[0]
+			var _ RelinearizationKeyGenerator = rkg[0]
 
-		ephSk := make([]*rlwe.SecretKey, nbParties)
-		share1 := make([]*RKGShare, nbParties)
-		share2 := make([]*RKGShare, nbParties)
+			ephSk := make([]*rlwe.SecretKey, nbParties)
+			share1 := make([]*RKGShare, nbParties)
+			share2 := make([]*RKGShare, nbParties)
 
-		for i := range rkg {
-			ephSk[i], share1[i], share2[i] = rkg[i].AllocateShare()
-		}
+			for i := range rkg {
+				ephSk[i], share1[i], share2[i] = rkg[i].AllocateShareLvl(levelQ, levelP)
+			}
 
-		crp := rkg[0].SampleCRP(testCtx.crs)
-		for i := range rkg {
-			rkg[i].GenShareRoundOne(testCtx.skShares[i], crp, ephSk[i], share1[i])
-		}
+			crp := rkg[0].SampleCRPLvl(levelQ, levelP, testCtx.crs)
+			for i := range rkg {
+				rkg[i].GenShareRoundOne(testCtx.skShares[i], crp, ephSk[i], share1[i])
+			}
 
-		for i := 1; i < nbParties; i++ {
-			rkg[0].AggregateShare(share1[0], share1[i], share1[0])
-		}
+			for i := 1; i < nbParties; i++ {
+				rkg[0].AggregateShare(share1[0], share1[i], share1[0])
+			}
 
-		for i := range rkg {
-			rkg[i].GenShareRoundTwo(ephSk[i], testCtx.skShares[i], share1[0], share2[i])
-		}
+			for i := range rkg {
+				rkg[i].GenShareRoundTwo(ephSk[i], testCtx.skShares[i], share1[0], share2[i])
+			}
 
-		for i := 1; i < nbParties; i++ {
-			rkg[0].AggregateShare(share2[0], share2[i], share2[0])
-		}
+			for i := 1; i < nbParties; i++ {
+				rkg[0].AggregateShare(share2[0], share2[i], share2[0])
+			}
 
-		rlk := rlwe.NewRelinKey(params, 2)
-		rkg[0].GenRelinearizationKey(share1[0], share2[0], rlk)
+			rlk := rlwe.NewRelinKeyLvl(params, 2, levelQ, levelP)
+			rkg[0].GenRelinearizationKey(share1[0], share2[0], rlk)
 
-		skIn := testCtx.skIdeal.CopyNew()
-		skOut := testCtx.skIdeal.CopyNew()
-		ringQP.MulCoeffsMontgomeryLvl(levelQ, levelP, skIn.Value, skIn.Value, skIn.Value)
+			skIn := testCtx.skIdeal.CopyNew()
+			skOut := testCtx.skIdeal.CopyNew()
+			ringQP.MulCoeffsMontgomeryLvl(levelQ, levelP, skIn.Value, skIn.Value, skIn.Value)
 
-		swk := rlk.Keys[0]
+			swk := rlk.Keys[0]
 
-		// Decrypts
-		// [-asIn + w*P*sOut + e, a] + [asIn]
-		for i := range swk.Value {
-			for j := range swk.Value[i] {
-				ringQP.MulCoeffsMontgomeryAndAddLvl(levelQ, levelP, swk.Value[i][j].Value[1], skOut.Value, swk.Value[i][j].Value[0])
+			// Decrypts
+			// [-asIn + w*P*sOut + e, a] + [asIn]
+			for i := range swk.Value {
+				for j := range swk.Value[i] {
+					ringQP.MulCoeffsMontgomeryAndAddLvl(levelQ, levelP, swk.Value[i][j].Value[1], skOut.Value, swk.Value[i][j].Value[0])
+				}
 			}
-		}
 
-		// Sums all basis together (equivalent to multiplying with CRT decomposition of 1)
-		// sum([1]_w * [RNS*PW2*P*sOut + e]) = PW2*P*sOut + sum(e)
-		for i := range swk.Value { // RNS decomp
-			if i > 0 {
-				for j := range swk.Value[i] { // PW2 decomp
-					ringQP.AddLvl(levelQ, levelP, swk.Value[0][j].Value[0], swk.Value[i][j].Value[0], swk.Value[0][j].Value[0])
+			// Sums all basis together (equivalent to multiplying with CRT decomposition of 1)
+			// sum([1]_w * [RNS*PW2*P*sOut + e]) = PW2*P*sOut + sum(e)
+			for i := range swk.Value { // RNS decomp
+				if i > 0 {
+					for j := range swk.Value[i] { // PW2 decomp
+						ringQP.AddLvl(levelQ, levelP, swk.Value[0][j].Value[0], swk.Value[i][j].Value[0], swk.Value[0][j].Value[0])
+					}
 				}
 			}
-		}
 
-		if levelP != -1 {
-			// sOut * P
-			ringQ.MulScalarBigint(skIn.Value.Q, ringP.ModulusAtLevel[levelP], skIn.Value.Q)
-		}
+			if levelP != -1 {
+				// sOut * P
+				ringQ.MulScalarBigint(skIn.Value.Q, ringP.ModulusAtLevel[levelP], skIn.Value.Q)
+			}
 
-		log2Bound := bits.Len64(uint64(params.N() * len(swk.Value) * len(swk.Value[0]) * (params.N()*3*int(math.Floor(rlwe.DefaultSigma*6)) + 2*3*int(math.Floor(rlwe.DefaultSigma*6)) + params.N()*3)))
-		for i := 0; i < decompPw2; i++ {
+			log2Bound := bits.Len64(uint64(params.N() * len(swk.Value) * len(swk.Value[0]) * (params.N()*3*int(math.Floor(rlwe.DefaultSigma*6)) + 2*3*int(math.Floor(rlwe.DefaultSigma*6)) + params.N()*3)))
+			for i := 0; i < decompPw2; i++ {
 
-			// P*s^i + sum(e) - P*s^i = sum(e)
-			ringQ.Sub(swk.Value[0][i].Value[0].Q, skIn.Value.Q, swk.Value[0][i].Value[0].Q)
+				// P*s^i + sum(e) - P*s^i = sum(e)
+				ringQ.Sub(swk.Value[0][i].Value[0].Q, skIn.Value.Q, swk.Value[0][i].Value[0].Q)
 
-			// Checks that the error is below the bound
-			// Worst error bound is N * floor(6*sigma) * #Keys
-			ringQP.InvNTTLvl(levelQ, levelP, swk.Value[0][i].Value[0], swk.Value[0][i].Value[0])
-			ringQP.InvMFormLvl(levelQ, levelP, swk.Value[0][i].Value[0], swk.Value[0][i].Value[0])
+				// Checks that the error is below the bound
+				// Worst error bound is N * floor(6*sigma) * #Keys
+				ringQP.InvNTTLvl(levelQ, levelP, swk.Value[0][i].Value[0], swk.Value[0][i].Value[0])
+				ringQP.InvMFormLvl(levelQ, levelP, swk.Value[0][i].Value[0], swk.Value[0][i].Value[0])
 
-			// Worst bound of inner sum
-			// N*#Keys*(N * #Parties * floor(sigma*6) + #Parties * floor(sigma*6) + N * #Parties  +  #Parties * floor(6*sigma))
+				// Worst bound of inner sum
+				// N*#Keys*(N * #Parties * floor(sigma*6) + #Parties * floor(sigma*6) + N * #Parties  +  #Parties * floor(6*sigma))
 
-			require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(levelQ, ringQ, swk.Value[0][i].Value[0].Q))
+				require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(levelQ, ringQ, swk.Value[0][i].Value[0].Q))
 
-			if levelP != -1 {
-				require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(levelP, ringP, swk.Value[0][i].Value[0].P))
-			}
+				if levelP != -1 {
+					require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(levelP, ringP, swk.Value[0][i].Value[0].P))
+				}
 
-			// sOut * P * PW2
-			ringQ.MulScalar(skIn.Value.Q, 1<<params.Pow2Base(), skIn.Value.Q)
-		}
-	})
+				// sOut * P * PW2
+				ringQ.MulScalar(skIn.Value.Q, 1<<params.Pow2Base(), skIn.Value.Q)
+			}
+		})
+	}
 }
 
 func testRotKeyGen(testCtx testContext, t *testing.T) {
@@ -383,100 +399,670 @@ func testRotKeyGen(testCtx testContext, t *testing.T) {
 	ringQ := params.RingQ()
 	ringP := params.RingP()
 	ringQP := params.RingQP()
-	levelQ, levelP := params.QCount()-1, params.PCount()-1
 
-	decompPw2 := params.DecompPw2(levelQ, levelP)
+	for _, levels := range testKeyLevels(params) {
+
+		levelQ, levelP := levels[0], levels[1]
+
+		decompPw2 := params.DecompPw2(levelQ, levelP)
+
+		t.Run(testString(params, fmt.Sprintf("RotKeyGen/levelQ=%d/levelP=%d", levelQ, levelP)), func(t *testing.T) {
+
+			rtg := make([]*RTGProtocol, nbParties)
+			for i := range rtg {
+				if i == 0 {
+					rtg[i] = NewRTGProtocol(params)
+				} else {
+					rtg[i] = rtg[0].ShallowCopy()
+				}
+			}
+
+			var _ RotationKeyGenerator = rtg[0]
+
+			shares := make([]*RTGShare, nbParties)
+			for i := range shares {
+				shares[i] = rtg[i].AllocateShareLvl(levelQ, levelP)
+			}
+
+			crp := rtg[0].SampleCRPLvl(levelQ, levelP, testCtx.crs)
+
+			galEl := params.GaloisElementForRowRotation()
+
+			for i := range shares {
+				rtg[i].GenShare(testCtx.skShares[i], galEl, crp, shares[i])
+			}
+
+			for i := 1; i < nbParties; i++ {
+				rtg[0].AggregateShare(shares[0], shares[i], shares[0])
+			}
+
+			rotKeySet := rlwe.NewRotationKeySetLvl(params, []uint64{galEl}, levelQ, levelP)
+			rtg[0].GenRotationKey(shares[0], crp, rotKeySet.Keys[galEl])
+
+			skIn := testCtx.skIdeal.CopyNew()
+			skOut := testCtx.skIdeal.CopyNew()
+			galElInv := ring.ModExp(galEl, uint64(2*params.N()-1), uint64(2*params.N()))
+			ringQ.PermuteNTT(testCtx.skIdeal.Value.Q, galElInv, skOut.Value.Q)
+
+			if levelP != -1 {
+				ringP.PermuteNTT(testCtx.skIdeal.Value.P, galElInv, skOut.Value.P)
+			}
+
+			swk := rotKeySet.Keys[galEl]
+
+			// Decrypts
+			// [-asIn + w*P*sOut + e, a] + [asIn]
+			for i := range swk.Value {
+				for j := range swk.Value[i] {
+					ringQP.MulCoeffsMontgomeryAndAddLvl(levelQ, levelP, swk.Value[i][j].Value[1], skOut.Value, swk.Value[i][j].Value[0])
+				}
+			}
+
+			// Sums all basis together (equivalent to multiplying with CRT decomposition of 1)
+			// sum([1]_w * [RNS*PW2*P*sOut + e]) = PWw*P*sOut + sum(e)
+			for i := range swk.Value { // RNS decomp
+				if i > 0 {
+					for j := range swk.Value[i] { // PW2 decomp
+						ringQP.AddLvl(levelQ, levelP, swk.Value[0][j].Value[0], swk.Value[i][j].Value[0], swk.Value[0][j].Value[0])
+					}
+				}
+			}
+
+			if levelP != -1 {
+				// sOut * P
+				ringQ.MulScalarBigint(skIn.Value.Q, ringP.ModulusAtLevel[levelP], skIn.Value.Q)
+			}
+
+			log2Bound := bits.Len64(uint64(params.N() * len(swk.Value) * len(swk.Value[0]) * (params.N()*3*int(math.Floor(rlwe.DefaultSigma*6)) + 2*3*int(math.Floor(rlwe.DefaultSigma*6)) + params.N()*3)))
+			for i := 0; i < decompPw2; i++ {
 
-	t.Run(testString(params, "RotKeyGen"), func(t *testing.T) {
+				// P*s^i + sum(e) - P*s^i = sum(e)
+				ringQ.Sub(swk.Value[0][i].Value[0].Q, skIn.Value.Q, swk.Value[0][i].Value[0].Q)
 
-		rtg := make([]*RTGProtocol, nbParties)
-		for i := range rtg {
+				// Checks that the error is below the bound
+				// Worst error bound is N * floor(6*sigma) * #Keys
+				ringQP.InvNTTLvl(levelQ, levelP, swk.Value[0][i].Value[0], swk.Value[0][i].Value[0])
+				ringQP.InvMFormLvl(levelQ, levelP, swk.Value[0][i].Value[0], swk.Value[0][i].Value[0])
+
+				// Worst bound of inner sum
+				// N*#Keys*(N * #Parties * floor(sigma*6) + #Parties * floor(sigma*6) + N * #Parties  +  #Parties * floor(6*sigma))
+
+				require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(levelQ, ringQ, swk.Value[0][i].Value[0].Q))
+
+				if levelP != -1 {
+					require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(levelP, ringP, swk.Value[0][i].Value[0].P))
+				}
+
+				// sOut * P * PW2
+				ringQ.MulScalar(skIn.Value.Q, 1<<params.Pow2Base(), skIn.Value.Q)
+			}
+		})
+	}
+}
+
+// testKeyLevels returns the levels at which the collective evaluation keys are tested: the maximum levels
+// and truncated levels.
+func testKeyLevels(params rlwe.Parameters) [][2]int {
+	return [][2]int{
+		{params.QCount() - 1, params.PCount() - 1},
+		{params.MaxLevel() / 2, utils.MinInt(0, params.PCount()-1)},
+	}
+}
+
+func testPublicSwitchingKeyGen(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQ := params.RingQ()
+	levelQ, levelP := params.QCount()-1, params.PCount()-1
+
+	t.Run(testString(params, "PublicSwitchingKeyGen"), func(t *testing.T) {
+
+		pskg := make([]*PSKGProtocol, nbParties)
+		for i := range pskg {
 			if i == 0 {
-				rtg[i] = NewRTGProtocol(params)
+				pskg[i] = NewPSKGProtocol(params)
 			} else {
-				rtg[i] = rtg[0].ShallowCopy()
+				pskg[i] = pskg[0].ShallowCopy()
 			}
 		}
 
-		var _ RotationKeyGenerator = rtg[0]
+		var _ PublicSwitchingKeyGenerator = pskg[0]
+
+		// collective key of the target party set
+		skOut := testCtx.kgen.GenSecretKey()
+		pkOut := testCtx.kgen.GenPublicKey(skOut)
 
-		shares := make([]*RTGShare, nbParties)
+		shares := make([]*PSKGShare, nbParties)
 		for i := range shares {
-			shares[i] = rtg[i].AllocateShare()
+			shares[i] = pskg[i].AllocateShare()
+			pskg[i].GenShare(testCtx.skShares[i], pkOut, shares[i])
+			if i > 0 {
+				pskg[0].AggregateShare(shares[0], shares[i], shares[0])
+			}
+		}
+
+		swk := rlwe.NewSwitchingKey(params, levelQ, levelP)
+		pskg[0].GenSwitchingKey(shares[0], swk)
+
+		// noise of the key-switching of a noiseless encryption of zero
+		noise := func(swk *rlwe.SwitchingKey) float64 {
+			ct := rlwe.NewCiphertextNTT(params, 1, levelQ)
+			testCtx.uniformSampler.Read(ct.Value[1])
+			ringQ.MulCoeffsMontgomeryAndSub(ct.Value[1], testCtx.skIdeal.Value.Q, ct.Value[0])
+			rlwe.NewEvaluator(params, nil).SwitchKeys(ct, swk, ct)
+			ringQ.MulCoeffsMontgomeryAndAdd(ct.Value[1], skOut.Value.Q, ct.Value[0])
+			ringQ.InvNTT(ct.Value[0], ct.Value[0])
+			return math.Log2(float64(normInf(centered(ct.Value[0].Coeffs[0], ringQ.Modulus[0]))) + 1)
+		}
+
+		// the switching key is an encryption under a public key, whose noise is larger
+		// by a factor about sqrt(N * #Parties) than the one of a secret-key encryption
+		want := noise(testCtx.kgen.GenSwitchingKey(testCtx.skIdeal, skOut))
+		require.LessOrEqual(t, noise(swk), want+float64(params.LogN())/2+math.Log2(float64(nbParties))+2)
+
+		data, err := shares[0].MarshalBinary()
+		require.NoError(t, err)
+		share := new(PSKGShare)
+		require.NoError(t, share.UnmarshalBinary(data))
+		for i := range share.Value {
+			for j := range share.Value[i] {
+				for k := range share.Value[i][j] {
+					require.True(t, shares[0].Value[i][j][k].Equals(share.Value[i][j][k]))
+				}
+			}
 		}
+	})
+}
 
-		crp := rtg[0].SampleCRP(testCtx.crs)
+func testShareCompression(testCtx testContext, t *testing.T) {
 
-		galEl := params.GaloisElementForRowRotation()
+	params := testCtx.params
+	ringQ := params.RingQ()
+	levelQ, levelP := params.QCount()-1, params.PCount()-1
 
+	logDrop := 16
+
+	ciphertext := &rlwe.Ciphertext{Value: []*ring.Poly{ringQ.NewPoly(), ringQ.NewPoly()}}
+	testCtx.uniformSampler.Read(ciphertext.Value[1])
+	ringQ.MulCoeffsMontgomeryAndSub(ciphertext.Value[1], testCtx.skIdeal.Value.Q, ciphertext.Value[0])
+	ciphertext.Value[0].IsNTT = true
+	ciphertext.Value[1].IsNTT = true
+
+	// checks that the decompressed polynomial is within 2^(logDrop-1) of the original one
+	checkRoundTrip := func(t *testing.T, have, want *ring.Poly) {
+		require.Equal(t, want.IsNTT, have.IsNTT)
+		require.Equal(t, want.Level(), have.Level())
+		diff := ringQ.NewPoly()
+		ringQ.Sub(have, want, diff)
+		ringQ.InvNTT(diff, diff)
+		for i := 0; i < levelQ+1; i++ {
+			require.LessOrEqual(t, normInf(centered(diff.Coeffs[i], ringQ.Modulus[i])), uint64(1)<<(logDrop-1))
+		}
+	}
+
+	t.Run(testString(params, "ShareCompression/CKS"), func(t *testing.T) {
+
+		cks := NewCKSProtocol(params, rlwe.DefaultSigma)
+		comp := NewShareCompressor(params, logDrop)
+
+		skOut := make([]*rlwe.SecretKey, nbParties)
+		skOutIdeal := rlwe.NewSecretKey(params)
+		shares := make([]*CKSShare, nbParties)
+		decoded := make([]*CKSShare, nbParties)
 		for i := range shares {
-			rtg[i].GenShare(testCtx.skShares[i], galEl, crp, shares[i])
+			skOut[i] = testCtx.kgen.GenSecretKey()
+			params.RingQP().AddLvl(levelQ, levelP, skOutIdeal.Value, skOut[i].Value, skOutIdeal.Value)
+
+			shares[i] = cks.AllocateShare(ciphertext.Level())
+			cks.GenShare(testCtx.skShares[i], skOut[i], ciphertext.Value[1], shares[i])
+
+			data, err := comp.MarshalCKSShare(shares[i])
+			require.NoError(t, err)
+
+			full, err := shares[i].MarshalBinary()
+			require.NoError(t, err)
+			require.Less(t, len(data), len(full))
+
+			decoded[i] = new(CKSShare)
+			require.NoError(t, comp.UnmarshalCKSShare(data, decoded[i]))
+			checkRoundTrip(t, decoded[i].Value, shares[i].Value)
 		}
 
 		for i := 1; i < nbParties; i++ {
-			rtg[0].AggregateShare(shares[0], shares[i], shares[0])
+			cks.AggregateShare(decoded[0], decoded[i], decoded[0])
 		}
 
-		rotKeySet := rlwe.NewRotationKeySet(params, []uint64{galEl})
-		rtg[0].GenRotationKey(shares[0], crp, rotKeySet.Keys[galEl])
+		ksCiphertext := &rlwe.Ciphertext{Value: []*ring.Poly{ringQ.NewPoly(), ringQ.NewPoly()}}
+		cks.KeySwitch(ciphertext, decoded[0], ksCiphertext)
 
-		skIn := testCtx.skIdeal.CopyNew()
-		skOut := testCtx.skIdeal.CopyNew()
-		galElInv := ring.ModExp(galEl, uint64(2*params.N()-1), uint64(2*params.N()))
-		ringQ.PermuteNTT(testCtx.skIdeal.Value.Q, galElInv, skOut.Value.Q)
+		ringQ.MulCoeffsMontgomeryAndAdd(ksCiphertext.Value[1], skOutIdeal.Value.Q, ksCiphertext.Value[0])
+		ringQ.InvNTT(ksCiphertext.Value[0], ksCiphertext.Value[0])
+		log2Bound := bits.Len64(3*uint64(math.Floor(rlwe.DefaultSigma*6))*uint64(params.N())) + int(math.Ceil(comp.LogError(nbParties)))
+		require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(ksCiphertext.Value[0].Level(), ringQ, ksCiphertext.Value[0]))
 
-		if levelP != -1 {
-			ringP.PermuteNTT(testCtx.skIdeal.Value.P, galElInv, skOut.Value.P)
-		}
+		// a share compressed with another LogDrop is rejected
+		data, err := comp.MarshalCKSShare(shares[0])
+		require.NoError(t, err)
+		require.Error(t, NewShareCompressor(params, logDrop+1).UnmarshalCKSShare(data, new(CKSShare)))
+	})
+
+	t.Run(testString(params, "ShareCompression/PCKS"), func(t *testing.T) {
+
+		_, pkOut := testCtx.kgen.GenKeyPair()
+
+		pcks := NewPCKSProtocol(params, rlwe.DefaultSigma)
+		comp := NewShareCompressor(params, logDrop)
+
+		share := pcks.AllocateShare(ciphertext.Level())
+		pcks.GenShare(testCtx.skShares[0], pkOut, ciphertext.Value[1], share)
+
+		data, err := comp.MarshalPCKSShare(share)
+		require.NoError(t, err)
+
+		full, err := share.MarshalBinary()
+		require.NoError(t, err)
+		require.Less(t, len(data), len(full))
+
+		decoded := new(PCKSShare)
+		require.NoError(t, comp.UnmarshalPCKSShare(data, decoded))
+		checkRoundTrip(t, decoded.Value[0], share.Value[0])
+		checkRoundTrip(t, decoded.Value[1], share.Value[1])
+	})
+}
+
+func testThreshold(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQP := params.RingQP()
+	levelQ, levelP := params.QCount()-1, params.PCount()-1
+
+	for _, threshold := range []int{1, nbParties - 1, nbParties} {
+
+		t.Run(testString(params, "Threshold")+fmt.Sprintf("/threshold=%d", threshold), func(t *testing.T) {
+
+			type Party struct {
+				*Thresholdizer
+				*Combiner
+				gen  *ShamirPolynomial
+				sk   *rlwe.SecretKey
+				tsks *ShamirSecretShare
+				tsk  *rlwe.SecretKey
+				tpk  ShamirPublicPoint
+			}
+
+			P := make([]*Party, nbParties)
+			shamirPks := make([]ShamirPublicPoint, nbParties)
+			for i := range P {
+				shamirPks[i] = ShamirPublicPoint(i + 1)
+			}
+
+			for i := range P {
+				p := new(Party)
+				p.Thresholdizer = NewThresholdizer(params)
+				p.sk = testCtx.skShares[i]
+				p.tsk = rlwe.NewSecretKey(params)
+				p.tpk = shamirPks[i]
+				p.Combiner = NewCombiner(params, p.tpk, shamirPks, threshold)
+				p.tsks = p.Thresholdizer.AllocateThresholdSecretShare()
+				P[i] = p
+			}
+
+			var _ ThresholdizerProtocol = P[0].Thresholdizer
+			var _ CombinerProtocol = P[0].Combiner
 
-		swk := rotKeySet.Keys[galEl]
+			shares := make(map[*Party]map[*Party]*ShamirSecretShare, nbParties)
+			var err error
+			// Every party generates a share for every other party
+			for _, pi := range P {
+
+				pi.gen, err = pi.Thresholdizer.GenShamirPolynomial(threshold, pi.sk)
+				require.NoError(t, err)
+
+				shares[pi] = make(map[*Party]*ShamirSecretShare)
+				for _, pj := range P {
+					shares[pi][pj] = pi.Thresholdizer.AllocateThresholdSecretShare()
+					pi.Thresholdizer.GenShamirSecretShare(pj.tpk, pi.gen, shares[pi][pj])
+				}
+			}
+
+			//Each party aggregates what it has received into a secret key
+			for _, pi := range P {
+				for _, pj := range P {
+					pi.Thresholdizer.AggregateShares(pi.tsks, shares[pj][pi], pi.tsks)
+				}
+			}
+
+			// Determining which parties are active. In a distributed context, a party
+			// would receive the ids of active players and retrieve (or compute) the corresponding keys.
+			activeParties := P[nbParties-threshold:]
+			activeShamirPks := make([]ShamirPublicPoint, threshold)
+			for i, p := range activeParties {
+				activeShamirPks[i] = p.tpk
+			}
+
+			// Combining: each active party derives its additive share of the ideal secret key
+			recSk := rlwe.NewSecretKey(params)
+			for _, pi := range activeParties {
+				pi.Combiner.GenAdditiveShare(activeShamirPks, pi.tpk, pi.tsks, pi.tsk)
+				ringQP.AddLvl(levelQ, levelP, pi.tsk.Value, recSk.Value, recSk.Value)
+			}
 
-		// Decrypts
-		// [-asIn + w*P*sOut + e, a] + [asIn]
-		for i := range swk.Value {
-			for j := range swk.Value[i] {
-				ringQP.MulCoeffsMontgomeryAndAddLvl(levelQ, levelP, swk.Value[i][j].Value[1], skOut.Value, swk.Value[i][j].Value[0])
+			require.True(t, testCtx.skIdeal.Value.Equals(recSk.Value)) // reconstructed key should match the ideal sk
+
+			// the share at zero would be the secret, and duplicated active parties would yield a wrong key
+			require.Panics(t, func() {
+				P[0].Thresholdizer.GenShamirSecretShare(0, P[0].gen, P[0].Thresholdizer.AllocateThresholdSecretShare())
+			})
+			if threshold > 1 {
+				pi := activeParties[0]
+				duplicates := append([]ShamirPublicPoint{}, activeShamirPks...)
+				duplicates[len(duplicates)-1] = duplicates[len(duplicates)-2]
+				require.Panics(t, func() { pi.Combiner.GenAdditiveShare(duplicates, pi.tpk, pi.tsks, rlwe.NewSecretKey(params)) })
 			}
+
+			data, err := P[0].tsks.MarshalBinary()
+			require.NoError(t, err)
+			tsksAfter := new(ShamirSecretShare)
+			require.NoError(t, tsksAfter.UnmarshalBinary(data))
+			require.True(t, P[0].tsks.Equals(tsksAfter.Poly))
+		})
+	}
+}
+
+func testSmudging(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQ := params.RingQ()
+
+	t.Run(testString(params, "Smudging/Calibration"), func(t *testing.T) {
+
+		// largest statistical security parameter for which the tests fit the smallest modulus
+		logQMin := 60
+		for _, qi := range ringQ.Modulus {
+			logQMin = utils.MinInt(logQMin, bits.Len64(qi))
 		}
+		lambda := logQMin - params.LogN()/2 - 12
+
+		s, err := NewSmudging(params, SmudgingParameters{LogNoise: 4, Parties: nbParties, Lambda: lambda})
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, s.LogNoiseAfter(), s.LogBound)
+
+		sLow, err := NewSmudging(params, SmudgingParameters{LogNoise: 4, Parties: nbParties, Lambda: lambda - 5})
+		require.NoError(t, err)
+		require.Less(t, sLow.Sigma, s.Sigma)
+
+		sHigh, err := NewSmudging(params, SmudgingParameters{LogNoise: 8, Parties: nbParties, Lambda: lambda})
+		require.NoError(t, err)
+		require.Greater(t, sHigh.Sigma, s.Sigma)
+
+		_, err = NewSmudging(params, SmudgingParameters{LogNoise: 4, Parties: nbParties, Lambda: lambda, LogBudget: s.LogBound - 1})
+		require.Error(t, err)
+
+		_, err = NewSmudging(params, SmudgingParameters{LogNoise: 4, Lambda: lambda})
+		require.Error(t, err)
+
+		_, err = NewSmudging(params, SmudgingParameters{LogNoise: 60, Parties: nbParties})
+		require.Error(t, err)
+	})
+
+	t.Run(testString(params, "Smudging/KeySwitching"), func(t *testing.T) {
+
+		s, err := NewSmudging(params, SmudgingParameters{LogNoise: 0, Parties: nbParties, Lambda: 10})
+		require.NoError(t, err)
 
-		// Sums all basis together (equivalent to multiplying with CRT decomposition of 1)
-		// sum([1]_w * [RNS*PW2*P*sOut + e]) = PWw*P*sOut + sum(e)
-		for i := range swk.Value { // RNS decomp
+		cks := NewCKSProtocol(params, s.Sigma)
+		zero := rlwe.NewSecretKey(params)
+
+		// noiseless encryption of zero, so that the decryption is the aggregated smudging noise
+		ciphertext := &rlwe.Ciphertext{Value: []*ring.Poly{ringQ.NewPoly(), ringQ.NewPoly()}}
+		testCtx.uniformSampler.Read(ciphertext.Value[1])
+		ringQ.MulCoeffsMontgomeryAndSub(ciphertext.Value[1], testCtx.skIdeal.Value.Q, ciphertext.Value[0])
+		ciphertext.Value[0].IsNTT = true
+		ciphertext.Value[1].IsNTT = true
+
+		shares := make([]*CKSShare, nbParties)
+		for i := range shares {
+			shares[i] = cks.AllocateShare(ciphertext.Level())
+			cks.GenShare(testCtx.skShares[i], zero, ciphertext.Value[1], shares[i])
 			if i > 0 {
-				for j := range swk.Value[i] { // PW2 decomp
-					ringQP.AddLvl(levelQ, levelP, swk.Value[0][j].Value[0], swk.Value[i][j].Value[0], swk.Value[0][j].Value[0])
-				}
+				cks.AggregateShare(shares[0], shares[i], shares[0])
 			}
 		}
 
-		if levelP != -1 {
-			// sOut * P
-			ringQ.MulScalarBigint(skIn.Value.Q, ringP.ModulusAtLevel[levelP], skIn.Value.Q)
+		cks.KeySwitch(ciphertext, shares[0], ciphertext)
+		ringQ.InvNTT(ciphertext.Value[0], ciphertext.Value[0])
+
+		norm := normInf(centered(ciphertext.Value[0].Coeffs[0], ringQ.Modulus[0]))
+		require.LessOrEqual(t, math.Log2(float64(norm)), s.LogBound)
+		require.Greater(t, math.Log2(float64(norm)), s.LogBound-4)
+	})
+}
+
+func testDP(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	t.Run(testString(params, "DP/Calibration"), func(t *testing.T) {
+
+		gaussian, err := NewDPNoise(DPParameters{Mechanism: GaussianMechanism, Epsilon: 0.5, Delta: 1e-5, Sensitivity: 2, Parties: nbParties})
+		require.NoError(t, err)
+
+		// the analytic calibration is tighter than the classical one
+		require.Less(t, gaussian.Scale, 2*math.Sqrt(2*math.Log(1.25/1e-5))/0.5)
+		require.Greater(t, gaussian.Scale, 2*math.Sqrt(2*math.Log(1.25/1e-5))/0.5/2)
+
+		laplace, err := NewDPNoise(DPParameters{Mechanism: LaplaceMechanism, Epsilon: 0.5, Sensitivity: 2, Parties: nbParties})
+		require.NoError(t, err)
+		require.Equal(t, 4.0, laplace.Scale)
+
+		// each party adds the whole Laplace noise, but only its share of the Gaussian noise
+		colluding, err := NewDPNoise(DPParameters{Mechanism: LaplaceMechanism, Epsilon: 0.5, Sensitivity: 2, Parties: nbParties, Colluding: nbParties - 1})
+		require.NoError(t, err)
+		require.Equal(t, colluding.StdDev(), laplace.StdDev())
+
+		colluding, err = NewDPNoise(DPParameters{Mechanism: GaussianMechanism, Epsilon: 0.5, Delta: 1e-5, Sensitivity: 2, Parties: nbParties, Colluding: nbParties - 1})
+		require.NoError(t, err)
+		require.Greater(t, colluding.StdDev(), gaussian.StdDev())
+
+		for _, dp := range []DPParameters{
+			{Mechanism: GaussianMechanism, Epsilon: 1, Delta: 0, Sensitivity: 1, Parties: nbParties},
+			{Mechanism: LaplaceMechanism, Epsilon: 0, Sensitivity: 1, Parties: nbParties},
+			{Mechanism: LaplaceMechanism, Epsilon: 1, Sensitivity: 1, Parties: nbParties, Colluding: nbParties},
+		} {
+			_, err = NewDPNoise(dp)
+			require.Error(t, err)
 		}
+	})
 
-		log2Bound := bits.Len64(uint64(params.N() * len(swk.Value) * len(swk.Value[0]) * (params.N()*3*int(math.Floor(rlwe.DefaultSigma*6)) + 2*3*int(math.Floor(rlwe.DefaultSigma*6)) + params.N()*3)))
-		for i := 0; i < decompPw2; i++ {
+	t.Run(testString(params, "DP/Sampling"), func(t *testing.T) {
 
-			// P*s^i + sum(e) - P*s^i = sum(e)
-			ringQ.Sub(swk.Value[0][i].Value[0].Q, skIn.Value.Q, swk.Value[0][i].Value[0].Q)
+		// scaling factor of the sensitivity, as for the encoding of real values on integers
+		scale := 1000.0
 
-			// Checks that the error is below the bound
-			// Worst error bound is N * floor(6*sigma) * #Keys
-			ringQP.InvNTTLvl(levelQ, levelP, swk.Value[0][i].Value[0], swk.Value[0][i].Value[0])
-			ringQP.InvMFormLvl(levelQ, levelP, swk.Value[0][i].Value[0], swk.Value[0][i].Value[0])
+		variance := func(values []*big.Int) (variance float64) {
+			for _, v := range values {
+				f, _ := new(big.Float).SetInt(v).Float64()
+				variance += f * f
+			}
+			return variance / float64(len(values))
+		}
 
-			// Worst bound of inner sum
-			// N*#Keys*(N * #Parties * floor(sigma*6) + #Parties * floor(sigma*6) + N * #Parties  +  #Parties * floor(6*sigma))
+		for _, mechanism := range []DPMechanism{GaussianMechanism, LaplaceMechanism} {
 
-			require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(levelQ, ringQ, swk.Value[0][i].Value[0].Q))
+			noise, err := NewDPNoise(DPParameters{Mechanism: mechanism, Epsilon: 1, Delta: 1e-5, Sensitivity: 1, Parties: nbParties, Colluding: 1})
+			require.NoError(t, err)
 
-			if levelP != -1 {
-				require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(levelP, ringP, swk.Value[0][i].Value[0].P))
+			// the sum of the shares of the honest parties has at least the variance of the mechanism
+			values := make([]*big.Int, 1<<14)
+			sum := make([]*big.Int, len(values))
+			for j := range values {
+				values[j], sum[j] = new(big.Int), new(big.Int)
+			}
+
+			for i := 0; i < nbParties-1; i++ {
+				noise.ShallowCopy().Read(values, scale)
+				require.InDelta(t, 1, variance(values)/(noise.StdDev()*noise.StdDev()*scale*scale), 0.1)
+				for j := range sum {
+					sum[j].Add(sum[j], values[j])
+				}
+			}
+
+			want := noise.Scale * noise.Scale * scale * scale
+			if mechanism == LaplaceMechanism {
+				want *= 2 * float64(nbParties-1)
 			}
+			require.InDelta(t, 1, variance(sum)/want, 0.1)
+		}
+	})
+}
+
+// TestProofsRNS tests the proofs, also in the short test suite, with several moduli Q: the relations of the
+// proofs hold modulo each of the primes.
+func TestProofsRNS(t *testing.T) {
+	params, err := rlwe.NewParametersFromLiteral(rlwe.TestPN12QP109)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, params.QCount(), 2)
+	testProofs(newTestContext(params), t)
+}
+
+func testProofs(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQ := params.RingQ()
+
+	com := NewCommitmentProtocol(params)
+	comCRP := com.SampleCRP(testCtx.crs)
+	coms := make([]*Commitment, nbParties)
+	for i := range coms {
+		coms[i] = com.AllocateCommitment()
+		com.GenCommitment(testCtx.skShares[i], comCRP, coms[i])
+	}
+
+	// tamper adds one to a coefficient of p modulo the i-th prime
+	tamper := func(p *ring.Poly, i int) {
+		p.Coeffs[i][0] = (p.Coeffs[i][0] + 1) % ringQ.Modulus[i]
+	}
+
+	t.Run(testString(params, "Proof/CKG"), func(t *testing.T) {
 
-			// sOut * P * PW2
-			ringQ.MulScalar(skIn.Value.Q, 1<<params.Pow2Base(), skIn.Value.Q)
+		ckg := NewCKGProtocol(params)
+		crp := ckg.SampleCRP(testCtx.crs)
+
+		shares := make([]*CKGShare, nbParties)
+		proofs := make([]*Proof, nbParties)
+		for i := range shares {
+			shares[i] = ckg.AllocateShare()
+			ckg.GenShare(testCtx.skShares[i], crp, shares[i])
+			var err error
+			proofs[i], err = ckg.ProveShare(testCtx.skShares[i], crp, shares[i], comCRP, coms[i])
+			require.NoError(t, err)
+		}
+
+		for i := range shares {
+			require.True(t, ckg.VerifyShare(crp, shares[i], comCRP, coms[i], proofs[i]))
 		}
+
+		data, err := proofs[0].MarshalBinary()
+		require.NoError(t, err)
+		proof := new(Proof)
+		require.NoError(t, proof.UnmarshalBinary(data))
+		require.True(t, ckg.VerifyShare(crp, shares[0], comCRP, coms[0], proof))
+		require.Error(t, proof.UnmarshalBinary(data[:len(data)-1]))
+
+		// a proof does not verify for another party or another share
+		require.False(t, ckg.VerifyShare(crp, shares[0], comCRP, coms[1], proofs[0]))
+		require.False(t, ckg.VerifyShare(crp, shares[1], comCRP, coms[1], proofs[0]))
+
+		// the relations are checked modulo each prime: a share tampered modulo the last prime only is rejected
+		tamper(shares[0].Value.Q, shares[0].Value.Q.Level())
+		require.False(t, ckg.VerifyShare(crp, shares[0], comCRP, coms[0], proofs[0]))
+
+		tamper(shares[1].Value.Q, 0)
+		require.False(t, ckg.VerifyShare(crp, shares[1], comCRP, coms[1], proofs[1]))
+
+		// a share that is not well-formed cannot be proven, whether its error is too large or it is not
+		// consistent modulo all the primes
+		_, err = ckg.ProveShare(testCtx.skShares[0], crp, shares[0], comCRP, coms[0])
+		require.Error(t, err)
+
+		ringQ.AddScalar(shares[2].Value.Q, 1<<20, shares[2].Value.Q)
+		_, err = ckg.ProveShare(testCtx.skShares[2], crp, shares[2], comCRP, coms[2])
+		require.Error(t, err)
+	})
+
+	t.Run(testString(params, "Proof/RKG"), func(t *testing.T) {
+
+		rkg := NewRKGProtocol(params)
+		crp := rkg.SampleCRP(testCtx.crs)
+
+		ephSk := make([]*rlwe.SecretKey, nbParties)
+		share1 := make([]*RKGShare, nbParties)
+		share2 := make([]*RKGShare, nbParties)
+		for i := range ephSk {
+			ephSk[i], share1[i], share2[i] = rkg.AllocateShare()
+			rkg.GenShareRoundOne(testCtx.skShares[i], crp, ephSk[i], share1[i])
+			proof, err := rkg.ProveShareRoundOne(testCtx.skShares[i], ephSk[i], crp, share1[i], comCRP, coms[i])
+			require.NoError(t, err)
+			require.True(t, rkg.VerifyShareRoundOne(crp, share1[i], comCRP, coms[i], proof))
+			require.False(t, rkg.VerifyShareRoundOne(crp, share1[i], comCRP, coms[(i+1)%nbParties], proof))
+		}
+
+		_, round1, _ := rkg.AllocateShare()
+		rkg.AggregateShare(share1[0], share1[1], round1)
+		for i := 2; i < nbParties; i++ {
+			rkg.AggregateShare(round1, share1[i], round1)
+		}
+
+		for i := range ephSk {
+			rkg.GenShareRoundTwo(ephSk[i], testCtx.skShares[i], round1, share2[i])
+		}
+
+		proof, err := rkg.ProveShareRoundTwo(ephSk[0], testCtx.skShares[0], crp, share1[0], round1, share2[0], comCRP, coms[0])
+		require.NoError(t, err)
+		require.True(t, rkg.VerifyShareRoundTwo(crp, share1[0], round1, share2[0], comCRP, coms[0], proof))
+
+		// the share of the second round must use the ephemeral key of the share of the first round
+		require.False(t, rkg.VerifyShareRoundTwo(crp, share1[1], round1, share2[0], comCRP, coms[0], proof))
+
+		tamper(share2[0].Value[0][0][1].Q, share2[0].Value[0][0][1].Q.Level())
+		require.False(t, rkg.VerifyShareRoundTwo(crp, share1[0], round1, share2[0], comCRP, coms[0], proof))
+	})
+
+	t.Run(testString(params, "Proof/CKS"), func(t *testing.T) {
+
+		cks := NewCKSProtocol(params, rlwe.DefaultSigma).ShallowCopy()
+
+		skOut := testCtx.kgen.GenSecretKey()
+		comOut := com.AllocateCommitment()
+		com.GenCommitment(skOut, comCRP, comOut)
+
+		ciphertext := &rlwe.Ciphertext{Value: []*ring.Poly{ringQ.NewPoly(), ringQ.NewPoly()}}
+		testCtx.uniformSampler.Read(ciphertext.Value[1])
+		ciphertext.Value[1].IsNTT = true
+
+		share := cks.AllocateShare(ciphertext.Level())
+
+		// key-switching to skOut
+		cks.GenShare(testCtx.skShares[0], skOut, ciphertext.Value[1], share)
+		proof, err := cks.ProveShare(testCtx.skShares[0], skOut, ciphertext.Value[1], share, comCRP, coms[0], comOut)
+		require.NoError(t, err)
+		require.True(t, cks.VerifyShare(ciphertext.Value[1], share, comCRP, coms[0], comOut, proof))
+		require.False(t, cks.VerifyShare(ciphertext.Value[1], share, comCRP, coms[0], nil, proof))
+
+		// decryption
+		cks.GenShare(testCtx.skShares[0], rlwe.NewSecretKey(params), ciphertext.Value[1], share)
+		proof, err = cks.ProveShare(testCtx.skShares[0], nil, ciphertext.Value[1], share, comCRP, coms[0], nil)
+		require.NoError(t, err)
+		require.True(t, cks.VerifyShare(ciphertext.Value[1], share, comCRP, coms[0], nil, proof))
+		require.False(t, cks.VerifyShare(ciphertext.Value[1], share, comCRP, coms[1], nil, proof))
+
+		tamper(share.Value, share.Value.Level())
+		require.False(t, cks.VerifyShare(ciphertext.Value[1], share, comCRP, coms[0], nil, proof))
+
+		// the share of another secret key cannot be proven
+		cks.GenShare(testCtx.skShares[1], rlwe.NewSecretKey(params), ciphertext.Value[1], share)
+		_, err = cks.ProveShare(testCtx.skShares[0], nil, ciphertext.Value[1], share, comCRP, coms[0], nil)
+		require.Error(t, err)
 	})
 }
 
@@ -519,6 +1105,63 @@ func testMarshalling(testCtx testContext, t *testing.T) {
 		}
 	})
 
+	t.Run(testString(params, "Marshalling/Packed"), func(t *testing.T) {
+
+		type marshaler interface {
+			MarshalBinary() ([]byte, error)
+			MarshalBinaryPacked() ([]byte, error)
+			UnmarshalBinary([]byte) error
+		}
+
+		// Checks that the packed encoding is smaller than the 64-bit one and
+		// decodes to the same share with UnmarshalBinary.
+		checkPacked := func(t *testing.T, want, have marshaler) {
+			data, err := want.MarshalBinary()
+			require.NoError(t, err)
+			dataPacked, err := want.MarshalBinaryPacked()
+			require.NoError(t, err)
+			require.Less(t, len(dataPacked), len(data))
+			require.NoError(t, have.UnmarshalBinary(dataPacked))
+			dataHave, err := have.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, data, dataHave)
+		}
+
+		ckg := NewCKGProtocol(params)
+		ckgShare := ckg.AllocateShare()
+		ckg.GenShare(testCtx.skShares[0], ckg.SampleCRP(testCtx.crs), ckgShare)
+		checkPacked(t, ckgShare, new(CKGShare))
+
+		rkg := NewRKGProtocol(params)
+		ephSk, rkgShare1, _ := rkg.AllocateShare()
+		rkg.GenShareRoundOne(testCtx.skShares[0], rkg.SampleCRP(testCtx.crs), ephSk, rkgShare1)
+		checkPacked(t, rkgShare1, new(RKGShare))
+
+		rtg := NewRTGProtocol(params)
+		rtgShare := rtg.AllocateShare()
+		rtg.GenShare(testCtx.skShares[0], params.GaloisElementForColumnRotationBy(1), rtg.SampleCRP(testCtx.crs), rtgShare)
+		checkPacked(t, rtgShare, new(RTGShare))
+
+		cks := NewCKSProtocol(params, rlwe.DefaultSigma)
+		cksShare := cks.AllocateShare(ciphertext.Level())
+		cks.GenShare(testCtx.skShares[0], testCtx.skShares[1], ciphertext.Value[1], cksShare)
+		checkPacked(t, cksShare, new(CKSShare))
+
+		_, pkOut := testCtx.kgen.GenKeyPair()
+
+		pcks := NewPCKSProtocol(params, rlwe.DefaultSigma)
+		pcksShare := pcks.AllocateShare(ciphertext.Level())
+		pcks.GenShare(testCtx.skShares[0], pkOut, ciphertext.Value[1], pcksShare)
+		checkPacked(t, pcksShare, new(PCKSShare))
+
+		pskg := NewPSKGProtocol(params)
+		pskgShare := pskg.AllocateShare()
+		pskg.GenShare(testCtx.skShares[0], pkOut, pskgShare)
+		checkPacked(t, pskgShare, new(PSKGShare))
+
+		checkPacked(t, &ShamirSecretShare{testCtx.skShares[0].Value}, new(ShamirSecretShare))
+	})
+
 	t.Run(testString(params, "Marshalling/PCKS"), func(t *testing.T) {
 		//Check marshalling for the PCKS
 
@@ -689,3 +1332,266 @@ func log2OfInnerSum(level int, ringQ *ring.Ring, poly *ring.Poly) (logSum int) {
 
 	return
 }
+
+// share is the interface of the shares of the protocols that are checked against the parameters.
+type share interface {
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary([]byte) error
+	rlwe.Validator
+}
+
+// genShares returns a share of each protocol, indexed by the name of its type, and the constructors of the
+// empty shares of each type.
+func genShares(testCtx testContext) (shares map[string]share, newShare map[string]func() share) {
+
+	params := testCtx.params
+	sk := testCtx.skShares[0]
+	_, pkOut := testCtx.kgen.GenKeyPair()
+
+	ct1 := params.RingQ().NewPoly()
+	testCtx.uniformSampler.Read(ct1)
+
+	ckg := NewCKGProtocol(params)
+	ckgShare := ckg.AllocateShare()
+	ckg.GenShare(sk, ckg.SampleCRP(testCtx.crs), ckgShare)
+
+	rkg := NewRKGProtocol(params)
+	ephSk, rkgShare1, rkgShare2 := rkg.AllocateShare()
+	rkg.GenShareRoundOne(sk, rkg.SampleCRP(testCtx.crs), ephSk, rkgShare1)
+	rkg.GenShareRoundTwo(ephSk, sk, rkgShare1, rkgShare2)
+
+	rtg := NewRTGProtocol(params)
+	rtgShare := rtg.AllocateShare()
+	rtg.GenShare(sk, params.GaloisElementForColumnRotationBy(1), rtg.SampleCRP(testCtx.crs), rtgShare)
+
+	cks := NewCKSProtocol(params, rlwe.DefaultSigma)
+	cksShare := cks.AllocateShare(params.MaxLevel())
+	cks.GenShare(sk, testCtx.skShares[1], ct1, cksShare)
+
+	pcks := NewPCKSProtocol(params, rlwe.DefaultSigma)
+	pcksShare := pcks.AllocateShare(params.MaxLevel())
+	pcks.GenShare(sk, pkOut, ct1, pcksShare)
+
+	pskg := NewPSKGProtocol(params)
+	pskgShare := pskg.AllocateShare()
+	pskg.GenShare(sk, pkOut, pskgShare)
+
+	cp := NewCommitmentProtocol(params)
+	com := cp.AllocateCommitment()
+	cp.GenCommitment(sk, cp.SampleCRP(testCtx.crs), com)
+
+	shares = map[string]share{
+		"CKGShare":          ckgShare,
+		"RKGShare/Round1":   rkgShare1,
+		"RKGShare/Round2":   rkgShare2,
+		"RTGShare":          rtgShare,
+		"CKSShare":          cksShare,
+		"PCKSShare":         pcksShare,
+		"PSKGShare":         pskgShare,
+		"ShamirSecretShare": &ShamirSecretShare{sk.Value},
+		"Commitment":        com,
+	}
+
+	newShare = map[string]func() share{
+		"CKGShare":          func() share { return new(CKGShare) },
+		"RKGShare/Round1":   func() share { return new(RKGShare) },
+		"RKGShare/Round2":   func() share { return new(RKGShare) },
+		"RTGShare":          func() share { return new(RTGShare) },
+		"CKSShare":          func() share { return new(CKSShare) },
+		"PCKSShare":         func() share { return new(PCKSShare) },
+		"PSKGShare":         func() share { return new(PSKGShare) },
+		"ShamirSecretShare": func() share { return new(ShamirSecretShare) },
+		"Commitment":        func() share { return new(Commitment) },
+	}
+
+	return
+}
+
+func testValidate(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	shares, newShare := genShares(testCtx)
+
+	// parameters with one modulus less, under which the shares are invalid
+	paramsSmall, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{LogN: params.LogN(), Q: params.Q()[:1], P: params.P(), Pow2Base: params.Pow2Base()})
+	require.NoError(t, err)
+
+	for name, share := range shares {
+
+		t.Run(testString(params, "Validate/"+name), func(t *testing.T) {
+
+			require.NoError(t, share.Validate(params))
+
+			data, err := share.MarshalBinary()
+			require.NoError(t, err)
+			require.NoError(t, rlwe.UnmarshalValidated(params, data, newShare[name]()))
+
+			// the truncated encodings and the encodings with trailing data are rejected
+			for _, size := range []int{0, 1, 2, 7, 8, 9, len(data) / 2, len(data) - 1} {
+				require.Error(t, newShare[name]().UnmarshalBinary(data[:size]))
+			}
+			require.Error(t, newShare[name]().UnmarshalBinary(append(data, 0)))
+
+			// coefficient not reduced modulo q_0
+			dataMalformed := append([]byte{}, data...)
+			require.NoError(t, newShare[name]().UnmarshalBinary(dataMalformed))
+			for i := len(data) - 8; i < len(data); i++ {
+				dataMalformed[i] = 0xFF
+			}
+			require.True(t, errors.Is(rlwe.UnmarshalValidated(params, dataMalformed, newShare[name]()), rlwe.ErrMalformed))
+
+			if params.MaxLevel() > 0 {
+				require.True(t, errors.Is(share.Validate(paramsSmall), rlwe.ErrMalformed))
+			}
+		})
+	}
+
+	t.Run(testString(params, "Validate/EmptyShares"), func(t *testing.T) {
+		// the empty gadget shares cannot be encoded
+		for _, name := range []string{"RKGShare/Round1", "RTGShare", "PSKGShare"} {
+			_, err := newShare[name]().MarshalBinary()
+			require.Error(t, err, name)
+		}
+		_, err := (&PSKGShare{Value: make([][][2]ringqp.Poly, 1)}).MarshalBinary()
+		require.Error(t, err)
+	})
+
+	t.Run(testString(params, "Validate/Proof"), func(t *testing.T) {
+
+		// a proof with empty responses would allocate 2^32 slices
+		data := make([]byte, 8+64)
+		binary.LittleEndian.PutUint32(data, math.MaxUint32)
+		require.Error(t, new(Proof).UnmarshalBinary(data))
+
+		// an empty decomposition is rejected
+		require.True(t, errors.Is(new(RTGShare).UnmarshalBinary([]byte{0, 1}), rlwe.ErrMalformed))
+		require.True(t, errors.Is(new(RKGShare).UnmarshalBinary([]byte{0xFF, 0xFF, 0, 0}), rlwe.ErrMalformed))
+	})
+}
+
+func testDeterministic(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	// genSharesFromSeed generates a secret key and the shares of the protocols of a party from seed.
+	genSharesFromSeed := func(seed []byte) (shares map[string][]byte) {
+
+		prng, err := utils.NewKeyedPRNG(seed)
+		require.NoError(t, err)
+
+		crs, err := utils.NewKeyedPRNG([]byte{'c', 'r', 's'})
+		require.NoError(t, err)
+
+		kgen := rlwe.NewKeyGeneratorWithPRNG(params, prng)
+		sk := kgen.GenSecretKey()
+		pk := kgen.GenPublicKey(sk)
+
+		ct1 := params.RingQ().NewPoly()
+		ring.NewUniformSampler(crs, params.RingQ()).Read(ct1)
+
+		ckg := NewCKGProtocol(params)
+		ckg.SetPRNG(prng)
+		ckgShare := ckg.AllocateShare()
+		ckg.GenShare(sk, ckg.SampleCRP(crs), ckgShare)
+
+		rkg := NewRKGProtocol(params)
+		rkg.SetPRNG(prng)
+		ephSk, rkgShare1, rkgShare2 := rkg.AllocateShare()
+		rkg.GenShareRoundOne(sk, rkg.SampleCRP(crs), ephSk, rkgShare1)
+		rkg.GenShareRoundTwo(ephSk, sk, rkgShare1, rkgShare2)
+
+		rtg := NewRTGProtocol(params)
+		rtg.SetPRNG(prng)
+		rtgShare := rtg.AllocateShare()
+		rtg.GenShare(sk, params.GaloisElementForColumnRotationBy(1), rtg.SampleCRP(crs), rtgShare)
+
+		cks := NewCKSProtocol(params, rlwe.DefaultSigma)
+		cks.SetPRNG(prng)
+		cksShare := cks.AllocateShare(params.MaxLevel())
+		cks.GenShare(sk, testCtx.skShares[1], ct1, cksShare)
+
+		pcks := NewPCKSProtocol(params, rlwe.DefaultSigma)
+		pcks.SetPRNG(prng)
+		pcksShare := pcks.AllocateShare(params.MaxLevel())
+		pcks.GenShare(sk, pk, ct1, pcksShare)
+
+		pskg := NewPSKGProtocol(params)
+		pskg.SetPRNG(prng)
+		pskgShare := pskg.AllocateShare()
+		pskg.GenShare(sk, pk, pskgShare)
+
+		thr := NewThresholdizer(params)
+		thr.SetPRNG(prng)
+		poly, err := thr.GenShamirPolynomial(2, sk)
+		require.NoError(t, err)
+		thrShare := thr.AllocateThresholdSecretShare()
+		thr.GenShamirSecretShare(1, poly, thrShare)
+
+		shares = make(map[string][]byte)
+		for name, obj := range map[string]interface{ MarshalBinary() ([]byte, error) }{
+			"SecretKey":         sk,
+			"EphemeralKey":      ephSk,
+			"CKGShare":          ckgShare,
+			"RKGShare/Round1":   rkgShare1,
+			"RKGShare/Round2":   rkgShare2,
+			"RTGShare":          rtgShare,
+			"CKSShare":          cksShare,
+			"PCKSShare":         pcksShare,
+			"PSKGShare":         pskgShare,
+			"ShamirSecretShare": thrShare,
+		} {
+			shares[name], err = obj.MarshalBinary()
+			require.NoError(t, err)
+		}
+
+		return
+	}
+
+	sharesA := genSharesFromSeed([]byte{'a'})
+	sharesB := genSharesFromSeed([]byte{'a'})
+	sharesC := genSharesFromSeed([]byte{'c'})
+
+	for name := range sharesA {
+		t.Run(testString(params, "Deterministic/"+name), func(t *testing.T) {
+			require.Equal(t, sharesA[name], sharesB[name])
+			require.NotEqual(t, sharesA[name], sharesC[name])
+		})
+	}
+}
+
+func testSealedEphemeralKey(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	t.Run(testString(params, "SealedEphemeralKey"), func(t *testing.T) {
+
+		sk := testCtx.skShares[0]
+		password := []byte("password")
+		kdf := rlwe.KDFParameters{Time: 1, Memory: 64, Threads: 1}
+
+		rkg := NewRKGProtocol(params)
+		ephSk, share1, share2 := rkg.AllocateShare()
+		rkg.GenShareRoundOne(sk, rkg.SampleCRP(testCtx.crs), ephSk, share1)
+
+		data, err := SealEphemeralKey(params, ephSk, password, kdf)
+		require.NoError(t, err)
+
+		paramsOpen, ephSkOpen, err := OpenEphemeralKey(data, password)
+		require.NoError(t, err)
+		require.True(t, params.Equals(paramsOpen))
+
+		require.True(t, ephSk.Value.Equals(ephSkOpen.Value))
+		rkg.GenShareRoundTwo(ephSkOpen, sk, share1, share2)
+		require.NoError(t, share2.Validate(params))
+
+		// an ephemeral key is not opened as a secret key, and conversely
+		_, _, err = rlwe.OpenSecretKey(data, password)
+		require.True(t, errors.Is(err, rlwe.ErrMalformed))
+
+		data, err = rlwe.SealSecretKey(params, sk, password, kdf)
+		require.NoError(t, err)
+		_, _, err = OpenEphemeralKey(data, password)
+		require.True(t, errors.Is(err, rlwe.ErrMalformed))
+	})
+}
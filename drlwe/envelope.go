@@ -0,0 +1,16 @@
+package drlwe
+
+import "github.com/tuneinsight/lattigo/v3/rlwe"
+
+// The kinds of the envelopes of the shares of the drlwe protocols, see rlwe.Seal.
+const (
+	KindCKGShare          rlwe.ObjectKind = "drlwe.CKGShare"
+	KindRKGShare          rlwe.ObjectKind = "drlwe.RKGShare"
+	KindRTGShare          rlwe.ObjectKind = "drlwe.RTGShare"
+	KindCKSShare          rlwe.ObjectKind = "drlwe.CKSShare"
+	KindPCKSShare         rlwe.ObjectKind = "drlwe.PCKSShare"
+	KindPSKGShare         rlwe.ObjectKind = "drlwe.PSKGShare"
+	KindShamirSecretShare rlwe.ObjectKind = "drlwe.ShamirSecretShare"
+	KindCommitment        rlwe.ObjectKind = "drlwe.Commitment"
+	KindProof             rlwe.ObjectKind = "drlwe.Proof"
+)
@@ -0,0 +1,99 @@
+//go:build go1.18
+// +build go1.18
+
+package drlwe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// fuzzParams are small parameters with a gadget decomposition in both the RNS and the power of two bases.
+var fuzzParams = rlwe.ParametersLiteral{LogN: 5, LogQ: []int{30, 30}, LogP: []int{30}, Pow2Base: 16}
+
+// fuzzShare adds the encodings of the share of the given type to the corpus of f and fuzzes its decoder:
+// the decoder must not panic and the decoded shares that are valid for the parameters must be re-encoded
+// and decoded again without error.
+func fuzzShare(f *testing.F, name string) {
+
+	params, err := rlwe.NewParametersFromLiteral(fuzzParams)
+	require.NoError(f, err)
+
+	shares, newShare := genShares(newTestContext(params))
+
+	data, err := shares[name].MarshalBinary()
+	require.NoError(f, err)
+	f.Add(data)
+	f.Add(data[:len(data)/2])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		share := newShare[name]()
+		if err := share.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		if share.Validate(params) != nil {
+			return
+		}
+
+		dataTest, err := share.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, rlwe.UnmarshalValidated(params, dataTest, newShare[name]()))
+	})
+}
+
+func FuzzCKGShareUnmarshalBinary(f *testing.F) {
+	fuzzShare(f, "CKGShare")
+}
+
+func FuzzRKGShareUnmarshalBinary(f *testing.F) {
+	fuzzShare(f, "RKGShare/Round1")
+}
+
+func FuzzRTGShareUnmarshalBinary(f *testing.F) {
+	fuzzShare(f, "RTGShare")
+}
+
+func FuzzCKSShareUnmarshalBinary(f *testing.F) {
+	fuzzShare(f, "CKSShare")
+}
+
+func FuzzPCKSShareUnmarshalBinary(f *testing.F) {
+	fuzzShare(f, "PCKSShare")
+}
+
+func FuzzPSKGShareUnmarshalBinary(f *testing.F) {
+	fuzzShare(f, "PSKGShare")
+}
+
+func FuzzShamirSecretShareUnmarshalBinary(f *testing.F) {
+	fuzzShare(f, "ShamirSecretShare")
+}
+
+func FuzzCommitmentUnmarshalBinary(f *testing.F) {
+	fuzzShare(f, "Commitment")
+}
+
+func FuzzProofUnmarshalBinary(f *testing.F) {
+
+	data, err := (&Proof{Challenge: make([]byte, 64), Responses: [][]int64{{1, -1, 1 << 40}, {0, 0, -1 << 40}}}).MarshalBinary()
+	require.NoError(f, err)
+	f.Add(data)
+	f.Add(data[:len(data)/2])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		p := new(Proof)
+		if err := p.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		dataTest, err := p.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, new(Proof).UnmarshalBinary(dataTest))
+	})
+}
@@ -34,6 +34,13 @@ func (ckg *CKGProtocol) ShallowCopy() *CKGProtocol {
 	return &CKGProtocol{ckg.params, ring.NewGaussianSampler(prng, ckg.params.RingQ(), ckg.params.Sigma(), int(6*ckg.params.Sigma()))}
 }
 
+// SetPRNG sets prng as the source of randomness of the errors of the shares, so that the shares
+// generated by the receiver are reproducible from the state of prng.
+func (ckg *CKGProtocol) SetPRNG(prng utils.PRNG) {
+	prngs := derivePRNGs(prng, "drlwe/ckg/noise")
+	ckg.gaussianSamplerQ = ring.NewGaussianSampler(prngs[0], ckg.params.RingQ(), ckg.params.Sigma(), int(6*ckg.params.Sigma()))
+}
+
 // CKGShare is a struct storing the CKG protocol's share.
 type CKGShare struct {
 	Value ringqp.Poly
@@ -44,8 +51,18 @@ type CKGCRP ringqp.Poly
 
 // MarshalBinary encodes the target element on a slice of bytes.
 func (share *CKGShare) MarshalBinary() (data []byte, err error) {
-	data = make([]byte, share.Value.GetDataLen64(true))
-	if _, err = share.Value.WriteTo64(data); err != nil {
+	return share.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes the target element on a slice of bytes with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (share *CKGShare) MarshalBinaryPacked() (data []byte, err error) {
+	return share.marshalBinary(true)
+}
+
+func (share *CKGShare) marshalBinary(packed bool) (data []byte, err error) {
+	data = make([]byte, ring.GetDataLen(&share.Value, true, packed))
+	if _, err = ring.WriteTo(&share.Value, data, packed); err != nil {
 		return nil, err
 	}
 	return
@@ -53,8 +70,17 @@ func (share *CKGShare) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary decodes a slice of bytes on the target element.
 func (share *CKGShare) UnmarshalBinary(data []byte) (err error) {
-	_, err = share.Value.DecodePoly64(data)
-	return err
+
+	var ptr int
+	if ptr, err = decodePoly(&share.Value, data); err != nil {
+		return
+	}
+
+	if ptr != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
+	return
 }
 
 // NewCKGProtocol creates a new CKGProtocol instance
@@ -114,3 +140,33 @@ func (ckg *CKGProtocol) GenPublicKey(roundShare *CKGShare, crp CKGCRP, pubkey *r
 	pubkey.Value[0].Copy(roundShare.Value)
 	pubkey.Value[1].Copy(ringqp.Poly(crp))
 }
+
+// ProveShare generates a proof that the share was generated with a short error and the secret key sk committed in com,
+// that is, of the knowledge of short s, e and e' such that share = -crp*s + e and com = -comCRP*s + e'.
+// It returns an error if the share or the commitment were not generated with short errors and the secret key sk.
+func (ckg *CKGProtocol) ProveShare(sk *rlwe.SecretKey, crp CKGCRP, share *CKGShare, comCRP CommitmentCRP, com *Commitment) (*Proof, error) {
+	return ckg.statement(crp, share, comCRP, com).prove([][]int64{secretCoeffs(ckg.params, sk)})
+}
+
+// VerifyShare verifies the proof generated by ProveShare for the share of the party with commitment com.
+func (ckg *CKGProtocol) VerifyShare(crp CKGCRP, share *CKGShare, comCRP CommitmentCRP, com *Commitment, proof *Proof) bool {
+	return ckg.statement(crp, share, comCRP, com).verify(proof)
+}
+
+func (ckg *CKGProtocol) statement(crp CKGCRP, share *CKGShare, comCRP CommitmentCRP, com *Commitment) *statement {
+
+	ringQP := ckg.params.RingQP()
+	levelQ, levelP := ckg.params.QCount()-1, ckg.params.PCount()-1
+
+	st := newStatement(ckg.params, "CKG", 1)
+	st.addCommitment(0, comCRP, com)
+
+	// share = MForm(e) - s*crp, hence InvMForm(share) = e - s*InvMForm(crp)
+	value, coeff := ringQP.NewPoly(), ringQP.NewPoly()
+	ringQP.InvMFormLvl(levelQ, levelP, share.Value, value)
+	ringQP.InvMFormLvl(levelQ, levelP, ringqp.Poly(crp), coeff)
+	ringQP.NegLvl(levelQ, levelP, coeff, coeff)
+	st.add(levelQ, levelP, st.errorBound(), value, []int{0}, coeff)
+
+	return st
+}
@@ -2,6 +2,7 @@ package drlwe
 
 import (
 	"errors"
+	"math/big"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
@@ -12,6 +13,7 @@ import (
 // RelinearizationKeyGenerator is an interface describing the local steps of a generic RLWE RKG protocol.
 type RelinearizationKeyGenerator interface {
 	AllocateShare() (ephKey *rlwe.SecretKey, r1 *RKGShare, r2 *RKGShare)
+	AllocateShareLvl(levelQ, levelP int) (ephKey *rlwe.SecretKey, r1 *RKGShare, r2 *RKGShare)
 	GenShareRoundOne(sk *rlwe.SecretKey, crp RKGCRP, ephKeyOut *rlwe.SecretKey, shareOut *RKGShare)
 	GenShareRoundTwo(ephSk, sk *rlwe.SecretKey, round1 *RKGShare, shareOut *RKGShare)
 	AggregateShare(share1, share2, shareOut *RKGShare)
@@ -50,6 +52,16 @@ func (ekg *RKGProtocol) ShallowCopy() *RKGProtocol {
 	}
 }
 
+// SetPRNG sets prng as the source of randomness of the ephemeral keys and of the errors of the shares, so
+// that the ephemeral keys and the shares generated by the receiver are reproducible from the state of prng.
+// The ephemeral keys and the errors are sampled from independent streams derived from prng.
+func (ekg *RKGProtocol) SetPRNG(prng utils.PRNG) {
+	params := ekg.params
+	prngs := derivePRNGs(prng, "drlwe/rkg/ephemeral", "drlwe/rkg/noise")
+	ekg.ternarySamplerQ = ring.NewTernarySamplerWithHammingWeight(prngs[0], params.RingQ(), params.HammingWeight(), false)
+	ekg.gaussianSamplerQ = ring.NewGaussianSampler(prngs[1], params.RingQ(), params.Sigma(), int(6*params.Sigma()))
+}
+
 // RKGShare is a share in the RKG protocol.
 type RKGShare struct {
 	Value [][][2]ringqp.Poly
@@ -78,12 +90,21 @@ func NewRKGProtocol(params rlwe.Parameters) *RKGProtocol {
 
 // AllocateShare allocates the share of the EKG protocol.
 func (ekg *RKGProtocol) AllocateShare() (ephSk *rlwe.SecretKey, r1 *RKGShare, r2 *RKGShare) {
+	return ekg.AllocateShareLvl(ekg.params.QCount()-1, ekg.params.PCount()-1)
+}
+
+// AllocateShareLvl allocates the share of the EKG protocol for a relinearization key at levels levelQ and levelP.
+// The shares must be used with a RKGCRP sampled by SampleCRPLvl at the same levels, and the protocol outputs a
+// RelinearizationKey allocated with rlwe.NewRelinKeyLvl at the same levels.
+func (ekg *RKGProtocol) AllocateShareLvl(levelQ, levelP int) (ephSk *rlwe.SecretKey, r1 *RKGShare, r2 *RKGShare) {
 	params := ekg.params
+	checkKeyLevels("AllocateShareLvl", params, levelQ, levelP)
+
 	ephSk = rlwe.NewSecretKey(params)
 	r1, r2 = new(RKGShare), new(RKGShare)
 
-	decompRNS := params.DecompRNS(params.QCount()-1, params.PCount()-1)
-	decompPw2 := params.DecompPw2(params.QCount()-1, params.PCount()-1)
+	decompRNS := params.DecompRNS(levelQ, levelP)
+	decompPw2 := params.DecompPw2(levelQ, levelP)
 
 	r1.Value = make([][][2]ringqp.Poly, decompRNS)
 	r2.Value = make([][][2]ringqp.Poly, decompRNS)
@@ -92,10 +113,10 @@ func (ekg *RKGProtocol) AllocateShare() (ephSk *rlwe.SecretKey, r1 *RKGShare, r2
 		r1.Value[i] = make([][2]ringqp.Poly, decompPw2)
 		r2.Value[i] = make([][2]ringqp.Poly, decompPw2)
 		for j := 0; j < decompPw2; j++ {
-			r1.Value[i][j][0] = ekg.params.RingQP().NewPoly()
-			r1.Value[i][j][1] = ekg.params.RingQP().NewPoly()
-			r2.Value[i][j][0] = ekg.params.RingQP().NewPoly()
-			r2.Value[i][j][1] = ekg.params.RingQP().NewPoly()
+			r1.Value[i][j][0] = params.RingQP().NewPolyLvl(levelQ, levelP)
+			r1.Value[i][j][1] = params.RingQP().NewPolyLvl(levelQ, levelP)
+			r2.Value[i][j][0] = params.RingQP().NewPolyLvl(levelQ, levelP)
+			r2.Value[i][j][1] = params.RingQP().NewPolyLvl(levelQ, levelP)
 		}
 	}
 	return
@@ -104,17 +125,25 @@ func (ekg *RKGProtocol) AllocateShare() (ephSk *rlwe.SecretKey, r1 *RKGShare, r2
 // SampleCRP samples a common random polynomial to be used in the RKG protocol from the provided
 // common reference string.
 func (ekg *RKGProtocol) SampleCRP(crs CRS) RKGCRP {
+	return ekg.SampleCRPLvl(ekg.params.QCount()-1, ekg.params.PCount()-1, crs)
+}
+
+// SampleCRPLvl samples a common random polynomial to be used in the RKG protocol at levels levelQ and levelP
+// from the provided common reference string.
+func (ekg *RKGProtocol) SampleCRPLvl(levelQ, levelP int, crs CRS) RKGCRP {
 	params := ekg.params
-	decompRNS := params.DecompRNS(params.QCount()-1, params.PCount()-1)
-	decompPw2 := params.DecompPw2(params.QCount()-1, params.PCount()-1)
+	checkKeyLevels("SampleCRPLvl", params, levelQ, levelP)
+
+	decompRNS := params.DecompRNS(levelQ, levelP)
+	decompPw2 := params.DecompPw2(levelQ, levelP)
 
 	crp := make([][]ringqp.Poly, decompRNS)
 	us := ringqp.NewUniformSampler(crs, *params.RingQP())
 	for i := range crp {
 		crp[i] = make([]ringqp.Poly, decompPw2)
 		for j := range crp[i] {
-			crp[i][j] = params.RingQP().NewPoly()
-			us.Read(crp[i][j])
+			crp[i][j] = params.RingQP().NewPolyLvl(levelQ, levelP)
+			us.ReadLvl(levelQ, levelP, crp[i][j])
 		}
 	}
 	return RKGCRP(crp)
@@ -122,7 +151,7 @@ func (ekg *RKGProtocol) SampleCRP(crs CRS) RKGCRP {
 
 // GenShareRoundOne is the first of three rounds of the RKGProtocol protocol. Each party generates a pseudo encryption of
 // its secret share of the key s_i under its ephemeral key u_i : [-u_i*a + s_i*w + e_i] and broadcasts it to the other
-// j-1 parties.
+// j-1 parties. The share is generated at the levels of shareOut.
 func (ekg *RKGProtocol) GenShareRoundOne(sk *rlwe.SecretKey, crp RKGCRP, ephSkOut *rlwe.SecretKey, shareOut *RKGShare) {
 	// Given a base decomposition w_i (here the CRT decomposition)
 	// computes [-u*a_i + P*s_i + e_i]
@@ -131,8 +160,8 @@ func (ekg *RKGProtocol) GenShareRoundOne(sk *rlwe.SecretKey, crp RKGCRP, ephSkOu
 	ringQ := ekg.params.RingQ()
 	ringQP := ekg.params.RingQP()
 
-	levelQ := sk.Value.LevelQ()
-	levelP := sk.Value.LevelP()
+	levelQ := shareOut.Value[0][0][0].LevelQ()
+	levelP := shareOut.Value[0][0][0].LevelP()
 
 	hasModulusP := levelP > -1
 
@@ -144,10 +173,10 @@ func (ekg *RKGProtocol) GenShareRoundOne(sk *rlwe.SecretKey, crp RKGCRP, ephSkOu
 		ring.CopyLvl(levelQ, sk.Value.Q, ekg.tmpPoly1.Q)
 	}
 
-	ringQ.InvMForm(ekg.tmpPoly1.Q, ekg.tmpPoly1.Q)
+	ringQ.InvMFormLvl(levelQ, ekg.tmpPoly1.Q, ekg.tmpPoly1.Q)
 
 	// u
-	ekg.ternarySamplerQ.Read(ephSkOut.Value.Q)
+	ekg.ternarySamplerQ.ReadLvl(levelQ, ephSkOut.Value.Q)
 	if hasModulusP {
 		ringQP.ExtendBasisSmallNormAndCenter(ephSkOut.Value.Q, levelP, nil, ephSkOut.Value.P)
 	}
@@ -161,7 +190,7 @@ func (ekg *RKGProtocol) GenShareRoundOne(sk *rlwe.SecretKey, crp RKGCRP, ephSkOu
 	for j := 0; j < BITDecomp; j++ {
 		for i := 0; i < RNSDecomp; i++ {
 			// h = e
-			ekg.gaussianSamplerQ.Read(shareOut.Value[i][j][0].Q)
+			ekg.gaussianSamplerQ.ReadLvl(levelQ, shareOut.Value[i][j][0].Q)
 
 			if hasModulusP {
 				ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value[i][j][0].Q, levelP, nil, shareOut.Value[i][j][0].P)
@@ -193,7 +222,7 @@ func (ekg *RKGProtocol) GenShareRoundOne(sk *rlwe.SecretKey, crp RKGCRP, ephSkOu
 
 			// Second Element
 			// e_2i
-			ekg.gaussianSamplerQ.Read(shareOut.Value[i][j][1].Q)
+			ekg.gaussianSamplerQ.ReadLvl(levelQ, shareOut.Value[i][j][1].Q)
 
 			if hasModulusP {
 				ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value[i][j][1].Q, levelP, nil, shareOut.Value[i][j][1].P)
@@ -218,12 +247,12 @@ func (ekg *RKGProtocol) GenShareRoundOne(sk *rlwe.SecretKey, crp RKGCRP, ephSkOu
 func (ekg *RKGProtocol) GenShareRoundTwo(ephSk, sk *rlwe.SecretKey, round1 *RKGShare, shareOut *RKGShare) {
 
 	ringQP := ekg.params.RingQP()
-	levelQ := sk.Value.Q.Level()
+	levelQ := shareOut.Value[0][0][0].LevelQ()
 
-	hasModulusP := sk.Value.P != nil
+	hasModulusP := shareOut.Value[0][0][0].P != nil
 	var levelP int
 	if hasModulusP {
-		levelP = sk.Value.P.Level()
+		levelP = shareOut.Value[0][0][0].LevelP()
 	}
 
 	// (u_i - s_i)
@@ -240,10 +269,10 @@ func (ekg *RKGProtocol) GenShareRoundTwo(ephSk, sk *rlwe.SecretKey, round1 *RKGS
 			// Computes [(sum samples)*sk + e_1i, sk*a + e_2i]
 
 			// (AggregateShareRoundTwo samples) * sk
-			ringQP.MulCoeffsMontgomeryConstantLvl(levelQ, levelP, round1.Value[i][j][0], sk.Value, shareOut.Value[i][j][0])
+			ringQP.MulCoeffsMontgomeryLvl(levelQ, levelP, round1.Value[i][j][0], sk.Value, shareOut.Value[i][j][0])
 
 			// (AggregateShareRoundTwo samples) * sk + e_1i
-			ekg.gaussianSamplerQ.Read(ekg.tmpPoly2.Q)
+			ekg.gaussianSamplerQ.ReadLvl(levelQ, ekg.tmpPoly2.Q)
 
 			if hasModulusP {
 				ringQP.ExtendBasisSmallNormAndCenter(ekg.tmpPoly2.Q, levelP, nil, ekg.tmpPoly2.P)
@@ -254,7 +283,7 @@ func (ekg *RKGProtocol) GenShareRoundTwo(ephSk, sk *rlwe.SecretKey, round1 *RKGS
 
 			// second part
 			// (u - s) * (sum [x][s*a_i + e_2i]) + e3i
-			ekg.gaussianSamplerQ.Read(shareOut.Value[i][j][1].Q)
+			ekg.gaussianSamplerQ.ReadLvl(levelQ, shareOut.Value[i][j][1].Q)
 
 			if hasModulusP {
 				ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value[i][j][1].Q, levelP, nil, shareOut.Value[i][j][1].P)
@@ -310,8 +339,27 @@ func (ekg *RKGProtocol) GenRelinearizationKey(round1 *RKGShare, round2 *RKGShare
 
 // MarshalBinary encodes the target element on a slice of bytes.
 func (share *RKGShare) MarshalBinary() ([]byte, error) {
+	return share.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes the target element on a slice of bytes with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (share *RKGShare) MarshalBinaryPacked() ([]byte, error) {
+	return share.marshalBinary(true)
+}
+
+func (share *RKGShare) marshalBinary(packed bool) ([]byte, error) {
+	if len(share.Value) == 0 || len(share.Value[0]) == 0 {
+		return []byte{}, errors.New("RKGShare : share is empty")
+	}
 	//we have modulus * bitLog * Len of 1 ring rings
-	data := make([]byte, 2+2*share.Value[0][0][0].GetDataLen64(true)*len(share.Value)*len(share.Value[0]))
+	dataLen := 2
+	for i := range share.Value {
+		for _, el := range share.Value[i] {
+			dataLen += ring.GetDataLen(&el[0], true, packed) + ring.GetDataLen(&el[1], true, packed)
+		}
+	}
+	data := make([]byte, dataLen)
 	if len(share.Value) > 0xFF {
 		return []byte{}, errors.New("RKGShare : uint8 overflow on length")
 	}
@@ -331,12 +379,12 @@ func (share *RKGShare) MarshalBinary() ([]byte, error) {
 	for i := range share.Value {
 		for _, el := range share.Value[i] {
 
-			if inc, err = el[0].WriteTo64(data[ptr:]); err != nil {
+			if inc, err = ring.WriteTo(&el[0], data[ptr:], packed); err != nil {
 				return []byte{}, err
 			}
 			ptr += inc
 
-			if inc, err = el[1].WriteTo64(data[ptr:]); err != nil {
+			if inc, err = ring.WriteTo(&el[1], data[ptr:], packed); err != nil {
 				return []byte{}, err
 			}
 			ptr += inc
@@ -348,24 +396,153 @@ func (share *RKGShare) MarshalBinary() ([]byte, error) {
 
 // UnmarshalBinary decodes a slice of bytes on the target element.
 func (share *RKGShare) UnmarshalBinary(data []byte) (err error) {
-	share.Value = make([][][2]ringqp.Poly, data[0])
+
+	// each element is a pair of ringqp.Poly that have at least a header of 2 bytes
+	decompRNS, decompPw2, err := decodeDecomposition(data, 4)
+	if err != nil {
+		return err
+	}
+
+	share.Value = make([][][2]ringqp.Poly, decompRNS)
 	ptr := 2
 	var inc int
 	for i := range share.Value {
-		share.Value[i] = make([][2]ringqp.Poly, data[1])
+		share.Value[i] = make([][2]ringqp.Poly, decompPw2)
 		for j := range share.Value[i] {
 
-			if inc, err = share.Value[i][j][0].DecodePoly64(data[ptr:]); err != nil {
+			if inc, err = decodePoly(&share.Value[i][j][0], data[ptr:]); err != nil {
 				return err
 			}
 			ptr += inc
 
-			if inc, err = share.Value[i][j][1].DecodePoly64(data[ptr:]); err != nil {
+			if inc, err = decodePoly(&share.Value[i][j][1], data[ptr:]); err != nil {
 				return err
 			}
 			ptr += inc
 		}
 	}
 
+	if ptr != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
 	return nil
 }
+
+// ProveShareRoundOne generates a proof that the share of the first round was generated with short errors, a short
+// ephemeral secret key ephSk and the secret key sk committed in com.
+// It returns an error if the share or the commitment were not generated with short errors and these secret keys.
+func (ekg *RKGProtocol) ProveShareRoundOne(sk, ephSk *rlwe.SecretKey, crp RKGCRP, share *RKGShare, comCRP CommitmentCRP, com *Commitment) (*Proof, error) {
+	st := newStatement(ekg.params, "RKG/1", 1, 1)
+	st.addCommitment(0, comCRP, com)
+	ekg.addRoundOne(st, crp, share)
+	return st.prove([][]int64{secretCoeffs(ekg.params, sk), secretCoeffs(ekg.params, ephSk)})
+}
+
+// VerifyShareRoundOne verifies the proof generated by ProveShareRoundOne for the share of the first round
+// of the party with commitment com.
+func (ekg *RKGProtocol) VerifyShareRoundOne(crp RKGCRP, share *RKGShare, comCRP CommitmentCRP, com *Commitment, proof *Proof) bool {
+	st := newStatement(ekg.params, "RKG/1", 1, 1)
+	st.addCommitment(0, comCRP, com)
+	ekg.addRoundOne(st, crp, share)
+	return st.verify(proof)
+}
+
+// ProveShareRoundTwo generates a proof that the share of the second round was generated from the aggregated
+// share round1 of the first round, with short errors, the secret key sk committed in com and the ephemeral
+// secret key ephSk of the share share1 of the party in the first round.
+// It returns an error if the shares or the commitment were not generated with short errors and these secret keys.
+func (ekg *RKGProtocol) ProveShareRoundTwo(ephSk, sk *rlwe.SecretKey, crp RKGCRP, share1, round1, share *RKGShare, comCRP CommitmentCRP, com *Commitment) (*Proof, error) {
+	return ekg.statementRoundTwo(crp, share1, round1, share, comCRP, com).prove([][]int64{secretCoeffs(ekg.params, sk), secretCoeffs(ekg.params, ephSk)})
+}
+
+// VerifyShareRoundTwo verifies the proof generated by ProveShareRoundTwo for the share of the second round
+// of the party with commitment com and share share1 in the first round.
+func (ekg *RKGProtocol) VerifyShareRoundTwo(crp RKGCRP, share1, round1, share *RKGShare, comCRP CommitmentCRP, com *Commitment, proof *Proof) bool {
+	return ekg.statementRoundTwo(crp, share1, round1, share, comCRP, com).verify(proof)
+}
+
+func (ekg *RKGProtocol) statementRoundTwo(crp RKGCRP, share1, round1, share *RKGShare, comCRP CommitmentCRP, com *Commitment) *statement {
+
+	ringQP := ekg.params.RingQP()
+	levelQ, levelP := ekg.params.QCount()-1, ekg.params.PCount()-1
+
+	// the relations of the first round bind the ephemeral secret key
+	st := newStatement(ekg.params, "RKG/2", 1, 1)
+	st.addCommitment(0, comCRP, com)
+	ekg.addRoundOne(st, crp, share1)
+
+	for i := range share.Value {
+		for j := range share.Value[i] {
+
+			// h0 = s*round1[0] + e
+			st.add(levelQ, levelP, st.errorBound(), share.Value[i][j][0], []int{0}, round1.Value[i][j][0])
+
+			// h1 = (u - s)*round1[1] + e
+			neg := ringQP.NewPoly()
+			ringQP.NegLvl(levelQ, levelP, round1.Value[i][j][1], neg)
+			st.add(levelQ, levelP, st.errorBound(), share.Value[i][j][1], []int{1, 0}, round1.Value[i][j][1], neg)
+		}
+	}
+
+	return st
+}
+
+// addRoundOne adds the relations of a share of the first round to the statement, where the secret 0 is the
+// secret key and the secret 1 the ephemeral secret key.
+func (ekg *RKGProtocol) addRoundOne(st *statement, crp RKGCRP, share *RKGShare) {
+
+	params := ekg.params
+	ringQ := params.RingQ()
+	ringQP := params.RingQP()
+	levelQ, levelP := params.QCount()-1, params.PCount()-1
+
+	P := big.NewInt(1)
+	decompP := 1
+	if levelP > -1 {
+		P = params.RingP().ModulusAtLevel[levelP]
+		decompP = levelP + 1
+	}
+
+	for i := range share.Value {
+		for j := range share.Value[i] {
+
+			// the gadget vector: P * 2^(j*pw2) modulo the i-th group of primes of Q, 0 otherwise
+			gadget := ringQP.NewPoly()
+			w := new(big.Int).Lsh(P, uint(j*params.Pow2Base()))
+			for k := 0; k < decompP; k++ {
+				index := i*decompP + k
+				if index > levelQ {
+					break
+				}
+				qi := ringQ.Modulus[index]
+				v := new(big.Int).Mod(w, ring.NewUint(qi)).Uint64()
+				for x := range gadget.Q.Coeffs[index] {
+					gadget.Q.Coeffs[index][x] = v
+				}
+			}
+
+			// h0 = -u*crp + s*w + e
+			negCRP := ringQP.NewPoly()
+			ringQP.NegLvl(levelQ, levelP, crp[i][j], negCRP)
+			st.add(levelQ, levelP, st.errorBound(), share.Value[i][j][0], []int{1, 0}, negCRP, gadget)
+
+			// h1 = s*crp + e
+			st.add(levelQ, levelP, st.errorBound(), share.Value[i][j][1], []int{0}, crp[i][j])
+		}
+	}
+}
+
+// SealedEphemeralKeyPurpose is the purpose of the ephemeral keys of the RKG protocol sealed by SealEphemeralKey.
+const SealedEphemeralKeyPurpose = "drlwe/rkg/ephemeral-key"
+
+// SealEphemeralKey encrypts the ephemeral key ephSk of the RKG protocol under password, so that it can be
+// stored between the two rounds of the protocol. See rlwe.SealKey.
+func SealEphemeralKey(params rlwe.Parameters, ephSk *rlwe.SecretKey, password []byte, kdf rlwe.KDFParameters) ([]byte, error) {
+	return rlwe.SealKey(SealedEphemeralKeyPurpose, params, ephSk, password, kdf)
+}
+
+// OpenEphemeralKey decrypts the ephemeral key sealed by SealEphemeralKey with password. See rlwe.OpenKey.
+func OpenEphemeralKey(data, password []byte) (rlwe.Parameters, *rlwe.SecretKey, error) {
+	return rlwe.OpenKey(SealedEphemeralKeyPurpose, data, password)
+}
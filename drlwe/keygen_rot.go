@@ -2,6 +2,7 @@ package drlwe
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
@@ -12,6 +13,7 @@ import (
 // RotationKeyGenerator is an interface for the local operation in the generation of rotation keys.
 type RotationKeyGenerator interface {
 	AllocateShare() (rtgShare *RTGShare)
+	AllocateShareLvl(levelQ, levelP int) (rtgShare *RTGShare)
 	GenShare(sk *rlwe.SecretKey, galEl uint64, crp RTGCRP, shareOut *RTGShare)
 	AggregateShare(share1, share2, shareOut *RTGShare)
 	GenRotationKey(share *RTGShare, crp RTGCRP, rotKey *rlwe.SwitchingKey)
@@ -50,6 +52,13 @@ func (rtg *RTGProtocol) ShallowCopy() *RTGProtocol {
 	}
 }
 
+// SetPRNG sets prng as the source of randomness of the errors of the shares, so that the shares
+// generated by the receiver are reproducible from the state of prng.
+func (rtg *RTGProtocol) SetPRNG(prng utils.PRNG) {
+	prngs := derivePRNGs(prng, "drlwe/rtg/noise")
+	rtg.gaussianSamplerQ = ring.NewGaussianSampler(prngs[0], rtg.params.RingQ(), rtg.params.Sigma(), int(6*rtg.params.Sigma()))
+}
+
 // NewRTGProtocol creates a RTGProtocol instance.
 func NewRTGProtocol(params rlwe.Parameters) *RTGProtocol {
 	rtg := new(RTGProtocol)
@@ -66,18 +75,27 @@ func NewRTGProtocol(params rlwe.Parameters) *RTGProtocol {
 
 // AllocateShare allocates a party's share in the RTG protocol.
 func (rtg *RTGProtocol) AllocateShare() (rtgShare *RTGShare) {
+	return rtg.AllocateShareLvl(rtg.params.QCount()-1, rtg.params.PCount()-1)
+}
+
+// AllocateShareLvl allocates a party's share in the RTG protocol for a rotation key at levels levelQ and levelP.
+// The share must be used with a RTGCRP sampled by SampleCRPLvl at the same levels, and the protocol outputs a
+// SwitchingKey allocated with rlwe.NewSwitchingKeyLvl at the same levels.
+func (rtg *RTGProtocol) AllocateShareLvl(levelQ, levelP int) (rtgShare *RTGShare) {
 	rtgShare = new(RTGShare)
 
 	params := rtg.params
-	decompRNS := rtg.params.DecompRNS(params.QCount()-1, params.PCount()-1)
-	decompPw2 := rtg.params.DecompPw2(params.QCount()-1, params.PCount()-1)
+	checkKeyLevels("AllocateShareLvl", params, levelQ, levelP)
+
+	decompRNS := params.DecompRNS(levelQ, levelP)
+	decompPw2 := params.DecompPw2(levelQ, levelP)
 
 	rtgShare.Value = make([][]ringqp.Poly, decompRNS)
 
 	for i := 0; i < decompRNS; i++ {
 		rtgShare.Value[i] = make([]ringqp.Poly, decompPw2)
 		for j := 0; j < decompPw2; j++ {
-			rtgShare.Value[i][j] = rtg.params.RingQP().NewPoly()
+			rtgShare.Value[i][j] = params.RingQP().NewPolyLvl(levelQ, levelP)
 		}
 	}
 	return
@@ -86,31 +104,39 @@ func (rtg *RTGProtocol) AllocateShare() (rtgShare *RTGShare) {
 // SampleCRP samples a common random polynomial to be used in the RTG protocol from the provided
 // common reference string.
 func (rtg *RTGProtocol) SampleCRP(crs CRS) RTGCRP {
+	return rtg.SampleCRPLvl(rtg.params.QCount()-1, rtg.params.PCount()-1, crs)
+}
+
+// SampleCRPLvl samples a common random polynomial to be used in the RTG protocol at levels levelQ and levelP
+// from the provided common reference string.
+func (rtg *RTGProtocol) SampleCRPLvl(levelQ, levelP int, crs CRS) RTGCRP {
 
 	params := rtg.params
-	decompRNS := rtg.params.DecompRNS(params.QCount()-1, params.PCount()-1)
-	decompPw2 := rtg.params.DecompPw2(params.QCount()-1, params.PCount()-1)
+	checkKeyLevels("SampleCRPLvl", params, levelQ, levelP)
+
+	decompRNS := params.DecompRNS(levelQ, levelP)
+	decompPw2 := params.DecompPw2(levelQ, levelP)
 
 	crp := make([][]ringqp.Poly, decompRNS)
 	us := ringqp.NewUniformSampler(crs, *params.RingQP())
 	for i := 0; i < decompRNS; i++ {
 		crp[i] = make([]ringqp.Poly, decompPw2)
 		for j := 0; j < decompPw2; j++ {
-			crp[i][j] = rtg.params.RingQP().NewPoly()
-			us.Read(crp[i][j])
+			crp[i][j] = params.RingQP().NewPolyLvl(levelQ, levelP)
+			us.ReadLvl(levelQ, levelP, crp[i][j])
 		}
 	}
 	return RTGCRP(crp)
 }
 
-// GenShare generates a party's share in the RTG protocol.
+// GenShare generates a party's share in the RTG protocol, at the levels of shareOut.
 func (rtg *RTGProtocol) GenShare(sk *rlwe.SecretKey, galEl uint64, crp RTGCRP, shareOut *RTGShare) {
 
 	ringQ := rtg.params.RingQ()
 	ringQP := rtg.params.RingQP()
 
-	levelQ := sk.Value.LevelQ()
-	levelP := sk.Value.LevelP()
+	levelQ := shareOut.Value[0][0].LevelQ()
+	levelP := shareOut.Value[0][0].LevelP()
 
 	hasModulusP := levelP > -1
 
@@ -134,7 +160,7 @@ func (rtg *RTGProtocol) GenShare(sk *rlwe.SecretKey, galEl uint64, crp RTGCRP, s
 		for i := 0; i < RNSDecomp; i++ {
 
 			// e
-			rtg.gaussianSamplerQ.Read(shareOut.Value[i][j].Q)
+			rtg.gaussianSamplerQ.ReadLvl(levelQ, shareOut.Value[i][j].Q)
 
 			if hasModulusP {
 				ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value[i][j].Q, levelP, nil, shareOut.Value[i][j].P)
@@ -206,7 +232,26 @@ func (rtg *RTGProtocol) GenRotationKey(share *RTGShare, crp RTGCRP, rotKey *rlwe
 
 // MarshalBinary encode the target element on a slice of byte.
 func (share *RTGShare) MarshalBinary() (data []byte, err error) {
-	data = make([]byte, 2+share.Value[0][0].GetDataLen64(true)*len(share.Value)*len(share.Value[0]))
+	return share.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes the target element on a slice of bytes with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (share *RTGShare) MarshalBinaryPacked() (data []byte, err error) {
+	return share.marshalBinary(true)
+}
+
+func (share *RTGShare) marshalBinary(packed bool) (data []byte, err error) {
+	if len(share.Value) == 0 || len(share.Value[0]) == 0 {
+		return []byte{}, errors.New("RTGShare : share is empty")
+	}
+	dataLen := 2
+	for i := range share.Value {
+		for j := range share.Value[i] {
+			dataLen += ring.GetDataLen(&share.Value[i][j], true, packed)
+		}
+	}
+	data = make([]byte, dataLen)
 	if len(share.Value) > 0xFF {
 		return []byte{}, errors.New("RKGShare : uint8 overflow on length")
 	}
@@ -216,7 +261,7 @@ func (share *RTGShare) MarshalBinary() (data []byte, err error) {
 	var inc int
 	for i := range share.Value {
 		for _, el := range share.Value[i] {
-			if inc, err = el.WriteTo64(data[ptr:]); err != nil {
+			if inc, err = ring.WriteTo(&el, data[ptr:], packed); err != nil {
 				return []byte{}, err
 			}
 			ptr += inc
@@ -228,18 +273,44 @@ func (share *RTGShare) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary decodes a slice of bytes on the target element.
 func (share *RTGShare) UnmarshalBinary(data []byte) (err error) {
-	share.Value = make([][]ringqp.Poly, data[0])
+
+	// each element is a ringqp.Poly that has at least a header of 2 bytes
+	decompRNS, decompPw2, err := decodeDecomposition(data, 2)
+	if err != nil {
+		return err
+	}
+
+	share.Value = make([][]ringqp.Poly, decompRNS)
 	ptr := 2
 	var inc int
 	for i := range share.Value {
-		share.Value[i] = make([]ringqp.Poly, data[1])
+		share.Value[i] = make([]ringqp.Poly, decompPw2)
 		for j := range share.Value[i] {
-			if inc, err = share.Value[i][j].DecodePoly64(data[ptr:]); err != nil {
+			if inc, err = decodePoly(&share.Value[i][j], data[ptr:]); err != nil {
 				return err
 			}
 			ptr += inc
 		}
 	}
 
+	if ptr != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
 	return nil
 }
+
+// checkKeyLevels panics if levelQ and levelP are not valid levels for a switching key
+// of the parameters. See rlwe.NewSwitchingKeyLvl.
+func checkKeyLevels(method string, params rlwe.Parameters, levelQ, levelP int) {
+
+	maxLevelP := params.PCount() - 1
+
+	if levelQ < 0 || levelQ > params.MaxLevel() {
+		panic(fmt.Sprintf("cannot %s: levelQ=%d is not in [0, %d]", method, levelQ, params.MaxLevel()))
+	}
+
+	if (maxLevelP == -1 && levelP != -1) || (maxLevelP != -1 && (levelP < 0 || levelP > maxLevelP)) {
+		panic(fmt.Sprintf("cannot %s: levelP=%d is not a valid level for %d P primes", method, levelP, params.PCount()))
+	}
+}
@@ -0,0 +1,278 @@
+package drlwe
+
+import (
+	"errors"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// PublicSwitchingKeyGenerator is an interface for the local operations in the collective generation of a switching
+// key towards a public key.
+type PublicSwitchingKeyGenerator interface {
+	AllocateShare() (share *PSKGShare)
+	GenShare(skInput *rlwe.SecretKey, pkOutput *rlwe.PublicKey, shareOut *PSKGShare)
+	AggregateShare(share1, share2, shareOut *PSKGShare)
+	GenSwitchingKey(share *PSKGShare, swkOut *rlwe.SwitchingKey)
+}
+
+// PSKGShare represents a party's share in the PSKG protocol.
+type PSKGShare struct {
+	Value [][][2]ringqp.Poly
+}
+
+// PSKGProtocol is the structure storing the parameters for the collective generation of a switching key from a
+// secret-shared key s_A to the secret key s_B of a collective public key, without the knowledge of s_B.
+// The parties holding the shares of s_A each encrypt their share of the gadget decomposition of s_A under
+// the public key of s_B, so that the aggregated encryptions form a rlwe.SwitchingKey from s_A to s_B.
+// As for a public-key encryption, the noise of this switching key is larger than the one of a switching key
+// generated from both secret keys.
+type PSKGProtocol struct {
+	params rlwe.Parameters
+
+	gaussianSamplerQ *ring.GaussianSampler
+	ternarySamplerQ  *ring.TernarySampler
+
+	tmpSk ringqp.Poly
+	tmpU  ringqp.Poly
+}
+
+// ShallowCopy creates a shallow copy of PSKGProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// PSKGProtocol can be used concurrently.
+func (pskg *PSKGProtocol) ShallowCopy() *PSKGProtocol {
+	return NewPSKGProtocol(pskg.params)
+}
+
+// SetPRNG sets prng as the source of randomness of the ephemeral keys and of the errors of the shares, so
+// that the shares generated by the receiver are reproducible from the state of prng. The ephemeral keys and
+// the errors are sampled from independent streams derived from prng.
+func (pskg *PSKGProtocol) SetPRNG(prng utils.PRNG) {
+	params := pskg.params
+	prngs := derivePRNGs(prng, "drlwe/pskg/ephemeral", "drlwe/pskg/noise")
+	pskg.ternarySamplerQ = ring.NewTernarySamplerWithHammingWeight(prngs[0], params.RingQ(), params.HammingWeight(), false)
+	pskg.gaussianSamplerQ = ring.NewGaussianSampler(prngs[1], params.RingQ(), params.Sigma(), int(6*params.Sigma()))
+}
+
+// NewPSKGProtocol creates a new PSKGProtocol instance.
+func NewPSKGProtocol(params rlwe.Parameters) *PSKGProtocol {
+	pskg := new(PSKGProtocol)
+	pskg.params = params
+
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	pskg.gaussianSamplerQ = ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma()))
+	pskg.ternarySamplerQ = ring.NewTernarySamplerWithHammingWeight(prng, params.RingQ(), params.HammingWeight(), false)
+	pskg.tmpSk = params.RingQP().NewPoly()
+	pskg.tmpU = params.RingQP().NewPoly()
+	return pskg
+}
+
+// AllocateShare allocates a party's share in the PSKG protocol.
+func (pskg *PSKGProtocol) AllocateShare() (share *PSKGShare) {
+
+	params := pskg.params
+	decompRNS := params.DecompRNS(params.QCount()-1, params.PCount()-1)
+	decompPw2 := params.DecompPw2(params.QCount()-1, params.PCount()-1)
+
+	share = &PSKGShare{Value: make([][][2]ringqp.Poly, decompRNS)}
+	for i := range share.Value {
+		share.Value[i] = make([][2]ringqp.Poly, decompPw2)
+		for j := range share.Value[i] {
+			share.Value[i][j] = [2]ringqp.Poly{params.RingQP().NewPoly(), params.RingQP().NewPoly()}
+		}
+	}
+	return
+}
+
+// GenShare generates a party's share in the PSKG protocol. For each element w_ij of the gadget vector, it computes
+//
+// [u_ij * pk[0] + P * w_ij * s_i + e_0ij, u_ij * pk[1] + e_1ij]
+//
+// where s_i is the party's share of skInput and pkOutput is the collective public key of the target key.
+func (pskg *PSKGProtocol) GenShare(skInput *rlwe.SecretKey, pkOutput *rlwe.PublicKey, shareOut *PSKGShare) {
+
+	ringQ := pskg.params.RingQ()
+	ringQP := pskg.params.RingQP()
+
+	levelQ := skInput.Value.LevelQ()
+	levelP := skInput.Value.LevelP()
+
+	hasModulusP := levelP > -1
+
+	// P * s_i, outside of the Montgomery domain
+	if hasModulusP {
+		ringQ.MulScalarBigintLvl(levelQ, skInput.Value.Q, ringQP.RingP.ModulusAtLevel[levelP], pskg.tmpSk.Q)
+	} else {
+		levelP = 0
+		ring.CopyLvl(levelQ, skInput.Value.Q, pskg.tmpSk.Q)
+	}
+	ringQ.InvMFormLvl(levelQ, pskg.tmpSk.Q, pskg.tmpSk.Q)
+
+	RNSDecomp := len(shareOut.Value)
+	BITDecomp := len(shareOut.Value[0])
+
+	var index int
+	for j := 0; j < BITDecomp; j++ {
+		for i := 0; i < RNSDecomp; i++ {
+
+			// u
+			pskg.ternarySamplerQ.ReadLvl(levelQ, pskg.tmpU.Q)
+			if hasModulusP {
+				ringQP.ExtendBasisSmallNormAndCenter(pskg.tmpU.Q, levelP, nil, pskg.tmpU.P)
+			}
+			ringQP.NTTLvl(levelQ, levelP, pskg.tmpU, pskg.tmpU)
+
+			for k := range shareOut.Value[i][j] {
+
+				// e
+				pskg.gaussianSamplerQ.ReadLvl(levelQ, shareOut.Value[i][j][k].Q)
+				if hasModulusP {
+					ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value[i][j][k].Q, levelP, nil, shareOut.Value[i][j][k].P)
+				}
+				ringQP.NTTLvl(levelQ, levelP, shareOut.Value[i][j][k], shareOut.Value[i][j][k])
+
+				// u * pk[k] + e
+				ringQP.MulCoeffsMontgomeryAndAddLvl(levelQ, levelP, pskg.tmpU, pkOutput.Value[k], shareOut.Value[i][j][k])
+			}
+
+			// u * pk[0] + P * s_i * (qiBarre*qiStar) * 2^w + e
+			// (qiBarre*qiStar)%qi = 1, else 0
+			for k := 0; k < levelP+1; k++ {
+
+				index = i*(levelP+1) + k
+
+				// Handles the case where nb pj does not divides nb qi
+				if index >= levelQ+1 {
+					break
+				}
+
+				qi := ringQ.Modulus[index]
+				tmp0 := pskg.tmpSk.Q.Coeffs[index]
+				tmp1 := shareOut.Value[i][j][0].Q.Coeffs[index]
+
+				for w := 0; w < ringQ.N; w++ {
+					tmp1[w] = ring.CRed(tmp1[w]+tmp0[w], qi)
+				}
+			}
+
+			// The switching keys are stored in the Montgomery domain
+			ringQP.MFormLvl(levelQ, levelP, shareOut.Value[i][j][0], shareOut.Value[i][j][0])
+			ringQP.MFormLvl(levelQ, levelP, shareOut.Value[i][j][1], shareOut.Value[i][j][1])
+		}
+
+		ringQ.MulScalar(pskg.tmpSk.Q, 1<<pskg.params.Pow2Base(), pskg.tmpSk.Q)
+	}
+}
+
+// AggregateShare aggregates two shares in the PSKG protocol.
+func (pskg *PSKGProtocol) AggregateShare(share1, share2, shareOut *PSKGShare) {
+	ringQP := pskg.params.RingQP()
+	levelQ := share1.Value[0][0][0].Q.Level()
+
+	var levelP int
+	if share1.Value[0][0][0].P != nil {
+		levelP = share1.Value[0][0][0].P.Level()
+	}
+
+	for i := range shareOut.Value {
+		for j := range shareOut.Value[i] {
+			ringQP.AddLvl(levelQ, levelP, share1.Value[i][j][0], share2.Value[i][j][0], shareOut.Value[i][j][0])
+			ringQP.AddLvl(levelQ, levelP, share1.Value[i][j][1], share2.Value[i][j][1], shareOut.Value[i][j][1])
+		}
+	}
+}
+
+// GenSwitchingKey finalizes the PSKG protocol and populates swkOut with the switching key from the collective
+// input key to the secret key of the output public key.
+func (pskg *PSKGProtocol) GenSwitchingKey(share *PSKGShare, swkOut *rlwe.SwitchingKey) {
+	for i := range share.Value {
+		for j := range share.Value[i] {
+			swkOut.Value[i][j].Value[0].CopyValues(share.Value[i][j][0])
+			swkOut.Value[i][j].Value[1].CopyValues(share.Value[i][j][1])
+		}
+	}
+}
+
+// MarshalBinary encodes the target element on a slice of bytes.
+func (share *PSKGShare) MarshalBinary() (data []byte, err error) {
+	return share.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes the target element on a slice of bytes with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (share *PSKGShare) MarshalBinaryPacked() (data []byte, err error) {
+	return share.marshalBinary(true)
+}
+
+func (share *PSKGShare) marshalBinary(packed bool) (data []byte, err error) {
+
+	if len(share.Value) == 0 || len(share.Value[0]) == 0 {
+		return []byte{}, errors.New("PSKGShare : share is empty")
+	}
+
+	if len(share.Value) > 0xFF || len(share.Value[0]) > 0xFF {
+		return []byte{}, errors.New("PSKGShare : uint8 overflow on length")
+	}
+
+	dataLen := 2
+	for i := range share.Value {
+		for _, el := range share.Value[i] {
+			dataLen += ring.GetDataLen(&el[0], true, packed) + ring.GetDataLen(&el[1], true, packed)
+		}
+	}
+
+	data = make([]byte, dataLen)
+	data[0] = uint8(len(share.Value))
+	data[1] = uint8(len(share.Value[0]))
+
+	ptr := 2
+	var inc int
+	for i := range share.Value {
+		for _, el := range share.Value[i] {
+			for k := range el {
+				if inc, err = ring.WriteTo(&el[k], data[ptr:], packed); err != nil {
+					return []byte{}, err
+				}
+				ptr += inc
+			}
+		}
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+func (share *PSKGShare) UnmarshalBinary(data []byte) (err error) {
+
+	// each element is a pair of ringqp.Poly that have at least a header of 2 bytes
+	decompRNS, decompPw2, err := decodeDecomposition(data, 4)
+	if err != nil {
+		return err
+	}
+
+	share.Value = make([][][2]ringqp.Poly, decompRNS)
+	ptr := 2
+	var inc int
+	for i := range share.Value {
+		share.Value[i] = make([][2]ringqp.Poly, decompPw2)
+		for j := range share.Value[i] {
+			for k := range share.Value[i][j] {
+				if inc, err = decodePoly(&share.Value[i][j][k], data[ptr:]); err != nil {
+					return err
+				}
+				ptr += inc
+			}
+		}
+	}
+
+	if ptr != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
+	return nil
+}
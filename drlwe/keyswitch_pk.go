@@ -60,6 +60,16 @@ func (pcks *PCKSProtocol) ShallowCopy() *PCKSProtocol {
 	}
 }
 
+// SetPRNG sets prng as the source of randomness of the ephemeral keys and of the smudging noise of the shares,
+// so that the shares generated by the receiver are reproducible from the state of prng. The ephemeral keys and
+// the smudging noise are sampled from independent streams derived from prng.
+func (pcks *PCKSProtocol) SetPRNG(prng utils.PRNG) {
+	params := pcks.params
+	prngs := derivePRNGs(prng, "drlwe/pcks/ephemeral", "drlwe/pcks/smudging")
+	pcks.ternarySamplerMontgomeryQ = ring.NewTernarySamplerWithHammingWeight(prngs[0], params.RingQ(), params.HammingWeight(), false)
+	pcks.gaussianSampler = ring.NewGaussianSampler(prngs[1], params.RingQ(), pcks.sigmaSmudging, int(6*pcks.sigmaSmudging))
+}
+
 // NewPCKSProtocol creates a new PCKSProtocol object and will be used to re-encrypt a ciphertext ctx encrypted under a secret-shared key among j parties under a new
 // collective public-key.
 func NewPCKSProtocol(params rlwe.Parameters, sigmaSmudging float64) (pcks *PCKSProtocol) {
@@ -91,7 +101,7 @@ func (pcks *PCKSProtocol) AllocateShare(levelQ int) (s *PCKSShare) {
 
 // GenShare is the first part of the unique round of the PCKSProtocol protocol. Each party computes the following :
 //
-// [s_i * ct[1] + (u_i * pk[0] + e_0i)/P, (u_i * pk[1] + e_1i)/P]
+// [s_i * ct[1] + (u_i * pk[0])/P + e_0i, (u_i * pk[1] + e_1i)/P]
 //
 // and broadcasts the result to the other j-1 parties.
 // ct1 is the degree 1 element of the rlwe.Ciphertext to keyswitch, i.e. ct1 = rlwe.Ciphertext.Value[1].
@@ -128,31 +138,26 @@ func (pcks *PCKSProtocol) GenShare(sk *rlwe.SecretKey, pk *rlwe.PublicKey, ct1 *
 	ringQP.InvNTTLvl(levelQ, levelP, shareOutQP0, shareOutQP0)
 	ringQP.InvNTTLvl(levelQ, levelP, shareOutQP1, shareOutQP1)
 
-	// h_0 = u_i * pk_0
-	pcks.gaussianSampler.ReadLvl(levelQ, pcks.tmpQP.Q)
-	if ringP != nil {
-		ringQP.ExtendBasisSmallNormAndCenter(pcks.tmpQP.Q, levelP, nil, pcks.tmpQP.P)
-	}
-
-	ringQP.AddLvl(levelQ, levelP, shareOutQP0, pcks.tmpQP, shareOutQP0)
-
-	// h_1 = u_i * pk_1 + e1
-	pcks.gaussianSampler.ReadLvl(levelQ, pcks.tmpQP.Q)
+	// h_1 = u_i * pk_1 + e1, where e1 follows the distribution of the fresh encryption noise
+	pcks.gaussianSampler.ReadFromDistLvl(levelQ, pcks.tmpQP.Q, ringQ, pcks.params.Sigma(), int(6*pcks.params.Sigma()))
 	if ringP != nil {
 		ringQP.ExtendBasisSmallNormAndCenter(pcks.tmpQP.Q, levelP, nil, pcks.tmpQP.P)
 	}
-
 	ringQP.AddLvl(levelQ, levelP, shareOutQP1, pcks.tmpQP, shareOutQP1)
 
 	if ringP != nil {
-		// h_0 = (u_i * pk_0 + e0)/P
+		// h_0 = (u_i * pk_0)/P
 		pcks.basisExtender.ModDownQPtoQ(levelQ, levelP, shareOutQP0.Q, shareOutQP0.P, shareOutQP0.Q)
 
 		// h_1 = (u_i * pk_1 + e1)/P
 		pcks.basisExtender.ModDownQPtoQ(levelQ, levelP, shareOutQP1.Q, shareOutQP1.P, shareOutQP1.Q)
 	}
 
-	// h_0 = s_i*c_1 + (u_i * pk_0 + e0)/P
+	// The smudging noise is added in Q, so that its standard deviation is sigmaSmudging in the share.
+	// h_0 = (u_i * pk_0)/P + e0
+	pcks.gaussianSampler.ReadAndAddLvl(levelQ, shareOut.Value[0])
+
+	// h_0 = s_i*c_1 + (u_i * pk_0)/P + e0
 	if ct1.IsNTT {
 		ringQ.NTTLvl(levelQ, shareOut.Value[0], shareOut.Value[0])
 		ringQ.NTTLvl(levelQ, shareOut.Value[1], shareOut.Value[1])
@@ -163,9 +168,12 @@ func (pcks *PCKSProtocol) GenShare(sk *rlwe.SecretKey, pk *rlwe.PublicKey, ct1 *
 		ringQ.MulCoeffsMontgomeryConstantLvl(levelQ, pcks.tmpQP.Q, sk.Value.Q, pcks.tmpQP.Q)
 		ringQ.InvNTTLvl(levelQ, pcks.tmpQP.Q, pcks.tmpQP.Q)
 
-		// h_0 = s_i*c_1 + (u_i * pk_0 + e0)/P
+		// h_0 = s_i*c_1 + (u_i * pk_0)/P + e0
 		ringQ.AddLvl(levelQ, shareOut.Value[0], pcks.tmpQP.Q, shareOut.Value[0])
 	}
+
+	shareOut.Value[0].IsNTT = ct1.IsNTT
+	shareOut.Value[1].IsNTT = ct1.IsNTT
 }
 
 // AggregateShare is the second part of the first and unique round of the PCKSProtocol protocol. Each party uppon receiving the j-1 elements from the
@@ -191,14 +199,24 @@ func (pcks *PCKSProtocol) KeySwitch(ctIn *rlwe.Ciphertext, combined *PCKSShare,
 
 // MarshalBinary encodes a PCKS share on a slice of bytes.
 func (share *PCKSShare) MarshalBinary() (data []byte, err error) {
-	data = make([]byte, share.Value[0].GetDataLen64(true)+share.Value[1].GetDataLen64(true))
+	return share.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes a PCKS share on a slice of bytes with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (share *PCKSShare) MarshalBinaryPacked() (data []byte, err error) {
+	return share.marshalBinary(true)
+}
+
+func (share *PCKSShare) marshalBinary(packed bool) (data []byte, err error) {
+	data = make([]byte, ring.GetDataLen(share.Value[0], true, packed)+ring.GetDataLen(share.Value[1], true, packed))
 	var inc, pt int
-	if inc, err = share.Value[0].WriteTo64(data[pt:]); err != nil {
+	if inc, err = ring.WriteTo(share.Value[0], data[pt:], packed); err != nil {
 		return nil, err
 	}
 	pt += inc
 
-	if _, err = share.Value[1].WriteTo64(data[pt:]); err != nil {
+	if _, err = ring.WriteTo(share.Value[1], data[pt:], packed); err != nil {
 		return nil, err
 	}
 	return
@@ -206,16 +224,19 @@ func (share *PCKSShare) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary decodes marshaled PCKS share on the target PCKS share.
 func (share *PCKSShare) UnmarshalBinary(data []byte) (err error) {
-	var pt, inc int
-	share.Value[0] = new(ring.Poly)
-	if inc, err = share.Value[0].DecodePoly64(data[pt:]); err != nil {
-		return
+
+	var ptr, inc int
+	for i := range share.Value {
+		share.Value[i] = new(ring.Poly)
+		if inc, err = decodePoly(share.Value[i], data[ptr:]); err != nil {
+			return
+		}
+		ptr += inc
 	}
-	pt += inc
 
-	share.Value[1] = new(ring.Poly)
-	if _, err = share.Value[1].DecodePoly64(data[pt:]); err != nil {
-		return
+	if ptr != len(data) {
+		return malformed("remaining unparsed data")
 	}
+
 	return
 }
@@ -20,7 +20,6 @@ type CKSProtocol struct {
 	params          rlwe.Parameters
 	sigmaSmudging   float64
 	gaussianSampler *ring.GaussianSampler
-	basisExtender   *ring.BasisExtender
 	tmpQP           ringqp.Poly
 	tmpDelta        *ring.Poly
 }
@@ -38,13 +37,20 @@ func (cks *CKSProtocol) ShallowCopy() *CKSProtocol {
 
 	return &CKSProtocol{
 		params:          params,
+		sigmaSmudging:   cks.sigmaSmudging,
 		gaussianSampler: ring.NewGaussianSampler(prng, params.RingQ(), cks.sigmaSmudging, int(6*cks.sigmaSmudging)),
-		basisExtender:   cks.basisExtender.ShallowCopy(),
 		tmpQP:           params.RingQP().NewPoly(),
 		tmpDelta:        params.RingQ().NewPoly(),
 	}
 }
 
+// SetPRNG sets prng as the source of randomness of the smudging noise of the shares, so that the shares
+// generated by the receiver are reproducible from the state of prng.
+func (cks *CKSProtocol) SetPRNG(prng utils.PRNG) {
+	prngs := derivePRNGs(prng, "drlwe/cks/smudging")
+	cks.gaussianSampler = ring.NewGaussianSampler(prngs[0], cks.params.RingQ(), cks.sigmaSmudging, int(6*cks.sigmaSmudging))
+}
+
 // CKSShare is a type for the CKS protocol shares.
 type CKSShare struct {
 	Value *ring.Poly
@@ -58,10 +64,27 @@ func (ckss *CKSShare) MarshalBinary() (data []byte, err error) {
 	return ckss.Value.MarshalBinary()
 }
 
+// MarshalBinaryPacked encodes a CKS share on a slice of bytes with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (ckss *CKSShare) MarshalBinaryPacked() (data []byte, err error) {
+	return ckss.Value.MarshalBinaryPacked()
+}
+
 // UnmarshalBinary decodes marshaled CKS share on the target CKS share.
 func (ckss *CKSShare) UnmarshalBinary(data []byte) (err error) {
+
 	ckss.Value = new(ring.Poly)
-	return ckss.Value.UnmarshalBinary(data)
+
+	var ptr int
+	if ptr, err = decodePoly(ckss.Value, data); err != nil {
+		return
+	}
+
+	if ptr != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
+	return
 }
 
 // NewCKSProtocol creates a new CKSProtocol that will be used to perform a collective key-switching on a ciphertext encrypted under a collective public-key, whose
@@ -76,10 +99,6 @@ func NewCKSProtocol(params rlwe.Parameters, sigmaSmudging float64) *CKSProtocol
 		panic(err)
 	}
 	cks.gaussianSampler = ring.NewGaussianSampler(prng, params.RingQ(), sigmaSmudging, int(6*sigmaSmudging))
-
-	if cks.params.RingP() != nil {
-		cks.basisExtender = ring.NewBasisExtender(params.RingQ(), params.RingP())
-	}
 	cks.tmpQP = params.RingQP().NewPoly()
 	cks.tmpDelta = params.RingQ().NewPoly()
 	return cks
@@ -104,11 +123,8 @@ func (cks *CKSProtocol) SampleCRP(level int, crs CRS) CKSCRP {
 func (cks *CKSProtocol) GenShare(skInput, skOutput *rlwe.SecretKey, c1 *ring.Poly, shareOut *CKSShare) {
 
 	ringQ := cks.params.RingQ()
-	ringP := cks.params.RingP()
-	ringQP := cks.params.RingQP()
 
 	levelQ := utils.MinInt(shareOut.Value.Level(), c1.Level())
-	levelP := cks.params.PCount() - 1
 
 	ringQ.SubLvl(levelQ, skInput.Value.Q, skOutput.Value.Q, cks.tmpDelta)
 
@@ -119,58 +135,22 @@ func (cks *CKSProtocol) GenShare(skInput, skOutput *rlwe.SecretKey, c1 *ring.Pol
 	}
 
 	// a * (skIn - skOut) mod Q
-	ringQ.MulCoeffsMontgomeryConstantLvl(levelQ, ct1, cks.tmpDelta, shareOut.Value)
-
-	if ringP != nil {
-		// P * a * (skIn - skOut) mod QP (mod P = 0)
-		ringQ.MulScalarBigintLvl(levelQ, shareOut.Value, ringP.ModulusAtLevel[levelP], shareOut.Value)
-	}
+	ringQ.MulCoeffsMontgomeryLvl(levelQ, ct1, cks.tmpDelta, shareOut.Value)
 
+	// The smudging noise is added in Q, so that its standard deviation is sigmaSmudging in the share.
 	if !c1.IsNTT {
-		// InvNTT(P * a * (skIn - skOut)) mod QP (mod P = 0)
-		ringQ.InvNTTLazyLvl(levelQ, shareOut.Value, shareOut.Value)
-
-		// Samples e in Q
-		cks.gaussianSampler.ReadLvl(levelQ, cks.tmpQP.Q)
-
-		if ringP != nil {
-			// Extend e to P (assumed to have norm < qi)
-			ringQP.ExtendBasisSmallNormAndCenter(cks.tmpQP.Q, levelP, nil, cks.tmpQP.P)
-		}
-
-		// InvNTT(P * a * (skIn - skOut) + e) mod QP (mod P = e)
-		ringQ.AddLvl(levelQ, shareOut.Value, cks.tmpQP.Q, shareOut.Value)
-
-		if ringP != nil {
-			// InvNTT(P * a * (skIn - skOut) + e) * (1/P) mod QP (mod P = e)
-			cks.basisExtender.ModDownQPtoQ(levelQ, levelP, shareOut.Value, cks.tmpQP.P, shareOut.Value)
-		}
-
+		// InvNTT(a * (skIn - skOut)) + e mod Q
+		ringQ.InvNTTLvl(levelQ, shareOut.Value, shareOut.Value)
+		cks.gaussianSampler.ReadAndAddLvl(levelQ, shareOut.Value)
 	} else {
-		// Sample e in Q
+		// a * (skIn - skOut) + NTT(e) mod Q
 		cks.gaussianSampler.ReadLvl(levelQ, cks.tmpQP.Q)
-
-		if ringP != nil {
-			// Extend e to P (assumed to have norm < qi)
-			ringQP.ExtendBasisSmallNormAndCenter(cks.tmpQP.Q, levelP, nil, cks.tmpQP.P)
-		}
-
-		// Takes the error to the NTT domain
-		ringQ.InvNTTLvl(levelQ, shareOut.Value, shareOut.Value)
-
-		// P * a * (skIn - skOut) + e mod Q (mod P = 0, so P = e)
+		ringQ.NTTLvl(levelQ, cks.tmpQP.Q, cks.tmpQP.Q)
 		ringQ.AddLvl(levelQ, shareOut.Value, cks.tmpQP.Q, shareOut.Value)
-
-		if ringP != nil {
-			// (P * a * (skIn - skOut) + e) * (1/P) mod QP (mod P = e)
-			cks.basisExtender.ModDownQPtoQ(levelQ, levelP, shareOut.Value, cks.tmpQP.P, shareOut.Value)
-		}
-
-		ringQ.NTTLvl(levelQ, shareOut.Value, shareOut.Value)
-
 	}
 
 	shareOut.Value.Resize(levelQ)
+	shareOut.Value.IsNTT = c1.IsNTT
 }
 
 // AggregateShare is the second part of the unique round of the CKSProtocol protocol. Upon receiving the j-1 elements each party computes :
@@ -186,3 +166,56 @@ func (cks *CKSProtocol) KeySwitch(ctIn *rlwe.Ciphertext, combined *CKSShare, ctO
 	cks.params.RingQ().AddLvl(level, ctIn.Value[0], combined.Value, ctOut.Value[0])
 	ring.CopyValuesLvl(level, ctIn.Value[1], ctOut.Value[1])
 }
+
+// ProveShare generates a proof that the share was generated with a short error, the secret key skInput committed in
+// comInput and the secret key skOutput committed in comOutput. The commitment comOutput is nil if skOutput is the
+// zero key, as for a collective decryption. The error of the share must be bounded by 6*sigmaSmudging: the method
+// returns an error for the shares with a larger noise, such as the shares of a differentially private key switching,
+// or for the shares that were not generated with these secret keys.
+func (cks *CKSProtocol) ProveShare(skInput, skOutput *rlwe.SecretKey, c1 *ring.Poly, share *CKSShare, comCRP CommitmentCRP, comInput, comOutput *Commitment) (*Proof, error) {
+	secrets := [][]int64{secretCoeffs(cks.params, skInput)}
+	if comOutput != nil {
+		secrets = append(secrets, secretCoeffs(cks.params, skOutput))
+	}
+	return cks.statement(c1, share, comCRP, comInput, comOutput).prove(secrets)
+}
+
+// VerifyShare verifies the proof generated by ProveShare for the share of the party with commitments comInput and comOutput.
+func (cks *CKSProtocol) VerifyShare(c1 *ring.Poly, share *CKSShare, comCRP CommitmentCRP, comInput, comOutput *Commitment, proof *Proof) bool {
+	return cks.statement(c1, share, comCRP, comInput, comOutput).verify(proof)
+}
+
+func (cks *CKSProtocol) statement(c1 *ring.Poly, share *CKSShare, comCRP CommitmentCRP, comInput, comOutput *Commitment) *statement {
+
+	ringQ := cks.params.RingQ()
+	ringQP := cks.params.RingQP()
+	level := share.Value.Level()
+
+	// the smudging noise is added in Q after the product, so the error is bounded by the bound of its sampler
+	bound := uint64(6 * cks.sigmaSmudging)
+
+	st := newStatement(cks.params, "CKS", 1)
+	st.addCommitment(0, comCRP, comInput)
+	if comOutput != nil {
+		st.secrets = append(st.secrets, 1)
+		st.addCommitment(1, comCRP, comOutput)
+	}
+
+	// share = c1*(skIn - skOut) + e
+	value, coeff, neg := ringQP.NewPoly(), ringQP.NewPoly(), ringQP.NewPoly()
+	ring.CopyLvl(level, share.Value, value.Q)
+	ring.CopyLvl(level, c1, coeff.Q)
+	if !c1.IsNTT {
+		ringQ.NTTLvl(level, value.Q, value.Q)
+		ringQ.NTTLvl(level, coeff.Q, coeff.Q)
+	}
+
+	if comOutput == nil {
+		st.add(level, -1, bound, value, []int{0}, coeff)
+	} else {
+		ringQ.NegLvl(level, coeff.Q, neg.Q)
+		st.add(level, -1, bound, value, []int{0, 1}, coeff, neg)
+	}
+
+	return st
+}
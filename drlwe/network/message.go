@@ -0,0 +1,169 @@
+// Package network implements the transport layer and a protocol runner for the multiparty protocols of the drlwe package.
+// It carries the parties' shares over a pluggable Transport, either in a cloud-assisted topology, where a single node
+// aggregates the shares and broadcasts the result, or in a peer-to-peer topology, where every party aggregates locally.
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MessageType identifies the kind of share carried by a Message.
+type MessageType uint8
+
+const (
+	// CKGShareType is the type of the messages carrying a drlwe.CKGShare.
+	CKGShareType MessageType = iota + 1
+	// RKGRoundOneShareType is the type of the messages carrying a drlwe.RKGShare of the first round.
+	RKGRoundOneShareType
+	// RKGRoundTwoShareType is the type of the messages carrying a drlwe.RKGShare of the second round.
+	RKGRoundTwoShareType
+	// RTGShareType is the type of the messages carrying a drlwe.RTGShare.
+	RTGShareType
+	// CKSShareType is the type of the messages carrying a drlwe.CKSShare.
+	CKSShareType
+	// PCKSShareType is the type of the messages carrying a drlwe.PCKSShare.
+	PCKSShareType
+	// RefreshShareType is the type of the messages carrying a dbfv.RefreshShare or a dckks.RefreshShare.
+	RefreshShareType
+)
+
+func (t MessageType) String() string {
+	switch t {
+	case CKGShareType:
+		return "CKGShare"
+	case RKGRoundOneShareType:
+		return "RKGShare(round 1)"
+	case RKGRoundTwoShareType:
+		return "RKGShare(round 2)"
+	case RTGShareType:
+		return "RTGShare"
+	case CKSShareType:
+		return "CKSShare"
+	case PCKSShareType:
+		return "PCKSShare"
+	case RefreshShareType:
+		return "RefreshShare"
+	default:
+		return fmt.Sprintf("MessageType(%d)", uint8(t))
+	}
+}
+
+// Message is a share sent by a node to another node.
+// The Tag disambiguates concurrent instances of the same protocol, for example
+// the Galois element of an RTG instance or the index of the ciphertext being key-switched.
+// Resent is set on the messages sent by a node that resumes after a crash, so that the
+// receivers reply with the messages the node may have lost. A resent message with an empty
+// Share is a request for the share of the receiver.
+//
+// From is not authenticated by the message itself: the Transport is responsible for guaranteeing that it is the
+// identity of the sender, see Transport.
+type Message struct {
+	Type   MessageType
+	Resent bool
+	Tag    uint64
+	From   string
+	Share  []byte
+}
+
+const messageHeaderLen = 12
+
+// MaxFrameSize is the maximum length in bytes of a marshalled Message sent over a stream (1 GiB), which is large
+// enough for the shares of the largest parameters. The frames announcing a larger length are rejected before being read.
+const MaxFrameSize = 1 << 30
+
+// GetDataLen returns the length in bytes of the target Message.
+func (msg *Message) GetDataLen() int {
+	return messageHeaderLen + len(msg.From) + len(msg.Share)
+}
+
+// MarshalBinary encodes the target Message on a slice of bytes.
+func (msg *Message) MarshalBinary() (data []byte, err error) {
+
+	if len(msg.From) > 0xFFFF {
+		return nil, errors.New("cannot MarshalBinary: sender ID is too long")
+	}
+
+	data = make([]byte, msg.GetDataLen())
+	data[0] = uint8(msg.Type)
+	if msg.Resent {
+		data[1] = 1
+	}
+	binary.LittleEndian.PutUint64(data[2:], msg.Tag)
+	binary.LittleEndian.PutUint16(data[10:], uint16(len(msg.From)))
+	copy(data[messageHeaderLen:], msg.From)
+	copy(data[messageHeaderLen+len(msg.From):], msg.Share)
+
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target Message.
+func (msg *Message) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < messageHeaderLen {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	lenFrom := int(binary.LittleEndian.Uint16(data[10:]))
+	if len(data) < messageHeaderLen+lenFrom {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	msg.Type = MessageType(data[0])
+	msg.Resent = data[1] == 1
+	msg.Tag = binary.LittleEndian.Uint64(data[2:])
+	msg.From = string(data[messageHeaderLen : messageHeaderLen+lenFrom])
+	msg.Share = make([]byte, len(data)-messageHeaderLen-lenFrom)
+	copy(msg.Share, data[messageHeaderLen+lenFrom:])
+
+	return
+}
+
+// writeFrame writes msg on w, prefixed by its length encoded on 4 bytes.
+func writeFrame(w io.Writer, msg *Message) (err error) {
+
+	var data []byte
+	if data, err = msg.MarshalBinary(); err != nil {
+		return
+	}
+
+	if uint64(len(data)) > MaxFrameSize {
+		return fmt.Errorf("cannot write frame: message of %d bytes is larger than MaxFrameSize", len(data))
+	}
+
+	frame := make([]byte, 4+len(data))
+	binary.LittleEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+
+	_, err = w.Write(frame)
+	return
+}
+
+// readFrame reads a length-prefixed Message of at most maxSize bytes from r. The frame is read into a buffer that
+// grows with the data actually received, so that a length prefix alone cannot trigger a large allocation.
+func readFrame(r io.Reader, maxSize uint64) (msg *Message, err error) {
+
+	var header [4]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := uint64(binary.LittleEndian.Uint32(header[:]))
+	if size > maxSize {
+		return nil, fmt.Errorf("cannot read frame: length %d is larger than %d", size, maxSize)
+	}
+
+	var buf bytes.Buffer
+	if _, err = io.CopyN(&buf, r, int64(size)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	msg = new(Message)
+	return msg, msg.UnmarshalBinary(buf.Bytes())
+}
@@ -0,0 +1,684 @@
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/dbfv"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+var partyIDs = []string{"alice", "bob", "carol"}
+var cloudID = "cloud"
+
+func testString(opname string, topology Topology, params bfv.Parameters) string {
+	return fmt.Sprintf("%s/%s/logN=%d/logQP=%d/parties=%d", opname, topology, params.LogN(), params.LogQP(), len(partyIDs))
+}
+
+type testContext struct {
+	params  bfv.Parameters
+	encoder bfv.Encoder
+	sk      map[string]*rlwe.SecretKey
+	tsk     *rlwe.SecretKey
+	tpk     *rlwe.PublicKey
+}
+
+func newTestContext(params bfv.Parameters) (tc *testContext) {
+
+	tc = &testContext{
+		params:  params,
+		encoder: bfv.NewEncoder(params),
+		sk:      make(map[string]*rlwe.SecretKey),
+	}
+
+	kgen := bfv.NewKeyGenerator(params)
+	for _, id := range partyIDs {
+		tc.sk[id] = kgen.GenSecretKey()
+	}
+
+	tc.tsk, tc.tpk = kgen.GenKeyPair()
+
+	return
+}
+
+func TestNetwork(t *testing.T) {
+
+	params, err := bfv.NewParametersFromLiteral(bfv.PN12QP109)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tc := newTestContext(params)
+
+	testMessageMarshalling(t)
+	testPendingMessages(tc, t)
+	testUnexpectedSender(tc, t)
+
+	for _, topology := range []Topology{CloudTopology, PeerTopology} {
+
+		t.Run(testString("Runner/Local", topology, params), func(t *testing.T) {
+			transports := make(map[string]Transport)
+			for id, lt := range NewLocalNetwork(append([]string{cloudID}, partyIDs...)) {
+				transports[id] = lt
+			}
+			testRunner(tc, transports, topology, t)
+		})
+
+		t.Run(testString("Runner/TCP", topology, params), func(t *testing.T) {
+			testRunner(tc, newTCPNetwork(append([]string{cloudID}, partyIDs...), t), topology, t)
+		})
+
+		testSession(tc, topology, t)
+		testSessionResume(tc, topology, "alice", t)
+	}
+
+	testSessionResume(tc, CloudTopology, cloudID, t)
+
+	testSessionError(tc, t)
+}
+
+func newTCPNetwork(ids []string, t *testing.T) map[string]Transport {
+
+	tcps := make(map[string]*TCPTransport)
+	for _, id := range ids {
+		tcp, err := NewTCPTransport(id, "127.0.0.1:0")
+		require.NoError(t, err)
+		tcps[id] = tcp
+	}
+
+	transports := make(map[string]Transport)
+	for id, tcp := range tcps {
+		for peer, other := range tcps {
+			if peer != id {
+				tcp.AddPeer(peer, other.Addr().String())
+			}
+		}
+		transports[id] = tcp
+	}
+
+	return transports
+}
+
+// runNodes executes f concurrently for all the nodes of the topology and returns the first error.
+func runNodes(topology Topology, transports map[string]Transport, f func(runner *Runner) error) error {
+
+	ids := partyIDs
+	if topology == CloudTopology {
+		ids = append([]string{cloudID}, partyIDs...)
+	}
+
+	errs := make([]error, len(ids))
+	wg := new(sync.WaitGroup)
+	wg.Add(len(ids))
+	for i, id := range ids {
+		go func(i int, runner *Runner) {
+			defer wg.Done()
+			errs[i] = f(runner)
+		}(i, NewRunner(transports[id], topology, partyIDs, cloudID))
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testMessageMarshalling(t *testing.T) {
+
+	t.Run("Marshalling/Message", func(t *testing.T) {
+
+		msg := &Message{Type: RTGShareType, Tag: 0x1234567890, From: "alice", Share: []byte{1, 2, 3, 4, 5}}
+
+		data, err := msg.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, msg.GetDataLen(), len(data))
+
+		msgNew := new(Message)
+		require.NoError(t, msgNew.UnmarshalBinary(data))
+		require.Equal(t, msg, msgNew)
+
+		require.Error(t, msgNew.UnmarshalBinary(data[:8]))
+		require.Error(t, msgNew.UnmarshalBinary(data[:12]))
+	})
+
+	t.Run("Marshalling/Frame", func(t *testing.T) {
+
+		msg := &Message{Type: CKSShareType, From: "alice", Share: []byte{1, 2, 3, 4, 5}}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeFrame(&buf, msg))
+		frame := buf.Bytes()
+
+		msgNew, err := readFrame(bytes.NewReader(frame), MaxFrameSize)
+		require.NoError(t, err)
+		require.Equal(t, msg, msgNew)
+
+		// a truncated frame or a frame larger than the maximum size is rejected
+		_, err = readFrame(bytes.NewReader(frame[:len(frame)-1]), MaxFrameSize)
+		require.Error(t, err)
+
+		_, err = readFrame(bytes.NewReader(frame), uint64(len(frame)-5))
+		require.Error(t, err)
+
+		binary.LittleEndian.PutUint32(frame, MaxFrameSize+1)
+		_, err = readFrame(bytes.NewReader(frame), MaxFrameSize)
+		require.Error(t, err)
+	})
+}
+
+func testPendingMessages(tc *testContext, t *testing.T) {
+
+	params := tc.params
+
+	t.Run(testString("PendingMessages", PeerTopology, params), func(t *testing.T) {
+
+		transports := NewLocalNetwork([]string{"alice", "bob"})
+		runner := NewRunner(transports["alice"], PeerTopology, []string{"alice", "bob"}, "")
+
+		cks := drlwe.NewCKSProtocol(params.Parameters, 3.2)
+		agg := NewCKSAggregator(cks, params.MaxLevel())
+
+		prng, _ := utils.NewPRNG()
+		sampler := ring.NewUniformSampler(prng, params.RingQ())
+
+		// bob sends the share of the second round first
+		own, other := make([]*drlwe.CKSShare, 2), make([]*drlwe.CKSShare, 2)
+		for _, tag := range []uint64{1, 0} {
+			own[tag], other[tag] = &drlwe.CKSShare{Value: sampler.ReadNew()}, &drlwe.CKSShare{Value: sampler.ReadNew()}
+			data, err := other[tag].MarshalBinary()
+			require.NoError(t, err)
+			require.NoError(t, transports["bob"].Send("alice", &Message{Type: CKSShareType, Tag: tag, From: "bob", Share: data}))
+		}
+
+		for tag := range own {
+			shareOut := cks.AllocateShare(params.MaxLevel())
+			require.NoError(t, runner.Exchange(CKSShareType, uint64(tag), agg, own[tag], shareOut))
+
+			want := params.RingQ().NewPoly()
+			params.RingQ().Add(own[tag].Value, other[tag].Value, want)
+			require.True(t, params.RingQ().Equal(want, shareOut.Value))
+		}
+
+		// bob received alice's shares
+		for tag := range own {
+			msg, err := transports["bob"].Receive()
+			require.NoError(t, err)
+			require.Equal(t, uint64(tag), msg.Tag)
+		}
+	})
+}
+
+func testUnexpectedSender(tc *testContext, t *testing.T) {
+
+	params := tc.params
+
+	t.Run(testString("UnexpectedSender", CloudTopology, params), func(t *testing.T) {
+
+		transports := NewLocalNetwork([]string{"alice", "bob", cloudID, "mallory"})
+		runner := NewRunner(transports[cloudID], CloudTopology, []string{"alice", "bob"}, cloudID)
+
+		ckg := drlwe.NewCKGProtocol(params.Parameters)
+		data, err := ckg.AllocateShare().MarshalBinary()
+		require.NoError(t, err)
+
+		// a node cannot send in the name of another one
+		require.Error(t, transports["mallory"].Send(cloudID, &Message{Type: CKGShareType, From: "alice", Share: data}))
+
+		require.NoError(t, transports["mallory"].Send(cloudID, &Message{Type: CKGShareType, From: "mallory", Share: data}))
+		require.Error(t, runner.Exchange(CKGShareType, 0, NewCKGAggregator(ckg), nil, ckg.AllocateShare()))
+
+		// a resent share is ignored, a different share from the same sender is rejected
+		conflicting := append([]byte{}, data...)
+		conflicting[len(conflicting)-1]++
+		require.NoError(t, transports["alice"].Send(cloudID, &Message{Type: CKGShareType, From: "alice", Share: data}))
+		require.NoError(t, transports["alice"].Send(cloudID, &Message{Type: CKGShareType, From: "alice", Share: data}))
+		require.NoError(t, transports["alice"].Send(cloudID, &Message{Type: CKGShareType, From: "alice", Share: conflicting}))
+		require.Error(t, runner.Exchange(CKGShareType, 0, NewCKGAggregator(ckg), nil, ckg.AllocateShare()))
+
+		require.Error(t, runner.Exchange(CKGShareType, 1, NewCKGAggregator(ckg), ckg.AllocateShare(), ckg.AllocateShare()))
+	})
+}
+
+type nodeKeys struct {
+	pk   *rlwe.PublicKey
+	rlk  *rlwe.RelinearizationKey
+	rtks *rlwe.RotationKeySet
+}
+
+type nodeOutputs struct {
+	ctCKS  *bfv.Ciphertext
+	ctPCKS *bfv.Ciphertext
+}
+
+func testRunner(tc *testContext, transports map[string]Transport, topology Topology, t *testing.T) {
+
+	defer func() {
+		for _, transport := range transports {
+			transport.Close()
+		}
+	}()
+
+	params := tc.params
+	galEl := params.GaloisElementForColumnRotationBy(1)
+
+	var mu sync.Mutex
+	keys := make(map[string]*nodeKeys)
+
+	// Setup phase: CKG, RKG and RTG
+	require.NoError(t, runNodes(topology, transports, func(runner *Runner) (err error) {
+
+		crs, _ := utils.NewKeyedPRNG([]byte{'t', 'e', 's', 't'})
+
+		ckg := dbfv.NewCKGProtocol(params)
+		rkg := dbfv.NewRKGProtocol(params)
+		rtg := dbfv.NewRotKGProtocol(params)
+
+		ckgCRP := ckg.SampleCRP(crs)
+		rkgCRP := rkg.SampleCRP(crs)
+		rtgCRP := rtg.SampleCRP(crs)
+
+		id := runner.transport.ID()
+		sk := tc.sk[id]
+
+		k := &nodeKeys{
+			pk:   bfv.NewPublicKey(params),
+			rlk:  bfv.NewRelinearizationKey(params, 1),
+			rtks: bfv.NewRotationKeySet(params, []uint64{galEl}),
+		}
+
+		if err = runner.RunCKG(&ckg.CKGProtocol, sk, ckgCRP, k.pk); err != nil {
+			return
+		}
+
+		if err = runner.RunRKG(&rkg.RKGProtocol, sk, rkgCRP, k.rlk); err != nil {
+			return
+		}
+
+		if err = runner.RunRTG(&rtg.RTGProtocol, sk, galEl, rtgCRP, k.rtks.Keys[galEl]); err != nil {
+			return
+		}
+
+		mu.Lock()
+		keys[id] = k
+		mu.Unlock()
+
+		return
+	}))
+
+	ref := keys[partyIDs[0]]
+	for _, k := range keys {
+		require.True(t, ref.pk.Equals(k.pk))
+		require.True(t, ref.rlk.Equals(k.rlk))
+		require.True(t, ref.rtks.Equals(k.rtks))
+	}
+
+	// Computation phase
+	prng, _ := utils.NewPRNG()
+	sampler := ring.NewUniformSampler(prng, params.RingT())
+	coeffs0, coeffs1 := sampler.ReadNew(), sampler.ReadNew()
+
+	encryptor := bfv.NewEncryptor(params, ref.pk)
+	pt := bfv.NewPlaintext(params)
+	tc.encoder.Encode(coeffs0.Coeffs[0], pt)
+	ct0 := encryptor.EncryptNew(pt)
+	tc.encoder.Encode(coeffs1.Coeffs[0], pt)
+	ct1 := encryptor.EncryptNew(pt)
+
+	eval := bfv.NewEvaluator(params, rlwe.EvaluationKey{Rlk: ref.rlk, Rtks: ref.rtks})
+	ct := eval.MulNew(ct0, ct1)
+	eval.Relinearize(ct, ct)
+	eval.RotateColumns(ct, 1, ct)
+
+	params.RingT().MulCoeffs(coeffs0, coeffs1, coeffs0)
+	want := utils.RotateUint64Slots(coeffs0.Coeffs[0], 1)
+
+	outputs := make(map[string]*nodeOutputs)
+
+	// Output phase: Refresh, then CKS and PCKS
+	require.NoError(t, runNodes(topology, transports, func(runner *Runner) (err error) {
+
+		crs, _ := utils.NewKeyedPRNG([]byte{'r', 'e', 'f', 'r', 'e', 's', 'h'})
+
+		id := runner.transport.ID()
+		sk := tc.sk[id]
+
+		refresh := dbfv.NewRefreshProtocol(params, 3.2)
+		crp := refresh.SampleCRP(params.MaxLevel(), crs)
+
+		agg := NewAggregator(
+			func() Share { return refresh.AllocateShare() },
+			func(share1, share2, shareOut Share) {
+				refresh.AggregateShare(share1.(*dbfv.RefreshShare), share2.(*dbfv.RefreshShare), shareOut.(*dbfv.RefreshShare))
+			})
+
+		var share Share
+		if sk != nil {
+			s := refresh.AllocateShare()
+			refresh.GenShare(sk, ct.Value[1], crp, s)
+			share = s
+		}
+
+		shareOut := refresh.AllocateShare()
+		if err = runner.Exchange(RefreshShareType, 0, agg, share, shareOut); err != nil {
+			return
+		}
+
+		ctRefreshed := bfv.NewCiphertext(params, 1)
+		refresh.Finalize(ct, crp, shareOut, ctRefreshed)
+
+		out := &nodeOutputs{ctCKS: bfv.NewCiphertext(params, 1), ctPCKS: bfv.NewCiphertext(params, 1)}
+
+		cks := dbfv.NewCKSProtocol(params, 3.2)
+		if err = runner.RunCKS(&cks.CKSProtocol, 1, sk, bfv.NewSecretKey(params), ctRefreshed.Ciphertext, out.ctCKS.Ciphertext); err != nil {
+			return
+		}
+
+		pcks := dbfv.NewPCKSProtocol(params, 3.2)
+		if err = runner.RunPCKS(&pcks.PCKSProtocol, 2, sk, tc.tpk, ctRefreshed.Ciphertext, out.ctPCKS.Ciphertext); err != nil {
+			return
+		}
+
+		mu.Lock()
+		outputs[id] = out
+		mu.Unlock()
+
+		return
+	}))
+
+	decryptorZero := bfv.NewDecryptor(params, bfv.NewSecretKey(params))
+	decryptorTarget := bfv.NewDecryptor(params, tc.tsk)
+
+	for _, out := range outputs {
+		require.True(t, utils.EqualSliceUint64(want, tc.encoder.DecodeUintNew(decryptorZero.DecryptNew(out.ctCKS))))
+		require.True(t, utils.EqualSliceUint64(want, tc.encoder.DecodeUintNew(decryptorTarget.DecryptNew(out.ctPCKS))))
+	}
+}
+
+func testSession(tc *testContext, topology Topology, t *testing.T) {
+
+	params := tc.params
+
+	t.Run(testString("Session", topology, params), func(t *testing.T) {
+
+		ids := partyIDs
+		if topology == CloudTopology {
+			ids = append([]string{cloudID}, partyIDs...)
+		}
+
+		network := NewLocalNetwork(ids)
+
+		var mu sync.Mutex
+		phases := make(map[string][]Phase)
+
+		sessions := make([]*Session, len(ids))
+		for i, id := range ids {
+			id := id
+			config := SessionConfig{
+				Parameters:     params.Parameters,
+				Topology:       topology,
+				Parties:        partyIDs,
+				Cloud:          cloudID,
+				CRSSeed:        []byte{'t', 'e', 's', 't'},
+				GaloisElements: []uint64{params.GaloisElementForRowRotation()},
+				Rotations:      []int{1},
+				OnPhaseDone: func(phase Phase, err error) {
+					mu.Lock()
+					defer mu.Unlock()
+					if err == nil {
+						phases[id] = append(phases[id], phase)
+					}
+				},
+			}
+			sessions[i] = NewSession(config, network[id], tc.sk[id])
+		}
+
+		require.Nil(t, sessions[0].PublicKey())
+		require.Nil(t, sessions[0].EvaluationKey())
+		require.Error(t, sessions[0].KeySwitch(0, nil, nil, nil))
+
+		require.NoError(t, runSessions(sessions, func(s *Session) error { return s.Run() }))
+
+		for i := range sessions {
+			require.Equal(t, []Phase{PhaseCKG, PhaseRKGRoundOne, PhaseRKGRoundTwo, PhaseRTG}, phases[ids[i]])
+		}
+
+		galEls := []uint64{params.GaloisElementForRowRotation(), params.GaloisElementForColumnRotationBy(1)}
+		require.ElementsMatch(t, galEls, sessions[0].EvaluationKey().Rtks.GaloisElements())
+
+		verifySessionKeys(tc, sessions, t)
+	})
+}
+
+// verifySessionKeys checks that the keys of the sessions are equal and evaluates a circuit with them.
+func verifySessionKeys(tc *testContext, sessions []*Session, t *testing.T) {
+
+	params := tc.params
+
+	for _, s := range sessions {
+		require.Equal(t, PhaseReady, s.Phase())
+		require.True(t, sessions[0].PublicKey().Equals(s.PublicKey()))
+		require.True(t, sessions[0].EvaluationKey().Rlk.Equals(s.EvaluationKey().Rlk))
+		require.True(t, sessions[0].EvaluationKey().Rtks.(*rlwe.RotationKeySet).Equals(s.EvaluationKey().Rtks.(*rlwe.RotationKeySet)))
+	}
+
+	prng, _ := utils.NewPRNG()
+	sampler := ring.NewUniformSampler(prng, params.RingT())
+	coeffs0, coeffs1 := sampler.ReadNew(), sampler.ReadNew()
+
+	encryptor := bfv.NewEncryptor(params, sessions[0].PublicKey())
+	pt := bfv.NewPlaintext(params)
+	tc.encoder.Encode(coeffs0.Coeffs[0], pt)
+	ct0 := encryptor.EncryptNew(pt)
+	tc.encoder.Encode(coeffs1.Coeffs[0], pt)
+	ct1 := encryptor.EncryptNew(pt)
+
+	eval := bfv.NewEvaluator(params, *sessions[0].EvaluationKey())
+	ct := eval.MulNew(ct0, ct1)
+	eval.Relinearize(ct, ct)
+	eval.RotateColumns(ct, 1, ct)
+	eval.RotateRows(ct, ct)
+
+	params.RingT().MulCoeffs(coeffs0, coeffs1, coeffs0)
+	want := utils.RotateUint64Slots(coeffs0.Coeffs[0], 1)
+	want = append(want[params.N()>>1:], want[:params.N()>>1]...)
+
+	ctOut := make([]*bfv.Ciphertext, len(sessions))
+	for i := range ctOut {
+		ctOut[i] = bfv.NewCiphertext(params, 1)
+	}
+
+	require.NoError(t, runSessions(sessions, func(s *Session) error {
+		for i := range sessions {
+			if sessions[i] == s {
+				return s.PublicKeySwitch(0, tc.tpk, ct.Ciphertext, ctOut[i].Ciphertext)
+			}
+		}
+		return nil
+	}))
+
+	decryptor := bfv.NewDecryptor(params, tc.tsk)
+	for i := range ctOut {
+		require.True(t, utils.EqualSliceUint64(want, tc.encoder.DecodeUintNew(decryptor.DecryptNew(ctOut[i]))))
+	}
+}
+
+// testSessionResume crashes a node at its crashAt-th checkpoint of the setup, for every crashAt until the setup
+// completes without crashing, and resumes the node from its last checkpoint.
+func testSessionResume(tc *testContext, topology Topology, crashed string, t *testing.T) {
+
+	params := tc.params
+
+	t.Run(testString("Session/Resume/"+crashed, topology, params), func(t *testing.T) {
+
+		ids := partyIDs
+		if topology == CloudTopology {
+			ids = append([]string{cloudID}, partyIDs...)
+		}
+
+		galEls := []uint64{params.GaloisElementForColumnRotationBy(1), params.GaloisElementForRowRotation()}
+
+		errCrash := errors.New("crash")
+
+		stride := 1
+		if testing.Short() {
+			stride = 5
+		}
+
+		for crashAt := 2; ; crashAt += stride {
+
+			network := NewLocalNetwork(ids)
+
+			var mu sync.Mutex
+			states := make(map[string][]byte)
+			checkpoints := 0
+			hasCrashed, ready := false, false
+
+			configs := make(map[string]SessionConfig)
+			for _, id := range ids {
+				id := id
+				configs[id] = SessionConfig{
+					Parameters:     params.Parameters,
+					Topology:       topology,
+					Parties:        partyIDs,
+					Cloud:          cloudID,
+					CRSSeed:        []byte{'t', 'e', 's', 't'},
+					GaloisElements: galEls,
+					Checkpoint: func(state []byte) error {
+						mu.Lock()
+						defer mu.Unlock()
+						if id == crashed && !hasCrashed && !ready {
+							if checkpoints++; checkpoints == crashAt {
+								hasCrashed = true
+								return errCrash
+							}
+						}
+						states[id] = state
+						return nil
+					},
+					OnPhaseDone: func(phase Phase, err error) {
+						mu.Lock()
+						defer mu.Unlock()
+						if id == crashed && phase == PhaseRTG && err == nil {
+							ready = true
+						}
+					},
+				}
+			}
+
+			sessions := make([]*Session, len(ids))
+			for i, id := range ids {
+				sessions[i] = NewSession(configs[id], network[id], tc.sk[id])
+			}
+
+			ctIn := rlwe.NewCiphertext(params.Parameters, 1, params.MaxLevel())
+			require.NoError(t, runSessions(sessions, func(s *Session) (err error) {
+
+				i, id := 0, s.runner.transport.ID()
+				for ids[i] != id {
+					i++
+				}
+
+				for {
+					// the key-switching keeps every node running until the others have completed the setup
+					if err = s.Run(); err == nil {
+						err = s.PublicKeySwitch(1, tc.tpk, ctIn, rlwe.NewCiphertext(params.Parameters, 1, params.MaxLevel()))
+					}
+
+					if !errors.Is(err, errCrash) {
+						return
+					}
+
+					// the messages received before the crash are lost
+					network[id].mu.Lock()
+					network[id].queue = nil
+					network[id].mu.Unlock()
+
+					mu.Lock()
+					state := states[id]
+					mu.Unlock()
+
+					if s, err = ResumeSession(configs[id], network[id], tc.sk[id], state); err != nil {
+						return
+					}
+					sessions[i] = s
+				}
+			}))
+
+			verifySessionKeys(tc, sessions, t)
+
+			if !hasCrashed {
+				break
+			}
+		}
+	})
+}
+
+func testSessionError(tc *testContext, t *testing.T) {
+
+	params := tc.params
+
+	t.Run(testString("Session/Error", CloudTopology, params), func(t *testing.T) {
+
+		network := NewLocalNetwork([]string{cloudID, "alice"})
+
+		var failed Phase = -1
+		config := SessionConfig{
+			Parameters:  params.Parameters,
+			Topology:    CloudTopology,
+			Parties:     []string{"alice"},
+			Cloud:       cloudID,
+			CRSSeed:     []byte{'t', 'e', 's', 't'},
+			OnPhaseDone: func(phase Phase, err error) { failed = phase },
+		}
+
+		session := NewSession(config, network[cloudID], nil)
+		network[cloudID].Close()
+
+		err := session.Run()
+		require.Error(t, err)
+
+		var phaseErr *PhaseError
+		require.True(t, errors.As(err, &phaseErr))
+		require.Equal(t, PhaseCKG, phaseErr.Phase)
+		require.True(t, errors.Is(err, ErrClosed))
+		require.Equal(t, PhaseCKG, failed)
+		require.Equal(t, PhaseCKG, session.Phase())
+	})
+}
+
+// runSessions executes f concurrently on all the sessions and returns the first error.
+func runSessions(sessions []*Session, f func(s *Session) error) error {
+
+	errs := make([]error, len(sessions))
+	wg := new(sync.WaitGroup)
+	wg.Add(len(sessions))
+	for i := range sessions {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = f(sessions[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,632 @@
+package network
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// Share is an interface for the shares of the multiparty protocols.
+type Share interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// Aggregator is an interface for the share allocation and aggregation of a multiparty protocol.
+type Aggregator interface {
+	AllocateShare() Share
+	AggregateShare(share1, share2, shareOut Share)
+}
+
+// Topology is the communication pattern between the nodes of a Runner.
+type Topology int
+
+const (
+	// CloudTopology is the topology in which the parties send their share to a single node (the cloud),
+	// which aggregates them and broadcasts the aggregated share back to the parties.
+	CloudTopology Topology = iota
+	// PeerTopology is the topology in which every party broadcasts its share to all the other parties,
+	// which aggregate them locally.
+	PeerTopology
+)
+
+func (t Topology) String() string {
+	switch t {
+	case CloudTopology:
+		return "Cloud"
+	case PeerTopology:
+		return "Peer"
+	default:
+		return fmt.Sprintf("Topology(%d)", int(t))
+	}
+}
+
+// Runner executes the multiparty protocols of the drlwe package over a Transport.
+// Every node involved in a protocol must execute the same sequence of calls on its own Runner.
+//
+// The rounds of a Runner are idempotent: the share of the local node is sent again when a round is resumed, identical
+// shares received twice are ignored and a share received for the last completed round is answered with the reply of
+// that round. Together with MarshalBinary and UnmarshalBinary, this enables a node to resume after a crash without
+// invalidating the shares already sent by the other nodes.
+type Runner struct {
+	transport Transport
+	topology  Topology
+	parties   []string
+	cloud     string
+	pending   []*Message
+
+	// current is the round being executed and last is the last completed round.
+	current *round
+	last    *round
+
+	// resumed is set when the state is restored. resend is the number of rounds, after the restoration, whose messages
+	// are sent again: the other nodes can be one round ahead, so the messages of two rounds may have been lost.
+	resumed bool
+	resend  int
+
+	// checkpoint, if set, is called after each update of the state of the Runner.
+	checkpoint func() error
+}
+
+// NewRunner creates a new Runner for the local node of transport. The parties are the identifiers of the nodes holding
+// a share of the collective secret key. In the CloudTopology, cloud is the identifier of the aggregating node, which can
+// be one of the parties. In the PeerTopology, cloud is ignored and the local node must be one of the parties.
+func NewRunner(transport Transport, topology Topology, parties []string, cloud string) *Runner {
+
+	runner := &Runner{transport: transport, topology: topology, parties: parties, cloud: cloud}
+
+	switch topology {
+	case CloudTopology:
+		if !runner.isParty() && transport.ID() != cloud {
+			panic(fmt.Sprintf("cannot NewRunner: node %q is neither a party nor the cloud", transport.ID()))
+		}
+	case PeerTopology:
+		if !runner.isParty() {
+			panic(fmt.Sprintf("cannot NewRunner: node %q is not a party", transport.ID()))
+		}
+	default:
+		panic("cannot NewRunner: invalid topology")
+	}
+
+	return runner
+}
+
+// HasShare returns true if the share of the local node for the round (msgType, tag) has already been registered,
+// that is, if the round is being resumed or has already completed. In this case, the share given to Exchange is ignored
+// and does not need to be generated.
+func (r *Runner) HasShare(msgType MessageType, tag uint64) bool {
+	return r.current.is(msgType, tag) || r.last.is(msgType, tag)
+}
+
+// Exchange executes one aggregation round of a protocol. The parties provide their share, the other nodes provide a nil share.
+// When the method returns, shareOut stores the aggregation of the shares of all the parties.
+// Rounds are identified by their message type and tag, so that messages of a later round received early are kept until they are needed.
+func (r *Runner) Exchange(msgType MessageType, tag uint64, agg Aggregator, share, shareOut Share) (err error) {
+
+	id := r.transport.ID()
+
+	if err = r.Resume(); err != nil {
+		return
+	}
+
+	if r.last.is(msgType, tag) {
+		return shareOut.UnmarshalBinary(r.last.aggregate)
+	}
+
+	if !r.current.is(msgType, tag) {
+
+		if r.isParty() != (share != nil) {
+			return fmt.Errorf("cannot Exchange: node %q must provide a share if and only if it is a party", id)
+		}
+
+		rd := newRound(msgType, tag)
+		if share != nil {
+			if rd.share, err = share.MarshalBinary(); err != nil {
+				return
+			}
+		}
+
+		r.current = rd
+
+		// the share is registered before being sent, so that the same share is sent again if the round is resumed
+		if err = r.update(); err != nil {
+			return
+		}
+	}
+
+	rd := r.current
+
+	if r.topology == CloudTopology && id != r.cloud {
+
+		if err = r.send(r.cloud, msgType, tag, rd.share, r.resend > 0); err != nil {
+			return
+		}
+
+		if err = r.collect(rd, []string{r.cloud}, func(from string, data []byte) (err error) {
+			rd.aggregate = data
+			return
+		}); err != nil {
+			return
+		}
+
+		return r.complete(shareOut, nil)
+	}
+
+	others := r.others()
+
+	switch {
+	case r.topology == PeerTopology:
+		for _, party := range others {
+			if err = r.send(party, msgType, tag, rd.share, r.resend > 0); err != nil {
+				return
+			}
+		}
+	case r.resend > 0:
+		// requests the shares that may have been lost by the cloud
+		for _, party := range others {
+			if _, ok := rd.received[party]; !ok {
+				if err = r.send(party, msgType, tag, nil, true); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	var acc, tmp Share
+
+	add := func(from string, data []byte) (err error) {
+
+		if acc == nil {
+			acc = agg.AllocateShare()
+			if rd.aggregate == nil {
+				return acc.UnmarshalBinary(data)
+			}
+			if err = acc.UnmarshalBinary(rd.aggregate); err != nil {
+				return
+			}
+		}
+
+		if tmp == nil {
+			tmp = agg.AllocateShare()
+		}
+
+		if err = tmp.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		agg.AggregateShare(tmp, acc, acc)
+
+		return
+	}
+
+	// the partial aggregation is marshalled only when the state is checkpointed
+	rd.marshalAggregate = func() (err error) {
+		if acc != nil {
+			rd.aggregate, err = acc.MarshalBinary()
+		}
+		return
+	}
+	defer func() { rd.marshalAggregate = nil }()
+
+	if _, ok := rd.received[id]; !ok && rd.share != nil {
+		if err = add(id, rd.share); err != nil {
+			return
+		}
+		rd.received[id] = digest(rd.share)
+		if err = r.update(); err != nil {
+			return
+		}
+	}
+
+	if err = r.collect(rd, others, add); err != nil {
+		return
+	}
+
+	if err = rd.marshalAggregate(); err != nil {
+		return
+	}
+
+	reply := rd.share
+	if r.topology == CloudTopology {
+		reply = rd.aggregate
+	}
+
+	if err = r.complete(shareOut, reply); err != nil {
+		return
+	}
+
+	if r.topology == CloudTopology {
+		for _, party := range others {
+			if err = r.send(party, msgType, tag, reply, false); err != nil {
+				return
+			}
+		}
+	}
+
+	return nil
+}
+
+// Resume sends again the reply of the last completed round to the other nodes, if the state of the Runner has been
+// restored with UnmarshalBinary, and marks the next two rounds as resent. It is called by Exchange and needs to be called
+// explicitly only if no other round is executed after the restoration.
+func (r *Runner) Resume() (err error) {
+
+	if !r.resumed {
+		return nil
+	}
+
+	if r.last != nil && r.last.reply != nil {
+		for _, other := range r.others() {
+			if err = r.send(other, r.last.msgType, r.last.tag, r.last.reply, false); err != nil {
+				return
+			}
+		}
+	}
+
+	r.resumed, r.resend = false, 2
+
+	return nil
+}
+
+// RunCKG executes the collective public key generation protocol and writes the collective public key on pkOut.
+// The secret key sk is ignored if the local node is not a party.
+func (r *Runner) RunCKG(ckg *drlwe.CKGProtocol, sk *rlwe.SecretKey, crp drlwe.CKGCRP, pkOut *rlwe.PublicKey) (err error) {
+
+	var share Share
+	if r.isParty() && !r.HasShare(CKGShareType, 0) {
+		s := ckg.AllocateShare()
+		ckg.GenShare(sk, crp, s)
+		share = s
+	}
+
+	shareOut := ckg.AllocateShare()
+	if err = r.Exchange(CKGShareType, 0, NewCKGAggregator(ckg), share, shareOut); err != nil {
+		return
+	}
+
+	ckg.GenPublicKey(shareOut, crp, pkOut)
+
+	return
+}
+
+// RunRKG executes the two rounds of the collective relinearization key generation protocol and writes the
+// relinearization key on rlkOut. The secret key sk is ignored if the local node is not a party.
+// The ephemeral secret key of the first round is only kept in memory, hence a RKG interrupted between
+// the two rounds cannot be resumed with this method. The Session type supports this case.
+func (r *Runner) RunRKG(rkg *drlwe.RKGProtocol, sk *rlwe.SecretKey, crp drlwe.RKGCRP, rlkOut *rlwe.RelinearizationKey) (err error) {
+
+	agg := NewRKGAggregator(rkg)
+
+	ephSk, share1, share2 := rkg.AllocateShare()
+	_, round1, round2 := rkg.AllocateShare()
+
+	var share Share
+	if r.isParty() {
+		rkg.GenShareRoundOne(sk, crp, ephSk, share1)
+		share = share1
+	}
+
+	if err = r.Exchange(RKGRoundOneShareType, 0, agg, share, round1); err != nil {
+		return
+	}
+
+	if r.isParty() {
+		rkg.GenShareRoundTwo(ephSk, sk, round1, share2)
+		share = share2
+	}
+
+	if err = r.Exchange(RKGRoundTwoShareType, 0, agg, share, round2); err != nil {
+		return
+	}
+
+	rkg.GenRelinearizationKey(round1, round2, rlkOut)
+
+	return
+}
+
+// RunRTG executes the collective rotation key generation protocol for the Galois element galEl and writes the
+// rotation key on rotKeyOut. The secret key sk is ignored if the local node is not a party.
+func (r *Runner) RunRTG(rtg *drlwe.RTGProtocol, sk *rlwe.SecretKey, galEl uint64, crp drlwe.RTGCRP, rotKeyOut *rlwe.SwitchingKey) (err error) {
+
+	var share Share
+	if r.isParty() && !r.HasShare(RTGShareType, galEl) {
+		s := rtg.AllocateShare()
+		rtg.GenShare(sk, galEl, crp, s)
+		share = s
+	}
+
+	shareOut := rtg.AllocateShare()
+	if err = r.Exchange(RTGShareType, galEl, NewRTGAggregator(rtg), share, shareOut); err != nil {
+		return
+	}
+
+	rtg.GenRotationKey(shareOut, crp, rotKeyOut)
+
+	return
+}
+
+// RunCKS executes the collective key-switching protocol from skInput to skOutput on ctIn and writes the result on ctOut.
+// The tag identifies the ciphertext among concurrent executions. The secret keys are ignored if the local node is not a party.
+func (r *Runner) RunCKS(cks *drlwe.CKSProtocol, tag uint64, skInput, skOutput *rlwe.SecretKey, ctIn, ctOut *rlwe.Ciphertext) (err error) {
+
+	level := ctIn.Level()
+
+	var share Share
+	if r.isParty() && !r.HasShare(CKSShareType, tag) {
+		s := cks.AllocateShare(level)
+		cks.GenShare(skInput, skOutput, ctIn.Value[1], s)
+		share = s
+	}
+
+	shareOut := cks.AllocateShare(level)
+	if err = r.Exchange(CKSShareType, tag, NewCKSAggregator(cks, level), share, shareOut); err != nil {
+		return
+	}
+
+	cks.KeySwitch(ctIn, shareOut, ctOut)
+
+	return
+}
+
+// RunPCKS executes the collective public key-switching protocol towards pk on ctIn and writes the result on ctOut.
+// The tag identifies the ciphertext among concurrent executions. The secret key sk is ignored if the local node is not a party.
+func (r *Runner) RunPCKS(pcks *drlwe.PCKSProtocol, tag uint64, sk *rlwe.SecretKey, pk *rlwe.PublicKey, ctIn, ctOut *rlwe.Ciphertext) (err error) {
+
+	level := ctIn.Level()
+
+	var share Share
+	if r.isParty() && !r.HasShare(PCKSShareType, tag) {
+		s := pcks.AllocateShare(level)
+		pcks.GenShare(sk, pk, ctIn.Value[1], s)
+		share = s
+	}
+
+	shareOut := pcks.AllocateShare(level)
+	if err = r.Exchange(PCKSShareType, tag, NewPCKSAggregator(pcks, level), share, shareOut); err != nil {
+		return
+	}
+
+	pcks.KeySwitch(ctIn, shareOut, ctOut)
+
+	return
+}
+
+func (r *Runner) isParty() bool {
+	for _, party := range r.parties {
+		if party == r.transport.ID() {
+			return true
+		}
+	}
+	return false
+}
+
+// others returns the parties other than the local node.
+func (r *Runner) others() (others []string) {
+	others = make([]string, 0, len(r.parties))
+	for _, party := range r.parties {
+		if party != r.transport.ID() {
+			others = append(others, party)
+		}
+	}
+	return
+}
+
+func (r *Runner) send(to string, msgType MessageType, tag uint64, data []byte, resent bool) error {
+	return r.transport.Send(to, &Message{Type: msgType, Resent: resent, Tag: tag, From: r.transport.ID(), Share: data})
+}
+
+// update calls the checkpoint function of the Runner, if any.
+func (r *Runner) update() error {
+	if r.checkpoint != nil {
+		return r.checkpoint()
+	}
+	return nil
+}
+
+// complete marks the current round as completed and writes its aggregated share on shareOut.
+// The reply is sent to the nodes that send again their share for this round.
+func (r *Runner) complete(shareOut Share, reply []byte) (err error) {
+
+	rd := r.current
+	rd.reply = reply
+	rd.share = nil
+
+	r.last, r.current = rd, nil
+
+	if r.resend > 0 {
+		r.resend--
+	}
+
+	if err = shareOut.UnmarshalBinary(rd.aggregate); err != nil {
+		return
+	}
+
+	return r.update()
+}
+
+// collect receives the shares of the round rd sent by the nodes in from and passes them to handle.
+// The messages of later rounds are kept for the later calls.
+func (r *Runner) collect(rd *round, from []string, handle func(from string, data []byte) error) (err error) {
+
+	expected := make(map[string]bool, len(from))
+	for _, id := range from {
+		expected[id] = true
+	}
+
+	missing := func() (n int) {
+		for _, id := range from {
+			if _, ok := rd.received[id]; !ok {
+				n++
+			}
+		}
+		return
+	}
+
+	process := func(msg *Message) (consumed bool, err error) {
+
+		switch {
+		case rd.is(msg.Type, msg.Tag):
+
+			if msg.Resent && len(msg.Share) == 0 {
+				// the cloud resumed and requests the share of the local node
+				if msg.From == r.cloud && rd.share != nil {
+					return true, r.send(msg.From, msg.Type, msg.Tag, rd.share, false)
+				}
+				return true, nil
+			}
+
+			if !expected[msg.From] {
+				return false, fmt.Errorf("cannot collect %s: unexpected share from node %q", msg.Type, msg.From)
+			}
+
+			d := digest(msg.Share)
+			if prev, ok := rd.received[msg.From]; ok {
+				if prev != d {
+					return false, fmt.Errorf("cannot collect %s: conflicting shares from node %q", msg.Type, msg.From)
+				}
+			} else {
+
+				if err = handle(msg.From, msg.Share); err != nil {
+					return
+				}
+
+				rd.received[msg.From] = d
+
+				if err = r.update(); err != nil {
+					return
+				}
+			}
+
+			// the sender resumed and may have lost the share of the local node
+			if msg.Resent && r.topology == PeerTopology {
+				return true, r.send(msg.From, msg.Type, msg.Tag, rd.share, false)
+			}
+
+			return true, nil
+
+		case r.last.is(msg.Type, msg.Tag):
+
+			if msg.Resent && len(msg.Share) == 0 {
+				return true, nil
+			}
+
+			prev, ok := r.last.received[msg.From]
+			if !ok {
+				return false, fmt.Errorf("cannot collect %s: unexpected share from node %q", msg.Type, msg.From)
+			}
+
+			if prev != digest(msg.Share) {
+				return false, fmt.Errorf("cannot collect %s: conflicting shares from node %q", msg.Type, msg.From)
+			}
+
+			// the sender resumed a round that is already completed locally
+			if msg.Resent && r.last.reply != nil {
+				return true, r.send(msg.From, msg.Type, msg.Tag, r.last.reply, false)
+			}
+
+			return true, nil
+		}
+
+		return false, nil
+	}
+
+	pending := r.pending[:0]
+	for _, msg := range r.pending {
+		var consumed bool
+		if consumed, err = process(msg); err != nil {
+			return
+		}
+		if !consumed {
+			pending = append(pending, msg)
+		}
+	}
+	r.pending = pending
+
+	for missing() > 0 {
+
+		var msg *Message
+		if msg, err = r.transport.Receive(); err != nil {
+			return
+		}
+
+		var consumed bool
+		if consumed, err = process(msg); err != nil {
+			return
+		}
+
+		if !consumed {
+			r.pending = append(r.pending, msg)
+		}
+	}
+
+	return
+}
+
+type aggregator struct {
+	allocate  func() Share
+	aggregate func(share1, share2, shareOut Share)
+}
+
+// NewAggregator creates a new Aggregator from an allocation and an aggregation function,
+// for example to run the Refresh protocols of the dbfv and dckks packages.
+func NewAggregator(allocate func() Share, aggregate func(share1, share2, shareOut Share)) Aggregator {
+	return &aggregator{allocate: allocate, aggregate: aggregate}
+}
+
+func (agg *aggregator) AllocateShare() Share {
+	return agg.allocate()
+}
+
+func (agg *aggregator) AggregateShare(share1, share2, shareOut Share) {
+	agg.aggregate(share1, share2, shareOut)
+}
+
+// NewCKGAggregator creates a new Aggregator for the shares of the CKG protocol.
+func NewCKGAggregator(ckg *drlwe.CKGProtocol) Aggregator {
+	return NewAggregator(
+		func() Share { return ckg.AllocateShare() },
+		func(share1, share2, shareOut Share) {
+			ckg.AggregateShare(share1.(*drlwe.CKGShare), share2.(*drlwe.CKGShare), shareOut.(*drlwe.CKGShare))
+		})
+}
+
+// NewRKGAggregator creates a new Aggregator for the shares of both rounds of the RKG protocol.
+func NewRKGAggregator(rkg *drlwe.RKGProtocol) Aggregator {
+	return NewAggregator(
+		func() Share {
+			_, share, _ := rkg.AllocateShare()
+			return share
+		},
+		func(share1, share2, shareOut Share) {
+			rkg.AggregateShare(share1.(*drlwe.RKGShare), share2.(*drlwe.RKGShare), shareOut.(*drlwe.RKGShare))
+		})
+}
+
+// NewRTGAggregator creates a new Aggregator for the shares of the RTG protocol.
+func NewRTGAggregator(rtg *drlwe.RTGProtocol) Aggregator {
+	return NewAggregator(
+		func() Share { return rtg.AllocateShare() },
+		func(share1, share2, shareOut Share) {
+			rtg.AggregateShare(share1.(*drlwe.RTGShare), share2.(*drlwe.RTGShare), shareOut.(*drlwe.RTGShare))
+		})
+}
+
+// NewCKSAggregator creates a new Aggregator for the shares of the CKS protocol at the given level.
+func NewCKSAggregator(cks *drlwe.CKSProtocol, level int) Aggregator {
+	return NewAggregator(
+		func() Share { return cks.AllocateShare(level) },
+		func(share1, share2, shareOut Share) {
+			cks.AggregateShare(share1.(*drlwe.CKSShare), share2.(*drlwe.CKSShare), shareOut.(*drlwe.CKSShare))
+		})
+}
+
+// NewPCKSAggregator creates a new Aggregator for the shares of the PCKS protocol at the given level.
+func NewPCKSAggregator(pcks *drlwe.PCKSProtocol, level int) Aggregator {
+	return NewAggregator(
+		func() Share { return pcks.AllocateShare(level) },
+		func(share1, share2, shareOut Share) {
+			pcks.AggregateShare(share1.(*drlwe.PCKSShare), share2.(*drlwe.PCKSShare), shareOut.(*drlwe.PCKSShare))
+		})
+}
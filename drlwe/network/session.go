@@ -0,0 +1,527 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// Phase is a state of the setup of a Session.
+type Phase int
+
+const (
+	// PhaseCKG is the collective public key generation phase.
+	PhaseCKG Phase = iota
+	// PhaseRKGRoundOne is the first round of the collective relinearization key generation.
+	PhaseRKGRoundOne
+	// PhaseRKGRoundTwo is the second round of the collective relinearization key generation.
+	PhaseRKGRoundTwo
+	// PhaseRTG is the collective rotation key generation phase, which runs one instance of the RTG protocol per Galois element.
+	PhaseRTG
+	// PhaseReady is the final state, in which the collective keys are available.
+	PhaseReady
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseCKG:
+		return "CKG"
+	case PhaseRKGRoundOne:
+		return "RKG(round 1)"
+	case PhaseRKGRoundTwo:
+		return "RKG(round 2)"
+	case PhaseRTG:
+		return "RTG"
+	case PhaseReady:
+		return "Ready"
+	default:
+		return fmt.Sprintf("Phase(%d)", int(p))
+	}
+}
+
+// PhaseError is the error returned when a phase of a Session fails.
+type PhaseError struct {
+	Phase Phase
+	Err   error
+}
+
+func (e *PhaseError) Error() string {
+	return fmt.Sprintf("session phase %s: %s", e.Phase, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PhaseError) Unwrap() error {
+	return e.Err
+}
+
+// SessionConfig is the configuration of a Session, which must be the same for all the nodes.
+type SessionConfig struct {
+	Parameters rlwe.Parameters
+
+	// Topology, Parties and Cloud are the arguments of NewRunner.
+	Topology Topology
+	Parties  []string
+	Cloud    string
+
+	// CRSSeed is the seed of the common reference string from which the common reference polynomials are sampled.
+	CRSSeed []byte
+
+	// GaloisElements are the Galois elements of the rotation keys to generate.
+	GaloisElements []uint64
+
+	// Rotations are the column rotations of the rotation keys to generate, in addition to GaloisElements. Their
+	// Galois elements are given by Parameters.GaloisElementForColumnRotationBy. The row rotation of BFV is
+	// generated by adding Parameters.GaloisElementForRowRotation to GaloisElements.
+	Rotations []int
+
+	// SigmaSmudging is the standard deviation of the smudging noise of the key-switching protocols.
+	// If left unset, it is set to rlwe.DefaultSigma.
+	SigmaSmudging float64
+
+	// OnPhaseStart, if set, is called before the execution of each phase.
+	OnPhaseStart func(phase Phase)
+	// OnPhaseDone, if set, is called after the execution of each phase with its error, if any.
+	OnPhaseDone func(phase Phase, err error)
+
+	// Checkpoint, if set, is called with the output of Session.MarshalBinary each time the state of the Session
+	// changes: when a share is generated, received or aggregated, and when a phase or a Galois element is completed.
+	// If it returns an error, the current phase fails. The state contains the ephemeral secret key of the RKG protocol
+	// and should be stored with the same care as the secret key share.
+	Checkpoint func(state []byte) error
+}
+
+// Session runs the setup of the collective keys of a multiparty session as a state machine
+// CKG -> RKG round 1 -> RKG round 2 -> RTG -> Ready, and then the collective key-switching protocols.
+type Session struct {
+	config SessionConfig
+	runner *Runner
+	sk     *rlwe.SecretKey
+	phase  Phase
+
+	ckg  *drlwe.CKGProtocol
+	rkg  *drlwe.RKGProtocol
+	rtg  *drlwe.RTGProtocol
+	cks  *drlwe.CKSProtocol
+	pcks *drlwe.PCKSProtocol
+
+	pk        *rlwe.PublicKey
+	ephSk     *rlwe.SecretKey
+	rkgRound1 *drlwe.RKGShare
+	evk       *rlwe.EvaluationKey
+	rtgDone   []uint64
+}
+
+// NewSession creates a new Session for the local node of transport. The secret key share sk is ignored if the
+// local node is not a party.
+func NewSession(config SessionConfig, transport Transport, sk *rlwe.SecretKey) *Session {
+
+	params := config.Parameters
+
+	if config.SigmaSmudging == 0 {
+		config.SigmaSmudging = rlwe.DefaultSigma
+	}
+
+	config.GaloisElements = galoisElements(params, config.GaloisElements, config.Rotations)
+
+	s := &Session{
+		config: config,
+		runner: NewRunner(transport, config.Topology, config.Parties, config.Cloud),
+		sk:     sk,
+		phase:  PhaseCKG,
+		ckg:    drlwe.NewCKGProtocol(params),
+		rkg:    drlwe.NewRKGProtocol(params),
+		rtg:    drlwe.NewRTGProtocol(params),
+		cks:    drlwe.NewCKSProtocol(params, config.SigmaSmudging),
+		pcks:   drlwe.NewPCKSProtocol(params, config.SigmaSmudging),
+		pk:     rlwe.NewPublicKey(params),
+		evk: &rlwe.EvaluationKey{
+			Rlk:  rlwe.NewRelinKey(params, 1),
+			Rtks: rlwe.NewRotationKeySet(params, config.GaloisElements),
+		},
+	}
+
+	s.runner.checkpoint = s.update
+
+	return s
+}
+
+// galoisElements returns galEls followed by the Galois elements of the column rotations that are not already in galEls.
+func galoisElements(params rlwe.Parameters, galEls []uint64, rotations []int) []uint64 {
+
+	galEls = append([]uint64{}, galEls...)
+
+	inSet := make(map[uint64]bool, len(galEls)+len(rotations))
+	for _, galEl := range galEls {
+		inSet[galEl] = true
+	}
+
+	for _, k := range rotations {
+		if galEl := params.GaloisElementForColumnRotationBy(k); !inSet[galEl] {
+			galEls = append(galEls, galEl)
+			inSet[galEl] = true
+		}
+	}
+
+	return galEls
+}
+
+// ResumeSession creates a new Session for the local node of transport from a state generated by Session.MarshalBinary,
+// for example after a crash of the node. The configuration and the secret key share must be the same as for the Session
+// that generated the state. The next call to Run or Step resumes the Session: the messages that may have been lost
+// are sent again and the other nodes reply with the messages the local node may have lost.
+func ResumeSession(config SessionConfig, transport Transport, sk *rlwe.SecretKey, state []byte) (s *Session, err error) {
+	s = NewSession(config, transport, sk)
+	if err = s.UnmarshalBinary(state); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// MarshalBinary encodes the state of the Session on a slice of bytes: the current phase, the keys generated
+// so far, the list of completed Galois elements, the ephemeral state of the RKG protocol and the state of the
+// underlying Runner.
+func (s *Session) MarshalBinary() (data []byte, err error) {
+
+	w := new(stateWriter)
+
+	w.uint64(uint64(s.phase))
+
+	w.uint64(uint64(len(s.rtgDone)))
+	for _, galEl := range s.rtgDone {
+		w.uint64(galEl)
+	}
+
+	var pk, ephSk, rkgRound1, rlk []byte
+
+	if s.phase > PhaseCKG {
+		if pk, err = s.pk.MarshalBinary(); err != nil {
+			return
+		}
+	}
+
+	if s.ephSk != nil {
+		if ephSk, err = s.ephSk.MarshalBinary(); err != nil {
+			return
+		}
+	}
+
+	if s.rkgRound1 != nil {
+		if rkgRound1, err = s.rkgRound1.MarshalBinary(); err != nil {
+			return
+		}
+	}
+
+	if s.phase > PhaseRKGRoundTwo {
+		if rlk, err = s.evk.Rlk.MarshalBinary(); err != nil {
+			return
+		}
+	}
+
+	w.bytes(pk)
+	w.bytes(ephSk)
+	w.bytes(rkgRound1)
+	w.bytes(rlk)
+
+	for _, galEl := range s.rtgDone {
+		var swk []byte
+		rtk, _ := s.evk.Rtks.GetRotationKey(galEl)
+		if swk, err = rtk.MarshalBinary(); err != nil {
+			return
+		}
+		w.bytes(swk)
+	}
+
+	var runner []byte
+	if runner, err = s.runner.MarshalBinary(); err != nil {
+		return
+	}
+	w.bytes(runner)
+
+	return w.buf, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target Session.
+func (s *Session) UnmarshalBinary(data []byte) (err error) {
+
+	r := &stateReader{buf: data}
+
+	phase := Phase(r.uint64())
+	if phase < PhaseCKG || phase > PhaseReady {
+		return fmt.Errorf("cannot UnmarshalBinary: invalid phase %d", phase)
+	}
+
+	n := r.length()
+	if n > len(s.config.GaloisElements) {
+		return errors.New("cannot UnmarshalBinary: too many completed Galois elements")
+	}
+
+	rtgDone := make([]uint64, n)
+	for i := range rtgDone {
+		if rtgDone[i] = r.uint64(); r.err == nil && rtgDone[i] != s.config.GaloisElements[i] {
+			return fmt.Errorf("cannot UnmarshalBinary: completed Galois element %d does not match the configuration", rtgDone[i])
+		}
+	}
+
+	pk, ephSk, rkgRound1, rlk := r.bytes(), r.bytes(), r.bytes(), r.bytes()
+
+	swks := make([][]byte, n)
+	for i := range swks {
+		swks[i] = r.bytes()
+	}
+
+	runner := r.bytes()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	if phase > PhaseCKG {
+		if err = s.pk.UnmarshalBinary(pk); err != nil {
+			return
+		}
+	}
+
+	s.ephSk = nil
+	if ephSk != nil {
+		s.ephSk = new(rlwe.SecretKey)
+		if err = s.ephSk.UnmarshalBinary(ephSk); err != nil {
+			return
+		}
+	}
+
+	s.rkgRound1 = nil
+	if rkgRound1 != nil {
+		s.rkgRound1 = new(drlwe.RKGShare)
+		if err = s.rkgRound1.UnmarshalBinary(rkgRound1); err != nil {
+			return
+		}
+	}
+
+	if phase > PhaseRKGRoundTwo {
+		if err = s.evk.Rlk.UnmarshalBinary(rlk); err != nil {
+			return
+		}
+	}
+
+	for i, galEl := range rtgDone {
+		rtk, _ := s.evk.Rtks.GetRotationKey(galEl)
+		if err = rtk.UnmarshalBinary(swks[i]); err != nil {
+			return
+		}
+	}
+
+	if err = s.runner.UnmarshalBinary(runner); err != nil {
+		return
+	}
+
+	s.phase, s.rtgDone = phase, rtgDone
+
+	return nil
+}
+
+// update calls the checkpoint function of the Session, if any.
+func (s *Session) update() (err error) {
+
+	if s.config.Checkpoint == nil {
+		return nil
+	}
+
+	var state []byte
+	if state, err = s.MarshalBinary(); err != nil {
+		return
+	}
+
+	return s.config.Checkpoint(state)
+}
+
+// Phase returns the current phase of the Session.
+func (s *Session) Phase() Phase {
+	return s.phase
+}
+
+// Run executes the remaining phases of the setup until the Session is ready.
+// If a phase fails, Run returns a *PhaseError and a later call resumes from the failed phase.
+func (s *Session) Run() (err error) {
+
+	if err = s.runner.Resume(); err != nil {
+		return &PhaseError{Phase: s.phase, Err: err}
+	}
+
+	for s.phase != PhaseReady {
+		if err = s.Step(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Step executes the current phase of the setup and moves the Session to the next phase.
+// If the phase fails, Step returns a *PhaseError and the Session stays in the current phase, unless the
+// error comes from the checkpoint of the completed phase.
+func (s *Session) Step() (err error) {
+
+	phase := s.phase
+
+	if phase == PhaseReady {
+		return nil
+	}
+
+	if s.config.OnPhaseStart != nil {
+		s.config.OnPhaseStart(phase)
+	}
+
+	switch phase {
+	case PhaseCKG:
+		err = s.runCKG()
+	case PhaseRKGRoundOne:
+		err = s.runRKGRoundOne()
+	case PhaseRKGRoundTwo:
+		err = s.runRKGRoundTwo()
+	case PhaseRTG:
+		err = s.runRTG()
+	default:
+		err = errors.New("invalid phase")
+	}
+
+	if err == nil {
+		s.phase++
+		err = s.update()
+	}
+
+	if err != nil {
+		err = &PhaseError{Phase: phase, Err: err}
+	}
+
+	if s.config.OnPhaseDone != nil {
+		s.config.OnPhaseDone(phase, err)
+	}
+
+	return
+}
+
+// PublicKey returns the collective public key, or nil if the CKG phase has not completed.
+func (s *Session) PublicKey() *rlwe.PublicKey {
+	if s.phase <= PhaseCKG {
+		return nil
+	}
+	return s.pk
+}
+
+// EvaluationKey returns the collective evaluation key, or nil if the Session is not ready.
+func (s *Session) EvaluationKey() *rlwe.EvaluationKey {
+	if s.phase != PhaseReady {
+		return nil
+	}
+	return s.evk
+}
+
+// KeySwitch runs the collective key-switching protocol from the collective secret key to skOutput on ctIn and writes the result on ctOut.
+// The tag identifies the ciphertext among concurrent executions. The key skOutput is ignored if the local node is not a party.
+func (s *Session) KeySwitch(tag uint64, skOutput *rlwe.SecretKey, ctIn, ctOut *rlwe.Ciphertext) (err error) {
+	if s.phase != PhaseReady {
+		return fmt.Errorf("cannot KeySwitch: session is in phase %s", s.phase)
+	}
+	return s.runner.RunCKS(s.cks, tag, s.sk, skOutput, ctIn, ctOut)
+}
+
+// PublicKeySwitch runs the collective public key-switching protocol from the collective secret key to pk on ctIn and writes the result on ctOut.
+// The tag identifies the ciphertext among concurrent executions.
+func (s *Session) PublicKeySwitch(tag uint64, pk *rlwe.PublicKey, ctIn, ctOut *rlwe.Ciphertext) (err error) {
+	if s.phase != PhaseReady {
+		return fmt.Errorf("cannot PublicKeySwitch: session is in phase %s", s.phase)
+	}
+	return s.runner.RunPCKS(s.pcks, tag, s.sk, pk, ctIn, ctOut)
+}
+
+func (s *Session) runCKG() (err error) {
+	crs, err := s.crs(PhaseCKG, 0)
+	if err != nil {
+		return
+	}
+	return s.runner.RunCKG(s.ckg, s.sk, s.ckg.SampleCRP(crs), s.pk)
+}
+
+func (s *Session) runRKGRoundOne() (err error) {
+
+	crs, err := s.crs(PhaseRKGRoundOne, 0)
+	if err != nil {
+		return
+	}
+
+	// the ephemeral secret key is stored before the share is checkpointed by the Runner
+	var share Share
+	if s.runner.isParty() && !s.runner.HasShare(RKGRoundOneShareType, 0) {
+		ephSk, share1, _ := s.rkg.AllocateShare()
+		s.rkg.GenShareRoundOne(s.sk, s.rkg.SampleCRP(crs), ephSk, share1)
+		s.ephSk, share = ephSk, share1
+	}
+
+	_, round1, _ := s.rkg.AllocateShare()
+	if err = s.runner.Exchange(RKGRoundOneShareType, 0, NewRKGAggregator(s.rkg), share, round1); err != nil {
+		return
+	}
+
+	s.rkgRound1 = round1
+
+	return
+}
+
+func (s *Session) runRKGRoundTwo() (err error) {
+
+	var share Share
+	_, _, share2 := s.rkg.AllocateShare()
+	if s.runner.isParty() && !s.runner.HasShare(RKGRoundTwoShareType, 0) {
+		s.rkg.GenShareRoundTwo(s.ephSk, s.sk, s.rkgRound1, share2)
+		share = share2
+	}
+
+	_, _, round2 := s.rkg.AllocateShare()
+	if err = s.runner.Exchange(RKGRoundTwoShareType, 0, NewRKGAggregator(s.rkg), share, round2); err != nil {
+		return
+	}
+
+	s.rkg.GenRelinearizationKey(s.rkgRound1, round2, s.evk.Rlk)
+	s.ephSk, s.rkgRound1 = nil, nil
+
+	return
+}
+
+func (s *Session) runRTG() (err error) {
+
+	for len(s.rtgDone) < len(s.config.GaloisElements) {
+
+		galEl := s.config.GaloisElements[len(s.rtgDone)]
+
+		var crs utils.PRNG
+		if crs, err = s.crs(PhaseRTG, galEl); err != nil {
+			return
+		}
+
+		rtk, _ := s.evk.Rtks.GetRotationKey(galEl)
+		if err = s.runner.RunRTG(s.rtg, s.sk, galEl, s.rtg.SampleCRP(crs), rtk); err != nil {
+			return fmt.Errorf("galois element %d: %w", galEl, err)
+		}
+
+		s.rtgDone = append(s.rtgDone, galEl)
+
+		if err = s.update(); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// crs returns the common reference string of the given phase, derived from the seed of the Session
+// so that the phases can be executed and resumed independently.
+func (s *Session) crs(phase Phase, tag uint64) (utils.PRNG, error) {
+	key := make([]byte, len(s.config.CRSSeed)+9)
+	copy(key, s.config.CRSSeed)
+	key[len(s.config.CRSSeed)] = uint8(phase)
+	binary.LittleEndian.PutUint64(key[len(s.config.CRSSeed)+1:], tag)
+	return utils.NewKeyedPRNG(key)
+}
@@ -0,0 +1,222 @@
+package network
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// round is the state of an aggregation round of a Runner.
+type round struct {
+	msgType MessageType
+	tag     uint64
+
+	// share is the share of the local node, if it is a party.
+	share []byte
+
+	// received stores the digest of the share received from each node.
+	received map[string][sha256.Size]byte
+
+	// aggregate is the (partial) aggregation of the received shares,
+	// or the aggregated share received from the cloud.
+	aggregate []byte
+
+	// reply is the message sent back to a node that resends its share for this round once it is completed.
+	reply []byte
+
+	// marshalAggregate, if set, updates aggregate with the ongoing aggregation.
+	marshalAggregate func() error
+}
+
+func newRound(msgType MessageType, tag uint64) *round {
+	return &round{msgType: msgType, tag: tag, received: make(map[string][sha256.Size]byte)}
+}
+
+// is returns true if the round is the round (msgType, tag). It returns false if rd is nil.
+func (rd *round) is(msgType MessageType, tag uint64) bool {
+	return rd != nil && rd.msgType == msgType && rd.tag == tag
+}
+
+func digest(data []byte) [sha256.Size]byte {
+	return sha256.Sum256(data)
+}
+
+func (rd *round) encode(w *stateWriter) (err error) {
+
+	if rd.marshalAggregate != nil {
+		if err = rd.marshalAggregate(); err != nil {
+			return
+		}
+	}
+
+	w.uint64(uint64(rd.msgType))
+	w.uint64(rd.tag)
+	w.bytes(rd.share)
+	w.bytes(rd.aggregate)
+	w.bytes(rd.reply)
+
+	ids := make([]string, 0, len(rd.received))
+	for id := range rd.received {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	w.uint64(uint64(len(ids)))
+	for _, id := range ids {
+		d := rd.received[id]
+		w.bytes([]byte(id))
+		w.bytes(d[:])
+	}
+
+	return
+}
+
+func (rd *round) decode(r *stateReader) {
+
+	rd.msgType = MessageType(r.uint64())
+	rd.tag = r.uint64()
+	rd.share = r.bytes()
+	rd.aggregate = r.bytes()
+	rd.reply = r.bytes()
+
+	n := r.length()
+	rd.received = make(map[string][sha256.Size]byte, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		id := string(r.bytes())
+		var d [sha256.Size]byte
+		if len(r.bytesInto(d[:])) != sha256.Size {
+			r.err = errShortState
+		}
+		rd.received[id] = d
+	}
+}
+
+// MarshalBinary encodes the state of the Runner on a slice of bytes: the shares of the ongoing round and the last completed
+// round, the partial aggregation of the ongoing round and the messages received in advance. It does not encode the
+// configuration of the Runner.
+func (r *Runner) MarshalBinary() (data []byte, err error) {
+
+	w := new(stateWriter)
+
+	for _, rd := range []*round{r.current, r.last} {
+		if rd == nil {
+			w.uint64(0)
+			continue
+		}
+		w.uint64(1)
+		if err = rd.encode(w); err != nil {
+			return nil, err
+		}
+	}
+
+	w.uint64(uint64(len(r.pending)))
+	for _, msg := range r.pending {
+		var msgData []byte
+		if msgData, err = msg.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		w.bytes(msgData)
+	}
+
+	return w.buf, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the state of the target Runner.
+// The next call to Exchange (or Resume) sends again the messages that may have been lost by the receiver
+// of the state, for example because the process crashed.
+func (r *Runner) UnmarshalBinary(data []byte) (err error) {
+
+	rd := &stateReader{buf: data}
+
+	rounds := make([]*round, 2)
+	for i := range rounds {
+		if rd.uint64() == 1 {
+			rounds[i] = new(round)
+			rounds[i].decode(rd)
+		}
+	}
+
+	n := rd.length()
+	pending := make([]*Message, 0, n)
+	for i := 0; i < n && rd.err == nil; i++ {
+		msg := new(Message)
+		if err = msg.UnmarshalBinary(rd.bytes()); err != nil {
+			return
+		}
+		pending = append(pending, msg)
+	}
+
+	if rd.err != nil {
+		return rd.err
+	}
+
+	r.current, r.last, r.pending = rounds[0], rounds[1], pending
+	r.resumed = true
+
+	return nil
+}
+
+var errShortState = errors.New("cannot UnmarshalBinary: state is too short")
+
+// stateWriter encodes integers and length-prefixed byte slices.
+type stateWriter struct {
+	buf []byte
+}
+
+func (w *stateWriter) uint64(v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	w.buf = append(w.buf, b[:]...)
+}
+
+func (w *stateWriter) bytes(b []byte) {
+	w.uint64(uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+// stateReader decodes the output of a stateWriter. The first error is recorded and
+// the subsequent reads return zero values.
+type stateReader struct {
+	buf []byte
+	err error
+}
+
+func (r *stateReader) uint64() (v uint64) {
+	if r.err != nil {
+		return
+	}
+	if len(r.buf) < 8 {
+		r.err = errShortState
+		return
+	}
+	v, r.buf = binary.LittleEndian.Uint64(r.buf), r.buf[8:]
+	return
+}
+
+// length reads a length and checks that it does not exceed the remaining data.
+func (r *stateReader) length() int {
+	n := r.uint64()
+	if n > uint64(len(r.buf)) {
+		r.err = errShortState
+		return 0
+	}
+	return int(n)
+}
+
+func (r *stateReader) bytes() (b []byte) {
+	n := r.length()
+	if r.err != nil || n == 0 {
+		return nil
+	}
+	b = make([]byte, n)
+	copy(b, r.buf[:n])
+	r.buf = r.buf[n:]
+	return
+}
+
+func (r *stateReader) bytesInto(dst []byte) []byte {
+	b := r.bytes()
+	copy(dst, b)
+	return b
+}
@@ -0,0 +1,201 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// TCPTransport is a Transport over TCP connections. Each Message is sent as a frame prefixed
+// by its length in bytes, of at most MaxFrameSize bytes. The connection to a peer is opened on the first call to Send.
+//
+// The sender of the first Message received on a connection is bound to the connection, and the connection is closed if
+// it carries a Message from another sender. The TCPTransport does not authenticate the nodes: it must only be used on a
+// network on which the peers are authenticated, for example through an authenticated tunnel.
+type TCPTransport struct {
+	id       string
+	listener net.Listener
+
+	mu       sync.Mutex
+	peers    map[string]string
+	conns    map[string]net.Conn
+	accepted map[net.Conn]bool
+
+	inbox  chan *Message
+	closed chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+}
+
+// NewTCPTransport creates a new TCPTransport for the node with identifier id listening on the given address.
+// The peers of the node are registered with AddPeer.
+func NewTCPTransport(id, address string) (tcp *TCPTransport, err error) {
+
+	var listener net.Listener
+	if listener, err = net.Listen("tcp", address); err != nil {
+		return nil, err
+	}
+
+	tcp = &TCPTransport{
+		id:       id,
+		listener: listener,
+		peers:    make(map[string]string),
+		conns:    make(map[string]net.Conn),
+		accepted: make(map[net.Conn]bool),
+		inbox:    make(chan *Message),
+		closed:   make(chan struct{}),
+	}
+
+	tcp.wg.Add(1)
+	go tcp.accept()
+
+	return tcp, nil
+}
+
+// ID returns the identifier of the local node.
+func (tcp *TCPTransport) ID() string {
+	return tcp.id
+}
+
+// Addr returns the address the TCPTransport is listening on.
+func (tcp *TCPTransport) Addr() net.Addr {
+	return tcp.listener.Addr()
+}
+
+// AddPeer registers the address of the node with identifier id.
+func (tcp *TCPTransport) AddPeer(id, address string) {
+	tcp.mu.Lock()
+	defer tcp.mu.Unlock()
+	tcp.peers[id] = address
+}
+
+// Send sends msg to the node with identifier to.
+// It returns an error if msg.From is not the identifier of the local node.
+func (tcp *TCPTransport) Send(to string, msg *Message) (err error) {
+
+	if msg.From != tcp.id {
+		return fmt.Errorf("cannot Send: sender %q is not the local node %q", msg.From, tcp.id)
+	}
+
+	select {
+	case <-tcp.closed:
+		return ErrClosed
+	default:
+	}
+
+	tcp.mu.Lock()
+	defer tcp.mu.Unlock()
+
+	conn, ok := tcp.conns[to]
+	if !ok {
+
+		address, ok := tcp.peers[to]
+		if !ok {
+			return fmt.Errorf("cannot Send: unknown node %q", to)
+		}
+
+		if conn, err = net.Dial("tcp", address); err != nil {
+			return fmt.Errorf("cannot Send: %w", err)
+		}
+
+		tcp.conns[to] = conn
+	}
+
+	if err = writeFrame(conn, msg); err != nil {
+		conn.Close()
+		delete(tcp.conns, to)
+		return fmt.Errorf("cannot Send: %w", err)
+	}
+
+	return nil
+}
+
+// Receive blocks until a Message is received from any node.
+func (tcp *TCPTransport) Receive() (*Message, error) {
+	select {
+	case msg := <-tcp.inbox:
+		return msg, nil
+	case <-tcp.closed:
+		return nil, ErrClosed
+	}
+}
+
+// Close closes the listener and all the connections of the TCPTransport.
+func (tcp *TCPTransport) Close() (err error) {
+	tcp.once.Do(func() {
+		close(tcp.closed)
+		err = tcp.listener.Close()
+		tcp.mu.Lock()
+		for id, conn := range tcp.conns {
+			conn.Close()
+			delete(tcp.conns, id)
+		}
+		for conn := range tcp.accepted {
+			conn.Close()
+		}
+		tcp.mu.Unlock()
+		tcp.wg.Wait()
+	})
+	return
+}
+
+func (tcp *TCPTransport) accept() {
+
+	defer tcp.wg.Done()
+
+	for {
+		conn, err := tcp.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		tcp.mu.Lock()
+		select {
+		case <-tcp.closed:
+			tcp.mu.Unlock()
+			conn.Close()
+			return
+		default:
+			tcp.accepted[conn] = true
+		}
+		tcp.mu.Unlock()
+
+		tcp.wg.Add(1)
+		go tcp.read(conn)
+	}
+}
+
+func (tcp *TCPTransport) read(conn net.Conn) {
+
+	defer func() {
+		conn.Close()
+		tcp.mu.Lock()
+		delete(tcp.accepted, conn)
+		tcp.mu.Unlock()
+		tcp.wg.Done()
+	}()
+
+	var from string
+
+	r := bufio.NewReader(conn)
+	for i := 0; ; i++ {
+		msg, err := readFrame(r, MaxFrameSize)
+		if err != nil {
+			return
+		}
+
+		// the connection is bound to the sender of its first message
+		if i == 0 {
+			from = msg.From
+		} else if msg.From != from {
+			return
+		}
+
+		select {
+		case tcp.inbox <- msg:
+		case <-tcp.closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrClosed is returned when sending or receiving on a closed Transport.
+var ErrClosed = errors.New("transport is closed")
+
+// Transport is an interface for the point-to-point channels between the nodes of a multiparty protocol.
+// Implementations must be safe for concurrent use by a sending and a receiving goroutine.
+//
+// The Runner identifies the sender of a share by Message.From, which implementations must bind to the channel the
+// message was received on. Implementations over a network must moreover authenticate the nodes (for example with
+// mutually authenticated TLS), otherwise a node can impersonate another one.
+type Transport interface {
+	// ID returns the identifier of the local node.
+	ID() string
+	// Send sends msg to the node with identifier to.
+	Send(to string, msg *Message) error
+	// Receive blocks until a Message is received from any node.
+	Receive() (*Message, error)
+	// Close closes the Transport and unblocks pending calls to Receive.
+	Close() error
+}
+
+// LocalTransport is an in-memory Transport between nodes running in the same process.
+type LocalTransport struct {
+	id      string
+	network map[string]*LocalTransport
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  []*Message
+	closed bool
+}
+
+// NewLocalNetwork creates a fully connected in-memory network between the nodes with the given identifiers
+// and returns the Transport of each node, indexed by identifier.
+func NewLocalNetwork(ids []string) map[string]*LocalTransport {
+	network := make(map[string]*LocalTransport, len(ids))
+	for _, id := range ids {
+		if _, exists := network[id]; exists {
+			panic(fmt.Sprintf("cannot NewLocalNetwork: duplicate node ID %q", id))
+		}
+		lt := &LocalTransport{id: id, network: network}
+		lt.cond = sync.NewCond(&lt.mu)
+		network[id] = lt
+	}
+	return network
+}
+
+// ID returns the identifier of the local node.
+func (lt *LocalTransport) ID() string {
+	return lt.id
+}
+
+// Send enqueues msg in the inbox of the node with identifier to.
+// It returns an error if msg.From is not the identifier of the local node.
+func (lt *LocalTransport) Send(to string, msg *Message) error {
+
+	if msg.From != lt.id {
+		return fmt.Errorf("cannot Send: sender %q is not the local node %q", msg.From, lt.id)
+	}
+
+	lt.mu.Lock()
+	closed := lt.closed
+	lt.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+
+	dst, ok := lt.network[to]
+	if !ok {
+		return fmt.Errorf("cannot Send: unknown node %q", to)
+	}
+
+	dst.mu.Lock()
+	defer dst.mu.Unlock()
+	if dst.closed {
+		return fmt.Errorf("cannot Send: node %q: %w", to, ErrClosed)
+	}
+	dst.queue = append(dst.queue, msg)
+	dst.cond.Signal()
+
+	return nil
+}
+
+// Receive blocks until a Message is in the inbox of the local node.
+func (lt *LocalTransport) Receive() (msg *Message, err error) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	for len(lt.queue) == 0 && !lt.closed {
+		lt.cond.Wait()
+	}
+	if len(lt.queue) == 0 {
+		return nil, ErrClosed
+	}
+	msg, lt.queue = lt.queue[0], lt.queue[1:]
+	return msg, nil
+}
+
+// Close closes the inbox of the local node.
+func (lt *LocalTransport) Close() error {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.closed = true
+	lt.cond.Broadcast()
+	return nil
+}
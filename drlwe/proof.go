@@ -0,0 +1,625 @@
+package drlwe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
+	"github.com/tuneinsight/lattigo/v3/utils"
+	"golang.org/x/crypto/blake2b"
+)
+
+// CommitmentCRP is a type for common reference polynomials of the secret key commitments.
+type CommitmentCRP ringqp.Poly
+
+// Commitment is the public commitment of a party to its secret key share s, of the form -a*s + e where a
+// is a CommitmentCRP. The proofs of well-formedness of the shares of a party are bound to its Commitment,
+// which ensures that all the shares of the party are generated with the same secret key.
+type Commitment struct {
+	Value ringqp.Poly
+}
+
+// MarshalBinary encodes the target element on a slice of bytes.
+func (com *Commitment) MarshalBinary() (data []byte, err error) {
+	data = make([]byte, com.Value.GetDataLen64(true))
+	if _, err = com.Value.WriteTo64(data); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+func (com *Commitment) UnmarshalBinary(data []byte) (err error) {
+
+	var ptr int
+	if ptr, err = decodePoly(&com.Value, data); err != nil {
+		return
+	}
+
+	if ptr != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
+	return
+}
+
+// CommitmentProtocol is the structure storing the parameters and the samplers to generate the secret key commitments.
+type CommitmentProtocol struct {
+	params           rlwe.Parameters
+	gaussianSamplerQ *ring.GaussianSampler
+}
+
+// NewCommitmentProtocol creates a new CommitmentProtocol instance.
+func NewCommitmentProtocol(params rlwe.Parameters) *CommitmentProtocol {
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	return &CommitmentProtocol{params, ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma()))}
+}
+
+// ShallowCopy creates a shallow copy of CommitmentProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// CommitmentProtocol can be used concurrently.
+func (cp *CommitmentProtocol) ShallowCopy() *CommitmentProtocol {
+	return NewCommitmentProtocol(cp.params)
+}
+
+// AllocateCommitment allocates a Commitment.
+func (cp *CommitmentProtocol) AllocateCommitment() *Commitment {
+	return &Commitment{cp.params.RingQP().NewPoly()}
+}
+
+// SampleCRP samples a common random polynomial to be used for the commitments from the provided
+// common reference string.
+func (cp *CommitmentProtocol) SampleCRP(crs CRS) CommitmentCRP {
+	crp := cp.params.RingQP().NewPoly()
+	ringqp.NewUniformSampler(crs, *cp.params.RingQP()).Read(crp)
+	return CommitmentCRP(crp)
+}
+
+// GenCommitment generates the commitment -crp*s + e of the party to its secret key s.
+func (cp *CommitmentProtocol) GenCommitment(sk *rlwe.SecretKey, crp CommitmentCRP, comOut *Commitment) {
+
+	ringQP := cp.params.RingQP()
+	levelQ, levelP := cp.params.QCount()-1, cp.params.PCount()-1
+
+	cp.gaussianSamplerQ.Read(comOut.Value.Q)
+
+	if ringQP.RingP != nil {
+		ringQP.ExtendBasisSmallNormAndCenter(comOut.Value.Q, levelP, nil, comOut.Value.P)
+	}
+
+	ringQP.NTTLvl(levelQ, levelP, comOut.Value, comOut.Value)
+	ringQP.MulCoeffsMontgomeryAndSubLvl(levelQ, levelP, sk.Value, ringqp.Poly(crp), comOut.Value)
+}
+
+// Proof is a non-interactive zero-knowledge proof of knowledge of short secrets and short errors satisfying
+// a set of linear relations over the ring, for example the relation between a share, its common reference
+// polynomial and the commitment of the party. It follows the Fiat-Shamir with aborts paradigm: Challenge is
+// the seed of the sparse challenge polynomial c and Responses stores the masked witnesses y + c*x.
+// As for all such proofs, the soundness holds with a slack: the extracted witnesses are short but
+// larger than the ones of an honest party.
+type Proof struct {
+	Challenge []byte
+	Responses [][]int64
+}
+
+// MarshalBinary encodes the target Proof on a slice of bytes.
+func (p *Proof) MarshalBinary() (data []byte, err error) {
+
+	if len(p.Challenge) != blake2b.Size {
+		return nil, errors.New("cannot MarshalBinary: invalid challenge size")
+	}
+
+	var N int
+	if len(p.Responses) > 0 {
+		N = len(p.Responses[0])
+	}
+
+	data = make([]byte, 8+blake2b.Size, 8+blake2b.Size+len(p.Responses)*N*2)
+	binary.LittleEndian.PutUint32(data, uint32(len(p.Responses)))
+	binary.LittleEndian.PutUint32(data[4:], uint32(N))
+	copy(data[8:], p.Challenge)
+
+	var buf [binary.MaxVarintLen64]byte
+	for _, z := range p.Responses {
+		if len(z) != N {
+			return nil, errors.New("cannot MarshalBinary: responses have different sizes")
+		}
+		for _, c := range z {
+			data = append(data, buf[:binary.PutVarint(buf[:], c)]...)
+		}
+	}
+
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target Proof.
+func (p *Proof) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 8+blake2b.Size {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	count := int(binary.LittleEndian.Uint32(data))
+	N := int(binary.LittleEndian.Uint32(data[4:]))
+
+	// each coefficient is encoded on at least one byte, and a proof has no empty response
+	if (count > 0 && N == 0) || uint64(count)*uint64(N) > uint64(len(data)-8-blake2b.Size) {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	p.Challenge = make([]byte, blake2b.Size)
+	copy(p.Challenge, data[8:])
+
+	ptr := 8 + blake2b.Size
+	p.Responses = make([][]int64, count)
+	for i := range p.Responses {
+		p.Responses[i] = make([]int64, N)
+		for j := range p.Responses[i] {
+			c, n := binary.Varint(data[ptr:])
+			if n <= 0 {
+				return errors.New("cannot UnmarshalBinary: invalid coefficient encoding")
+			}
+			p.Responses[i][j] = c
+			ptr += n
+		}
+	}
+
+	if ptr != len(data) {
+		return errors.New("cannot UnmarshalBinary: trailing data")
+	}
+
+	return nil
+}
+
+// relation is the relation value = sum_j coeff_j * x_j + e over the NTT domain at levels (levelQ, levelP), where the x_j
+// are the secrets of the statement and e is an error with infinity norm at most bound. The polynomials are in the NTT
+// domain and not in the Montgomery domain. A negative levelP indicates that the relation holds only modulo Q.
+type relation struct {
+	levelQ, levelP int
+	value          ringqp.Poly
+	terms          []relationTerm
+	bound          uint64
+}
+
+type relationTerm struct {
+	secret int
+	coeff  ringqp.Poly
+}
+
+// statement is a set of relations between public polynomials and short secrets.
+type statement struct {
+	params    rlwe.Parameters
+	label     string
+	secrets   []uint64 // bounds on the infinity norm of the secrets
+	relations []relation
+}
+
+func newStatement(params rlwe.Parameters, label string, secrets ...uint64) *statement {
+	return &statement{params: params, label: label, secrets: secrets}
+}
+
+// add adds the relation value = sum_j coeffs[j] * x_{secrets[j]} + e to the statement. The value is reduced
+// and copied, the coefficients are not copied.
+func (st *statement) add(levelQ, levelP int, bound uint64, value ringqp.Poly, secrets []int, coeffs ...ringqp.Poly) {
+
+	rel := relation{levelQ: levelQ, levelP: levelP, bound: bound}
+
+	rel.value = st.params.RingQP().NewPoly()
+	for _, c := range st.components(levelQ, levelP) {
+		c.ring.ReduceLvl(c.level, c.part(value), c.part(rel.value))
+	}
+
+	for i := range secrets {
+		rel.terms = append(rel.terms, relationTerm{secrets[i], coeffs[i]})
+	}
+
+	st.relations = append(st.relations, rel)
+}
+
+// addCommitment adds the relation com = -crp*x_secret + e to the statement.
+func (st *statement) addCommitment(secret int, crp CommitmentCRP, com *Commitment) {
+	ringQP := st.params.RingQP()
+	levelQ, levelP := st.params.QCount()-1, st.params.PCount()-1
+	negCRP := ringQP.NewPoly()
+	ringQP.NegLvl(levelQ, levelP, ringqp.Poly(crp), negCRP)
+	st.add(levelQ, levelP, st.errorBound(), com.Value, []int{secret}, negCRP)
+}
+
+// errorBound returns the bound on the errors sampled with the parameters' Gaussian distribution.
+func (st *statement) errorBound() uint64 {
+	return uint64(6 * st.params.Sigma())
+}
+
+type component struct {
+	ring  *ring.Ring
+	level int
+	part  func(p ringqp.Poly) *ring.Poly
+}
+
+// components returns the rings and levels on which a relation at levels (levelQ, levelP) is defined.
+func (st *statement) components(levelQ, levelP int) (c []component) {
+	c = append(c, component{st.params.RingQ(), levelQ, func(p ringqp.Poly) *ring.Poly { return p.Q }})
+	if st.params.RingP() != nil && levelP >= 0 {
+		c = append(c, component{st.params.RingP(), levelP, func(p ringqp.Poly) *ring.Poly { return p.P }})
+	}
+	return
+}
+
+// challengeWeight returns the number of non-zero coefficients of the challenge polynomials, so that the
+// challenge space has at least 2^128 elements.
+func (st *statement) challengeWeight() int {
+	N := float64(st.params.N())
+	for k := 1; ; k++ {
+		lg, _ := math.Lgamma(N + 1)
+		lk, _ := math.Lgamma(float64(k) + 1)
+		lnk, _ := math.Lgamma(N - float64(k) + 1)
+		if (lg-lk-lnk)/math.Ln2+float64(k) >= 128 {
+			return k
+		}
+	}
+}
+
+// bounds returns the bounds on the infinity norm of the masks and of the responses of each witness:
+// the secrets followed by the errors of each relation.
+func (st *statement) bounds() (masks, responses []uint64) {
+
+	kappa := uint64(st.challengeWeight())
+	n := len(st.secrets) + len(st.relations)
+	D := uint64(n * st.params.N())
+
+	masks = make([]uint64, n)
+	responses = make([]uint64, n)
+	for i := range masks {
+		var bound uint64
+		if i < len(st.secrets) {
+			bound = st.secrets[i]
+		} else {
+			bound = st.relations[i-len(st.secrets)].bound
+		}
+		// the masks are large enough for the responses to be accepted with probability about 1/e
+		masks[i] = kappa * bound * D
+		responses[i] = masks[i] - kappa*bound
+	}
+	return
+}
+
+// prove generates a Proof of the statement from its secrets given in the coefficient domain.
+// The errors are recomputed from the relations. It returns an error if the secrets or the errors exceed their bounds,
+// in which case the statement cannot be proven.
+func (st *statement) prove(secrets [][]int64) (proof *Proof, err error) {
+
+	params := st.params
+	ringQP := params.RingQP()
+	N := params.N()
+
+	// computes the errors e = value - sum coeff * x modulo each prime, checks that they are the same small
+	// integers modulo all the primes and checks their norm
+	secretsNTT := make([]ringqp.Poly, len(secrets))
+	for i := range secrets {
+		secretsNTT[i] = st.liftNTTMForm(secrets[i])
+	}
+
+	witnesses := append([][]int64{}, secrets...)
+	for k, rel := range st.relations {
+
+		tmp := ringQP.NewPoly()
+
+		var e []int64
+		for _, comp := range st.components(rel.levelQ, rel.levelP) {
+
+			r, level, pol := comp.ring, comp.level, comp.part(tmp)
+
+			ring.CopyLvl(level, comp.part(rel.value), pol)
+			for _, term := range rel.terms {
+				r.MulCoeffsMontgomeryAndSubLvl(level, comp.part(secretsNTT[term.secret]), comp.part(term.coeff), pol)
+			}
+			r.InvNTTLvl(level, pol, pol)
+
+			for i, qi := range r.Modulus[:level+1] {
+				ei := centered(pol.Coeffs[i], qi)
+				if e == nil {
+					e = ei
+				} else if !equalInt64(e, ei) {
+					return nil, fmt.Errorf("cannot prove: relation %d does not hold modulo the prime %d: the witness is invalid", k, qi)
+				}
+			}
+		}
+
+		if norm := normInf(e); norm > rel.bound {
+			return nil, fmt.Errorf("cannot prove: the error of relation %d has norm %d, which exceeds its bound %d: the witness is invalid", k, norm, rel.bound)
+		}
+		witnesses = append(witnesses, e)
+	}
+
+	for i := range secrets {
+		if normInf(secrets[i]) > st.secrets[i] {
+			return nil, fmt.Errorf("cannot prove: a secret exceeds its bound %d", st.secrets[i])
+		}
+	}
+
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		return nil, err
+	}
+
+	masks, responses := st.bounds()
+
+	y := make([][]int64, len(witnesses))
+	for i := range y {
+		y[i] = make([]int64, N)
+	}
+
+	for {
+
+		for i := range y {
+			sampleUniform(prng, masks[i], y[i])
+		}
+
+		seed := st.commit(y, nil)
+		c := st.challenge(seed)
+
+		z := make([][]int64, len(witnesses))
+		accepted := true
+		for i := range z {
+			z[i] = mulSparse(c, witnesses[i])
+			for j := range z[i] {
+				z[i][j] += y[i][j]
+			}
+			if normInf(z[i]) > responses[i] {
+				accepted = false
+				break
+			}
+		}
+
+		if accepted {
+			return &Proof{Challenge: seed, Responses: z}, nil
+		}
+	}
+}
+
+// verify checks a Proof of the statement.
+func (st *statement) verify(proof *Proof) bool {
+
+	if proof == nil || len(proof.Challenge) != blake2b.Size || len(proof.Responses) != len(st.secrets)+len(st.relations) {
+		return false
+	}
+
+	_, responses := st.bounds()
+	for i, z := range proof.Responses {
+		if len(z) != st.params.N() || normInf(z) > responses[i] {
+			return false
+		}
+	}
+
+	seed := st.commit(proof.Responses, st.challenge(proof.Challenge))
+
+	return bytes.Equal(seed, proof.Challenge)
+}
+
+// commit computes the commitments w = sum coeff * z_x + z_e - c * value of the relations, where c is nil for the
+// prover, and returns the hash of the statement and of the commitments.
+func (st *statement) commit(z [][]int64, c []int64) []byte {
+
+	ringQP := st.params.RingQP()
+	levelQ, levelP := st.params.QCount()-1, st.params.PCount()-1
+
+	secretsNTT := make([]ringqp.Poly, len(st.secrets))
+	for i := range secretsNTT {
+		secretsNTT[i] = st.liftNTTMForm(z[i])
+	}
+
+	var cNTT ringqp.Poly
+	if c != nil {
+		cNTT = st.liftNTTMForm(c)
+	}
+
+	h, err := blake2b.New512(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	h.Write([]byte(st.label))
+	writeUint64(h, uint64(st.params.N()))
+	for _, qi := range st.params.Q() {
+		writeUint64(h, qi)
+	}
+	for _, pi := range st.params.P() {
+		writeUint64(h, pi)
+	}
+
+	w := ringQP.NewPoly()
+	for k, rel := range st.relations {
+
+		writeUint64(h, uint64(rel.levelQ))
+		writeUint64(h, uint64(rel.levelP+1))
+		writeUint64(h, rel.bound)
+
+		st.lift(z[len(st.secrets)+k], levelQ, levelP, w)
+
+		for _, comp := range st.components(rel.levelQ, rel.levelP) {
+
+			r, level := comp.ring, comp.level
+
+			writePoly(h, level, comp.part(rel.value))
+			for _, term := range rel.terms {
+				writeUint64(h, uint64(term.secret))
+				writePoly(h, level, comp.part(term.coeff))
+			}
+
+			r.NTTLvl(level, comp.part(w), comp.part(w))
+			for _, term := range rel.terms {
+				r.MulCoeffsMontgomeryAndAddLvl(level, comp.part(secretsNTT[term.secret]), comp.part(term.coeff), comp.part(w))
+			}
+			if c != nil {
+				r.MulCoeffsMontgomeryAndSubLvl(level, comp.part(cNTT), comp.part(rel.value), comp.part(w))
+			}
+
+			writePoly(h, level, comp.part(w))
+		}
+	}
+
+	return h.Sum(nil)
+}
+
+// challenge derives the challenge polynomial, with challengeWeight coefficients in {-1, 1}, from the seed.
+func (st *statement) challenge(seed []byte) (c []int64) {
+
+	prng, err := utils.NewKeyedPRNG(seed)
+	if err != nil {
+		panic(err)
+	}
+
+	N := st.params.N()
+	c = make([]int64, N)
+
+	var buf [4]byte
+	for weight := st.challengeWeight(); weight > 0; {
+		if _, err = prng.Read(buf[:]); err != nil {
+			panic(err)
+		}
+		v := binary.LittleEndian.Uint32(buf[:])
+		// N is a power of two
+		if i := int(v>>1) & (N - 1); c[i] == 0 {
+			c[i] = 1 - 2*int64(v&1)
+			weight--
+		}
+	}
+
+	return
+}
+
+// lift writes the polynomial with small integer coefficients on p, at levels (levelQ, levelP).
+func (st *statement) lift(coeffs []int64, levelQ, levelP int, p ringqp.Poly) {
+	for _, comp := range st.components(levelQ, levelP) {
+		pol := comp.part(p)
+		for i, qi := range comp.ring.Modulus[:comp.level+1] {
+			q := int64(qi)
+			tmp := pol.Coeffs[i]
+			for j, c := range coeffs {
+				if c %= q; c < 0 {
+					c += q
+				}
+				tmp[j] = uint64(c)
+			}
+		}
+	}
+}
+
+// liftNTTMForm lifts the polynomial with small integer coefficients in the NTT and Montgomery domain at the maximum level.
+func (st *statement) liftNTTMForm(coeffs []int64) (p ringqp.Poly) {
+	levelQ, levelP := st.params.QCount()-1, st.params.PCount()-1
+	p = st.params.RingQP().NewPoly()
+	st.lift(coeffs, levelQ, levelP, p)
+	for _, comp := range st.components(levelQ, levelP) {
+		comp.ring.NTTLvl(comp.level, comp.part(p), comp.part(p))
+		comp.ring.MFormLvl(comp.level, comp.part(p), comp.part(p))
+	}
+	return
+}
+
+// secretCoeffs returns the coefficients of a secret key, centered modulo the first prime.
+func secretCoeffs(params rlwe.Parameters, sk *rlwe.SecretKey) []int64 {
+	ringQ := params.RingQ()
+	tmp := ringQ.NewPolyLvl(0)
+	ringQ.InvMFormLvl(0, sk.Value.Q, tmp)
+	ringQ.InvNTTLvl(0, tmp, tmp)
+	return centered(tmp.Coeffs[0], ringQ.Modulus[0])
+}
+
+func centered(coeffs []uint64, q uint64) (c []int64) {
+	c = make([]int64, len(coeffs))
+	for i, v := range coeffs {
+		if v >= q>>1 {
+			c[i] = -int64(q - v)
+		} else {
+			c[i] = int64(v)
+		}
+	}
+	return
+}
+
+func equalInt64(a, b []int64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func normInf(coeffs []int64) (norm uint64) {
+	for _, c := range coeffs {
+		if c < 0 {
+			c = -c
+		}
+		if uint64(c) > norm {
+			norm = uint64(c)
+		}
+	}
+	return
+}
+
+// mulSparse returns the product of the sparse polynomial c by x in Z[X]/(X^N+1).
+func mulSparse(c, x []int64) (out []int64) {
+	N := len(x)
+	out = make([]int64, N)
+	for k, ck := range c {
+		if ck == 0 {
+			continue
+		}
+		for i, xi := range x {
+			if j := i + k; j < N {
+				out[j] += ck * xi
+			} else {
+				out[j-N] -= ck * xi
+			}
+		}
+	}
+	return
+}
+
+// sampleUniform samples the coefficients of out uniformly in [-bound, bound].
+func sampleUniform(prng utils.PRNG, bound uint64, out []int64) {
+
+	size := 2*bound + 1
+	mask := ^uint64(0) >> uint64(bits.LeadingZeros64(size))
+
+	var buf [8]byte
+	for i := range out {
+		for {
+			if _, err := prng.Read(buf[:]); err != nil {
+				panic(err)
+			}
+			if v := binary.LittleEndian.Uint64(buf[:]) & mask; v < size {
+				out[i] = int64(v) - int64(bound)
+				break
+			}
+		}
+	}
+}
+
+func writeUint64(w io.Writer, v uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	w.Write(buf[:])
+}
+
+func writePoly(w io.Writer, level int, p *ring.Poly) {
+	buf := make([]byte, 8*len(p.Coeffs[0]))
+	for _, coeffs := range p.Coeffs[:level+1] {
+		for j, c := range coeffs {
+			binary.LittleEndian.PutUint64(buf[8*j:], c)
+		}
+		w.Write(buf)
+	}
+}
@@ -0,0 +1,90 @@
+package drlwe
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// DefaultSmudgingLambda is the default statistical security parameter of the smudging noise.
+const DefaultSmudgingLambda = 40
+
+// SmudgingParameters are the inputs of the calibration of the smudging noise added by the parties
+// in the CKS, PCKS, refresh and masked transform protocols.
+type SmudgingParameters struct {
+	// LogNoise is the base-2 logarithm of a bound on the infinity norm of the noise of the input ciphertexts.
+	LogNoise float64
+	// Parties is the number of parties adding smudging noise.
+	Parties int
+	// Lambda is the statistical security parameter. DefaultSmudgingLambda is used if it is zero.
+	Lambda int
+	// LogBudget is the base-2 logarithm of the largest infinity norm of the aggregated smudging noise
+	// that can be tolerated in the output ciphertexts. It is not checked if it is zero.
+	LogBudget float64
+}
+
+// Smudging is a calibrated smudging noise distribution.
+type Smudging struct {
+	SmudgingParameters
+	// Sigma is the standard deviation of the noise sampled by each party.
+	Sigma float64
+	// LogBound is the base-2 logarithm of a bound on the infinity norm of the aggregated smudging noise
+	// that holds with probability at least 1-2^-Lambda.
+	LogBound float64
+}
+
+// NewSmudging computes the smudging noise distribution for which the noise of a single party hides the noise of
+// the input ciphertexts up to a statistical distance 2^-Lambda. By Pinsker's inequality, the distance between the
+// shares of two ciphertexts whose noise differ by at most 2^LogNoise is at most sqrt(N) * 2^LogNoise / (2 * Sigma).
+// It returns an error if the aggregated smudging noise exceeds the budget or if Sigma is too large to be sampled
+// in the ring Q of params.
+func NewSmudging(params rlwe.Parameters, sp SmudgingParameters) (s Smudging, err error) {
+
+	if sp.Parties < 1 {
+		return s, errors.New("cannot NewSmudging: Parties must be at least 1")
+	}
+
+	if sp.Lambda == 0 {
+		sp.Lambda = DefaultSmudgingLambda
+	}
+
+	if sp.Lambda < 0 {
+		return s, errors.New("cannot NewSmudging: Lambda cannot be negative")
+	}
+
+	sigma := math.Exp2(float64(sp.Lambda)-1+sp.LogNoise) * math.Sqrt(float64(params.N()))
+	if sigma < params.Sigma() {
+		sigma = params.Sigma()
+	}
+
+	qMin := params.Q()[0]
+	for _, qi := range params.Q() {
+		if qi < qMin {
+			qMin = qi
+		}
+	}
+
+	if math.Log2(6*sigma) >= math.Min(60, math.Log2(float64(qMin))-1) {
+		return s, fmt.Errorf("cannot NewSmudging: log2(6*Sigma)=%.2f does not fit the smallest modulus of Q", math.Log2(6*sigma))
+	}
+
+	// The tail bound of each coefficient of the aggregated noise is taken with a union bound over the N coefficients.
+	// The noise of each party is also truncated at 6*Sigma by the Gaussian sampler.
+	parties := float64(sp.Parties)
+	tail := math.Sqrt(2 * math.Ln2 * (float64(sp.Lambda) + 1 + float64(params.LogN())))
+	logBound := math.Log2(math.Min(tail*sigma*math.Sqrt(parties), 6*sigma*parties))
+
+	if sp.LogBudget != 0 && logBound > sp.LogBudget {
+		return s, fmt.Errorf("cannot NewSmudging: log2 of the smudging noise %.2f exceeds the budget %.2f", logBound, sp.LogBudget)
+	}
+
+	return Smudging{SmudgingParameters: sp, Sigma: sigma, LogBound: logBound}, nil
+}
+
+// LogNoiseAfter returns the base-2 logarithm of a bound on the infinity norm of the noise of the output ciphertexts,
+// i.e. the sum of the noise of the input ciphertexts and the aggregated smudging noise.
+func (s Smudging) LogNoiseAfter() float64 {
+	return math.Log2(math.Exp2(s.LogNoise) + math.Exp2(s.LogBound))
+}
@@ -0,0 +1,291 @@
+package drlwe
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// ShamirPublicPoint is a type for Shamir public point associated with a party identity within
+// the t-out-of-N-threshold scheme. It must be non-zero and distinct for each party.
+type ShamirPublicPoint uint64
+
+// ShamirPolynomial represents a polynomial with ringqp.Poly coefficients. It is used by the
+// Thresholdizer to generate t-out-of-N threshold secret-shares of a secret key, where the
+// secret is the constant coefficient of the polynomial.
+type ShamirPolynomial struct {
+	Coeffs []ringqp.Poly
+}
+
+// ShamirSecretShare represents a t-out-of-N-threshold secret-share of an rlwe.SecretKey.
+type ShamirSecretShare struct {
+	ringqp.Poly
+}
+
+// ThresholdizerProtocol is an interface describing the local steps of a generic threshold setup protocol.
+type ThresholdizerProtocol interface {
+	GenShamirPolynomial(threshold int, secret *rlwe.SecretKey) (*ShamirPolynomial, error)
+	AllocateThresholdSecretShare() *ShamirSecretShare
+	GenShamirSecretShare(recipient ShamirPublicPoint, secretPoly *ShamirPolynomial, shareOut *ShamirSecretShare)
+	AggregateShares(share1, share2, outShare *ShamirSecretShare)
+}
+
+// CombinerProtocol is an interface describing the local steps of a generic threshold combining protocol.
+type CombinerProtocol interface {
+	GenAdditiveShare(actives []ShamirPublicPoint, ownPoint ShamirPublicPoint, ownShare *ShamirSecretShare, skOut *rlwe.SecretKey)
+}
+
+// Thresholdizer is the structure storing the parameters for the threshold setup protocol.
+// In this protocol, each party re-shares its rlwe.SecretKey among the N parties with a
+// random polynomial of degree t-1. Aggregating the N received shares yields a t-out-of-N
+// Shamir secret-share of the ideal (collective) secret key.
+type Thresholdizer struct {
+	params   rlwe.Parameters
+	samplerQ ringqp.UniformSampler
+}
+
+// NewThresholdizer creates a new Thresholdizer instance from the given parameters.
+func NewThresholdizer(params rlwe.Parameters) *Thresholdizer {
+	thr := new(Thresholdizer)
+	thr.params = params
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	thr.samplerQ = ringqp.NewUniformSampler(prng, *params.RingQP())
+	return thr
+}
+
+// ShallowCopy creates a shallow copy of Thresholdizer in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Thresholdizer can be used concurrently.
+func (thr *Thresholdizer) ShallowCopy() *Thresholdizer {
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	return &Thresholdizer{params: thr.params, samplerQ: thr.samplerQ.WithPRNG(prng)}
+}
+
+// SetPRNG sets prng as the source of randomness of the coefficients of the Shamir polynomials, so that the
+// polynomials generated by the receiver are reproducible from the state of prng.
+func (thr *Thresholdizer) SetPRNG(prng utils.PRNG) {
+	prngs := derivePRNGs(prng, "drlwe/thresholdizer/uniform")
+	thr.samplerQ = thr.samplerQ.WithPRNG(prngs[0])
+}
+
+// GenShamirPolynomial generates a new secret ShamirPolynomial of degree threshold-1 whose constant
+// coefficient is the given secret key. The remaining coefficients are sampled uniformly at random.
+func (thr *Thresholdizer) GenShamirPolynomial(threshold int, secret *rlwe.SecretKey) (*ShamirPolynomial, error) {
+	if threshold < 1 {
+		return nil, fmt.Errorf("threshold should be >= 1, got %d", threshold)
+	}
+	gen := &ShamirPolynomial{Coeffs: make([]ringqp.Poly, threshold)}
+	gen.Coeffs[0] = secret.Value.CopyNew()
+	for i := 1; i < threshold; i++ {
+		gen.Coeffs[i] = thr.params.RingQP().NewPoly()
+		thr.samplerQ.Read(gen.Coeffs[i])
+	}
+	return gen, nil
+}
+
+// AllocateThresholdSecretShare allocates a ShamirSecretShare struct.
+func (thr *Thresholdizer) AllocateThresholdSecretShare() *ShamirSecretShare {
+	return &ShamirSecretShare{thr.params.RingQP().NewPoly()}
+}
+
+// GenShamirSecretShare generates the secret share for the given recipient, identified by its ShamirPublicPoint.
+// The result is stored in shareOut and should be sent privately to the recipient. The method panics if
+// recipient is zero, as the share would be the secret itself.
+func (thr *Thresholdizer) GenShamirSecretShare(recipient ShamirPublicPoint, secretPoly *ShamirPolynomial, shareOut *ShamirSecretShare) {
+
+	if recipient == 0 {
+		panic("cannot GenShamirSecretShare: ShamirPublicPoint must be non-zero")
+	}
+
+	ringQP := thr.params.RingQP()
+	levelQ, levelP := thr.params.QCount()-1, thr.params.PCount()-1
+
+	// Horner evaluation of the polynomial at the recipient's public point
+	ringQP.CopyValuesLvl(levelQ, levelP, secretPoly.Coeffs[len(secretPoly.Coeffs)-1], shareOut.Poly)
+	for i := len(secretPoly.Coeffs) - 2; i >= 0; i-- {
+		ringQP.MulScalarLvl(levelQ, levelP, shareOut.Poly, uint64(recipient), shareOut.Poly)
+		ringQP.AddLvl(levelQ, levelP, shareOut.Poly, secretPoly.Coeffs[i], shareOut.Poly)
+	}
+}
+
+// AggregateShares aggregates two ShamirSecretShare and stores the result in outShare.
+func (thr *Thresholdizer) AggregateShares(share1, share2, outShare *ShamirSecretShare) {
+	thr.params.RingQP().AddLvl(thr.params.QCount()-1, thr.params.PCount()-1, share1.Poly, share2.Poly, outShare.Poly)
+}
+
+// Combiner is a structure that holds the parameters for the combining phase of
+// a threshold secret sharing protocol. Given t active parties, it computes the party's
+// t-out-of-t additive share of the ideal secret key from its t-out-of-N Shamir share,
+// by applying the party's Lagrange coefficient. The resulting rlwe.SecretKey can be used
+// as-is in the CKS, PCKS and Refresh protocols of the drlwe, dbfv and dckks packages.
+type Combiner struct {
+	params    rlwe.Parameters
+	threshold int
+	own       ShamirPublicPoint
+
+	// inverses of (x_j - x_own) mod each modulus, for each other party j
+	inv map[ShamirPublicPoint][]uint64
+}
+
+// NewCombiner creates a new Combiner for the party with public point own. The others slice
+// lists the public points of all the other parties in the N-party setting.
+func NewCombiner(params rlwe.Parameters, own ShamirPublicPoint, others []ShamirPublicPoint, threshold int) *Combiner {
+
+	if own == 0 {
+		panic("cannot create Combiner: ShamirPublicPoint must be non-zero")
+	}
+
+	cmb := new(Combiner)
+	cmb.params = params
+	cmb.threshold = threshold
+	cmb.own = own
+	cmb.inv = make(map[ShamirPublicPoint][]uint64, len(others))
+
+	moduli := params.QP()
+
+	for _, x := range others {
+		if x == own {
+			continue
+		}
+		if x == 0 {
+			panic("cannot create Combiner: ShamirPublicPoint must be non-zero")
+		}
+		inv := make([]uint64, len(moduli))
+		for i, qi := range moduli {
+			// (x_j - x_own)^-1 mod qi
+			d := (uint64(x)%qi + qi - uint64(own)%qi) % qi
+			inv[i] = ring.ModExp(d, qi-2, qi)
+		}
+		cmb.inv[x] = inv
+	}
+
+	return cmb
+}
+
+// ShallowCopy creates a shallow copy of Combiner in which all the read-only data-structures are
+// shared with the receiver. The receiver and the returned Combiner can be used concurrently.
+func (cmb *Combiner) ShallowCopy() *Combiner {
+	return &Combiner{params: cmb.params, threshold: cmb.threshold, own: cmb.own, inv: cmb.inv}
+}
+
+// GenAdditiveShare generates a t-out-of-t additive share of the ideal secret key from the party's
+// t-out-of-N Shamir secret share. The actives slice must contain exactly threshold distinct public
+// points (including ownPoint), identifying the parties that take part in the subsequent protocol.
+func (cmb *Combiner) GenAdditiveShare(actives []ShamirPublicPoint, ownPoint ShamirPublicPoint, ownShare *ShamirSecretShare, skOut *rlwe.SecretKey) {
+
+	if len(actives) != cmb.threshold {
+		panic(fmt.Sprintf("cannot GenAdditiveShare: expected %d active parties, got %d", cmb.threshold, len(actives)))
+	}
+
+	if ownPoint != cmb.own {
+		panic("cannot GenAdditiveShare: ownPoint does not match the Combiner's point")
+	}
+
+	lagrange, err := cmb.lagrangeCoefficient(actives)
+	if err != nil {
+		panic(err)
+	}
+
+	ringQ, ringP := cmb.params.RingQ(), cmb.params.RingP()
+
+	for i, qi := range ringQ.Modulus {
+		c := ring.MForm(lagrange[i], qi, ringQ.BredParams[i])
+		ring.MulScalarMontgomeryVec(ownShare.Q.Coeffs[i], skOut.Value.Q.Coeffs[i], c, qi, ringQ.MredParams[i])
+	}
+
+	if ringP != nil {
+		offset := len(ringQ.Modulus)
+		for i, pi := range ringP.Modulus {
+			c := ring.MForm(lagrange[offset+i], pi, ringP.BredParams[i])
+			ring.MulScalarMontgomeryVec(ownShare.P.Coeffs[i], skOut.Value.P.Coeffs[i], c, pi, ringP.MredParams[i])
+		}
+	}
+}
+
+// lagrangeCoefficient returns the Lagrange coefficient prod_{j != own} x_j / (x_j - x_own)
+// evaluated at zero, modulo each of the moduli Q and P.
+func (cmb *Combiner) lagrangeCoefficient(actives []ShamirPublicPoint) (lagrange []uint64, err error) {
+
+	moduli := cmb.params.QP()
+
+	lagrange = make([]uint64, len(moduli))
+	for i := range lagrange {
+		lagrange[i] = 1
+	}
+
+	var found bool
+	seen := make(map[ShamirPublicPoint]bool, len(actives))
+	for _, x := range actives {
+
+		if seen[x] {
+			return nil, fmt.Errorf("duplicate ShamirPublicPoint %d among the active parties", x)
+		}
+		seen[x] = true
+
+		if x == cmb.own {
+			found = true
+			continue
+		}
+
+		inv, ok := cmb.inv[x]
+		if !ok {
+			return nil, fmt.Errorf("unknown ShamirPublicPoint %d", x)
+		}
+
+		for i, qi := range moduli {
+			bredParams := ring.BRedParams(qi)
+			lagrange[i] = ring.BRed(lagrange[i], uint64(x)%qi, qi, bredParams)
+			lagrange[i] = ring.BRed(lagrange[i], inv[i], qi, bredParams)
+		}
+	}
+
+	if !found {
+		return nil, errors.New("own ShamirPublicPoint is not among the active parties")
+	}
+
+	return
+}
+
+// MarshalBinary encodes the target element on a slice of bytes.
+func (s *ShamirSecretShare) MarshalBinary() (data []byte, err error) {
+	return s.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes the target element on a slice of bytes with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (s *ShamirSecretShare) MarshalBinaryPacked() (data []byte, err error) {
+	return s.marshalBinary(true)
+}
+
+func (s *ShamirSecretShare) marshalBinary(packed bool) (data []byte, err error) {
+	data = make([]byte, ring.GetDataLen(&s.Poly, true, packed))
+	if _, err = ring.WriteTo(&s.Poly, data, packed); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+func (s *ShamirSecretShare) UnmarshalBinary(data []byte) (err error) {
+
+	var ptr int
+	if ptr, err = decodePoly(&s.Poly, data); err != nil {
+		return
+	}
+
+	if ptr != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
+	return
+}
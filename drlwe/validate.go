@@ -0,0 +1,214 @@
+package drlwe
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
+)
+
+// The decoders of the shares only check that their encoding is structurally sound. The shares received from
+// other parties should be checked against the parameters of the protocol with their Validate method, or be
+// decoded with rlwe.UnmarshalValidated, before being aggregated. All the errors wrap rlwe.ErrMalformed.
+
+// malformed returns a new error wrapping rlwe.ErrMalformed with a message formatted from format and args.
+func malformed(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", rlwe.ErrMalformed, fmt.Sprintf(format, args...))
+}
+
+// decodePoly decodes data on p and wraps the decoding errors with rlwe.ErrMalformed.
+func decodePoly(p interface{ DecodePoly64([]byte) (int, error) }, data []byte) (n int, err error) {
+	if n, err = p.DecodePoly64(data); err != nil {
+		err = fmt.Errorf("%w: %s", rlwe.ErrMalformed, err)
+	}
+	return
+}
+
+// decodeDecomposition reads the dimensions of the gadget decomposition at the start of data, of which each
+// element is encoded on at least minSize bytes.
+func decodeDecomposition(data []byte, minSize int) (decompRNS, decompPw2 int, err error) {
+
+	if len(data) < 2 {
+		return 0, 0, malformed("data is too short")
+	}
+
+	decompRNS, decompPw2 = int(data[0]), int(data[1])
+
+	if decompRNS == 0 || decompPw2 == 0 || len(data)-2 < minSize*decompRNS*decompPw2 {
+		return 0, 0, malformed("invalid decomposition %dx%d", decompRNS, decompPw2)
+	}
+
+	return
+}
+
+// validatePoly checks that pol is a well-formed polynomial of the ring Q of params at the given level.
+func validatePoly(params rlwe.Parameters, level int, pol *ring.Poly) error {
+
+	if err := params.RingQ().Validate(pol); err != nil {
+		return fmt.Errorf("%w: %s", rlwe.ErrMalformed, err)
+	}
+
+	if pol.Level() != level {
+		return malformed("polynomial has level %d instead of %d", pol.Level(), level)
+	}
+
+	return nil
+}
+
+// validatePolyQP checks that p is a well-formed polynomial of the ring QP of params at the given levels.
+func validatePolyQP(params rlwe.Parameters, levelQ, levelP int, p ringqp.Poly) error {
+
+	if err := params.RingQP().Validate(p); err != nil {
+		return fmt.Errorf("%w: %s", rlwe.ErrMalformed, err)
+	}
+
+	if p.LevelQ() != levelQ || p.LevelP() != levelP {
+		return malformed("polynomial has levels (%d, %d) instead of (%d, %d)", p.LevelQ(), p.LevelP(), levelQ, levelP)
+	}
+
+	return nil
+}
+
+// validateDecomposition checks that a gadget decomposition of the given dimensions matches the one of params
+// at the given levels.
+func validateDecomposition(params rlwe.Parameters, levelQ, levelP, decompRNS, decompPw2 int) error {
+
+	if levelQ < 0 || levelQ > params.MaxLevel() || levelP > params.PCount()-1 {
+		return malformed("levels (%d, %d) are not supported by the parameters", levelQ, levelP)
+	}
+
+	if decompRNS != params.DecompRNS(levelQ, levelP) || decompPw2 != params.DecompPw2(levelQ, levelP) {
+		return malformed("decomposition %dx%d does not match the decomposition %dx%d of the parameters",
+			decompRNS, decompPw2, params.DecompRNS(levelQ, levelP), params.DecompPw2(levelQ, levelP))
+	}
+
+	return nil
+}
+
+// validateGadgetShare checks that value is a well-formed share of a gadget ciphertext of params.
+func validateGadgetShare(params rlwe.Parameters, value [][][2]ringqp.Poly) (err error) {
+
+	if len(value) == 0 || len(value[0]) == 0 || value[0][0][0].Q == nil {
+		return malformed("share is empty")
+	}
+
+	levelQ, levelP := value[0][0][0].LevelQ(), value[0][0][0].LevelP()
+
+	for i := range value {
+		if len(value[i]) != len(value[0]) {
+			return malformed("share is not rectangular")
+		}
+	}
+
+	if err = validateDecomposition(params, levelQ, levelP, len(value), len(value[0])); err != nil {
+		return
+	}
+
+	for i := range value {
+		for j := range value[i] {
+			for _, p := range value[i][j] {
+				if err = validatePolyQP(params, levelQ, levelP, p); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// Validate checks that the target share is a well-formed share of the CKG protocol for params.
+func (share *CKGShare) Validate(params rlwe.Parameters) error {
+	if share == nil {
+		return malformed("share is nil")
+	}
+	return validatePolyQP(params, params.QCount()-1, params.PCount()-1, share.Value)
+}
+
+// Validate checks that the target share is a well-formed share of the RKG protocol for params, for
+// either of the two rounds.
+func (share *RKGShare) Validate(params rlwe.Parameters) error {
+	if share == nil {
+		return malformed("share is nil")
+	}
+	return validateGadgetShare(params, share.Value)
+}
+
+// Validate checks that the target share is a well-formed share of the RTG protocol for params.
+func (share *RTGShare) Validate(params rlwe.Parameters) (err error) {
+
+	if share == nil || len(share.Value) == 0 || len(share.Value[0]) == 0 || share.Value[0][0].Q == nil {
+		return malformed("share is empty")
+	}
+
+	levelQ, levelP := share.Value[0][0].LevelQ(), share.Value[0][0].LevelP()
+
+	for i := range share.Value {
+		if len(share.Value[i]) != len(share.Value[0]) {
+			return malformed("share is not rectangular")
+		}
+	}
+
+	if err = validateDecomposition(params, levelQ, levelP, len(share.Value), len(share.Value[0])); err != nil {
+		return
+	}
+
+	for i := range share.Value {
+		for _, p := range share.Value[i] {
+			if err = validatePolyQP(params, levelQ, levelP, p); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// Validate checks that the target share is a well-formed share of the PSKG protocol for params.
+func (share *PSKGShare) Validate(params rlwe.Parameters) error {
+	if share == nil {
+		return malformed("share is nil")
+	}
+	return validateGadgetShare(params, share.Value)
+}
+
+// Validate checks that the target share is a well-formed share of the CKS protocol for params.
+func (ckss *CKSShare) Validate(params rlwe.Parameters) error {
+	if ckss == nil || ckss.Value == nil {
+		return malformed("share is empty")
+	}
+	return validatePoly(params, ckss.Value.Level(), ckss.Value)
+}
+
+// Validate checks that the target share is a well-formed share of the PCKS protocol for params.
+func (share *PCKSShare) Validate(params rlwe.Parameters) (err error) {
+
+	if share == nil || share.Value[0] == nil || share.Value[1] == nil {
+		return malformed("share is empty")
+	}
+
+	for _, pol := range share.Value {
+		if err = validatePoly(params, share.Value[0].Level(), pol); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Validate checks that the target share is a well-formed share of the threshold secret key for params.
+func (s *ShamirSecretShare) Validate(params rlwe.Parameters) error {
+	if s == nil {
+		return malformed("share is nil")
+	}
+	return validatePolyQP(params, params.QCount()-1, params.PCount()-1, s.Poly)
+}
+
+// Validate checks that the target commitment is a well-formed commitment for params.
+func (com *Commitment) Validate(params rlwe.Parameters) error {
+	if com == nil {
+		return malformed("commitment is nil")
+	}
+	return validatePolyQP(params, params.QCount()-1, params.PCount()-1, com.Value)
+}
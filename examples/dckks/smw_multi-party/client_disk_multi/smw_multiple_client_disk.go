@@ -1,11 +1,13 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"math"
 	"os"
@@ -48,6 +50,8 @@ var Unmarshal = func(r io.Reader, v interface{}) error {
 }
 
 // Save saves a representation of v to the file at path.
+// The objects implementing io.WriterTo, such as the keys and ciphertexts,
+// are streamed to the file, the others are marshalled with Marshal.
 func Save(path string, v interface{}) error {
 	lock.Lock()
 	defer lock.Unlock()
@@ -56,6 +60,13 @@ func Save(path string, v interface{}) error {
 		return err
 	}
 	defer f.Close()
+	if wt, ok := v.(io.WriterTo); ok {
+		w := bufio.NewWriter(f)
+		if _, err = wt.WriteTo(w); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
 	r, err := Marshal(v)
 	if err != nil {
 		return err
@@ -65,6 +76,7 @@ func Save(path string, v interface{}) error {
 }
 
 // Load loads the file at path into v.
+// The objects implementing io.ReaderFrom are streamed from the file.
 // Use os.IsNotExist() to see if the returned error is due
 // to the file being missing.
 func Load(path string, v interface{}) error {
@@ -75,9 +87,32 @@ func Load(path string, v interface{}) error {
 		return err
 	}
 	defer f.Close()
+	if rf, ok := v.(io.ReaderFrom); ok {
+		_, err = rf.ReadFrom(bufio.NewReader(f))
+		return err
+	}
 	return Unmarshal(f, v)
 }
 
+// keyPasswordEnv is the environment variable holding the password of the secret key files.
+const keyPasswordEnv = "LATTIGO_KEY_PASSWORD"
+
+// SaveSecretKey seals sk under the password of the environment variable keyPasswordEnv and writes
+// it to the file at path, so that the key material is never written in the clear.
+func SaveSecretKey(path string, params rlwe.Parameters, sk *rlwe.SecretKey) error {
+	password := os.Getenv(keyPasswordEnv)
+	if password == "" {
+		return fmt.Errorf("the password of the secret key files must be set in %s", keyPasswordEnv)
+	}
+	data, err := rlwe.SealSecretKey(params, sk, []byte(password), rlwe.DefaultKDFParameters)
+	if err != nil {
+		return err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	return ioutil.WriteFile(path, data, 0600)
+}
+
 func almostEqual(a, b float64) bool {
 	return math.Abs(a-b) <= float64EqualityThreshold
 }
@@ -312,7 +347,7 @@ func genparties(params ckks.Parameters, folderName string, householdIDs []int) [
 		elapsedSKGParty += runTimedParty(func() {
 			pi.sk = ckks.NewKeyGenerator(params).GenSecretKey()
 			if i == 0 {
-				if err := Save("./multi_sk0.tmp", pi.sk); err != nil {
+				if err := SaveSecretKey("./multi_sk0.tmp", params.Parameters, pi.sk); err != nil {
 					log.Fatalln(err)
 				}
 			}
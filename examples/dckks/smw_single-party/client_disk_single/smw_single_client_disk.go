@@ -1,11 +1,13 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"runtime"
@@ -41,6 +43,8 @@ var Unmarshal = func(r io.Reader, v interface{}) error {
 }
 
 // Save saves a representation of v to the file at path.
+// The objects implementing io.WriterTo, such as the keys and ciphertexts,
+// are streamed to the file, the others are marshalled with Marshal.
 func Save(path string, v interface{}) error {
 	lock.Lock()
 	defer lock.Unlock()
@@ -49,6 +53,13 @@ func Save(path string, v interface{}) error {
 		return err
 	}
 	defer f.Close()
+	if wt, ok := v.(io.WriterTo); ok {
+		w := bufio.NewWriter(f)
+		if _, err = wt.WriteTo(w); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
 	r, err := Marshal(v)
 	if err != nil {
 		return err
@@ -58,6 +69,7 @@ func Save(path string, v interface{}) error {
 }
 
 // Load loads the file at path into v.
+// The objects implementing io.ReaderFrom are streamed from the file.
 // Use os.IsNotExist() to see if the returned error is due
 // to the file being missing.
 func Load(path string, v interface{}) error {
@@ -68,9 +80,32 @@ func Load(path string, v interface{}) error {
 		return err
 	}
 	defer f.Close()
+	if rf, ok := v.(io.ReaderFrom); ok {
+		_, err = rf.ReadFrom(bufio.NewReader(f))
+		return err
+	}
 	return Unmarshal(f, v)
 }
 
+// keyPasswordEnv is the environment variable holding the password of the secret key files.
+const keyPasswordEnv = "LATTIGO_KEY_PASSWORD"
+
+// SaveSecretKey seals sk under the password of the environment variable keyPasswordEnv and writes
+// it to the file at path, so that the key material is never written in the clear.
+func SaveSecretKey(path string, params rlwe.Parameters, sk *rlwe.SecretKey) error {
+	password := os.Getenv(keyPasswordEnv)
+	if password == "" {
+		return fmt.Errorf("the password of the secret key files must be set in %s", keyPasswordEnv)
+	}
+	data, err := rlwe.SealSecretKey(params, sk, []byte(password), rlwe.DefaultKDFParameters)
+	if err != nil {
+		return err
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	return ioutil.WriteFile(path, data, 0600)
+}
+
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -184,7 +219,7 @@ func process(householdIDs []int, maximumLenPartyRows int, folderName string, par
 	if err := Save("./single_tpk.tmp", tpk); err != nil {
 		log.Fatalln(err)
 	}
-	if err := Save("./single_tsk.tmp", tsk); err != nil {
+	if err := SaveSecretKey("./single_tsk.tmp", params.Parameters, tsk); err != nil {
 		log.Fatalln(err)
 	}
 	if err := Save("./single_rlk.tmp", rlk); err != nil {
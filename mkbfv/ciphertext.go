@@ -0,0 +1,23 @@
+// Package mkbfv implements a multi-key version of the BFV scheme, in which ciphertexts encrypted under the
+// independent keys of several parties can be homomorphically combined and decrypted collaboratively.
+package mkbfv
+
+import (
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+)
+
+// Ciphertext is a multi-key BFV ciphertext.
+type Ciphertext struct {
+	*mkrlwe.Ciphertext
+}
+
+// NewCiphertext creates a new Ciphertext for the given party IDs at the maximum level.
+func NewCiphertext(params bfv.Parameters, ids []string) *Ciphertext {
+	return &Ciphertext{mkrlwe.NewCiphertext(params.Parameters, ids, params.MaxLevel())}
+}
+
+// CopyNew creates a deep copy of the target Ciphertext.
+func (ct *Ciphertext) CopyNew() *Ciphertext {
+	return &Ciphertext{ct.Ciphertext.CopyNew()}
+}
@@ -0,0 +1,29 @@
+package mkbfv
+
+import (
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+)
+
+// Encryptor encrypts BFV plaintexts under the public key of a single party.
+type Encryptor struct {
+	id  string
+	enc bfv.Encryptor
+}
+
+// NewEncryptor creates a new Encryptor for the party owning the given public key.
+func NewEncryptor(params bfv.Parameters, pk *mkrlwe.PublicKey) *Encryptor {
+	return &Encryptor{id: pk.ID, enc: bfv.NewEncryptor(params, pk.EncryptionKey())}
+}
+
+// ShallowCopy creates a shallow copy of Encryptor in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Encryptor can be used concurrently.
+func (enc *Encryptor) ShallowCopy() *Encryptor {
+	return &Encryptor{id: enc.id, enc: enc.enc.ShallowCopy()}
+}
+
+// EncryptNew encrypts the input plaintext and returns the result in a newly created single-party Ciphertext.
+func (enc *Encryptor) EncryptNew(pt *bfv.Plaintext) *Ciphertext {
+	return &Ciphertext{mkrlwe.NewCiphertextFromRLWE(enc.id, enc.enc.EncryptNew(pt).Ciphertext)}
+}
@@ -0,0 +1,113 @@
+package mkbfv
+
+import (
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// Evaluator is a struct that holds the necessary elements to evaluate homomorphic operations
+// on multi-key BFV ciphertexts.
+type Evaluator struct {
+	*mkrlwe.Evaluator
+	params bfv.Parameters
+	eval   bfv.Evaluator
+}
+
+// NewEvaluator creates a new Evaluator from the given parameters and set of public evaluation keys.
+func NewEvaluator(params bfv.Parameters, keys mkrlwe.EvaluationKeySet) *Evaluator {
+	return &Evaluator{
+		Evaluator: mkrlwe.NewEvaluator(params.Parameters, keys),
+		params:    params,
+		eval:      bfv.NewEvaluator(params, rlwe.EvaluationKey{}),
+	}
+}
+
+// ShallowCopy creates a shallow copy of this Evaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Evaluators can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+	return &Evaluator{Evaluator: eval.Evaluator.ShallowCopy(), params: eval.params, eval: eval.eval.ShallowCopy()}
+}
+
+// Add adds ct0 to ct1 and returns the result in ctOut.
+func (eval *Evaluator) Add(ct0, ct1, ctOut *Ciphertext) {
+	eval.Evaluator.Add(ct0.Ciphertext, ct1.Ciphertext, ctOut.Ciphertext)
+}
+
+// Sub subtracts ct1 from ct0 and returns the result in ctOut.
+func (eval *Evaluator) Sub(ct0, ct1, ctOut *Ciphertext) {
+	eval.Evaluator.Sub(ct0.Ciphertext, ct1.Ciphertext, ctOut.Ciphertext)
+}
+
+// MulRelin multiplies ct0 by ct1, relinearizes the result and returns it in ctOut.
+// Each term of the tensor product is computed as round(t/Q * a_i * b_j) with the scale-invariant
+// tensoring of the bfv package.
+func (eval *Evaluator) MulRelin(ct0, ct1, ctOut *Ciphertext) {
+
+	ringQ := eval.params.RingQ()
+
+	level := utils.MinInt(ct0.Level(), ct1.Level())
+
+	tensor := mkrlwe.NewTensorCiphertext(eval.params.Parameters, level)
+
+	tmp := bfv.NewCiphertextLvl(eval.params, 0, level)
+
+	ids0, ids1 := append([]string{""}, ct0.IDs()...), append([]string{""}, ct1.IDs()...)
+
+	for _, i := range ids0 {
+
+		a := ct0.Value0
+		if i != "" {
+			a = ct0.Value[i]
+		}
+
+		for _, j := range ids1 {
+
+			b := ct1.Value0
+			if j != "" {
+				b = ct1.Value[j]
+			}
+
+			eval.eval.Mul(degreeZero(level, a), degreeZero(level, b), tmp)
+
+			switch {
+			case i == "" && j == "":
+				ring.CopyLvl(level, tmp.Value[0], tensor.Value0)
+			case i == "" || j == "":
+				if out, ok := tensor.Value1[i+j]; ok {
+					ringQ.AddLvl(level, out, tmp.Value[0], out)
+				} else {
+					tensor.Value1[i+j] = tmp.Value[0].CopyNew()
+				}
+			default:
+				if out, ok := tensor.Value2[mkrlwe.PairID(i, j)]; ok {
+					ringQ.AddLvl(level, out, tmp.Value[0], out)
+				} else {
+					tensor.Value2[mkrlwe.PairID(i, j)] = tmp.Value[0].CopyNew()
+				}
+			}
+		}
+	}
+
+	eval.Relinearize(tensor, ctOut.Ciphertext)
+}
+
+// RotateColumns rotates the columns of ctIn by k positions to the left and returns the result in ctOut.
+// The method requires the corresponding rotation key of every party involved in ctIn.
+func (eval *Evaluator) RotateColumns(ctIn *Ciphertext, k int, ctOut *Ciphertext) {
+	eval.Automorphism(ctIn.Ciphertext, eval.params.GaloisElementForColumnRotationBy(k), ctOut.Ciphertext)
+}
+
+// RotateRows swaps the rows of ctIn and returns the result in ctOut.
+// The method requires the row rotation key of every party involved in ctIn.
+func (eval *Evaluator) RotateRows(ctIn *Ciphertext, ctOut *Ciphertext) {
+	eval.Automorphism(ctIn.Ciphertext, eval.params.GaloisElementForRowRotation(), ctOut.Ciphertext)
+}
+
+// degreeZero wraps a polynomial as a degree-zero bfv.Ciphertext at the given level.
+func degreeZero(level int, p *ring.Poly) *bfv.Ciphertext {
+	return &bfv.Ciphertext{Ciphertext: &rlwe.Ciphertext{Value: []*ring.Poly{{Coeffs: p.Coeffs[:level+1], IsNTT: p.IsNTT}}}}
+}
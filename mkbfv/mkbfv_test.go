@@ -0,0 +1,203 @@
+package mkbfv
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+var flagParamString = flag.String("params", "", "specify the test cryptographic parameters as a JSON string. Overrides -short and -long.")
+var partyIDs = []string{"alice", "bob", "carol"}
+
+func testString(opname string, params bfv.Parameters) string {
+	return fmt.Sprintf("%s/LogN=%d/logQ=%d/parties=%d", opname, params.LogN(), params.LogQP(), len(partyIDs))
+}
+
+type testContext struct {
+	params     bfv.Parameters
+	ringT      *ring.Ring
+	encoder    bfv.Encoder
+	sk         map[string]*rlwe.SecretKey
+	encryptors map[string]*Encryptor
+	evaluator  *Evaluator
+	dec        *DecryptionProtocol
+}
+
+func TestMKBFV(t *testing.T) {
+
+	var err error
+
+	defaultParams := bfv.DefaultParams[:2]
+	if testing.Short() {
+		defaultParams = bfv.DefaultParams[:1]
+	}
+	if *flagParamString != "" {
+		var jsonParams bfv.ParametersLiteral
+		if err = json.Unmarshal([]byte(*flagParamString), &jsonParams); err != nil {
+			t.Fatal(err)
+		}
+		defaultParams = []bfv.ParametersLiteral{jsonParams} // the custom test suite reads the parameters from the -params flag
+	}
+
+	for _, p := range defaultParams {
+
+		var params bfv.Parameters
+		if params, err = bfv.NewParametersFromLiteral(p); err != nil {
+			t.Fatal(err)
+		}
+
+		tc := genTestContext(params)
+
+		for _, testSet := range []func(tc *testContext, t *testing.T){
+			testAdd,
+			testMulRelin,
+			testRotateColumns,
+			testRotateRows,
+		} {
+			testSet(tc, t)
+			runtime.GC()
+		}
+	}
+}
+
+func genTestContext(params bfv.Parameters) (tc *testContext) {
+
+	tc = &testContext{
+		params:     params,
+		ringT:      params.RingT(),
+		encoder:    bfv.NewEncoder(params),
+		sk:         make(map[string]*rlwe.SecretKey),
+		encryptors: make(map[string]*Encryptor),
+	}
+
+	kgen := NewKeyGenerator(params)
+
+	prng, _ := utils.NewKeyedPRNG([]byte{'t', 'e', 's', 't'})
+	crp := kgen.SampleCRP(prng)
+
+	evk := make(mkrlwe.EvaluationKeySet)
+	for _, id := range partyIDs {
+		tc.sk[id] = kgen.GenSecretKey()
+		pk := kgen.GenPublicKey(id, tc.sk[id], crp)
+		rlk := kgen.GenRelinearizationKey(id, tc.sk[id], crp)
+		rtks := kgen.GenRotationKeysForRotations(id, []int{1}, true, tc.sk[id])
+		evk.Add(pk, rlk, rtks)
+		tc.encryptors[id] = NewEncryptor(params, pk)
+	}
+
+	tc.evaluator = NewEvaluator(params, evk)
+	tc.dec = NewDecryptionProtocol(params, 3.2)
+
+	return
+}
+
+func newTestVectors(tc *testContext, id string) (coeffs []uint64, ciphertext *Ciphertext) {
+	prng, _ := utils.NewPRNG()
+	coeffs = ring.NewUniformSampler(prng, tc.ringT).ReadNew().Coeffs[0]
+	pt := bfv.NewPlaintext(tc.params)
+	tc.encoder.Encode(coeffs, pt)
+	return coeffs, tc.encryptors[id].EncryptNew(pt)
+}
+
+func verifyTestVectors(tc *testContext, coeffs []uint64, ct *Ciphertext, t *testing.T) {
+	shares := make(map[string]*drlwe.CKSShare)
+	for _, id := range ct.IDs() {
+		shares[id] = tc.dec.AllocateShare(ct.Level())
+		tc.dec.GenShare(id, tc.sk[id], ct, shares[id])
+	}
+	pt := bfv.NewPlaintextLvl(tc.params, ct.Level())
+	tc.dec.Merge(ct, shares, pt)
+	require.True(t, utils.EqualSliceUint64(coeffs, tc.encoder.DecodeUintNew(pt)))
+}
+
+func testAdd(tc *testContext, t *testing.T) {
+
+	t.Run(testString("Add", tc.params), func(t *testing.T) {
+
+		want, ct := newTestVectors(tc, partyIDs[0])
+
+		for _, id := range partyIDs[1:] {
+			coeffs, ctID := newTestVectors(tc, id)
+			tc.ringT.Add(&ring.Poly{Coeffs: [][]uint64{want}}, &ring.Poly{Coeffs: [][]uint64{coeffs}}, &ring.Poly{Coeffs: [][]uint64{want}})
+			tc.evaluator.Add(ct, ctID, ct)
+		}
+
+		require.Equal(t, partyIDs, ct.IDs())
+
+		verifyTestVectors(tc, want, ct, t)
+	})
+}
+
+func testMulRelin(tc *testContext, t *testing.T) {
+
+	t.Run(testString("MulRelin", tc.params), func(t *testing.T) {
+
+		coeffs0, ct0 := newTestVectors(tc, partyIDs[0])
+		coeffs1, ct1 := newTestVectors(tc, partyIDs[1])
+		coeffs2, ct2 := newTestVectors(tc, partyIDs[2])
+
+		tc.evaluator.Add(ct1, ct2, ct1)
+
+		want := make([]uint64, len(coeffs0))
+		T := tc.params.T()
+		for i := range want {
+			want[i] = ring.BRedAdd(coeffs1[i]+coeffs2[i], T, tc.ringT.BredParams[0])
+			want[i] = ring.BRed(coeffs0[i], want[i], T, tc.ringT.BredParams[0])
+		}
+
+		tc.evaluator.MulRelin(ct0, ct1, ct0)
+		require.Equal(t, partyIDs, ct0.IDs())
+
+		verifyTestVectors(tc, want, ct0, t)
+	})
+}
+
+func testRotateColumns(tc *testContext, t *testing.T) {
+
+	t.Run(testString("RotateColumns", tc.params), func(t *testing.T) {
+
+		coeffs0, ct := newTestVectors(tc, partyIDs[0])
+		coeffs1, ct1 := newTestVectors(tc, partyIDs[1])
+		tc.evaluator.Add(ct, ct1, ct)
+
+		want := make([]uint64, len(coeffs0))
+		for i := range want {
+			want[i] = (coeffs0[i] + coeffs1[i]) % tc.params.T()
+		}
+
+		tc.evaluator.RotateColumns(ct, 1, ct)
+
+		verifyTestVectors(tc, utils.RotateUint64Slots(want, 1), ct, t)
+	})
+}
+
+func testRotateRows(tc *testContext, t *testing.T) {
+
+	t.Run(testString("RotateRows", tc.params), func(t *testing.T) {
+
+		coeffs0, ct := newTestVectors(tc, partyIDs[0])
+		coeffs1, ct1 := newTestVectors(tc, partyIDs[1])
+		tc.evaluator.Add(ct, ct1, ct)
+
+		want := make([]uint64, len(coeffs0))
+		for i := range want {
+			want[i] = (coeffs0[i] + coeffs1[i]) % tc.params.T()
+		}
+
+		tc.evaluator.RotateRows(ct, ct)
+
+		half := len(want) >> 1
+		verifyTestVectors(tc, append(want[half:], want[:half]...), ct, t)
+	})
+}
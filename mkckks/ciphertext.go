@@ -0,0 +1,24 @@
+// Package mkckks implements a multi-key version of the CKKS scheme, in which ciphertexts encrypted under the
+// independent keys of several parties can be homomorphically combined and decrypted collaboratively.
+package mkckks
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+)
+
+// Ciphertext is a multi-key CKKS ciphertext.
+type Ciphertext struct {
+	*mkrlwe.Ciphertext
+	Scale float64
+}
+
+// NewCiphertext creates a new Ciphertext for the given party IDs, level and scale.
+func NewCiphertext(params ckks.Parameters, ids []string, level int, scale float64) *Ciphertext {
+	return &Ciphertext{Ciphertext: mkrlwe.NewCiphertextNTT(params.Parameters, ids, level), Scale: scale}
+}
+
+// CopyNew creates a deep copy of the target Ciphertext.
+func (ct *Ciphertext) CopyNew() *Ciphertext {
+	return &Ciphertext{Ciphertext: ct.Ciphertext.CopyNew(), Scale: ct.Scale}
+}
@@ -0,0 +1,36 @@
+package mkckks
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// DecryptionProtocol is the structure storing the parameters for the multi-party decryption of a Ciphertext.
+type DecryptionProtocol struct {
+	mkrlwe.DecryptionProtocol
+}
+
+// NewDecryptionProtocol creates a new DecryptionProtocol.
+func NewDecryptionProtocol(params ckks.Parameters, sigmaSmudging float64) *DecryptionProtocol {
+	return &DecryptionProtocol{*mkrlwe.NewDecryptionProtocol(params.Parameters, sigmaSmudging)}
+}
+
+// ShallowCopy creates a shallow copy of DecryptionProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// DecryptionProtocol can be used concurrently.
+func (dec *DecryptionProtocol) ShallowCopy() *DecryptionProtocol {
+	return &DecryptionProtocol{*dec.DecryptionProtocol.ShallowCopy()}
+}
+
+// GenShare computes the partial decryption share of the party id for the Ciphertext ct.
+func (dec *DecryptionProtocol) GenShare(id string, sk *rlwe.SecretKey, ct *Ciphertext, shareOut *drlwe.CKSShare) {
+	dec.DecryptionProtocol.GenShare(id, sk, ct.Ciphertext, shareOut)
+}
+
+// Merge merges the partial decryption shares of all the parties involved in ct and returns the plaintext in ptOut.
+func (dec *DecryptionProtocol) Merge(ct *Ciphertext, shares map[string]*drlwe.CKSShare, ptOut *ckks.Plaintext) {
+	dec.DecryptionProtocol.Merge(ct.Ciphertext, shares, ptOut.Plaintext)
+	ptOut.Scale = ct.Scale
+}
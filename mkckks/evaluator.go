@@ -0,0 +1,110 @@
+package mkckks
+
+import (
+	"errors"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// Evaluator is a struct that holds the necessary elements to evaluate homomorphic operations
+// on multi-key CKKS ciphertexts.
+type Evaluator struct {
+	*mkrlwe.Evaluator
+	params ckks.Parameters
+	buffQ  *ring.Poly
+}
+
+// NewEvaluator creates a new Evaluator from the given parameters and set of public evaluation keys.
+func NewEvaluator(params ckks.Parameters, keys mkrlwe.EvaluationKeySet) *Evaluator {
+	return &Evaluator{Evaluator: mkrlwe.NewEvaluator(params.Parameters, keys), params: params, buffQ: params.RingQ().NewPoly()}
+}
+
+// ShallowCopy creates a shallow copy of this Evaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Evaluators can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+	return &Evaluator{Evaluator: eval.Evaluator.ShallowCopy(), params: eval.params, buffQ: eval.params.RingQ().NewPoly()}
+}
+
+// Add adds ct0 to ct1 and returns the result in ctOut. The inputs must have the same scale.
+func (eval *Evaluator) Add(ct0, ct1, ctOut *Ciphertext) {
+	checkScales(ct0, ct1)
+	eval.Evaluator.Add(ct0.Ciphertext, ct1.Ciphertext, ctOut.Ciphertext)
+	ctOut.Scale = ct0.Scale
+}
+
+// Sub subtracts ct1 from ct0 and returns the result in ctOut. The inputs must have the same scale.
+func (eval *Evaluator) Sub(ct0, ct1, ctOut *Ciphertext) {
+	checkScales(ct0, ct1)
+	eval.Evaluator.Sub(ct0.Ciphertext, ct1.Ciphertext, ctOut.Ciphertext)
+	ctOut.Scale = ct0.Scale
+}
+
+// MulRelin multiplies ct0 by ct1, relinearizes the result and returns it in ctOut.
+// The scale of ctOut is the product of the input scales; the result is not rescaled.
+func (eval *Evaluator) MulRelin(ct0, ct1, ctOut *Ciphertext) {
+	eval.Evaluator.MulRelin(ct0.Ciphertext, ct1.Ciphertext, ctOut.Ciphertext)
+	ctOut.Scale = ct0.Scale * ct1.Scale
+}
+
+// Rescale divides ctIn by the last modulus of its current level, as long as the resulting scale stays above
+// minScale/2, and returns the result in ctOut.
+func (eval *Evaluator) Rescale(ctIn *Ciphertext, minScale float64, ctOut *Ciphertext) (err error) {
+
+	ringQ := eval.params.RingQ()
+
+	if minScale <= 0 {
+		return errors.New("cannot Rescale: minScale is 0")
+	}
+
+	if ctIn.Level() == 0 {
+		return errors.New("cannot Rescale: input Ciphertext already at level 0")
+	}
+
+	if ctIn != ctOut {
+		eval.Evaluator.Add(ctIn.Ciphertext, mkrlwe.NewCiphertextNTT(eval.params.Parameters, nil, ctIn.Level()), ctOut.Ciphertext)
+	}
+
+	ctOut.Scale = ctIn.Scale
+
+	level := ctOut.Level()
+	for level > 0 && ctOut.Scale/float64(ringQ.Modulus[level]) >= minScale/2 {
+		ctOut.Scale /= float64(ringQ.Modulus[level])
+		ringQ.DivRoundByLastModulusNTTLvl(level, ctOut.Value0, eval.buffQ, ctOut.Value0)
+		for _, p := range ctOut.Value {
+			ringQ.DivRoundByLastModulusNTTLvl(level, p, eval.buffQ, p)
+		}
+		level--
+		ctOut.Resize(level)
+	}
+
+	return nil
+}
+
+// Rotate rotates the slots of ctIn by k positions to the left and returns the result in ctOut.
+// The method requires the corresponding rotation key of every party involved in ctIn.
+func (eval *Evaluator) Rotate(ctIn *Ciphertext, k int, ctOut *Ciphertext) {
+	eval.Automorphism(ctIn.Ciphertext, eval.params.GaloisElementForColumnRotationBy(k), ctOut.Ciphertext)
+	ctOut.Scale = ctIn.Scale
+}
+
+// Conjugate conjugates the slots of ctIn and returns the result in ctOut.
+// The method requires the conjugation key of every party involved in ctIn.
+func (eval *Evaluator) Conjugate(ctIn *Ciphertext, ctOut *Ciphertext) {
+
+	if eval.params.RingType() == ring.ConjugateInvariant {
+		panic("cannot Conjugate: method is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+
+	eval.Automorphism(ctIn.Ciphertext, eval.params.GaloisElementForRowRotation(), ctOut.Ciphertext)
+	ctOut.Scale = ctIn.Scale
+}
+
+func checkScales(ct0, ct1 *Ciphertext) {
+	if math.Abs(ct0.Scale-ct1.Scale) > 1e-9*ct0.Scale {
+		panic("cannot evaluate: input Ciphertexts must have the same scale")
+	}
+}
@@ -0,0 +1,32 @@
+package mkckks
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// KeyGenerator is a structure that stores the elements required to generate the keys of a party
+// in the multi-key CKKS setting.
+type KeyGenerator struct {
+	*mkrlwe.KeyGenerator
+	params ckks.Parameters
+}
+
+// NewKeyGenerator creates a new KeyGenerator from the given parameters.
+func NewKeyGenerator(params ckks.Parameters) *KeyGenerator {
+	return &KeyGenerator{KeyGenerator: mkrlwe.NewKeyGenerator(params.Parameters), params: params}
+}
+
+// GenRotationKeysForRotations generates the rotation keys of the party id for the given rotations,
+// and for the conjugation if includeConjugate is true.
+func (kgen *KeyGenerator) GenRotationKeysForRotations(id string, ks []int, includeConjugate bool, sk *rlwe.SecretKey) *mkrlwe.RotationKeySet {
+	galEls := make([]uint64, 0, len(ks)+1)
+	for _, k := range ks {
+		galEls = append(galEls, kgen.params.GaloisElementForColumnRotationBy(k))
+	}
+	if includeConjugate {
+		galEls = append(galEls, kgen.params.GaloisElementForRowRotation())
+	}
+	return kgen.GenRotationKeys(id, galEls, sk)
+}
@@ -0,0 +1,231 @@
+package mkckks
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+var flagParamString = flag.String("params", "", "specify the test cryptographic parameters as a JSON string. Overrides -short and -long.")
+var printPrecisionStats = flag.Bool("print-precision", false, "print precision stats")
+var minPrec float64 = 15.0
+var partyIDs = []string{"alice", "bob", "carol"}
+
+func testString(opname string, params ckks.Parameters) string {
+	return fmt.Sprintf("%s/logN=%d/logSlots=%d/logQ=%d/levels=%d/#Pi=%d/Decomp=%d/parties=%d",
+		opname,
+		params.LogN(),
+		params.LogSlots(),
+		params.LogQP(),
+		params.MaxLevel()+1,
+		params.PCount(),
+		params.DecompRNS(params.QCount()-1, params.PCount()-1),
+		len(partyIDs))
+}
+
+type testContext struct {
+	params     ckks.Parameters
+	encoder    ckks.Encoder
+	sk         map[string]*rlwe.SecretKey
+	encryptors map[string]*Encryptor
+	evaluator  *Evaluator
+	dec        *DecryptionProtocol
+}
+
+func TestMKCKKS(t *testing.T) {
+
+	var err error
+
+	var testParams []ckks.ParametersLiteral
+	switch {
+	case *flagParamString != "": // the custom test suite reads the parameters from the -params flag
+		testParams = append(testParams, ckks.ParametersLiteral{})
+		if err = json.Unmarshal([]byte(*flagParamString), &testParams[0]); err != nil {
+			t.Fatal(err)
+		}
+	case testing.Short():
+		testParams = ckks.DefaultParams[:1]
+	default:
+		testParams = ckks.DefaultParams[:2]
+	}
+
+	for _, paramsLiteral := range testParams {
+
+		var params ckks.Parameters
+		if params, err = ckks.NewParametersFromLiteral(paramsLiteral); err != nil {
+			t.Fatal(err)
+		}
+
+		tc := genTestParams(params)
+
+		for _, testSet := range []func(tc *testContext, t *testing.T){
+			testAdd,
+			testMulRelin,
+			testRotate,
+			testConjugate,
+		} {
+			testSet(tc, t)
+			runtime.GC()
+		}
+	}
+}
+
+func genTestParams(params ckks.Parameters) (tc *testContext) {
+
+	tc = &testContext{
+		params:     params,
+		encoder:    ckks.NewEncoder(params),
+		sk:         make(map[string]*rlwe.SecretKey),
+		encryptors: make(map[string]*Encryptor),
+	}
+
+	kgen := NewKeyGenerator(params)
+
+	prng, _ := utils.NewKeyedPRNG([]byte{'t', 'e', 's', 't'})
+	crp := kgen.SampleCRP(prng)
+
+	evk := make(mkrlwe.EvaluationKeySet)
+	for _, id := range partyIDs {
+		tc.sk[id] = kgen.GenSecretKey()
+		pk := kgen.GenPublicKey(id, tc.sk[id], crp)
+		rlk := kgen.GenRelinearizationKey(id, tc.sk[id], crp)
+		rtks := kgen.GenRotationKeysForRotations(id, []int{1}, true, tc.sk[id])
+		evk.Add(pk, rlk, rtks)
+		tc.encryptors[id] = NewEncryptor(params, pk)
+	}
+
+	tc.evaluator = NewEvaluator(params, evk)
+	tc.dec = NewDecryptionProtocol(params, 3.2)
+
+	return
+}
+
+func newTestVectors(tc *testContext, id string) (values []complex128, ciphertext *Ciphertext) {
+
+	params := tc.params
+
+	values = make([]complex128, params.Slots())
+	for i := range values {
+		values[i] = complex(utils.RandFloat64(-1, 1), utils.RandFloat64(-1, 1))
+	}
+
+	pt := tc.encoder.EncodeNew(values, params.MaxLevel(), params.DefaultScale(), params.LogSlots())
+
+	return values, tc.encryptors[id].EncryptNew(pt)
+}
+
+func decrypt(tc *testContext, ct *Ciphertext) *ckks.Plaintext {
+	shares := make(map[string]*drlwe.CKSShare)
+	for _, id := range ct.IDs() {
+		shares[id] = tc.dec.AllocateShare(ct.Level())
+		tc.dec.GenShare(id, tc.sk[id], ct, shares[id])
+	}
+	pt := ckks.NewPlaintext(tc.params, ct.Level(), 0)
+	tc.dec.Merge(ct, shares, pt)
+	return pt
+}
+
+func verifyTestVectors(tc *testContext, valuesWant []complex128, ct *Ciphertext, t *testing.T) {
+
+	precStats := ckks.GetPrecisionStats(tc.params, tc.encoder, nil, valuesWant, decrypt(tc, ct), tc.params.LogSlots(), 0)
+
+	if *printPrecisionStats {
+		t.Log(precStats.String())
+	}
+
+	require.GreaterOrEqual(t, precStats.MeanPrecision.Real, minPrec)
+	require.GreaterOrEqual(t, precStats.MeanPrecision.Imag, minPrec)
+}
+
+func testAdd(tc *testContext, t *testing.T) {
+
+	t.Run(testString("Add", tc.params), func(t *testing.T) {
+
+		want, ct := newTestVectors(tc, partyIDs[0])
+
+		for _, id := range partyIDs[1:] {
+			values, ctID := newTestVectors(tc, id)
+			for i := range want {
+				want[i] += values[i]
+			}
+			tc.evaluator.Add(ct, ctID, ct)
+		}
+
+		require.Equal(t, partyIDs, ct.IDs())
+
+		verifyTestVectors(tc, want, ct, t)
+	})
+}
+
+func testMulRelin(tc *testContext, t *testing.T) {
+
+	t.Run(testString("MulRelin", tc.params), func(t *testing.T) {
+
+		values0, ct0 := newTestVectors(tc, partyIDs[0])
+		values1, ct1 := newTestVectors(tc, partyIDs[1])
+		values2, ct2 := newTestVectors(tc, partyIDs[2])
+
+		tc.evaluator.Add(ct1, ct2, ct1)
+
+		want := make([]complex128, len(values0))
+		for i := range want {
+			want[i] = values0[i] * (values1[i] + values2[i])
+		}
+
+		tc.evaluator.MulRelin(ct0, ct1, ct0)
+		require.NoError(t, tc.evaluator.Rescale(ct0, tc.params.DefaultScale(), ct0))
+		require.Equal(t, tc.params.MaxLevel()-1, ct0.Level())
+		require.Equal(t, partyIDs, ct0.IDs())
+
+		verifyTestVectors(tc, want, ct0, t)
+	})
+}
+
+func testRotate(tc *testContext, t *testing.T) {
+
+	t.Run(testString("Rotate", tc.params), func(t *testing.T) {
+
+		values0, ct := newTestVectors(tc, partyIDs[0])
+		values1, ct1 := newTestVectors(tc, partyIDs[1])
+		tc.evaluator.Add(ct, ct1, ct)
+
+		want := make([]complex128, len(values0))
+		for i := range want {
+			want[i] = values0[i] + values1[i]
+		}
+
+		tc.evaluator.Rotate(ct, 1, ct)
+
+		verifyTestVectors(tc, utils.RotateComplex128Slice(want, 1), ct, t)
+	})
+}
+
+func testConjugate(tc *testContext, t *testing.T) {
+
+	t.Run(testString("Conjugate", tc.params), func(t *testing.T) {
+
+		values0, ct := newTestVectors(tc, partyIDs[0])
+		values1, ct1 := newTestVectors(tc, partyIDs[1])
+		tc.evaluator.Add(ct, ct1, ct)
+
+		want := make([]complex128, len(values0))
+		for i := range want {
+			v := values0[i] + values1[i]
+			want[i] = complex(real(v), -imag(v))
+		}
+
+		tc.evaluator.Conjugate(ct, ct)
+
+		verifyTestVectors(tc, want, ct, t)
+	})
+}
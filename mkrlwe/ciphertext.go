@@ -0,0 +1,242 @@
+// Package mkrlwe implements the generic operations of multi-key R-LWE schemes, in which ciphertexts
+// encrypted under the independent keys of several parties can be homomorphically combined without
+// any prior interactive setup. The mkckks and mkbfv packages extend this package with their scheme-specific
+// operations.
+package mkrlwe
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+var errShortBuffer = errors.New("too small bytearray")
+
+// Ciphertext is a multi-key RLWE ciphertext. It is made of a common component Value0 and of one
+// component per involved party, indexed by the party ID, and decrypts as Value0 + sum_i Value[i]*s_i.
+// Party IDs must be non-empty strings.
+type Ciphertext struct {
+	Value0 *ring.Poly
+	Value  map[string]*ring.Poly
+}
+
+// TensorCiphertext is a multi-key RLWE ciphertext of degree two, as output by the tensoring of two Ciphertext.
+// It decrypts as Value0 + sum_i Value1[i]*s_i + sum_{i<=j} Value2[PairID(i, j)]*s_i*s_j.
+type TensorCiphertext struct {
+	Value0 *ring.Poly
+	Value1 map[string]*ring.Poly
+	Value2 map[[2]string]*ring.Poly
+}
+
+// NewCiphertext returns a new Ciphertext with zero values for the given party IDs.
+func NewCiphertext(params rlwe.Parameters, ids []string, level int) *Ciphertext {
+	ct := &Ciphertext{Value0: params.RingQ().NewPolyLvl(level), Value: make(map[string]*ring.Poly, len(ids))}
+	for _, id := range ids {
+		checkID(id)
+		ct.Value[id] = params.RingQ().NewPolyLvl(level)
+	}
+	return ct
+}
+
+// NewCiphertextNTT returns a new Ciphertext with zero values for the given party IDs
+// and whose polynomials are flagged as being in the NTT domain.
+func NewCiphertextNTT(params rlwe.Parameters, ids []string, level int) *Ciphertext {
+	ct := NewCiphertext(params, ids, level)
+	ct.Value0.IsNTT = true
+	for _, p := range ct.Value {
+		p.IsNTT = true
+	}
+	return ct
+}
+
+// NewCiphertextFromRLWE returns a single-party Ciphertext from a degree-one rlwe.Ciphertext encrypted under
+// the secret key of the party id. The returned Ciphertext shares its polynomials with ct.
+func NewCiphertextFromRLWE(id string, ct *rlwe.Ciphertext) *Ciphertext {
+	if ct.Degree() != 1 {
+		panic("cannot NewCiphertextFromRLWE: input Ciphertext must be of degree 1")
+	}
+	checkID(id)
+	return &Ciphertext{Value0: ct.Value[0], Value: map[string]*ring.Poly{id: ct.Value[1]}}
+}
+
+// NewTensorCiphertext returns a new empty TensorCiphertext at the given level.
+func NewTensorCiphertext(params rlwe.Parameters, level int) *TensorCiphertext {
+	return &TensorCiphertext{
+		Value0: params.RingQ().NewPolyLvl(level),
+		Value1: make(map[string]*ring.Poly),
+		Value2: make(map[[2]string]*ring.Poly),
+	}
+}
+
+// PairID returns the index in TensorCiphertext.Value2 of the term s_i*s_j.
+func PairID(i, j string) [2]string {
+	if j < i {
+		i, j = j, i
+	}
+	return [2]string{i, j}
+}
+
+// Level returns the level of the target Ciphertext.
+func (ct *Ciphertext) Level() int {
+	return ct.Value0.Level()
+}
+
+// IDs returns the sorted list of the party IDs involved in the target Ciphertext.
+func (ct *Ciphertext) IDs() []string {
+	return sortedIDs(ct.Value)
+}
+
+// CopyNew creates a deep copy of the target Ciphertext.
+func (ct *Ciphertext) CopyNew() *Ciphertext {
+	ctCopy := &Ciphertext{Value0: ct.Value0.CopyNew(), Value: make(map[string]*ring.Poly, len(ct.Value))}
+	for id, p := range ct.Value {
+		ctCopy.Value[id] = p.CopyNew()
+	}
+	return ctCopy
+}
+
+// Resize drops the moduli above the given level in all the components of the target Ciphertext.
+func (ct *Ciphertext) Resize(level int) {
+	ct.Value0.Resize(level)
+	for _, p := range ct.Value {
+		p.Resize(level)
+	}
+}
+
+// Level returns the level of the target TensorCiphertext.
+func (t *TensorCiphertext) Level() int {
+	return t.Value0.Level()
+}
+
+// IDs returns the sorted list of the party IDs involved in the target TensorCiphertext.
+func (t *TensorCiphertext) IDs() []string {
+	ids := make(map[string]*ring.Poly, len(t.Value1))
+	for id := range t.Value1 {
+		ids[id] = nil
+	}
+	for pair := range t.Value2 {
+		ids[pair[0]], ids[pair[1]] = nil, nil
+	}
+	return sortedIDs(ids)
+}
+
+// GetDataLen returns the length in bytes of the target Ciphertext.
+func (ct *Ciphertext) GetDataLen(WithMetaData bool) (dataLen int) {
+	dataLen = 4 + ct.Value0.GetDataLen64(WithMetaData)
+	for id, p := range ct.Value {
+		dataLen += 4 + len(id) + p.GetDataLen64(WithMetaData)
+	}
+	return
+}
+
+// MarshalBinary encodes the target Ciphertext on a slice of bytes.
+func (ct *Ciphertext) MarshalBinary() (data []byte, err error) {
+
+	data = make([]byte, ct.GetDataLen(true))
+
+	binary.LittleEndian.PutUint32(data, uint32(len(ct.Value)))
+
+	var pt, inc int
+	pt = 4
+
+	if inc, err = ct.Value0.WriteTo64(data[pt:]); err != nil {
+		return nil, err
+	}
+	pt += inc
+
+	for _, id := range ct.IDs() {
+		pt += copy(data[pt:], encodeID(id))
+		if inc, err = ct.Value[id].WriteTo64(data[pt:]); err != nil {
+			return nil, err
+		}
+		pt += inc
+	}
+
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target Ciphertext.
+func (ct *Ciphertext) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 4 {
+		return errShortBuffer
+	}
+
+	n := int(binary.LittleEndian.Uint32(data))
+
+	var pt, inc int
+	pt = 4
+
+	ct.Value0 = new(ring.Poly)
+	if inc, err = ct.Value0.DecodePoly64(data[pt:]); err != nil {
+		return err
+	}
+	pt += inc
+
+	ct.Value = make(map[string]*ring.Poly, n)
+	for i := 0; i < n; i++ {
+		var id string
+		if id, inc, err = decodeID(data[pt:]); err != nil {
+			return err
+		}
+		pt += inc
+		p := new(ring.Poly)
+		if inc, err = p.DecodePoly64(data[pt:]); err != nil {
+			return err
+		}
+		pt += inc
+		ct.Value[id] = p
+	}
+
+	if pt != len(data) {
+		return errors.New("remaining unparsed data")
+	}
+
+	return
+}
+
+func checkID(id string) {
+	if id == "" {
+		panic("party ID cannot be empty")
+	}
+}
+
+func sortedIDs(m map[string]*ring.Poly) (ids []string) {
+	ids = make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return
+}
+
+func encodeLength(n int) []byte {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, uint32(n))
+	return data
+}
+
+func decodeLength(data []byte) (n, pt int, err error) {
+	if len(data) < 4 {
+		return 0, 0, errShortBuffer
+	}
+	return int(binary.LittleEndian.Uint32(data)), 4, nil
+}
+
+func encodeID(id string) []byte {
+	return append(encodeLength(len(id)), id...)
+}
+
+func decodeID(data []byte) (id string, pt int, err error) {
+	var n int
+	if n, pt, err = decodeLength(data); err != nil {
+		return
+	}
+	if len(data[pt:]) < n {
+		return "", 0, errShortBuffer
+	}
+	return string(data[pt : pt+n]), pt + n, nil
+}
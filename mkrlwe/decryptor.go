@@ -0,0 +1,70 @@
+package mkrlwe
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// DecryptionProtocol is the structure storing the parameters for the multi-party decryption of a Ciphertext.
+// Each party involved in the Ciphertext computes a partial decryption share c_i*s_i + e_i, where e_i is a
+// smudging noise of standard deviation sigmaSmudging, and the shares are then merged with the common component.
+type DecryptionProtocol struct {
+	params rlwe.Parameters
+	cks    *drlwe.CKSProtocol
+	zero   *rlwe.SecretKey
+}
+
+// NewDecryptionProtocol creates a new DecryptionProtocol.
+func NewDecryptionProtocol(params rlwe.Parameters, sigmaSmudging float64) *DecryptionProtocol {
+	return &DecryptionProtocol{
+		params: params,
+		cks:    drlwe.NewCKSProtocol(params, sigmaSmudging),
+		zero:   rlwe.NewSecretKey(params),
+	}
+}
+
+// ShallowCopy creates a shallow copy of DecryptionProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// DecryptionProtocol can be used concurrently.
+func (dec *DecryptionProtocol) ShallowCopy() *DecryptionProtocol {
+	return &DecryptionProtocol{params: dec.params, cks: dec.cks.ShallowCopy(), zero: dec.zero}
+}
+
+// AllocateShare allocates a partial decryption share at the given level.
+func (dec *DecryptionProtocol) AllocateShare(level int) *drlwe.CKSShare {
+	return dec.cks.AllocateShare(level)
+}
+
+// GenShare computes the partial decryption share of the party id for the Ciphertext ct.
+func (dec *DecryptionProtocol) GenShare(id string, sk *rlwe.SecretKey, ct *Ciphertext, shareOut *drlwe.CKSShare) {
+	c, ok := ct.Value[id]
+	if !ok {
+		panic(fmt.Sprintf("cannot GenShare: party %s is not involved in the Ciphertext", id))
+	}
+	dec.cks.GenShare(sk, dec.zero, c, shareOut)
+}
+
+// Merge merges the partial decryption shares of all the parties involved in ct and returns the plaintext in ptOut.
+func (dec *DecryptionProtocol) Merge(ct *Ciphertext, shares map[string]*drlwe.CKSShare, ptOut *rlwe.Plaintext) {
+
+	ringQ := dec.params.RingQ()
+
+	level := utils.MinInt(ct.Level(), ptOut.Level())
+
+	ring.CopyLvl(level, ct.Value0, ptOut.Value)
+
+	for _, id := range ct.IDs() {
+		share, ok := shares[id]
+		if !ok {
+			panic(fmt.Sprintf("cannot Merge: missing share of party %s", id))
+		}
+		ringQ.AddLvl(level, ptOut.Value, share.Value, ptOut.Value)
+	}
+
+	ptOut.Value.IsNTT = ct.Value0.IsNTT
+	ptOut.Value.Resize(level)
+}
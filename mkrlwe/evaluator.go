@@ -0,0 +1,351 @@
+package mkrlwe
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// Evaluator is a struct that holds the necessary elements to execute the generic homomorphic operations
+// on multi-key RLWE ciphertexts, such as additions, relinearization and automorphisms.
+// It stores the public evaluation keys of all the parties.
+type Evaluator struct {
+	params          rlwe.Parameters
+	eval            *rlwe.Evaluator
+	keys            EvaluationKeySet
+	permuteNTTIndex map[uint64][]uint64
+	buffQ           [4]*ring.Poly
+}
+
+// NewEvaluator creates a new Evaluator from the given parameters and set of public evaluation keys.
+func NewEvaluator(params rlwe.Parameters, keys EvaluationKeySet) *Evaluator {
+
+	eval := &Evaluator{
+		params:          params,
+		eval:            rlwe.NewEvaluator(params, nil),
+		keys:            keys,
+		permuteNTTIndex: make(map[uint64][]uint64),
+	}
+
+	for _, evk := range keys {
+		if evk != nil && evk.Rtks != nil {
+			for _, galEl := range evk.Rtks.GaloisElements() {
+				if _, ok := eval.permuteNTTIndex[galEl]; !ok {
+					eval.permuteNTTIndex[galEl] = params.RingQ().PermuteNTTIndex(galEl)
+				}
+			}
+		}
+	}
+
+	eval.buffQ = newBuffers(params)
+
+	return eval
+}
+
+func newBuffers(params rlwe.Parameters) (buffQ [4]*ring.Poly) {
+	ringQ := params.RingQ()
+	return [4]*ring.Poly{ringQ.NewPoly(), ringQ.NewPoly(), ringQ.NewPoly(), ringQ.NewPoly()}
+}
+
+// ShallowCopy creates a shallow copy of this Evaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Evaluators can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+	return &Evaluator{
+		params:          eval.params,
+		eval:            eval.eval.ShallowCopy(),
+		keys:            eval.keys,
+		permuteNTTIndex: eval.permuteNTTIndex,
+		buffQ:           newBuffers(eval.params),
+	}
+}
+
+// Parameters returns the parameters of the Evaluator.
+func (eval *Evaluator) Parameters() rlwe.Parameters {
+	return eval.params
+}
+
+// Add adds ct0 to ct1 and returns the result in ctOut. The party IDs of ctOut are the union of the
+// party IDs of ct0 and ct1.
+func (eval *Evaluator) Add(ct0, ct1, ctOut *Ciphertext) {
+	eval.evaluate(ct0, ct1, ctOut, false)
+}
+
+// Sub subtracts ct1 from ct0 and returns the result in ctOut. The party IDs of ctOut are the union of the
+// party IDs of ct0 and ct1.
+func (eval *Evaluator) Sub(ct0, ct1, ctOut *Ciphertext) {
+	eval.evaluate(ct0, ct1, ctOut, true)
+}
+
+func (eval *Evaluator) evaluate(ct0, ct1, ctOut *Ciphertext, sub bool) {
+
+	ringQ := eval.params.RingQ()
+
+	level := utils.MinInt(utils.MinInt(ct0.Level(), ct1.Level()), ctOut.Level())
+	isNTT := ct0.Value0.IsNTT
+
+	ids := unionIDs(ct0.Value, ct1.Value)
+
+	in0, in1 := ct0.Value, ct1.Value
+
+	if sub {
+		ringQ.SubLvl(level, ct0.Value0, ct1.Value0, ctOut.Value0)
+	} else {
+		ringQ.AddLvl(level, ct0.Value0, ct1.Value0, ctOut.Value0)
+	}
+
+	values := make(map[string]*ring.Poly, len(ids))
+
+	for _, id := range ids {
+
+		p0, ok0 := in0[id]
+		p1, ok1 := in1[id]
+
+		out := eval.outputPoly(ctOut, id, level, isNTT)
+
+		switch {
+		case ok0 && ok1 && sub:
+			ringQ.SubLvl(level, p0, p1, out)
+		case ok0 && ok1:
+			ringQ.AddLvl(level, p0, p1, out)
+		case ok0:
+			ring.CopyLvl(level, p0, out)
+		case sub:
+			ringQ.NegLvl(level, p1, out)
+		default:
+			ring.CopyLvl(level, p1, out)
+		}
+
+		values[id] = out
+	}
+
+	ctOut.Value0.IsNTT = isNTT
+	ctOut.Value = values
+	ctOut.Resize(level)
+}
+
+// Tensor computes the tensor product of ct0 and ct1 and returns the result in tensorOut.
+// The input Ciphertexts must be in the NTT domain and the result is not rescaled.
+func (eval *Evaluator) Tensor(ct0, ct1 *Ciphertext, tensorOut *TensorCiphertext) {
+
+	if !ct0.Value0.IsNTT || !ct1.Value0.IsNTT {
+		panic("cannot Tensor: input Ciphertexts must be in the NTT domain")
+	}
+
+	ringQ := eval.params.RingQ()
+
+	level := utils.MinInt(utils.MinInt(ct0.Level(), ct1.Level()), tensorOut.Level())
+
+	tensorOut.Value0.Resize(level)
+	tensorOut.Value0.Zero()
+	tensorOut.Value0.IsNTT = true
+	tensorOut.Value1 = make(map[string]*ring.Poly)
+	tensorOut.Value2 = make(map[[2]string]*ring.Poly)
+
+	newPoly := func() *ring.Poly {
+		p := ringQ.NewPolyLvl(level)
+		p.IsNTT = true
+		return p
+	}
+
+	ids0, ids1 := append([]string{""}, ct0.IDs()...), append([]string{""}, ct1.IDs()...)
+
+	for _, i := range ids0 {
+
+		a := ct0.Value0
+		if i != "" {
+			a = ct0.Value[i]
+		}
+
+		ringQ.MFormLvl(level, a, eval.buffQ[0])
+
+		for _, j := range ids1 {
+
+			b := ct1.Value0
+			if j != "" {
+				b = ct1.Value[j]
+			}
+
+			var out *ring.Poly
+			var ok bool
+			switch {
+			case i == "" && j == "":
+				out = tensorOut.Value0
+			case i == "" || j == "":
+				if out, ok = tensorOut.Value1[i+j]; !ok {
+					out = newPoly()
+					tensorOut.Value1[i+j] = out
+				}
+			default:
+				if out, ok = tensorOut.Value2[PairID(i, j)]; !ok {
+					out = newPoly()
+					tensorOut.Value2[PairID(i, j)] = out
+				}
+			}
+
+			ringQ.MulCoeffsMontgomeryAndAddLvl(level, eval.buffQ[0], b, out)
+		}
+	}
+}
+
+// Relinearize reduces the TensorCiphertext tensor to a Ciphertext of degree one and returns the result in ctOut.
+// The method requires the PublicKey and the RelinearizationKey of every party involved in a term of degree two.
+func (eval *Evaluator) Relinearize(tensor *TensorCiphertext, ctOut *Ciphertext) {
+
+	ringQ := eval.params.RingQ()
+
+	level := utils.MinInt(tensor.Level(), ctOut.Level())
+	isNTT := tensor.Value0.IsNTT
+
+	ids := tensor.IDs()
+
+	ring.CopyLvl(level, tensor.Value0, ctOut.Value0)
+	ctOut.Value0.IsNTT = isNTT
+
+	values := make(map[string]*ring.Poly, len(ids))
+	for _, id := range ids {
+		out := eval.outputPoly(ctOut, id, level, isNTT)
+		if c, ok := tensor.Value1[id]; ok {
+			ring.CopyLvl(level, c, out)
+		} else {
+			out.Zero()
+		}
+		values[id] = out
+	}
+	ctOut.Value = values
+
+	c1, c2, d0, d1 := eval.buffQ[0], eval.buffQ[1], eval.buffQ[2], eval.buffQ[3]
+
+	for pair, c := range tensor.Value2 {
+
+		i, j := pair[0], pair[1]
+
+		pk := eval.publicKey(j)
+		rlk := eval.relinearizationKey(i)
+
+		// c' = <g^-1(c_ij), b_j>
+		eval.eval.GadgetProduct(level, c, pk.Value, c1, c2)
+		c1.IsNTT = isNTT
+
+		// (c_0, c_i) += g^-1(c') * (d0_i, d1_i)
+		eval.eval.GadgetProduct(level, c1, rlk.Value[0], d0, d1)
+		ringQ.AddLvl(level, ctOut.Value0, d0, ctOut.Value0)
+		ringQ.AddLvl(level, ctOut.Value[i], d1, ctOut.Value[i])
+
+		// c_j += <g^-1(c_ij), d2_i>
+		eval.eval.GadgetProduct(level, c, rlk.Value[1], d0, d1)
+		ringQ.AddLvl(level, ctOut.Value[j], d0, ctOut.Value[j])
+	}
+
+	ctOut.Resize(level)
+}
+
+// MulRelin multiplies ct0 by ct1, relinearizes the result and returns it in ctOut.
+// The input Ciphertexts must be in the NTT domain and the result is not rescaled.
+func (eval *Evaluator) MulRelin(ct0, ct1, ctOut *Ciphertext) {
+	level := utils.MinInt(ct0.Level(), ct1.Level())
+	tensor := NewTensorCiphertext(eval.params, level)
+	eval.Tensor(ct0, ct1, tensor)
+	eval.Relinearize(tensor, ctOut)
+}
+
+// Automorphism computes phi(ct), where phi is the map X -> X^galEl. The method requires the rotation
+// key for galEl of every party involved in ctIn.
+func (eval *Evaluator) Automorphism(ctIn *Ciphertext, galEl uint64, ctOut *Ciphertext) {
+
+	ringQ := eval.params.RingQ()
+
+	level := utils.MinInt(ctIn.Level(), ctOut.Level())
+	isNTT := ctIn.Value0.IsNTT
+
+	if galEl == 1 {
+		if ctIn != ctOut {
+			eval.Add(ctIn, NewCiphertext(eval.params, nil, level), ctOut)
+		}
+		return
+	}
+
+	acc, c0, c1 := eval.buffQ[0], eval.buffQ[1], eval.buffQ[2]
+	acc.Zero()
+
+	in := ctIn.Value
+	values := make(map[string]*ring.Poly, len(in))
+
+	for _, id := range ctIn.IDs() {
+
+		rtk := eval.rotationKey(id, galEl)
+
+		eval.eval.GadgetProduct(level, in[id], rtk.GadgetCiphertext, c0, c1)
+		ringQ.AddLvl(level, acc, c0, acc)
+
+		out := eval.outputPoly(ctOut, id, level, isNTT)
+		eval.permute(level, c1, galEl, isNTT, out)
+		values[id] = out
+	}
+
+	ringQ.AddLvl(level, acc, ctIn.Value0, acc)
+	eval.permute(level, acc, galEl, isNTT, ctOut.Value0)
+	ctOut.Value0.IsNTT = isNTT
+
+	ctOut.Value = values
+	ctOut.Resize(level)
+}
+
+func (eval *Evaluator) permute(level int, polIn *ring.Poly, galEl uint64, isNTT bool, polOut *ring.Poly) {
+	ringQ := eval.params.RingQ()
+	if isNTT {
+		index, ok := eval.permuteNTTIndex[galEl]
+		if !ok {
+			index = ringQ.PermuteNTTIndex(galEl)
+		}
+		ringQ.PermuteNTTWithIndexLvl(level, polIn, index, polOut)
+	} else {
+		ringQ.PermuteLvl(level, polIn, galEl, polOut)
+	}
+}
+
+// outputPoly returns the component of ctOut for the party id, allocating it if it does not exist.
+func (eval *Evaluator) outputPoly(ctOut *Ciphertext, id string, level int, isNTT bool) (p *ring.Poly) {
+	var ok bool
+	if p, ok = ctOut.Value[id]; !ok || p.Level() < level {
+		p = eval.params.RingQ().NewPolyLvl(level)
+	}
+	p.IsNTT = isNTT
+	return
+}
+
+func (eval *Evaluator) publicKey(id string) *PublicKey {
+	if evk, ok := eval.keys[id]; ok && evk.Pk != nil {
+		return evk.Pk
+	}
+	panic(fmt.Sprintf("missing PublicKey of party %s", id))
+}
+
+func (eval *Evaluator) relinearizationKey(id string) *RelinearizationKey {
+	if evk, ok := eval.keys[id]; ok && evk.Rlk != nil {
+		return evk.Rlk
+	}
+	panic(fmt.Sprintf("missing RelinearizationKey of party %s", id))
+}
+
+func (eval *Evaluator) rotationKey(id string, galEl uint64) *rlwe.SwitchingKey {
+	if evk, ok := eval.keys[id]; ok {
+		if rtk, ok := evk.Rtks.GetRotationKey(galEl); ok {
+			return rtk
+		}
+	}
+	panic(fmt.Sprintf("missing rotation key for galEl %d of party %s", galEl, id))
+}
+
+func unionIDs(m0, m1 map[string]*ring.Poly) []string {
+	union := make(map[string]*ring.Poly, len(m0)+len(m1))
+	for id := range m0 {
+		union[id] = nil
+	}
+	for id := range m1 {
+		union[id] = nil
+	}
+	return sortedIDs(union)
+}
@@ -0,0 +1,128 @@
+package mkrlwe
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// KeyGenerator is a structure that stores the elements required to generate the keys of a party
+// in the multi-key setting. Each party generates its keys locally and independently, the only
+// common element being the CRP sampled from a common reference string.
+type KeyGenerator struct {
+	kgen            rlwe.KeyGenerator
+	params          rlwe.Parameters
+	gaussianSampler *ring.GaussianSampler
+	buffQ           *ring.Poly
+}
+
+// NewKeyGenerator creates a new KeyGenerator from the given parameters.
+func NewKeyGenerator(params rlwe.Parameters) *KeyGenerator {
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	return &KeyGenerator{
+		kgen:            rlwe.NewKeyGenerator(params),
+		params:          params,
+		gaussianSampler: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma())),
+		buffQ:           params.RingQ().NewPoly(),
+	}
+}
+
+// GenSecretKey generates a new secret key with the distribution of the parameters.
+func (kgen *KeyGenerator) GenSecretKey() *rlwe.SecretKey {
+	return kgen.kgen.GenSecretKey()
+}
+
+// SampleCRP samples the common reference polynomials from the provided common reference string.
+// All the parties must use the same CRP.
+func (kgen *KeyGenerator) SampleCRP(crs utils.PRNG) CRP {
+	params := kgen.params
+	decompRNS := params.DecompRNS(params.QCount()-1, params.PCount()-1)
+	decompPw2 := params.DecompPw2(params.QCount()-1, params.PCount()-1)
+
+	crp := make([][]ringqp.Poly, decompRNS)
+	us := ringqp.NewUniformSampler(crs, *params.RingQP())
+	for i := range crp {
+		crp[i] = make([]ringqp.Poly, decompPw2)
+		for j := range crp[i] {
+			crp[i][j] = params.RingQP().NewPoly()
+			us.Read(crp[i][j])
+		}
+	}
+	return CRP(crp)
+}
+
+// GenPublicKey generates the multi-key public key of the party id from its secret key and the CRP.
+func (kgen *KeyGenerator) GenPublicKey(id string, sk *rlwe.SecretKey, crp CRP) (pk *PublicKey) {
+	checkID(id)
+	pk = &PublicKey{ID: id, Value: *kgen.newGadgetCiphertext()}
+	kgen.genGadgetEncryptionOfZero(sk.Value, false, crp, pk.Value)
+	return
+}
+
+// GenRelinearizationKey generates the multi-key relinearization key of the party id from its secret key and the CRP.
+func (kgen *KeyGenerator) GenRelinearizationKey(id string, sk *rlwe.SecretKey, crp CRP) (rlk *RelinearizationKey) {
+
+	checkID(id)
+
+	r := kgen.GenSecretKey()
+
+	rlk = &RelinearizationKey{ID: id}
+
+	// (-s*d1 + r*P*g + e, d1)
+	rlk.Value[0] = kgen.kgen.GenSwitchingKey(r, sk).GadgetCiphertext
+
+	// (r*a + s*P*g + e, a)
+	rlk.Value[1] = *kgen.newGadgetCiphertext()
+	kgen.genGadgetEncryptionOfZero(r.Value, true, crp, rlk.Value[1])
+	rlwe.AddPolyTimesGadgetVectorToGadgetCiphertext(sk.Value.Q, []rlwe.GadgetCiphertext{rlk.Value[1]}, *kgen.params.RingQP(), kgen.params.Pow2Base(), kgen.buffQ)
+
+	return
+}
+
+// GenRotationKeys generates the rotation keys of the party id for the given Galois elements.
+func (kgen *KeyGenerator) GenRotationKeys(id string, galEls []uint64, sk *rlwe.SecretKey) (rtks *RotationKeySet) {
+	checkID(id)
+	return &RotationKeySet{ID: id, Value: kgen.kgen.GenRotationKeys(galEls, sk)}
+}
+
+func (kgen *KeyGenerator) newGadgetCiphertext() *rlwe.GadgetCiphertext {
+	params := kgen.params
+	levelQ, levelP := params.QCount()-1, params.PCount()-1
+	return rlwe.NewGadgetCiphertext(levelQ, levelP, params.DecompRNS(levelQ, levelP), params.DecompPw2(levelQ, levelP), *params.RingQP())
+}
+
+// genGadgetEncryptionOfZero generates (-a*sk + e, a) for each element a of the CRP, or (a*sk + e, a) if negate is true.
+func (kgen *KeyGenerator) genGadgetEncryptionOfZero(sk ringqp.Poly, negate bool, crp CRP, ct rlwe.GadgetCiphertext) {
+
+	ringQP := kgen.params.RingQP()
+	levelQ, levelP := ct.LevelQ(), ct.LevelP()
+
+	for i := range ct.Value {
+		for j := range ct.Value[i] {
+
+			c0, c1 := ct.Value[i][j].Value[0], ct.Value[i][j].Value[1]
+
+			// e
+			kgen.gaussianSampler.ReadLvl(levelQ, c0.Q)
+			if levelP != -1 {
+				ringQP.ExtendBasisSmallNormAndCenter(c0.Q, levelP, nil, c0.P)
+			}
+			ringQP.NTTLvl(levelQ, levelP, c0, c0)
+			ringQP.MFormLvl(levelQ, levelP, c0, c0)
+
+			// a
+			ringQP.CopyValuesLvl(levelQ, levelP, crp[i][j], c1)
+
+			// -a*sk + e or a*sk + e
+			if negate {
+				ringQP.MulCoeffsMontgomeryAndAddLvl(levelQ, levelP, c1, sk, c0)
+			} else {
+				ringQP.MulCoeffsMontgomeryAndSubLvl(levelQ, levelP, c1, sk, c0)
+			}
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package mkrlwe
+
+import (
+	"sort"
+
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
+)
+
+// CRP is a type for the common reference polynomials shared by all the parties of the multi-key setting.
+// It is the uniform gadget vector a used in the gadget public keys and relinearization keys.
+type CRP [][]ringqp.Poly
+
+// PublicKey is the multi-key public key of a party: a gadget encryption of zero (-s*a + e, a)
+// under the party's secret key s, where a is the CRP.
+type PublicKey struct {
+	ID    string
+	Value rlwe.GadgetCiphertext
+}
+
+// EncryptionKey returns the rlwe.PublicKey (-s*a_0 + e, a_0) contained in the first element of the
+// target PublicKey, which can be used to encrypt under the secret key of the party.
+func (pk *PublicKey) EncryptionKey() *rlwe.PublicKey {
+	return &rlwe.PublicKey{Value: pk.Value.Value[0][0].Value}
+}
+
+// RelinearizationKey is the multi-key relinearization key of a party, generated with an ephemeral secret r.
+// Value[0] is a gadget encryption of r under the party's secret key s (with a fresh random mask) and
+// Value[1] is a gadget encryption (r*a + s*g + e, a) of s under the secret -r, where a is the CRP.
+type RelinearizationKey struct {
+	ID    string
+	Value [2]rlwe.GadgetCiphertext
+}
+
+// RotationKeySet is the set of rotation keys of a party.
+type RotationKeySet struct {
+	ID    string
+	Value *rlwe.RotationKeySet
+}
+
+// EvaluationKey is the set of public evaluation keys of a single party.
+type EvaluationKey struct {
+	Pk   *PublicKey
+	Rlk  *RelinearizationKey
+	Rtks *RotationKeySet
+}
+
+// EvaluationKeySet is a set of EvaluationKey indexed by party ID.
+type EvaluationKeySet map[string]*EvaluationKey
+
+// Add adds the public keys of a party to the set. Any of the keys can be nil.
+func (evk EvaluationKeySet) Add(pk *PublicKey, rlk *RelinearizationKey, rtks *RotationKeySet) {
+
+	for _, id := range []string{idOf(pk), idOf(rlk), idOf(rtks)} {
+		if id == "" {
+			continue
+		}
+		if _, ok := evk[id]; !ok {
+			evk[id] = new(EvaluationKey)
+		}
+	}
+
+	if pk != nil {
+		evk[pk.ID].Pk = pk
+	}
+
+	if rlk != nil {
+		evk[rlk.ID].Rlk = rlk
+	}
+
+	if rtks != nil {
+		evk[rtks.ID].Rtks = rtks
+	}
+}
+
+func idOf(key interface{}) string {
+	switch key := key.(type) {
+	case *PublicKey:
+		if key != nil {
+			return key.ID
+		}
+	case *RelinearizationKey:
+		if key != nil {
+			return key.ID
+		}
+	case *RotationKeySet:
+		if key != nil {
+			return key.ID
+		}
+	}
+	return ""
+}
+
+// MarshalBinary encodes the target PublicKey on a slice of bytes.
+func (pk *PublicKey) MarshalBinary() (data []byte, err error) {
+	var gct []byte
+	if gct, err = pk.Value.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	return append(encodeID(pk.ID), gct...), nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target PublicKey.
+func (pk *PublicKey) UnmarshalBinary(data []byte) (err error) {
+	var pt int
+	if pk.ID, pt, err = decodeID(data); err != nil {
+		return err
+	}
+	return pk.Value.UnmarshalBinary(data[pt:])
+}
+
+// MarshalBinary encodes the target RelinearizationKey on a slice of bytes.
+func (rlk *RelinearizationKey) MarshalBinary() (data []byte, err error) {
+
+	data = encodeID(rlk.ID)
+
+	for i := range rlk.Value {
+		var gct []byte
+		if gct, err = rlk.Value[i].MarshalBinary(); err != nil {
+			return nil, err
+		}
+		data = append(data, encodeLength(len(gct))...)
+		data = append(data, gct...)
+	}
+
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target RelinearizationKey.
+func (rlk *RelinearizationKey) UnmarshalBinary(data []byte) (err error) {
+
+	var pt int
+	if rlk.ID, pt, err = decodeID(data); err != nil {
+		return err
+	}
+
+	for i := range rlk.Value {
+		var n, inc int
+		if n, inc, err = decodeLength(data[pt:]); err != nil {
+			return err
+		}
+		pt += inc
+		if len(data[pt:]) < n {
+			return errShortBuffer
+		}
+		if err = rlk.Value[i].UnmarshalBinary(data[pt : pt+n]); err != nil {
+			return err
+		}
+		pt += n
+	}
+
+	return
+}
+
+// MarshalBinary encodes the target RotationKeySet on a slice of bytes.
+func (rtks *RotationKeySet) MarshalBinary() (data []byte, err error) {
+	var rtksData []byte
+	if rtksData, err = rtks.Value.MarshalBinary(); err != nil {
+		return nil, err
+	}
+	return append(encodeID(rtks.ID), rtksData...), nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target RotationKeySet.
+func (rtks *RotationKeySet) UnmarshalBinary(data []byte) (err error) {
+	var pt int
+	if rtks.ID, pt, err = decodeID(data); err != nil {
+		return err
+	}
+	rtks.Value = new(rlwe.RotationKeySet)
+	return rtks.Value.UnmarshalBinary(data[pt:])
+}
+
+// GetRotationKey returns the rotation key of the party for the given Galois element.
+func (rtks *RotationKeySet) GetRotationKey(galEl uint64) (*rlwe.SwitchingKey, bool) {
+	if rtks == nil || rtks.Value == nil {
+		return nil, false
+	}
+	return rtks.Value.GetRotationKey(galEl)
+}
+
+// GaloisElements returns the Galois elements for which the party has rotation keys.
+func (rtks *RotationKeySet) GaloisElements() (galEls []uint64) {
+	if rtks == nil || rtks.Value == nil {
+		return
+	}
+	galEls = make([]uint64, 0, len(rtks.Value.Keys))
+	for galEl := range rtks.Value.Keys {
+		galEls = append(galEls, galEl)
+	}
+	sort.Slice(galEls, func(i, j int) bool { return galEls[i] < galEls[j] })
+	return
+}
@@ -0,0 +1,375 @@
+package mkrlwe
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+var flagParamString = flag.String("params", "", "specify the test cryptographic parameters as a JSON string. Overrides -short and -long.")
+
+var partyIDs = []string{"alice", "bob", "carol"}
+
+func testString(params rlwe.Parameters, opname string) string {
+	return fmt.Sprintf("%s/logN=%d/logQ=%d/logP=%d/#Qi=%d/#Pi=%d/parties=%d",
+		opname,
+		params.LogN(),
+		params.LogQ(),
+		params.LogP(),
+		params.QCount(),
+		params.PCount(),
+		len(partyIDs))
+}
+
+// TestParams is a set of test parameters for the correctness of the mkrlwe package.
+var TestParams = []rlwe.ParametersLiteral{rlwe.TestPN12QP109, rlwe.TestPN13QP218, rlwe.TestPN14QP438}
+
+type testContext struct {
+	params rlwe.Parameters
+	kgen   *KeyGenerator
+	sk     map[string]*rlwe.SecretKey
+	pk     map[string]*PublicKey
+	rlk    map[string]*RelinearizationKey
+	rtks   map[string]*RotationKeySet
+	evk    EvaluationKeySet
+	galEl  uint64
+	eval   *Evaluator
+	dec    *DecryptionProtocol
+}
+
+func newTestContext(params rlwe.Parameters) (testCtx *testContext) {
+
+	testCtx = &testContext{
+		params: params,
+		kgen:   NewKeyGenerator(params),
+		sk:     make(map[string]*rlwe.SecretKey),
+		pk:     make(map[string]*PublicKey),
+		rlk:    make(map[string]*RelinearizationKey),
+		rtks:   make(map[string]*RotationKeySet),
+		evk:    make(EvaluationKeySet),
+		galEl:  params.GaloisElementForColumnRotationBy(1),
+	}
+
+	prng, _ := utils.NewKeyedPRNG([]byte{'t', 'e', 's', 't'})
+	crp := testCtx.kgen.SampleCRP(prng)
+
+	for _, id := range partyIDs {
+		testCtx.sk[id] = testCtx.kgen.GenSecretKey()
+		testCtx.pk[id] = testCtx.kgen.GenPublicKey(id, testCtx.sk[id], crp)
+		testCtx.rlk[id] = testCtx.kgen.GenRelinearizationKey(id, testCtx.sk[id], crp)
+		testCtx.rtks[id] = testCtx.kgen.GenRotationKeys(id, []uint64{testCtx.galEl}, testCtx.sk[id])
+		testCtx.evk.Add(testCtx.pk[id], testCtx.rlk[id], testCtx.rtks[id])
+	}
+
+	testCtx.eval = NewEvaluator(params, testCtx.evk)
+	testCtx.dec = NewDecryptionProtocol(params, 3.2)
+
+	return
+}
+
+func TestMKRLWE(t *testing.T) {
+
+	var err error
+
+	defaultParams := TestParams
+	if testing.Short() {
+		defaultParams = TestParams[:1]
+	}
+
+	if *flagParamString != "" {
+		var jsonParams rlwe.ParametersLiteral
+		if err = json.Unmarshal([]byte(*flagParamString), &jsonParams); err != nil {
+			t.Fatal(err)
+		}
+		defaultParams = []rlwe.ParametersLiteral{jsonParams} // the custom test suite reads the parameters from the -params flag
+	}
+
+	for _, defaultParam := range defaultParams {
+		var params rlwe.Parameters
+		if params, err = rlwe.NewParametersFromLiteral(defaultParam); err != nil {
+			t.Fatal(err)
+		}
+
+		testCtx := newTestContext(params)
+
+		for _, testSet := range []func(testCtx *testContext, t *testing.T){
+			testEncryptAndDecrypt,
+			testAdd,
+			testRelinearize,
+			testAutomorphism,
+			testMarshalling,
+		} {
+			testSet(testCtx, t)
+			runtime.GC()
+		}
+	}
+}
+
+// newTestCiphertext returns a single-party encryption of zero of the given party.
+func newTestCiphertext(testCtx *testContext, id string) *Ciphertext {
+	params := testCtx.params
+	ct := rlwe.NewCiphertextNTT(params, 1, params.MaxLevel())
+	rlwe.NewEncryptor(params, testCtx.pk[id].EncryptionKey()).EncryptZero(ct)
+	return NewCiphertextFromRLWE(id, ct)
+}
+
+// decryptIdeal decrypts the Ciphertext with the secret keys of all the parties.
+func decryptIdeal(testCtx *testContext, ct *Ciphertext) (pt *ring.Poly) {
+	ringQ := testCtx.params.RingQ()
+	level := ct.Level()
+	pt = ct.Value0.CopyNew()
+	for id, c := range ct.Value {
+		ringQ.MulCoeffsMontgomeryAndAddLvl(level, c, testCtx.sk[id].Value.Q, pt)
+	}
+	return
+}
+
+// decryptTensorIdeal decrypts the TensorCiphertext with the secret keys of all the parties.
+func decryptTensorIdeal(testCtx *testContext, tensor *TensorCiphertext) (pt *ring.Poly) {
+	ringQ := testCtx.params.RingQ()
+	level := tensor.Level()
+	pt = tensor.Value0.CopyNew()
+	for id, c := range tensor.Value1 {
+		ringQ.MulCoeffsMontgomeryAndAddLvl(level, c, testCtx.sk[id].Value.Q, pt)
+	}
+	tmp := ringQ.NewPolyLvl(level)
+	for pair, c := range tensor.Value2 {
+		ringQ.MulCoeffsMontgomeryLvl(level, c, testCtx.sk[pair[0]].Value.Q, tmp)
+		ringQ.MulCoeffsMontgomeryAndAddLvl(level, tmp, testCtx.sk[pair[1]].Value.Q, pt)
+	}
+	return
+}
+
+func testEncryptAndDecrypt(testCtx *testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQ := params.RingQ()
+
+	t.Run(testString(params, "EncryptAndDecrypt"), func(t *testing.T) {
+
+		ct := newTestCiphertext(testCtx, partyIDs[0])
+		for _, id := range partyIDs[1:] {
+			testCtx.eval.Add(ct, newTestCiphertext(testCtx, id), ct)
+		}
+
+		require.Equal(t, partyIDs, ct.IDs())
+
+		shares := make(map[string]*drlwe.CKSShare)
+		for _, id := range partyIDs {
+			shares[id] = testCtx.dec.AllocateShare(ct.Level())
+			testCtx.dec.GenShare(id, testCtx.sk[id], ct, shares[id])
+		}
+
+		pt := rlwe.NewPlaintext(params, ct.Level())
+		testCtx.dec.Merge(ct, shares, pt)
+
+		require.True(t, pt.Value.IsNTT)
+		ringQ.InvNTTLvl(pt.Level(), pt.Value, pt.Value)
+
+		log2Bound := bits.Len64(uint64(len(partyIDs)) * uint64(params.N()) * uint64(math.Floor(rlwe.DefaultSigma*6)) * uint64(params.N()))
+		require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(pt.Level(), ringQ, pt.Value))
+	})
+}
+
+func testAdd(testCtx *testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQ := params.RingQ()
+
+	t.Run(testString(params, "Add"), func(t *testing.T) {
+
+		ct0 := newTestCiphertext(testCtx, partyIDs[0])
+		ct1 := newTestCiphertext(testCtx, partyIDs[1])
+
+		have := NewCiphertextNTT(params, nil, params.MaxLevel())
+		testCtx.eval.Add(ct0, ct1, have)
+		require.Equal(t, partyIDs[:2], have.IDs())
+
+		want := decryptIdeal(testCtx, ct0)
+		ringQ.Add(want, decryptIdeal(testCtx, ct1), want)
+		require.True(t, ringQ.Equal(want, decryptIdeal(testCtx, have)))
+
+		testCtx.eval.Sub(have, ct1, have)
+		require.True(t, ringQ.Equal(decryptIdeal(testCtx, ct0), decryptIdeal(testCtx, have)))
+	})
+}
+
+func testRelinearize(testCtx *testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQ := params.RingQ()
+
+	t.Run(testString(params, "Relinearize"), func(t *testing.T) {
+
+		ct0 := newTestCiphertext(testCtx, partyIDs[0])
+		ct1 := newTestCiphertext(testCtx, partyIDs[1])
+		testCtx.eval.Add(ct1, newTestCiphertext(testCtx, partyIDs[2]), ct1)
+
+		tensor := NewTensorCiphertext(params, params.MaxLevel())
+		testCtx.eval.Tensor(ct0, ct1, tensor)
+		require.Equal(t, partyIDs, tensor.IDs())
+
+		ct := NewCiphertextNTT(params, nil, params.MaxLevel())
+		testCtx.eval.Relinearize(tensor, ct)
+		require.Equal(t, partyIDs, ct.IDs())
+
+		have := decryptIdeal(testCtx, ct)
+		ringQ.Sub(have, decryptTensorIdeal(testCtx, tensor), have)
+		ringQ.InvNTT(have, have)
+
+		log2Bound := bits.Len64(uint64(len(partyIDs)*len(partyIDs)) * uint64(params.N()*params.N()) * uint64(math.Floor(rlwe.DefaultSigma*6)) * uint64(params.DecompRNS(params.MaxLevel(), params.PCount()-1)))
+		require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(ct.Level(), ringQ, have))
+	})
+}
+
+func testAutomorphism(testCtx *testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQ := params.RingQ()
+
+	t.Run(testString(params, "Automorphism"), func(t *testing.T) {
+
+		ct := newTestCiphertext(testCtx, partyIDs[0])
+		for _, id := range partyIDs[1:] {
+			testCtx.eval.Add(ct, newTestCiphertext(testCtx, id), ct)
+		}
+
+		want := ringQ.NewPoly()
+		ringQ.PermuteNTTWithIndexLvl(ct.Level(), decryptIdeal(testCtx, ct), ringQ.PermuteNTTIndex(testCtx.galEl), want)
+
+		testCtx.eval.Automorphism(ct, testCtx.galEl, ct)
+
+		have := decryptIdeal(testCtx, ct)
+		ringQ.Sub(have, want, have)
+		ringQ.InvNTT(have, have)
+
+		log2Bound := bits.Len64(uint64(len(partyIDs)) * uint64(params.N()*params.N()) * uint64(math.Floor(rlwe.DefaultSigma*6)) * uint64(params.DecompRNS(params.MaxLevel(), params.PCount()-1)))
+		require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(ct.Level(), ringQ, have))
+	})
+}
+
+func testMarshalling(testCtx *testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	t.Run(testString(params, "Marshalling/Ciphertext"), func(t *testing.T) {
+		ct := newTestCiphertext(testCtx, partyIDs[0])
+		testCtx.eval.Add(ct, newTestCiphertext(testCtx, partyIDs[1]), ct)
+
+		data, err := ct.MarshalBinary()
+		require.NoError(t, err)
+
+		ctNew := new(Ciphertext)
+		require.NoError(t, ctNew.UnmarshalBinary(data))
+		require.True(t, ct.Value0.Equals(ctNew.Value0))
+		require.Equal(t, ct.IDs(), ctNew.IDs())
+		for id := range ct.Value {
+			require.True(t, ct.Value[id].Equals(ctNew.Value[id]))
+		}
+	})
+
+	t.Run(testString(params, "Marshalling/PublicKey"), func(t *testing.T) {
+		pk := testCtx.pk[partyIDs[0]]
+
+		data, err := pk.MarshalBinary()
+		require.NoError(t, err)
+
+		pkNew := new(PublicKey)
+		require.NoError(t, pkNew.UnmarshalBinary(data))
+		require.Equal(t, pk.ID, pkNew.ID)
+		require.True(t, pk.Value.Equals(&pkNew.Value))
+	})
+
+	t.Run(testString(params, "Marshalling/RelinearizationKey"), func(t *testing.T) {
+		rlk := testCtx.rlk[partyIDs[0]]
+
+		data, err := rlk.MarshalBinary()
+		require.NoError(t, err)
+
+		rlkNew := new(RelinearizationKey)
+		require.NoError(t, rlkNew.UnmarshalBinary(data))
+		require.Equal(t, rlk.ID, rlkNew.ID)
+		require.True(t, rlk.Value[0].Equals(&rlkNew.Value[0]))
+		require.True(t, rlk.Value[1].Equals(&rlkNew.Value[1]))
+	})
+
+	t.Run(testString(params, "Marshalling/RotationKeySet"), func(t *testing.T) {
+		rtks := testCtx.rtks[partyIDs[0]]
+
+		data, err := rtks.MarshalBinary()
+		require.NoError(t, err)
+
+		rtksNew := new(RotationKeySet)
+		require.NoError(t, rtksNew.UnmarshalBinary(data))
+		require.Equal(t, rtks.ID, rtksNew.ID)
+		require.True(t, rtks.Value.Equals(rtksNew.Value))
+	})
+}
+
+// Returns the ceil(log2) of the sum of the absolute value of all the coefficients
+func log2OfInnerSum(level int, ringQ *ring.Ring, poly *ring.Poly) (logSum int) {
+	sumRNS := make([]uint64, level+1)
+	var sum uint64
+	for i := 0; i < level+1; i++ {
+
+		qi := ringQ.Modulus[i]
+		qiHalf := qi >> 1
+		coeffs := poly.Coeffs[i]
+		sum = 0
+
+		for j := 0; j < ringQ.N; j++ {
+
+			v := coeffs[j]
+
+			if v >= qiHalf {
+				sum = ring.CRed(sum+qi-v, qi)
+			} else {
+				sum = ring.CRed(sum+v, qi)
+			}
+		}
+
+		sumRNS[i] = sum
+	}
+
+	var smallNorm = true
+	for i := 1; i < level+1; i++ {
+		smallNorm = smallNorm && (sumRNS[0] == sumRNS[i])
+	}
+
+	if !smallNorm {
+		var crtReconstruction *big.Int
+
+		sumBigInt := ring.NewUint(0)
+		QiB := new(big.Int)
+		tmp := new(big.Int)
+		modulusBigint := ringQ.ModulusAtLevel[level]
+
+		for i := 0; i < level+1; i++ {
+			QiB.SetUint64(ringQ.Modulus[i])
+			crtReconstruction = new(big.Int).Quo(modulusBigint, QiB)
+			tmp.ModInverse(crtReconstruction, QiB)
+			tmp.Mod(tmp, QiB)
+			crtReconstruction.Mul(crtReconstruction, tmp)
+			sumBigInt.Add(sumBigInt, tmp.Mul(ring.NewUint(sumRNS[i]), crtReconstruction))
+		}
+
+		sumBigInt.Mod(sumBigInt, modulusBigint)
+
+		logSum = sumBigInt.BitLen()
+	} else {
+		logSum = bits.Len64(sumRNS[0])
+	}
+
+	return
+}
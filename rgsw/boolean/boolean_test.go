@@ -0,0 +1,205 @@
+package boolean
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+func testString(params Parameters, opname string) string {
+	return fmt.Sprintf("%s/logNLUT=%d/logNLWE=%d/logQLWE=%d/logBaseKS=%d",
+		opname,
+		params.LUT().LogN(),
+		params.LWE().LogN(),
+		params.LWE().LogQ(),
+		params.LogBaseKS())
+}
+
+type testContext struct {
+	params Parameters
+	sk     *SecretKey
+	key    *BootstrappingKey
+	enc    *Encryptor
+	dec    *Decryptor
+	eval   *Evaluator
+}
+
+func newTestContext(params Parameters) (tc *testContext) {
+	kgen := NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	key := kgen.GenBootstrappingKey(sk)
+	return &testContext{
+		params: params,
+		sk:     sk,
+		key:    key,
+		enc:    NewEncryptor(params, sk),
+		dec:    NewDecryptor(params, sk),
+		eval:   NewEvaluator(params, key),
+	}
+}
+
+// TestBoolean tests the boolean gates.
+func TestBoolean(t *testing.T) {
+
+	params, err := NewParametersFromLiteral(ParametersN10N10)
+	require.NoError(t, err)
+
+	tc := newTestContext(params)
+
+	for _, testSet := range []func(tc *testContext, t *testing.T){
+		testParameters,
+		testEncryptor,
+		testGates,
+		testCircuit,
+		testMarshaller,
+	} {
+		testSet(tc, t)
+		runtime.GC()
+	}
+}
+
+func testParameters(tc *testContext, t *testing.T) {
+	t.Run(testString(tc.params, "Parameters/Security"), func(t *testing.T) {
+
+		// LWE switching key modulo 2^27 in dimension 2^9
+		pl := ParametersN10N10
+		pl.LWE = rlwe.ParametersLiteral{LogN: 9, LogQ: []int{27}}
+		_, err := NewParametersFromLiteral(pl)
+		require.Error(t, err)
+
+		// LUT key modulo 2^40 in dimension 2^10
+		pl = ParametersN10N10
+		pl.LUT = rlwe.ParametersLiteral{LogN: 10, LogQ: []int{40}, Pow2Base: 6}
+		_, err = NewParametersFromLiteral(pl)
+		require.Error(t, err)
+
+		require.Equal(t, tc.params.Q(), tc.key.SwitchingKey.Q())
+	})
+}
+
+func testEncryptor(tc *testContext, t *testing.T) {
+	t.Run(testString(tc.params, "Encryptor"), func(t *testing.T) {
+		for _, bit := range []bool{false, true} {
+			require.Equal(t, bit, tc.dec.Decrypt(tc.enc.EncryptNew(bit)))
+			require.Equal(t, bit, tc.dec.Decrypt(tc.eval.Bootstrap(tc.enc.EncryptNew(bit))))
+			require.Equal(t, !bit, tc.dec.Decrypt(tc.eval.Not(tc.enc.EncryptNew(bit))))
+		}
+	})
+}
+
+func testGates(tc *testContext, t *testing.T) {
+
+	eval := tc.eval
+
+	gates := []struct {
+		name  string
+		gate  func(ct0, ct1 *rlwe.LWECiphertext) *rlwe.LWECiphertext
+		truth func(b0, b1 bool) bool
+	}{
+		{"And", eval.And, func(b0, b1 bool) bool { return b0 && b1 }},
+		{"Or", eval.Or, func(b0, b1 bool) bool { return b0 || b1 }},
+		{"Nand", eval.Nand, func(b0, b1 bool) bool { return !(b0 && b1) }},
+		{"Nor", eval.Nor, func(b0, b1 bool) bool { return !(b0 || b1) }},
+		{"Xor", eval.Xor, func(b0, b1 bool) bool { return b0 != b1 }},
+		{"Xnor", eval.Xnor, func(b0, b1 bool) bool { return b0 == b1 }},
+	}
+
+	for _, g := range gates {
+		t.Run(testString(tc.params, "Gates/"+g.name), func(t *testing.T) {
+			for _, b0 := range []bool{false, true} {
+				for _, b1 := range []bool{false, true} {
+					ct := g.gate(tc.enc.EncryptNew(b0), tc.enc.EncryptNew(b1))
+					require.Equal(t, g.truth(b0, b1), tc.dec.Decrypt(ct), "%s(%t, %t)", g.name, b0, b1)
+				}
+			}
+		})
+	}
+
+	t.Run(testString(tc.params, "Gates/Mux"), func(t *testing.T) {
+		for _, sel := range []bool{false, true} {
+			for _, b1 := range []bool{false, true} {
+				for _, b0 := range []bool{false, true} {
+
+					ct := eval.Mux(tc.enc.EncryptNew(sel), tc.enc.EncryptNew(b1), tc.enc.EncryptNew(b0))
+
+					want := b0
+					if sel {
+						want = b1
+					}
+
+					require.Equal(t, want, tc.dec.Decrypt(ct), "Mux(%t, %t, %t)", sel, b1, b0)
+				}
+			}
+		}
+	})
+
+	t.Run(testString(tc.params, "Gates/InvalidOperand"), func(t *testing.T) {
+		require.Panics(t, func() { eval.And(tc.enc.EncryptNew(true), rlwe.NewLWECiphertext(tc.params.N()+1, tc.params.Q())) })
+	})
+}
+
+// testCircuit evaluates a ripple-carry adder of 2-bit integers, whose gates consume the outputs of other gates.
+func testCircuit(tc *testContext, t *testing.T) {
+
+	t.Run(testString(tc.params, "Circuit/Adder"), func(t *testing.T) {
+
+		eval := tc.eval
+
+		x, y := 3, 2
+
+		ctX := []*rlwe.LWECiphertext{tc.enc.EncryptNew(x&1 == 1), tc.enc.EncryptNew(x&2 == 2)}
+		ctY := []*rlwe.LWECiphertext{tc.enc.EncryptNew(y&1 == 1), tc.enc.EncryptNew(y&2 == 2)}
+
+		carry := tc.enc.EncryptNew(false)
+		sum := make([]*rlwe.LWECiphertext, 3)
+
+		for i := range ctX {
+			p := eval.Xor(ctX[i], ctY[i])
+			sum[i] = eval.Xor(p, carry)
+			carry = eval.Or(eval.And(ctX[i], ctY[i]), eval.And(p, carry))
+		}
+
+		sum[2] = carry
+
+		var res int
+		for i, ct := range sum {
+			if tc.dec.Decrypt(ct) {
+				res |= 1 << i
+			}
+		}
+
+		require.Equal(t, x+y, res)
+	})
+}
+
+func testMarshaller(tc *testContext, t *testing.T) {
+
+	t.Run(testString(tc.params, "Marshaller/BootstrappingKey"), func(t *testing.T) {
+
+		data, err := tc.key.MarshalBinary()
+		require.NoError(t, err)
+
+		key := new(BootstrappingKey)
+		require.NoError(t, key.UnmarshalBinary(data))
+		require.NoError(t, key.Validate(tc.params))
+
+		require.Equal(t, tc.key.SwitchingKey, key.SwitchingKey)
+		for i := range key.LUTKey.SkPos {
+			for j := range key.LUTKey.SkPos[i].Value {
+				require.True(t, tc.key.LUTKey.SkPos[i].Value[j].Equals(&key.LUTKey.SkPos[i].Value[j]))
+				require.True(t, tc.key.LUTKey.SkNeg[i].Value[j].Equals(&key.LUTKey.SkNeg[i].Value[j]))
+			}
+		}
+
+		// the decoded key bootstraps
+		eval := NewEvaluator(tc.params, key)
+		require.True(t, tc.dec.Decrypt(eval.Nand(tc.enc.EncryptNew(true), tc.enc.EncryptNew(false))))
+
+		require.ErrorIs(t, key.UnmarshalBinary(data[:len(data)-1]), rlwe.ErrMalformed)
+		require.ErrorIs(t, key.UnmarshalBinary(data[:len(data)/2]), rlwe.ErrMalformed)
+	})
+}
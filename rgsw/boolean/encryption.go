@@ -0,0 +1,56 @@
+package boolean
+
+import (
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// Encryptor is a structure encrypting bits as LWE ciphertexts.
+// A bit is encoded as Q/8 if it is true and as -Q/8 otherwise.
+type Encryptor struct {
+	params    Parameters
+	encryptor rlwe.Encryptor
+	eval      *rlwe.Evaluator
+	pt        *rlwe.Plaintext
+	ct        *rlwe.Ciphertext
+}
+
+// NewEncryptor creates a new Encryptor encrypting under the LWE secret of sk.
+func NewEncryptor(params Parameters, sk *SecretKey) *Encryptor {
+	return &Encryptor{
+		params:    params,
+		encryptor: rlwe.NewEncryptor(params.LWE(), sk.LWE),
+		eval:      rlwe.NewEvaluator(params.LWE(), nil),
+		pt:        rlwe.NewPlaintext(params.LWE(), 0),
+		ct:        rlwe.NewCiphertext(params.LWE(), 1, 0),
+	}
+}
+
+// EncryptNew encrypts the bit and returns the result on a new LWE ciphertext.
+func (enc *Encryptor) EncryptNew(bit bool) (ct *rlwe.LWECiphertext) {
+
+	if bit {
+		enc.pt.Value.Coeffs[0][0] = enc.params.scaledConstant(1)
+	} else {
+		enc.pt.Value.Coeffs[0][0] = enc.params.scaledConstant(-1)
+	}
+
+	enc.encryptor.Encrypt(enc.pt, enc.ct)
+
+	return enc.eval.SampleExtract(enc.ct, 0)
+}
+
+// Decryptor is a structure decrypting LWE encryptions of bits.
+type Decryptor struct {
+	params Parameters
+	sk     *rlwe.LWESecretKey
+}
+
+// NewDecryptor creates a new Decryptor decrypting with the LWE secret of sk.
+func NewDecryptor(params Parameters, sk *SecretKey) *Decryptor {
+	return &Decryptor{params: params, sk: rlwe.NewLWESecretKeyFromSecretKey(params.LWE(), sk.LWE)}
+}
+
+// Decrypt decrypts the LWE encryption of a bit.
+func (dec *Decryptor) Decrypt(ct *rlwe.LWECiphertext) (bit bool) {
+	return dec.sk.Decrypt(ct) < dec.params.Q()>>1
+}
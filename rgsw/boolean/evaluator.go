@@ -0,0 +1,155 @@
+package boolean
+
+import (
+	"github.com/tuneinsight/lattigo/v3/rgsw/lut"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// Evaluator is a structure evaluating boolean gates on LWE encryptions of bits.
+// All the gates, except Not, bootstrap their output.
+type Evaluator struct {
+	params   Parameters
+	key      *BootstrappingKey
+	evalLUT  *lut.Evaluator
+	evalRLWE *rlwe.Evaluator
+	testPoly *ring.Poly
+}
+
+// NewEvaluator creates a new Evaluator bootstrapping with the provided BootstrappingKey.
+func NewEvaluator(params Parameters, key *BootstrappingKey) *Evaluator {
+
+	ringQ := params.LUT().RingQ()
+
+	// The constant coefficient of testPoly * X^{k} is Q/8 for 0 <= k < N and -Q/8 for N <= k < 2N,
+	// that is, the sign of the LWE ciphertext of which k is the phase modulo 2N.
+	testPoly := ringQ.NewPoly()
+	q := ringQ.Modulus[0] >> 3
+	for j, qj := range ringQ.Modulus {
+		testPoly.Coeffs[j][0] = q % qj
+		for i := 1; i < ringQ.N; i++ {
+			testPoly.Coeffs[j][i] = qj - q%qj
+		}
+	}
+	ringQ.NTT(testPoly, testPoly)
+
+	return &Evaluator{
+		params:   params,
+		key:      key,
+		evalLUT:  lut.NewEvaluator(params.LUT(), params.LWE(), nil),
+		evalRLWE: rlwe.NewEvaluator(params.LUT(), nil),
+		testPoly: testPoly,
+	}
+}
+
+// Bootstrap returns a fresh LWE encryption of the sign of the plaintext of ct, that is, of Q/8 if its plaintext
+// is in [0, Q/2) and of -Q/8 otherwise.
+func (eval *Evaluator) Bootstrap(ct *rlwe.LWECiphertext) (ctOut *rlwe.LWECiphertext) {
+	eval.checkOperand("Bootstrap", ct)
+	return eval.ProgrammableBootstrap(ct, eval.testPoly)
+}
+
+// ProgrammableBootstrap evaluates the LUT lutPoly, in the NTT domain of the ring Q of the LUT parameters (see lut.InitLUT),
+// on ct and returns a fresh LWE encryption of the result under the LWE secret. A value v * Q'/d of the LUT, where Q' is the
+// first modulus of the LUT parameters, is returned as an encryption of v * Q/d.
+func (eval *Evaluator) ProgrammableBootstrap(ct *rlwe.LWECiphertext, lutPoly *ring.Poly) (ctOut *rlwe.LWECiphertext) {
+
+	acc := eval.evalLUT.EvaluateLWE(ct, lutPoly, eval.key.LUTKey)
+	acc.Resize(1, 0)
+
+	// The key switching is done modulo the small modulus of the LWE parameters, for which the switching key is secure.
+	ctOut = eval.evalRLWE.SampleExtract(acc, 0)
+	ctOut.ModSwitch(eval.params.Q(), ctOut)
+	eval.evalRLWE.SwitchKeysLWE(ctOut, eval.key.SwitchingKey, ctOut)
+
+	return
+}
+
+// And returns a bootstrapped encryption of ct0 AND ct1.
+func (eval *Evaluator) And(ct0, ct1 *rlwe.LWECiphertext) *rlwe.LWECiphertext {
+	return eval.gate("And", -1, 1, ct0, ct1)
+}
+
+// Or returns a bootstrapped encryption of ct0 OR ct1.
+func (eval *Evaluator) Or(ct0, ct1 *rlwe.LWECiphertext) *rlwe.LWECiphertext {
+	return eval.gate("Or", 1, 1, ct0, ct1)
+}
+
+// Nand returns a bootstrapped encryption of NOT(ct0 AND ct1).
+func (eval *Evaluator) Nand(ct0, ct1 *rlwe.LWECiphertext) *rlwe.LWECiphertext {
+	return eval.gate("Nand", 1, -1, ct0, ct1)
+}
+
+// Nor returns a bootstrapped encryption of NOT(ct0 OR ct1).
+func (eval *Evaluator) Nor(ct0, ct1 *rlwe.LWECiphertext) *rlwe.LWECiphertext {
+	return eval.gate("Nor", -1, -1, ct0, ct1)
+}
+
+// Xor returns a bootstrapped encryption of ct0 XOR ct1.
+func (eval *Evaluator) Xor(ct0, ct1 *rlwe.LWECiphertext) *rlwe.LWECiphertext {
+	return eval.gate("Xor", 2, 2, ct0, ct1)
+}
+
+// Xnor returns a bootstrapped encryption of NOT(ct0 XOR ct1).
+func (eval *Evaluator) Xnor(ct0, ct1 *rlwe.LWECiphertext) *rlwe.LWECiphertext {
+	return eval.gate("Xnor", -2, -2, ct0, ct1)
+}
+
+// Not returns an encryption of NOT ct. The negation does not increase the error and is not bootstrapped.
+func (eval *Evaluator) Not(ct *rlwe.LWECiphertext) (ctOut *rlwe.LWECiphertext) {
+	eval.checkOperand("Not", ct)
+	return eval.linear(0, -1, ct)
+}
+
+// Mux returns a bootstrapped encryption of ct1 if sel is true and of ct0 otherwise.
+// The multiplexer is evaluated with three bootstrappings as (sel AND ct1) OR (NOT(sel) AND ct0).
+func (eval *Evaluator) Mux(sel, ct1, ct0 *rlwe.LWECiphertext) *rlwe.LWECiphertext {
+
+	eval.checkOperand("Mux", ct0)
+
+	u1 := eval.gate("Mux", -1, 1, sel, ct1)
+	u0 := eval.Bootstrap(eval.linear(-1, 1, eval.linear(0, -1, sel), ct0))
+
+	return eval.Bootstrap(eval.linear(1, 1, u1, u0))
+}
+
+// gate returns the bootstrapping of k * Q/8 + w * (ct0 + ct1).
+func (eval *Evaluator) gate(op string, k, w int64, ct0, ct1 *rlwe.LWECiphertext) *rlwe.LWECiphertext {
+	eval.checkOperand(op, ct0)
+	eval.checkOperand(op, ct1)
+	return eval.Bootstrap(eval.linear(k, w, ct0, ct1))
+}
+
+// linear returns a new LWE ciphertext k * Q/8 + w * sum(cts).
+func (eval *Evaluator) linear(k, w int64, cts ...*rlwe.LWECiphertext) (ctOut *rlwe.LWECiphertext) {
+
+	Q := eval.params.Q()
+
+	wQ := uint64(w)
+	if w < 0 {
+		wQ = Q - uint64(-w)
+	}
+
+	bredParams := ring.BRedParams(Q)
+
+	ctOut = rlwe.NewLWECiphertext(eval.params.N(), Q)
+	ctOut.B = eval.params.scaledConstant(k)
+
+	for _, ct := range cts {
+
+		ctOut.B = ring.CRed(ctOut.B+ring.BRed(ct.B, wQ, Q, bredParams), Q)
+
+		for i, a := range ct.A {
+			ctOut.A[i] = ring.CRed(ctOut.A[i]+ring.BRed(a, wQ, Q, bredParams), Q)
+		}
+	}
+
+	return
+}
+
+// checkOperand panics if ct is not an LWE ciphertext of the dimension and modulus of the parameters.
+func (eval *Evaluator) checkOperand(op string, ct *rlwe.LWECiphertext) {
+	if ct == nil || ct.N() != eval.params.N() || ct.Q != eval.params.Q() {
+		panic(rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "operand is not a LWE ciphertext of dimension %d modulo %d", eval.params.N(), eval.params.Q()))
+	}
+}
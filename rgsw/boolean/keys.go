@@ -0,0 +1,133 @@
+package boolean
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/rgsw"
+	"github.com/tuneinsight/lattigo/v3/rgsw/lut"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// SecretKey is the secret key of the boolean gates.
+type SecretKey struct {
+	LWE *rlwe.SecretKey // secret of the LWE encryptions of the bits
+	LUT *rlwe.SecretKey // secret of the RLWE ciphertexts of the blind rotation
+}
+
+// BootstrappingKey is the public key used to bootstrap the output of the gates.
+type BootstrappingKey struct {
+	LUTKey       lut.EvaluationKey     // RGSW encryptions of the LWE secret under the LUT secret
+	SwitchingKey *rlwe.LWESwitchingKey // switching key from the LUT secret to the LWE secret, modulo the LWE modulus
+}
+
+// KeyGenerator is a structure storing the elements required to generate the keys of the boolean gates.
+type KeyGenerator struct {
+	params  Parameters
+	kgenLWE rlwe.KeyGenerator
+	kgenLUT rlwe.KeyGenerator
+}
+
+// NewKeyGenerator creates a new KeyGenerator.
+func NewKeyGenerator(params Parameters) *KeyGenerator {
+	return &KeyGenerator{
+		params:  params,
+		kgenLWE: rlwe.NewKeyGenerator(params.LWE()),
+		kgenLUT: rlwe.NewKeyGenerator(params.LUT()),
+	}
+}
+
+// GenSecretKey generates a new SecretKey with ternary secrets.
+func (kgen *KeyGenerator) GenSecretKey() (sk *SecretKey) {
+	return &SecretKey{LWE: kgen.kgenLWE.GenSecretKey(), LUT: kgen.kgenLUT.GenSecretKey()}
+}
+
+// GenBootstrappingKey generates a new BootstrappingKey from the SecretKey.
+func (kgen *KeyGenerator) GenBootstrappingKey(sk *SecretKey) (key *BootstrappingKey) {
+
+	paramsLUT, paramsLWE := kgen.params.LUT(), kgen.params.LWE()
+
+	skLUT := rlwe.NewLWESecretKeyFromSecretKey(paramsLUT, sk.LUT)
+	skLWE := rlwe.NewLWESecretKeyFromSecretKey(paramsLWE, sk.LWE)
+
+	return &BootstrappingKey{
+		LUTKey:       lut.GenEvaluationKey(paramsLUT, sk.LUT, paramsLWE, sk.LWE),
+		SwitchingKey: kgen.kgenLUT.GenLWESwitchingKey(skLUT, skLWE, kgen.params.Q(), kgen.params.LogBaseKS()),
+	}
+}
+
+// MarshalBinary encodes the target BootstrappingKey on a slice of bytes.
+func (key *BootstrappingKey) MarshalBinary() (data []byte, err error) {
+
+	var dataLUT, dataSwk []byte
+
+	if dataLUT, err = key.LUTKey.MarshalBinary(); err != nil {
+		return nil, err
+	}
+
+	if dataSwk, err = key.SwitchingKey.MarshalBinary(); err != nil {
+		return nil, err
+	}
+
+	data = make([]byte, 8+len(dataLUT)+len(dataSwk))
+	binary.BigEndian.PutUint64(data, uint64(len(dataLUT)))
+	copy(data[8:], dataLUT)
+	copy(data[8+len(dataLUT):], dataSwk)
+
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target BootstrappingKey.
+func (key *BootstrappingKey) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 8 {
+		return fmt.Errorf("%w: data is too short", rlwe.ErrMalformed)
+	}
+
+	size := binary.BigEndian.Uint64(data)
+
+	if size > uint64(len(data)-8) {
+		return fmt.Errorf("%w: invalid size of the LUT key %d", rlwe.ErrMalformed, size)
+	}
+
+	if err = key.LUTKey.UnmarshalBinary(data[8 : 8+size]); err != nil {
+		return
+	}
+
+	key.SwitchingKey = new(rlwe.LWESwitchingKey)
+
+	return key.SwitchingKey.UnmarshalBinary(data[8+size:])
+}
+
+// Validate checks that the target BootstrappingKey has the dimensions and the moduli of the parameters.
+func (key *BootstrappingKey) Validate(params Parameters) error {
+
+	paramsLUT := params.LUT()
+
+	if len(key.LUTKey.SkPos) != params.N() || len(key.LUTKey.SkNeg) != params.N() {
+		return fmt.Errorf("%w: LUT key has dimension %d instead of %d", rlwe.ErrMalformed, len(key.LUTKey.SkPos), params.N())
+	}
+
+	for i := range key.LUTKey.SkPos {
+		for _, ct := range []*rgsw.Ciphertext{key.LUTKey.SkPos[i], key.LUTKey.SkNeg[i]} {
+
+			if ct == nil {
+				return fmt.Errorf("%w: LUT key has a nil ciphertext", rlwe.ErrMalformed)
+			}
+
+			for j := range ct.Value {
+				if err := ct.Value[j].Validate(paramsLUT); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	swk := key.SwitchingKey
+
+	if swk == nil || len(swk.Value) == 0 || len(swk.Value[0]) == 0 || len(swk.Value) != paramsLUT.N() || swk.Value[0][0].N() != params.N() || swk.Q() != params.Q() {
+		return fmt.Errorf("%w: LWE switching key does not match the parameters", rlwe.ErrMalformed)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// Package boolean implements the evaluation of boolean gates on encrypted bits, in the style of FHEW/TFHE.
+// The bits are encrypted as LWE ciphertexts and each gate is followed by a bootstrapping, which evaluates
+// the sign of the LWE ciphertext with the blind rotation of the package lut, reduces the modulus of the
+// result to the modulus of the LWE ciphertexts and switches it back to the LWE key. The error of the output
+// of a gate is therefore independent of the error of its inputs, so that circuits of arbitrary depth can be
+// evaluated exactly.
+package boolean
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// ParametersLiteral is a literal representation of the parameters of the boolean gates.
+type ParametersLiteral struct {
+	LUT       rlwe.ParametersLiteral // parameters of the RLWE ciphertexts of the blind rotation
+	LWE       rlwe.ParametersLiteral // parameters of the LWE encryptions of the bits
+	LogBaseKS int                    // log2 of the base of the decomposition of the LWE key switching
+}
+
+// ParametersN10N10 are parameters with a blind rotation in dimension 2^10 on LWE encryptions of dimension 2^10.
+var ParametersN10N10 = ParametersLiteral{
+	LUT: rlwe.ParametersLiteral{
+		LogN:     10,
+		Q:        []uint64{0x7fff801},
+		Pow2Base: 6,
+	},
+	LWE: rlwe.ParametersLiteral{
+		LogN: 10,
+		LogQ: []int{25},
+	},
+	LogBaseKS: 7,
+}
+
+// MinSecurity is the minimum estimated bit security, see rlwe.EstimateSecurity, of the RLWE instance of the LUT
+// parameters, which encrypts the LUT key, and of the LWE instance of the LWE parameters, which encrypts the bits and
+// the LWE switching key.
+const MinSecurity = 128
+
+// Parameters are the parameters of the boolean gates.
+type Parameters struct {
+	paramsLUT rlwe.Parameters
+	paramsLWE rlwe.Parameters
+	logBaseKS int
+}
+
+// NewParametersFromLiteral creates a new Parameters from a ParametersLiteral.
+func NewParametersFromLiteral(pl ParametersLiteral) (params Parameters, err error) {
+
+	if params.paramsLUT, err = rlwe.NewParametersFromLiteral(pl.LUT); err != nil {
+		return Parameters{}, err
+	}
+
+	if params.paramsLWE, err = rlwe.NewParametersFromLiteral(pl.LWE); err != nil {
+		return Parameters{}, err
+	}
+
+	if params.paramsLUT.RingType() != ring.Standard || params.paramsLWE.RingType() != ring.Standard {
+		return Parameters{}, fmt.Errorf("boolean gates are only supported in the Standard ring")
+	}
+
+	if pl.LogBaseKS < 1 || pl.LogBaseKS > 32 {
+		return Parameters{}, fmt.Errorf("LogBaseKS=%d is not in [1, 32]", pl.LogBaseKS)
+	}
+
+	// The LWE switching key is public and sampled with the error of the LUT parameters.
+	instLWE := params.paramsLWE.SecurityInstance()
+	instLWE.Sigma = math.Min(instLWE.Sigma, params.paramsLUT.Sigma())
+
+	for _, inst := range []rlwe.SecurityInstance{params.paramsLUT.SecurityInstance(), instLWE} {
+
+		// The estimator has no model of the hybrid attacks on sparse secrets.
+		if inst.Secret != rlwe.UniformTernary {
+			return Parameters{}, fmt.Errorf("insecure parameters: the security of a secret of Hamming weight H=%d < N/2 cannot be estimated", inst.H)
+		}
+
+		est, err := rlwe.EstimateSecurity(inst)
+		if err != nil {
+			return Parameters{}, err
+		}
+
+		if est.Bits() < MinSecurity {
+			return Parameters{}, fmt.Errorf("insecure parameters: LogN=%d and LogQP=%.1f give %.1f bits of security, less than %d", inst.LogN, inst.LogQP, est.Bits(), MinSecurity)
+		}
+	}
+
+	params.logBaseKS = pl.LogBaseKS
+
+	return
+}
+
+// LUT returns the parameters of the RLWE ciphertexts of the blind rotation.
+func (p Parameters) LUT() rlwe.Parameters {
+	return p.paramsLUT
+}
+
+// LWE returns the parameters of the LWE encryptions of the bits.
+func (p Parameters) LWE() rlwe.Parameters {
+	return p.paramsLWE
+}
+
+// LogBaseKS returns the log2 of the base of the decomposition of the LWE key switching.
+func (p Parameters) LogBaseKS() int {
+	return p.logBaseKS
+}
+
+// N returns the dimension of the LWE encryptions of the bits.
+func (p Parameters) N() int {
+	return p.paramsLWE.N()
+}
+
+// Q returns the modulus of the LWE encryptions of the bits.
+func (p Parameters) Q() uint64 {
+	return p.paramsLWE.Q()[0]
+}
+
+// scaledConstant returns round(k * Q/8) mod Q.
+func (p Parameters) scaledConstant(k int64) uint64 {
+
+	Q := p.Q()
+
+	if k < 0 {
+		if c := (uint64(-k)*Q + 4) / 8 % Q; c != 0 {
+			return Q - c
+		}
+		return 0
+	}
+
+	return (uint64(k)*Q + 4) / 8 % Q
+}
@@ -5,6 +5,8 @@
 package rgsw
 
 import (
+	"fmt"
+
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
 )
@@ -34,6 +36,60 @@ func NewCiphertext(levelQ, levelP, decompRNS, decompBit int, ringQP ringqp.Ring)
 	}
 }
 
+// GetDataLen returns the length in bytes of the target Ciphertext.
+func (ct *Ciphertext) GetDataLen(WithMetadata bool) (dataLen int) {
+	return ct.Value[0].GetDataLen(WithMetadata) + ct.Value[1].GetDataLen(WithMetadata)
+}
+
+// MarshalBinary encodes the target Ciphertext on a slice of bytes.
+func (ct *Ciphertext) MarshalBinary() (data []byte, err error) {
+	data = make([]byte, ct.GetDataLen(true))
+	_, err = ct.Encode(0, data)
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target Ciphertext.
+func (ct *Ciphertext) UnmarshalBinary(data []byte) (err error) {
+
+	var pointer int
+	if pointer, err = ct.Decode(data); err != nil {
+		return
+	}
+
+	if pointer != len(data) {
+		return fmt.Errorf("%w: remaining unparsed data", rlwe.ErrMalformed)
+	}
+
+	return
+}
+
+// Encode encodes the target Ciphertext on a pre-allocated slice of bytes, starting at pointer.
+func (ct *Ciphertext) Encode(pointer int, data []byte) (int, error) {
+
+	var err error
+	for i := range ct.Value {
+		if pointer, err = ct.Value[i].Encode(pointer, data); err != nil {
+			return pointer, err
+		}
+	}
+
+	return pointer, nil
+}
+
+// Decode decodes a slice of bytes on the target Ciphertext and returns the number of bytes decoded.
+func (ct *Ciphertext) Decode(data []byte) (pointer int, err error) {
+
+	var inc int
+	for i := range ct.Value {
+		if inc, err = ct.Value[i].Decode(data[pointer:]); err != nil {
+			return
+		}
+		pointer += inc
+	}
+
+	return
+}
+
 // Plaintext stores an RGSW plaintext value.
 type Plaintext rlwe.GadgetPlaintext
 
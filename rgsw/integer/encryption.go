@@ -0,0 +1,107 @@
+package integer
+
+import (
+	"github.com/tuneinsight/lattigo/v3/rgsw/boolean"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// Ciphertext is an encrypted unsigned integer, decomposed in radix 2^LogMessage.
+// The blocks are stored from the least to the most significant and each encrypts
+// its message m as m * Q/2^(LogMessage+3).
+type Ciphertext struct {
+	Blocks []*rlwe.LWECiphertext
+}
+
+// Bits returns the bit-size of the integer encrypted by ct.
+func (ct *Ciphertext) Bits(params Parameters) int {
+	return len(ct.Blocks) * params.LogMessage()
+}
+
+// CopyNew creates a deep copy of the receiver and returns it.
+func (ct *Ciphertext) CopyNew() *Ciphertext {
+	blocks := make([]*rlwe.LWECiphertext, len(ct.Blocks))
+	for i := range blocks {
+		blocks[i] = ct.Blocks[i].CopyNew()
+	}
+	return &Ciphertext{Blocks: blocks}
+}
+
+// Encryptor is a structure encrypting unsigned integers block by block.
+type Encryptor struct {
+	params    Parameters
+	encryptor rlwe.Encryptor
+	eval      *rlwe.Evaluator
+	pt        *rlwe.Plaintext
+	ct        *rlwe.Ciphertext
+}
+
+// NewEncryptor creates a new Encryptor encrypting under the LWE secret of sk.
+func NewEncryptor(params Parameters, sk *boolean.SecretKey) *Encryptor {
+	return &Encryptor{
+		params:    params,
+		encryptor: rlwe.NewEncryptor(params.LWE(), sk.LWE),
+		eval:      rlwe.NewEvaluator(params.LWE(), nil),
+		pt:        rlwe.NewPlaintext(params.LWE(), 0),
+		ct:        rlwe.NewCiphertext(params.LWE(), 1, 0),
+	}
+}
+
+// EncryptNew encrypts the value, reduced modulo 2^bits, on a new Ciphertext of the given bit-size.
+// The method returns an error if bits is not a multiple of LogMessage in [1, 64].
+func (enc *Encryptor) EncryptNew(value uint64, bits int) (ct *Ciphertext, err error) {
+
+	blocks, err := enc.params.Blocks(bits)
+	if err != nil {
+		return nil, err
+	}
+
+	// The blocks are encrypted in the first coefficients of a single RLWE ciphertext.
+	ringQ := enc.params.LWE().RingQ()
+	ringQ.MulScalar(enc.pt.Value, 0, enc.pt.Value)
+
+	logMessage, mask := uint64(enc.params.LogMessage()), enc.params.MessageModulus()-1
+	for i := 0; i < blocks; i++ {
+		enc.pt.Value.Coeffs[0][i] = enc.params.encode((value >> (uint64(i) * logMessage)) & mask)
+	}
+
+	enc.encryptor.Encrypt(enc.pt, enc.ct)
+
+	ct = &Ciphertext{Blocks: make([]*rlwe.LWECiphertext, blocks)}
+	for i := range ct.Blocks {
+		ct.Blocks[i] = enc.eval.SampleExtract(enc.ct, i)
+	}
+
+	return
+}
+
+// Decryptor is a structure decrypting encrypted unsigned integers.
+type Decryptor struct {
+	params Parameters
+	sk     *rlwe.LWESecretKey
+}
+
+// NewDecryptor creates a new Decryptor decrypting with the LWE secret of sk.
+func NewDecryptor(params Parameters, sk *boolean.SecretKey) *Decryptor {
+	return &Decryptor{params: params, sk: rlwe.NewLWESecretKeyFromSecretKey(params.LWE(), sk.LWE)}
+}
+
+// Decrypt decrypts the Ciphertext and returns its value.
+// The carries that may remain in the blocks are propagated and the value is reduced modulo 2^bits.
+func (dec *Decryptor) Decrypt(ct *Ciphertext) (value uint64) {
+
+	logMessage := uint64(dec.params.LogMessage())
+	for i, block := range ct.Blocks {
+		value += dec.DecryptBlock(block) << (uint64(i) * logMessage)
+	}
+
+	if bits := ct.Bits(dec.params); bits < 64 {
+		value &= (1 << uint64(bits)) - 1
+	}
+
+	return
+}
+
+// DecryptBlock decrypts a single block, such as the output of a comparison.
+func (dec *Decryptor) DecryptBlock(ct *rlwe.LWECiphertext) uint64 {
+	return dec.params.decode(dec.sk.Decrypt(ct))
+}
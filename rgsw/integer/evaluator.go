@@ -0,0 +1,378 @@
+package integer
+
+import (
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/rgsw/boolean"
+	"github.com/tuneinsight/lattigo/v3/rgsw/lut"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// Evaluator is a structure evaluating the arithmetic on encrypted unsigned integers.
+// The operations are modulo 2^bits and all their output blocks are bootstrapped.
+// The carries are propagated sequentially, so that the cost of an addition, a subtraction
+// or a comparison is linear in the number of blocks.
+type Evaluator struct {
+	params Parameters
+	eval   *boolean.Evaluator
+
+	lutMessage      *ring.Poly   // m mod 2^LogMessage
+	lutCarry        *ring.Poly   // m >> LogMessage
+	lutCarryScaled  *ring.Poly   // (m >> LogMessage) << LogMessage
+	lutSelector     *ring.Poly   // m << LogMessage, for m in {0, 1}
+	lutIsMax        *ring.Poly   // m == 2^LogMessage - 1
+	lutIsTwo        *ring.Poly   // m == 2
+	lutIfSelected   *ring.Poly   // m - 2^LogMessage if m >= 2^LogMessage and 0 otherwise
+	lutIfUnselected *ring.Poly   // m if m < 2^LogMessage and 0 otherwise
+	lutShiftLeft    []*ring.Poly // (m << r) mod 2^LogMessage, indexed by r
+	lutShiftRight   []*ring.Poly // m >> r, indexed by r
+}
+
+// NewEvaluator creates a new Evaluator bootstrapping with the provided boolean.BootstrappingKey.
+func NewEvaluator(params Parameters, key *boolean.BootstrappingKey) *Evaluator {
+
+	logMessage := uint64(params.LogMessage())
+	M := params.MessageModulus()
+
+	eval := &Evaluator{
+		params:          params,
+		eval:            boolean.NewEvaluator(params.Parameters, key),
+		lutMessage:      newLUT(params, func(m uint64) uint64 { return m & (M - 1) }),
+		lutCarry:        newLUT(params, func(m uint64) uint64 { return m >> logMessage }),
+		lutCarryScaled:  newLUT(params, func(m uint64) uint64 { return (m >> logMessage) << logMessage }),
+		lutSelector:     newLUT(params, func(m uint64) uint64 { return (m & 1) << logMessage }),
+		lutIsMax:        newLUT(params, func(m uint64) uint64 { return indicator(m == M-1) }),
+		lutIsTwo:        newLUT(params, func(m uint64) uint64 { return indicator(m == 2) }),
+		lutIfSelected:   newLUT(params, func(m uint64) uint64 { return (m - M) * indicator(m >= M) }),
+		lutIfUnselected: newLUT(params, func(m uint64) uint64 { return m * indicator(m < M) }),
+		lutShiftLeft:    make([]*ring.Poly, logMessage),
+		lutShiftRight:   make([]*ring.Poly, logMessage),
+	}
+
+	for r := uint64(1); r < logMessage; r++ {
+		shift := r
+		eval.lutShiftLeft[r] = newLUT(params, func(m uint64) uint64 { return (m << shift) & (M - 1) })
+		eval.lutShiftRight[r] = newLUT(params, func(m uint64) uint64 { return (m & (M - 1)) >> shift })
+	}
+
+	return eval
+}
+
+// newLUT returns the LUT of f on the 2^(LogMessage+1) values of a block.
+func newLUT(params Parameters, f func(m uint64) uint64) *ring.Poly {
+
+	ringQ := params.LUT().RingQ()
+
+	// The blind rotation maps the phase m * Q/2^(LogMessage+3) of a block to x = m/2^(LogMessage+1),
+	// which the interval [-bound, bound] scales back to m.
+	bound := float64(uint64(2) << uint64(params.LogMessage()))
+	scale := float64(ringQ.Modulus[0]) / float64(uint64(1)<<uint64(params.LogMessage()+3))
+
+	return lut.InitLUT(func(x float64) float64 {
+		m := math.Round(x)
+		if m < 0 {
+			m = 0
+		}
+		if m >= bound {
+			m = bound - 1
+		}
+		return float64(f(uint64(m)))
+	}, scale, ringQ, -bound, bound)
+}
+
+func indicator(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Add returns a new encryption of ct0 + ct1 mod 2^bits.
+func (eval *Evaluator) Add(ct0, ct1 *Ciphertext) (ctOut *Ciphertext) {
+	eval.checkOperands("Add", ct0, ct1)
+	return eval.propagate(0, ct0, nil, ct1)
+}
+
+// Sub returns a new encryption of ct0 - ct1 mod 2^bits.
+func (eval *Evaluator) Sub(ct0, ct1 *Ciphertext) (ctOut *Ciphertext) {
+	eval.checkOperands("Sub", ct0, ct1)
+	return eval.propagate(1, ct0, ct1, nil)
+}
+
+// propagate returns ct0 + ct1 + carry if ct1 is not nil and ct0 + NOT(ctNeg) + carry otherwise, where carry is
+// the initial carry, propagating the carries from the least to the most significant block.
+func (eval *Evaluator) propagate(carry uint64, ct0, ctNeg, ct1 *Ciphertext) (ctOut *Ciphertext) {
+
+	ctOut = &Ciphertext{Blocks: make([]*rlwe.LWECiphertext, len(ct0.Blocks))}
+
+	var c *rlwe.LWECiphertext
+	for i := range ct0.Blocks {
+
+		var s *rlwe.LWECiphertext
+		if ct1 != nil {
+			s = eval.linear(carry, []*rlwe.LWECiphertext{ct0.Blocks[i], ct1.Blocks[i], c}, nil)
+		} else {
+			s = eval.linear(carry+eval.params.MessageModulus()-1, []*rlwe.LWECiphertext{ct0.Blocks[i], c}, []*rlwe.LWECiphertext{ctNeg.Blocks[i]})
+		}
+
+		ctOut.Blocks[i] = eval.eval.ProgrammableBootstrap(s, eval.lutMessage)
+
+		if i < len(ct0.Blocks)-1 {
+			c = eval.eval.ProgrammableBootstrap(s, eval.lutCarry)
+		}
+
+		carry = 0
+	}
+
+	return
+}
+
+// Equal returns a new encryption of 1 if ct0 == ct1 and of 0 otherwise.
+func (eval *Evaluator) Equal(ct0, ct1 *Ciphertext) (ctOut *rlwe.LWECiphertext) {
+	eval.checkOperands("Equal", ct0, ct1)
+	return eval.equal(ct0, ct1)
+}
+
+// NotEqual returns a new encryption of 1 if ct0 != ct1 and of 0 otherwise.
+func (eval *Evaluator) NotEqual(ct0, ct1 *Ciphertext) (ctOut *rlwe.LWECiphertext) {
+	eval.checkOperands("NotEqual", ct0, ct1)
+	return eval.linear(1, nil, []*rlwe.LWECiphertext{eval.equal(ct0, ct1)})
+}
+
+// LessThan returns a new encryption of 1 if ct0 < ct1 and of 0 otherwise.
+func (eval *Evaluator) LessThan(ct0, ct1 *Ciphertext) (ctOut *rlwe.LWECiphertext) {
+	eval.checkOperands("LessThan", ct0, ct1)
+	return eval.linear(1, nil, []*rlwe.LWECiphertext{eval.greaterOrEqual(ct0, ct1, eval.lutCarry)})
+}
+
+// LessOrEqual returns a new encryption of 1 if ct0 <= ct1 and of 0 otherwise.
+func (eval *Evaluator) LessOrEqual(ct0, ct1 *Ciphertext) (ctOut *rlwe.LWECiphertext) {
+	eval.checkOperands("LessOrEqual", ct0, ct1)
+	return eval.greaterOrEqual(ct1, ct0, eval.lutCarry)
+}
+
+// GreaterThan returns a new encryption of 1 if ct0 > ct1 and of 0 otherwise.
+func (eval *Evaluator) GreaterThan(ct0, ct1 *Ciphertext) (ctOut *rlwe.LWECiphertext) {
+	eval.checkOperands("GreaterThan", ct0, ct1)
+	return eval.linear(1, nil, []*rlwe.LWECiphertext{eval.greaterOrEqual(ct1, ct0, eval.lutCarry)})
+}
+
+// GreaterOrEqual returns a new encryption of 1 if ct0 >= ct1 and of 0 otherwise.
+func (eval *Evaluator) GreaterOrEqual(ct0, ct1 *Ciphertext) (ctOut *rlwe.LWECiphertext) {
+	eval.checkOperands("GreaterOrEqual", ct0, ct1)
+	return eval.greaterOrEqual(ct0, ct1, eval.lutCarry)
+}
+
+// equal compares the blocks independently and returns the AND of the comparisons.
+func (eval *Evaluator) equal(ct0, ct1 *Ciphertext) (ctOut *rlwe.LWECiphertext) {
+
+	M := eval.params.MessageModulus()
+
+	for i := range ct0.Blocks {
+
+		// ct0[i] - ct1[i] + M - 1 is in [0, 2M-2] and equal to M-1 if and only if ct0[i] == ct1[i].
+		e := eval.linear(M-1, []*rlwe.LWECiphertext{ct0.Blocks[i]}, []*rlwe.LWECiphertext{ct1.Blocks[i]})
+		e = eval.eval.ProgrammableBootstrap(e, eval.lutIsMax)
+
+		if ctOut == nil {
+			ctOut = e
+		} else {
+			ctOut = eval.eval.ProgrammableBootstrap(eval.linear(0, []*rlwe.LWECiphertext{ctOut, e}, nil), eval.lutIsTwo)
+		}
+	}
+
+	return
+}
+
+// greaterOrEqual returns the last carry of ct0 + NOT(ct1) + 1, which is 1 if and only if ct0 >= ct1,
+// evaluating the last carry with lutLast.
+func (eval *Evaluator) greaterOrEqual(ct0, ct1 *Ciphertext, lutLast *ring.Poly) (c *rlwe.LWECiphertext) {
+
+	carry := eval.params.MessageModulus()
+
+	for i := range ct0.Blocks {
+
+		s := eval.linear(carry, []*rlwe.LWECiphertext{ct0.Blocks[i], c}, []*rlwe.LWECiphertext{ct1.Blocks[i]})
+
+		if i < len(ct0.Blocks)-1 {
+			c = eval.eval.ProgrammableBootstrap(s, eval.lutCarry)
+		} else {
+			c = eval.eval.ProgrammableBootstrap(s, lutLast)
+		}
+
+		carry = eval.params.MessageModulus() - 1
+	}
+
+	return
+}
+
+// Select returns a new encryption of ct1 if cond encrypts 1 and of ct0 if cond encrypts 0,
+// where cond is, for example, the output of a comparison.
+func (eval *Evaluator) Select(cond *rlwe.LWECiphertext, ct1, ct0 *Ciphertext) (ctOut *Ciphertext) {
+	eval.checkOperands("Select", ct0, ct1)
+	eval.checkBlock("Select", cond)
+	return eval.sel(eval.eval.ProgrammableBootstrap(cond, eval.lutSelector), ct1, ct0)
+}
+
+// Min returns a new encryption of the minimum of ct0 and ct1.
+func (eval *Evaluator) Min(ct0, ct1 *Ciphertext) (ctOut *Ciphertext) {
+	eval.checkOperands("Min", ct0, ct1)
+	return eval.sel(eval.greaterOrEqual(ct0, ct1, eval.lutCarryScaled), ct1, ct0)
+}
+
+// Max returns a new encryption of the maximum of ct0 and ct1.
+func (eval *Evaluator) Max(ct0, ct1 *Ciphertext) (ctOut *Ciphertext) {
+	eval.checkOperands("Max", ct0, ct1)
+	return eval.sel(eval.greaterOrEqual(ct0, ct1, eval.lutCarryScaled), ct0, ct1)
+}
+
+// sel returns ct1 if sel encrypts 2^LogMessage and ct0 if sel encrypts 0.
+func (eval *Evaluator) sel(sel *rlwe.LWECiphertext, ct1, ct0 *Ciphertext) (ctOut *Ciphertext) {
+
+	ctOut = &Ciphertext{Blocks: make([]*rlwe.LWECiphertext, len(ct0.Blocks))}
+
+	for i := range ctOut.Blocks {
+		b1 := eval.eval.ProgrammableBootstrap(eval.linear(0, []*rlwe.LWECiphertext{sel, ct1.Blocks[i]}, nil), eval.lutIfSelected)
+		b0 := eval.eval.ProgrammableBootstrap(eval.linear(0, []*rlwe.LWECiphertext{sel, ct0.Blocks[i]}, nil), eval.lutIfUnselected)
+		ctOut.Blocks[i] = eval.eval.ProgrammableBootstrap(eval.linear(0, []*rlwe.LWECiphertext{b1, b0}, nil), eval.lutMessage)
+	}
+
+	return
+}
+
+// ShiftLeft returns a new encryption of ct << k mod 2^bits.
+// Shifts by a multiple of LogMessage only move the blocks and are not bootstrapped.
+func (eval *Evaluator) ShiftLeft(ct *Ciphertext, k int) (ctOut *Ciphertext) {
+	eval.checkOperands("ShiftLeft", ct, ct)
+	eval.checkShift("ShiftLeft", k)
+	return eval.shift(ct, -k)
+}
+
+// ShiftRight returns a new encryption of ct >> k.
+// Shifts by a multiple of LogMessage only move the blocks and are not bootstrapped.
+func (eval *Evaluator) ShiftRight(ct *Ciphertext, k int) (ctOut *Ciphertext) {
+	eval.checkOperands("ShiftRight", ct, ct)
+	eval.checkShift("ShiftRight", k)
+	return eval.shift(ct, k)
+}
+
+// shift returns ct >> k if k >= 0 and ct << -k otherwise.
+func (eval *Evaluator) shift(ct *Ciphertext, k int) (ctOut *Ciphertext) {
+
+	logMessage := eval.params.LogMessage()
+
+	left := k < 0
+	if left {
+		k = -k
+	}
+
+	q, r := k/logMessage, k%logMessage
+
+	// block returns the i-th block of ct shifted by q blocks, or nil if it is zero.
+	block := func(i int) *rlwe.LWECiphertext {
+		if left {
+			i -= q
+		} else {
+			i += q
+		}
+		if i < 0 || i >= len(ct.Blocks) {
+			return nil
+		}
+		return ct.Blocks[i]
+	}
+
+	ctOut = &Ciphertext{Blocks: make([]*rlwe.LWECiphertext, len(ct.Blocks))}
+
+	for i := range ctOut.Blocks {
+
+		var terms []*rlwe.LWECiphertext
+
+		if r == 0 {
+			if b := block(i); b != nil {
+				terms = append(terms, b.CopyNew())
+			}
+		} else if left {
+			if b := block(i); b != nil {
+				terms = append(terms, eval.eval.ProgrammableBootstrap(b, eval.lutShiftLeft[r]))
+			}
+			if b := block(i - 1); b != nil {
+				terms = append(terms, eval.eval.ProgrammableBootstrap(b, eval.lutShiftRight[logMessage-r]))
+			}
+		} else {
+			if b := block(i); b != nil {
+				terms = append(terms, eval.eval.ProgrammableBootstrap(b, eval.lutShiftRight[r]))
+			}
+			if b := block(i + 1); b != nil {
+				terms = append(terms, eval.eval.ProgrammableBootstrap(b, eval.lutShiftLeft[logMessage-r]))
+			}
+		}
+
+		switch len(terms) {
+		case 0:
+			ctOut.Blocks[i] = rlwe.NewLWECiphertext(eval.params.N(), eval.params.Q())
+		case 1:
+			ctOut.Blocks[i] = terms[0]
+		default:
+			ctOut.Blocks[i] = eval.eval.ProgrammableBootstrap(eval.linear(0, terms, nil), eval.lutMessage)
+		}
+	}
+
+	return
+}
+
+// linear returns a new LWE ciphertext encrypting k + sum(add) - sum(sub), ignoring the nil ciphertexts.
+func (eval *Evaluator) linear(k uint64, add, sub []*rlwe.LWECiphertext) (ctOut *rlwe.LWECiphertext) {
+
+	Q := eval.params.Q()
+
+	ctOut = rlwe.NewLWECiphertext(eval.params.N(), Q)
+	ctOut.B = eval.params.encode(k)
+
+	for _, ct := range add {
+		if ct != nil {
+			ctOut.B = ring.CRed(ctOut.B+ct.B, Q)
+			for i, a := range ct.A {
+				ctOut.A[i] = ring.CRed(ctOut.A[i]+a, Q)
+			}
+		}
+	}
+
+	for _, ct := range sub {
+		if ct != nil {
+			ctOut.B = ring.CRed(ctOut.B+Q-ct.B, Q)
+			for i, a := range ct.A {
+				ctOut.A[i] = ring.CRed(ctOut.A[i]+Q-a, Q)
+			}
+		}
+	}
+
+	return
+}
+
+// checkOperands panics if ct0 and ct1 are not encryptions of integers of the same bit-size.
+func (eval *Evaluator) checkOperands(op string, ct0, ct1 *Ciphertext) {
+
+	if ct0 == nil || ct1 == nil || len(ct0.Blocks) == 0 || len(ct0.Blocks) != len(ct1.Blocks) {
+		panic(rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "operands are not encryptions of integers of the same bit-size"))
+	}
+
+	for i := range ct0.Blocks {
+		eval.checkBlock(op, ct0.Blocks[i])
+		eval.checkBlock(op, ct1.Blocks[i])
+	}
+}
+
+// checkBlock panics if ct is not an LWE ciphertext of the dimension and modulus of the parameters.
+func (eval *Evaluator) checkBlock(op string, ct *rlwe.LWECiphertext) {
+	if ct == nil || ct.N() != eval.params.N() || ct.Q != eval.params.Q() {
+		panic(rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "block is not a LWE ciphertext of dimension %d modulo %d", eval.params.N(), eval.params.Q()))
+	}
+}
+
+// checkShift panics if k is negative.
+func (eval *Evaluator) checkShift(op string, k int) {
+	if k < 0 {
+		panic(rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "shift amount %d is negative", k))
+	}
+}
@@ -0,0 +1,198 @@
+package integer
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuneinsight/lattigo/v3/rgsw/boolean"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+func testString(params Parameters, opname string, bits int) string {
+	return fmt.Sprintf("%s/logNLUT=%d/logNLWE=%d/logQLWE=%d/logMessage=%d/bits=%d",
+		opname,
+		params.LUT().LogN(),
+		params.LWE().LogN(),
+		params.LWE().LogQ(),
+		params.LogMessage(),
+		bits)
+}
+
+type testContext struct {
+	params Parameters
+	enc    *Encryptor
+	dec    *Decryptor
+	eval   *Evaluator
+}
+
+func newTestContext(params Parameters) (tc *testContext) {
+	kgen := boolean.NewKeyGenerator(params.Parameters)
+	sk := kgen.GenSecretKey()
+	return &testContext{
+		params: params,
+		enc:    NewEncryptor(params, sk),
+		dec:    NewDecryptor(params, sk),
+		eval:   NewEvaluator(params, kgen.GenBootstrappingKey(sk)),
+	}
+}
+
+func (tc *testContext) encrypt(t *testing.T, value uint64, bits int) *Ciphertext {
+	ct, err := tc.enc.EncryptNew(value, bits)
+	require.NoError(t, err)
+	return ct
+}
+
+// TestInteger tests the arithmetic on encrypted integers.
+func TestInteger(t *testing.T) {
+
+	params, err := NewParametersFromLiteral(ParametersN11N10)
+	require.NoError(t, err)
+
+	tc := newTestContext(params)
+
+	for _, testSet := range []func(tc *testContext, t *testing.T){
+		testParams,
+		testEncryptor,
+		testArithmetic,
+		testComparisons,
+		testShifts,
+	} {
+		testSet(tc, t)
+		runtime.GC()
+	}
+}
+
+func testParams(tc *testContext, t *testing.T) {
+	t.Run(testString(tc.params, "Parameters", 0), func(t *testing.T) {
+
+		pl := ParametersN11N10
+		pl.LogMessage = 6
+		_, err := NewParametersFromLiteral(pl)
+		require.Error(t, err)
+
+		for _, bits := range []int{0, 7, 66} {
+			_, err = tc.enc.EncryptNew(0, bits)
+			require.Error(t, err)
+		}
+	})
+
+	t.Run(testString(tc.params, "Parameters/EncodeLargeQ", 0), func(t *testing.T) {
+
+		// LWE modulus of 60 bits, for which m * Q and x * 2^(LogMessage+3) overflow 64 bits
+		pl := ParametersN11N10
+		pl.Boolean.LWE = rlwe.ParametersLiteral{LogN: 13, LogQ: []int{60}}
+		params, err := NewParametersFromLiteral(pl)
+		require.NoError(t, err)
+
+		Q := params.Q()
+		for m := uint64(0); m < 1<<uint64(params.LogMessage()+3); m++ {
+			x := params.encode(m)
+			require.Less(t, x, Q)
+			require.Equal(t, m, params.decode(x))
+			require.Equal(t, m, params.decode((x+1000)%Q))
+			require.Equal(t, m, params.decode((x+Q-1000)%Q))
+		}
+	})
+}
+
+func testEncryptor(tc *testContext, t *testing.T) {
+	for _, bits := range []int{8, 64} {
+		t.Run(testString(tc.params, "Encryptor", bits), func(t *testing.T) {
+			for _, value := range []uint64{0, 1, 0xa5, 0x0123456789abcdef, 0xffffffffffffffff} {
+				ct := tc.encrypt(t, value, bits)
+				require.Equal(t, bits/tc.params.LogMessage(), len(ct.Blocks))
+				require.Equal(t, bits, ct.Bits(tc.params))
+				if bits < 64 {
+					value &= (1 << uint64(bits)) - 1
+				}
+				require.Equal(t, value, tc.dec.Decrypt(ct))
+				require.Equal(t, value, tc.dec.Decrypt(ct.CopyNew()))
+			}
+		})
+	}
+}
+
+func testArithmetic(tc *testContext, t *testing.T) {
+
+	eval := tc.eval
+	bits := 8
+
+	t.Run(testString(tc.params, "Add/Sub", bits), func(t *testing.T) {
+		v0, v1 := uint64(0xc8), uint64(0x9b)
+		ct0, ct1 := tc.encrypt(t, v0, bits), tc.encrypt(t, v1, bits)
+		sum := eval.Add(ct0, ct1)
+		require.Equal(t, (v0+v1)&0xff, tc.dec.Decrypt(sum))
+		// The output of an operation is a valid input of the next one.
+		require.Equal(t, v0, tc.dec.Decrypt(eval.Sub(sum, ct1)))
+	})
+
+	t.Run(testString(tc.params, "InvalidOperand", bits), func(t *testing.T) {
+		ct8 := tc.encrypt(t, 1, bits)
+		ct16 := tc.encrypt(t, 1, 2*bits)
+		require.Panics(t, func() { eval.Add(ct8, ct16) })
+		require.Panics(t, func() { eval.Min(ct8, nil) })
+		require.Panics(t, func() { eval.ShiftLeft(ct8, -1) })
+		require.Panics(t, func() { eval.Select(rlwe.NewLWECiphertext(tc.params.N()/2, tc.params.Q()), ct8, ct8) })
+	})
+}
+
+// The comparisons and the shifts are tested on 4-bit integers, which exercise the carries
+// between blocks with fewer bootstrappings.
+func testComparisons(tc *testContext, t *testing.T) {
+
+	eval := tc.eval
+	bits := 4
+
+	comparisons := []struct {
+		name string
+		eval func(ct0, ct1 *Ciphertext) *rlwe.LWECiphertext
+		want func(a, b uint64) bool
+	}{
+		{"Equal", eval.Equal, func(a, b uint64) bool { return a == b }},
+		{"NotEqual", eval.NotEqual, func(a, b uint64) bool { return a != b }},
+		{"LessThan", eval.LessThan, func(a, b uint64) bool { return a < b }},
+		{"LessOrEqual", eval.LessOrEqual, func(a, b uint64) bool { return a <= b }},
+		{"GreaterThan", eval.GreaterThan, func(a, b uint64) bool { return a > b }},
+		{"GreaterOrEqual", eval.GreaterOrEqual, func(a, b uint64) bool { return a >= b }},
+	}
+
+	pairs := [][2]uint64{{0x9, 0x9}, {0xa, 0x6}}
+
+	for _, cmp := range comparisons {
+		t.Run(testString(tc.params, cmp.name, bits), func(t *testing.T) {
+			for _, v := range pairs {
+				ct := cmp.eval(tc.encrypt(t, v[0], bits), tc.encrypt(t, v[1], bits))
+				require.Equal(t, indicator(cmp.want(v[0], v[1])), tc.dec.DecryptBlock(ct), "%d %s %d", v[0], cmp.name, v[1])
+			}
+		})
+	}
+
+	t.Run(testString(tc.params, "MinMax", bits), func(t *testing.T) {
+		ct0, ct1 := tc.encrypt(t, 0xa, bits), tc.encrypt(t, 0x6, bits)
+		require.Equal(t, uint64(0x6), tc.dec.Decrypt(eval.Min(ct0, ct1)))
+		require.Equal(t, uint64(0xa), tc.dec.Decrypt(eval.Max(ct0, ct1)))
+	})
+
+	t.Run(testString(tc.params, "Select", bits), func(t *testing.T) {
+		ct0, ct1 := tc.encrypt(t, 0x2, bits), tc.encrypt(t, 0xd, bits)
+		require.Equal(t, uint64(0xd), tc.dec.Decrypt(eval.Select(eval.LessThan(ct0, ct1), ct1, ct0)))
+	})
+}
+
+func testShifts(tc *testContext, t *testing.T) {
+
+	eval := tc.eval
+	bits := 4
+	value := uint64(0xb)
+
+	t.Run(testString(tc.params, "Shift", bits), func(t *testing.T) {
+		ct := tc.encrypt(t, value, bits)
+		for _, k := range []int{0, 1, 3, 4} {
+			require.Equal(t, (value<<uint64(k))&0xf, tc.dec.Decrypt(eval.ShiftLeft(ct, k)), "ShiftLeft %d", k)
+			require.Equal(t, value>>uint64(k), tc.dec.Decrypt(eval.ShiftRight(ct, k)), "ShiftRight %d", k)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+// Package integer implements exact integer arithmetic on encrypted unsigned integers of up to 64 bits.
+// An integer is decomposed in radix 2^LogMessage and each of its blocks is encrypted as an LWE ciphertext
+// that has room for one carry bit above the message. The carries, the comparisons and the reductions of the
+// blocks are evaluated with programmable bootstrappings, that is, blind rotations of the package lut on LUTs
+// generated with lut.InitLUT, followed by the LWE key switching and modulus switching of the package boolean.
+// Since every block is refreshed by a bootstrapping, circuits of arbitrary depth can be evaluated exactly.
+package integer
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/rgsw/boolean"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// ParametersLiteral is a literal representation of the parameters of the integer arithmetic.
+type ParametersLiteral struct {
+	Boolean    boolean.ParametersLiteral // parameters of the bootstrapping of the blocks
+	LogMessage int                       // number of bits of message per block
+}
+
+// ParametersN11N10 are parameters with a blind rotation in dimension 2^11 on LWE encryptions of dimension 2^10
+// and blocks of 2 bits.
+var ParametersN11N10 = ParametersLiteral{
+	Boolean: boolean.ParametersLiteral{
+		LUT: rlwe.ParametersLiteral{
+			LogN:     11,
+			LogQ:     []int{36},
+			Pow2Base: 6,
+		},
+		LWE: rlwe.ParametersLiteral{
+			LogN: 10,
+			LogQ: []int{25},
+		},
+		LogBaseKS: 7,
+	},
+	LogMessage: 2,
+}
+
+// Parameters are the parameters of the integer arithmetic.
+// The keys are those of the package boolean, generated with the embedded boolean.Parameters.
+type Parameters struct {
+	boolean.Parameters
+	logMessage int
+}
+
+// NewParametersFromLiteral creates a new Parameters from a ParametersLiteral.
+// The parameters are checked to allow the blind rotation to distinguish the 2^(LogMessage+1) values of a block.
+func NewParametersFromLiteral(pl ParametersLiteral) (params Parameters, err error) {
+
+	if params.Parameters, err = boolean.NewParametersFromLiteral(pl.Boolean); err != nil {
+		return Parameters{}, err
+	}
+
+	if pl.LogMessage < 1 || pl.LogMessage > 8 {
+		return Parameters{}, fmt.Errorf("LogMessage=%d is not in [1, 8]", pl.LogMessage)
+	}
+
+	// The blind rotation reads the phase of a block modulo 2N and the LUT is only valid on a quarter
+	// of the torus, which must hold the 2^(LogMessage+1) values of a block with at least 16 phases each.
+	if pl.LogMessage+7 > params.LUT().LogN()+1 || uint64(1)<<(pl.LogMessage+7) > params.Q() {
+		return Parameters{}, fmt.Errorf("LogMessage=%d is too large for the parameters", pl.LogMessage)
+	}
+
+	params.logMessage = pl.LogMessage
+
+	return
+}
+
+// LogMessage returns the number of bits of message per block.
+func (p Parameters) LogMessage() int {
+	return p.logMessage
+}
+
+// MessageModulus returns the radix 2^LogMessage of the decomposition of the integers.
+func (p Parameters) MessageModulus() uint64 {
+	return 1 << p.logMessage
+}
+
+// Blocks returns the number of blocks of an integer of the given bit-size, which must be a positive multiple
+// of LogMessage not greater than 64.
+func (p Parameters) Blocks(bits int) (int, error) {
+	if bits < 1 || bits > 64 || bits%p.logMessage != 0 {
+		return 0, fmt.Errorf("bit-size %d is not a multiple of %d in [1, 64]", bits, p.logMessage)
+	}
+	return bits / p.logMessage, nil
+}
+
+// encode returns round(m * Q/2^(LogMessage+3)) mod Q, for m < 2^(LogMessage+3).
+func (p Parameters) encode(m uint64) uint64 {
+
+	Q, logDelta := p.Q(), uint(p.logMessage+3)
+
+	hi, lo := bits.Mul64(m, Q)
+	lo, carry := bits.Add64(lo, 1<<(logDelta-1), 0)
+	hi += carry
+
+	return (hi<<(64-logDelta) | lo>>logDelta) % Q
+}
+
+// decode returns round(x * 2^(LogMessage+3)/Q) mod 2^(LogMessage+3), for x < Q.
+func (p Parameters) decode(x uint64) uint64 {
+
+	Q, logDelta := p.Q(), uint(p.logMessage+3)
+
+	hi, lo := x>>(64-logDelta), x<<logDelta
+	lo, carry := bits.Add64(lo, Q>>1, 0)
+	hi += carry
+
+	m, _ := bits.Div64(hi, lo, Q)
+
+	return m & ((1 << logDelta) - 1)
+}
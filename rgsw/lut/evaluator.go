@@ -2,6 +2,7 @@ package lut
 
 import (
 	"math/big"
+	"sync"
 
 	"github.com/tuneinsight/lattigo/v3/rgsw"
 	"github.com/tuneinsight/lattigo/v3/ring"
@@ -27,6 +28,8 @@ type Evaluator struct {
 	tmpRGSW *rgsw.Ciphertext
 
 	one *rgsw.Plaintext
+
+	workers []*Evaluator // shallow copies used by EvaluateLWEBatch
 }
 
 // NewEvaluator creates a new Handler
@@ -129,6 +132,29 @@ func NewEvaluator(paramsLUT, paramsLWE rlwe.Parameters, rtks *rlwe.RotationKeySe
 	return
 }
 
+// ShallowCopy creates a shallow copy of this Evaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Evaluators can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+
+	paramsLUT, paramsLWE := eval.paramsLUT, eval.paramsLWE
+
+	levelQ := paramsLUT.QCount() - 1
+	levelP := paramsLUT.PCount() - 1
+
+	return &Evaluator{
+		Evaluator:    eval.Evaluator.ShallowCopy(),
+		paramsLUT:    paramsLUT,
+		paramsLWE:    paramsLWE,
+		xPowMinusOne: eval.xPowMinusOne,
+		poolMod2N:    [2]*ring.Poly{paramsLWE.RingQ().NewPolyLvl(0), paramsLWE.RingQ().NewPolyLvl(0)},
+		accumulator:  rlwe.NewCiphertextNTT(paramsLUT, 1, paramsLUT.MaxLevel()),
+		Sk:           eval.Sk,
+		tmpRGSW:      rgsw.NewCiphertext(levelQ, levelP, paramsLUT.DecompRNS(levelQ, levelP), paramsLUT.DecompPw2(levelQ, levelP), *paramsLUT.RingQP()),
+		one:          eval.one,
+	}
+}
+
 // EvaluateAndRepack extracts on the fly LWE samples, evaluates the provided LUT on the LWE and repacks everything into a single rlwe.Ciphertext.
 // ct : a rlwe Ciphertext with coefficient encoded values at level 0
 // lutPolyWihtSlotIndex : a map with [slot_index] -> LUT
@@ -154,6 +180,44 @@ func (eval *Evaluator) EvaluateAndRepack(ct *rlwe.Ciphertext, lutPolyWihtSlotInd
 // Returns a map[slot_index] -> LUT(ct[slot_index])
 func (eval *Evaluator) Evaluate(ct *rlwe.Ciphertext, lutPolyWihtSlotIndex map[int]*ring.Poly, key EvaluationKey) (res map[int]*rlwe.Ciphertext) {
 
+	res = make(map[int]*rlwe.Ciphertext)
+
+	eval.evaluateSlots(ct, func(index int) bool {
+		_, ok := lutPolyWihtSlotIndex[index]
+		return ok
+	}, func(index int, a []uint64, b uint64) {
+		eval.blindRotate(a, b, lutPolyWihtSlotIndex[index], key)
+		res[index] = eval.accumulator.CopyNew()
+	})
+
+	return
+}
+
+// EvaluateMultiValue extracts on the fly LWE samples and evaluates all the LUTs of the provided MultiValueLUT
+// on each LWE with a single blind rotation.
+// ct : a rlwe Ciphertext with coefficient encoded values at level 0
+// lutWihtSlotIndex : a map with [slot_index] -> MultiValueLUT
+// lutKey : lut.Key
+// Returns a map[slot_index] -> [LUT_0(ct[slot_index]), LUT_1(ct[slot_index]), ...]
+func (eval *Evaluator) EvaluateMultiValue(ct *rlwe.Ciphertext, lutWihtSlotIndex map[int]*MultiValueLUT, key EvaluationKey) (res map[int][]*rlwe.Ciphertext) {
+
+	res = make(map[int][]*rlwe.Ciphertext)
+
+	eval.evaluateSlots(ct, func(index int) bool {
+		_, ok := lutWihtSlotIndex[index]
+		return ok
+	}, func(index int, a []uint64, b uint64) {
+		eval.blindRotate(a, b, lutWihtSlotIndex[index].TestPoly, key)
+		res[index] = eval.multiplyFactors(lutWihtSlotIndex[index])
+	})
+
+	return
+}
+
+// evaluateSlots switches the modulus of ct to 2N and calls evaluate on the LWE sample of each slot for which
+// hasLUT returns true, in increasing order of the slots.
+func (eval *Evaluator) evaluateSlots(ct *rlwe.Ciphertext, hasLUT func(index int) bool, evaluate func(index int, a []uint64, b uint64)) {
+
 	bRLWEMod2N := eval.poolMod2N[0]
 	aRLWEMod2N := eval.poolMod2N[1]
 
@@ -161,7 +225,6 @@ func (eval *Evaluator) Evaluate(ct *rlwe.Ciphertext, lutPolyWihtSlotIndex map[in
 
 	ringQLUT := eval.paramsLUT.RingQ()
 	ringQLWE := eval.paramsLWE.RingQ()
-	ringQPLUT := *eval.paramsLUT.RingQP()
 
 	// mod 2N
 	mask := uint64(ringQLUT.N<<1) - 1
@@ -184,47 +247,164 @@ func (eval *Evaluator) Evaluate(ct *rlwe.Ciphertext, lutPolyWihtSlotIndex map[in
 
 	eval.ModSwitchRLWETo2NLvl(ct.Level(), acc.Value[0], bRLWEMod2N)
 
-	levelQ := key.SkPos[0].LevelQ()
-	levelP := key.SkPos[0].LevelP()
-
-	res = make(map[int]*rlwe.Ciphertext)
-
 	var prevIndex int
 	for index := 0; index < ringQLWE.N; index++ {
 
-		if lut, ok := lutPolyWihtSlotIndex[index]; ok {
+		if hasLUT(index) {
 
 			MulBySmallMonomialMod2N(mask, aRLWEMod2N, index-prevIndex)
 			prevIndex = index
 
-			a := aRLWEMod2N.Coeffs[0]
-			b := bRLWEMod2N.Coeffs[0][index]
+			evaluate(index, aRLWEMod2N.Coeffs[0], bRLWEMod2N.Coeffs[0][index])
+		}
+
+		// LUT[RLWE] = LUT[RLWE] * X^{m+e}
+	}
+}
+
+// EvaluateLWE evaluates the provided LUT on the LWE ciphertext ct, which must be of dimension paramsLWE.N().
+// ct : a rlwe.LWECiphertext, for example extracted with rlwe.Evaluator.SampleExtract
+// lutPoly : the LUT
+// key : lut.Key
+// Returns a *rlwe.Ciphertext in the NTT domain whose constant coefficient encrypts LUT(ct)
+func (eval *Evaluator) EvaluateLWE(ct *rlwe.LWECiphertext, lutPoly *ring.Poly, key EvaluationKey) (res *rlwe.Ciphertext) {
 
-			// LWE = -as + m + e, a
-			// LUT = LUT * X^{-as + m + e}
-			ringQLUT.MulCoeffsMontgomery(lut, eval.xPowMinusOne[b].Q, acc.Value[0])
-			ringQLUT.Add(acc.Value[0], lut, acc.Value[0])
-			acc.Value[1].Zero()
+	eval.checkLWE("EvaluateLWE", ct)
 
-			for j := 0; j < ringQLWE.N; j++ {
-				// RGSW[(X^{a} - 1) * sk_{j}[0] + (X^{-a} - 1) * sk_{j}[1] + 1]
-				rgsw.MulByXPowAlphaMinusOneConstantLvl(levelQ, levelP, key.SkPos[j], eval.xPowMinusOne[a[j]], ringQPLUT, eval.tmpRGSW)
-				rgsw.MulByXPowAlphaMinusOneAndAddNoModLvl(levelQ, levelP, key.SkNeg[j], eval.xPowMinusOne[-a[j]&mask], ringQPLUT, eval.tmpRGSW)
-				rgsw.AddNoModLvl(levelQ, levelP, eval.one, ringQPLUT, eval.tmpRGSW)
+	// Switch modulus from Q to 2N
+	ct2N := ct.ModSwitchNew(uint64(eval.paramsLUT.N() << 1))
 
-				// LUT[RLWE] = LUT[RLWE] x RGSW[(X^{a} - 1) * sk_{j}[0] + (X^{-a} - 1) * sk_{j}[1] + 1]
-				eval.ExternalProduct(acc, eval.tmpRGSW, acc)
-			}
+	eval.blindRotate(ct2N.A, ct2N.B, lutPoly, key)
+
+	return eval.accumulator.CopyNew()
+}
+
+// EvaluateLWEMultiValue evaluates all the LUTs of the provided MultiValueLUT on the LWE ciphertext ct, which must be
+// of dimension paramsLWE.N(), with a single blind rotation.
+// ct : a rlwe.LWECiphertext, for example extracted with rlwe.Evaluator.SampleExtract
+// mvLUT : the MultiValueLUT
+// key : lut.Key
+// Returns a slice of *rlwe.Ciphertext in the NTT domain whose i-th element has a constant coefficient encrypting LUT_i(ct)
+func (eval *Evaluator) EvaluateLWEMultiValue(ct *rlwe.LWECiphertext, mvLUT *MultiValueLUT, key EvaluationKey) (res []*rlwe.Ciphertext) {
+
+	eval.checkLWE("EvaluateLWEMultiValue", ct)
+
+	ct2N := ct.ModSwitchNew(uint64(eval.paramsLUT.N() << 1))
+
+	eval.blindRotate(ct2N.A, ct2N.B, mvLUT.TestPoly, key)
 
-			res[index] = acc.CopyNew()
+	return eval.multiplyFactors(mvLUT)
+}
+
+// EvaluateLWEBatch evaluates the provided LUT on each of the LWE ciphertexts of cts, which must be of dimension
+// paramsLWE.N(), with nbGoRoutines concurrent goroutines. The shallow copies of the Evaluator used by the
+// goroutines are allocated on the first call and reused by the subsequent calls.
+// cts : a slice of rlwe.LWECiphertext
+// lutPoly : the LUT
+// key : lut.Key
+// nbGoRoutines : the number of goroutines
+// Returns a slice of *rlwe.Ciphertext in the NTT domain whose i-th element has a constant coefficient encrypting LUT(cts[i])
+func (eval *Evaluator) EvaluateLWEBatch(cts []*rlwe.LWECiphertext, lutPoly *ring.Poly, key EvaluationKey, nbGoRoutines int) (res []*rlwe.Ciphertext) {
+
+	for _, ct := range cts {
+		eval.checkLWE("EvaluateLWEBatch", ct)
+	}
+
+	if nbGoRoutines < 1 {
+		nbGoRoutines = 1
+	}
+
+	for len(eval.workers) < nbGoRoutines-1 {
+		eval.workers = append(eval.workers, eval.ShallowCopy())
+	}
+
+	res = make([]*rlwe.Ciphertext, len(cts))
+
+	tasks := make(chan int)
+	workers := &sync.WaitGroup{}
+	workers.Add(nbGoRoutines)
+
+	for i := 0; i < nbGoRoutines; i++ {
+
+		// The receiver is the evaluator of the first goroutine
+		worker := eval
+		if i > 0 {
+			worker = eval.workers[i-1]
 		}
 
-		// LUT[RLWE] = LUT[RLWE] * X^{m+e}
+		go func(worker *Evaluator) {
+			for j := range tasks {
+				res[j] = worker.EvaluateLWE(cts[j], lutPoly, key)
+			}
+			workers.Done()
+		}(worker)
+	}
+
+	for j := range cts {
+		tasks <- j
 	}
+	close(tasks)
+
+	workers.Wait()
 
 	return
 }
 
+// multiplyFactors returns the products of the accumulator with the factors of mvLUT.
+func (eval *Evaluator) multiplyFactors(mvLUT *MultiValueLUT) (res []*rlwe.Ciphertext) {
+
+	acc := eval.accumulator
+	level := acc.Level()
+	ringQ := eval.paramsLUT.RingQ()
+
+	res = make([]*rlwe.Ciphertext, len(mvLUT.Factors))
+	for i, factor := range mvLUT.Factors {
+		res[i] = rlwe.NewCiphertextNTT(eval.paramsLUT, 1, level)
+		ringQ.MulCoeffsMontgomeryLvl(level, acc.Value[0], factor, res[i].Value[0])
+		ringQ.MulCoeffsMontgomeryLvl(level, acc.Value[1], factor, res[i].Value[1])
+	}
+
+	return
+}
+
+// checkLWE panics if ct is not of dimension paramsLWE.N().
+func (eval *Evaluator) checkLWE(op string, ct *rlwe.LWECiphertext) {
+	if ct.N() != eval.paramsLWE.N() {
+		panic(rlwe.NewOperationError(op, rlwe.ErrInvalidOperand, "LWE ciphertext has dimension %d instead of %d", ct.N(), eval.paramsLWE.N()))
+	}
+}
+
+// blindRotate evaluates LUT * X^{b + <a, s>} on the accumulator, where a and b are given modulo 2N.
+func (eval *Evaluator) blindRotate(a []uint64, b uint64, lut *ring.Poly, key EvaluationKey) {
+
+	acc := eval.accumulator
+
+	ringQLUT := eval.paramsLUT.RingQ()
+	ringQPLUT := *eval.paramsLUT.RingQP()
+
+	// mod 2N
+	mask := uint64(ringQLUT.N<<1) - 1
+
+	levelQ := key.SkPos[0].LevelQ()
+	levelP := key.SkPos[0].LevelP()
+
+	// LWE = -as + m + e, a
+	// LUT = LUT * X^{-as + m + e}
+	ringQLUT.MulCoeffsMontgomery(lut, eval.xPowMinusOne[b].Q, acc.Value[0])
+	ringQLUT.Add(acc.Value[0], lut, acc.Value[0])
+	acc.Value[1].Zero()
+
+	for j := range a {
+		// RGSW[(X^{a} - 1) * sk_{j}[0] + (X^{-a} - 1) * sk_{j}[1] + 1]
+		rgsw.MulByXPowAlphaMinusOneConstantLvl(levelQ, levelP, key.SkPos[j], eval.xPowMinusOne[a[j]], ringQPLUT, eval.tmpRGSW)
+		rgsw.MulByXPowAlphaMinusOneAndAddNoModLvl(levelQ, levelP, key.SkNeg[j], eval.xPowMinusOne[-a[j]&mask], ringQPLUT, eval.tmpRGSW)
+		rgsw.AddNoModLvl(levelQ, levelP, eval.one, ringQPLUT, eval.tmpRGSW)
+
+		// LUT[RLWE] = LUT[RLWE] x RGSW[(X^{a} - 1) * sk_{j}[0] + (X^{-a} - 1) * sk_{j}[1] + 1]
+		eval.ExternalProduct(acc, eval.tmpRGSW, acc)
+	}
+}
+
 // ModSwitchRLWETo2NLvl applies round(x * 2N / Q) to the coefficients of polQ and returns the result on pol2N.
 func (eval *Evaluator) ModSwitchRLWETo2NLvl(level int, polQ *ring.Poly, pol2N *ring.Poly) {
 	coeffsBigint := make([]*big.Int, len(polQ.Coeffs[0]))
@@ -1,6 +1,9 @@
 package lut
 
 import (
+	"encoding/binary"
+	"fmt"
+
 	"github.com/tuneinsight/lattigo/v3/rgsw"
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
@@ -13,6 +16,72 @@ type EvaluationKey struct {
 	SkNeg []*rgsw.Ciphertext
 }
 
+// GetDataLen returns the length in bytes of the target EvaluationKey.
+func (key *EvaluationKey) GetDataLen() (dataLen int) {
+	dataLen = 4
+	for i := range key.SkPos {
+		dataLen += key.SkPos[i].GetDataLen(true) + key.SkNeg[i].GetDataLen(true)
+	}
+	return
+}
+
+// MarshalBinary encodes the target EvaluationKey on a slice of bytes.
+func (key *EvaluationKey) MarshalBinary() (data []byte, err error) {
+
+	data = make([]byte, key.GetDataLen())
+	binary.BigEndian.PutUint32(data, uint32(len(key.SkPos)))
+
+	pointer := 4
+	for i := range key.SkPos {
+		if pointer, err = key.SkPos[i].Encode(pointer, data); err != nil {
+			return nil, err
+		}
+		if pointer, err = key.SkNeg[i].Encode(pointer, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target EvaluationKey.
+func (key *EvaluationKey) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 4 {
+		return fmt.Errorf("%w: data is too short", rlwe.ErrMalformed)
+	}
+
+	n := int(binary.BigEndian.Uint32(data))
+
+	// each RGSW ciphertext is encoded on at least 4 bytes
+	if n == 0 || (len(data)-4)/8 < n {
+		return fmt.Errorf("%w: invalid number of RGSW ciphertexts %d", rlwe.ErrMalformed, n)
+	}
+
+	key.SkPos = make([]*rgsw.Ciphertext, n)
+	key.SkNeg = make([]*rgsw.Ciphertext, n)
+
+	pointer := 4
+	for i := 0; i < n; i++ {
+		for _, ct := range []**rgsw.Ciphertext{&key.SkPos[i], &key.SkNeg[i]} {
+
+			*ct = new(rgsw.Ciphertext)
+
+			var inc int
+			if inc, err = (*ct).Decode(data[pointer:]); err != nil {
+				return
+			}
+			pointer += inc
+		}
+	}
+
+	if pointer != len(data) {
+		return fmt.Errorf("%w: remaining unparsed data", rlwe.ErrMalformed)
+	}
+
+	return
+}
+
 // GenEvaluationKey generates the LUT evaluation key
 func GenEvaluationKey(paramsRLWE rlwe.Parameters, skRLWE *rlwe.SecretKey, paramsLWE rlwe.Parameters, skLWE *rlwe.SecretKey) (key EvaluationKey) {
 
@@ -32,3 +32,47 @@ func InitLUT(g func(x float64) (y float64), scale float64, ringQ *ring.Ring, a,
 
 	return
 }
+
+// MultiValueLUT is a set of LUTs on the same input that are evaluated with a single blind rotation,
+// following Carpov, Izabachène and Mollimard, "New Techniques for Multi-value Input Homomorphic
+// Evaluation and Applications" (CT-RSA 2019).
+// The LUT of each function g_i is factored as TestPoly * Factors[i], where TestPoly = scale/2 * (1 + X + ... + X^{N-1})
+// is the polynomial that is blind rotated and Factors[i] = (1 - X) * round(g_i) has small coefficients.
+// The error of the i-th output is the error of the blind rotation multiplied by the L1 norm of Factors[i].
+type MultiValueLUT struct {
+	TestPoly *ring.Poly   // blind rotated polynomial, in the NTT domain
+	Factors  []*ring.Poly // factors of the LUTs, in the NTT and Montgomery domain
+}
+
+// InitMultiValueLUT takes functions g, and creates a MultiValueLUT for the functions in the interval [a, b],
+// with the same conventions as InitLUT. The values of the functions are rounded to the nearest integer before
+// being scaled, so the functions should take small integer values.
+func InitMultiValueLUT(g []func(x float64) (y float64), scale float64, ringQ *ring.Ring, a, b float64) (mvLUT *MultiValueLUT) {
+
+	mvLUT = &MultiValueLUT{TestPoly: ringQ.NewPoly(), Factors: make([]*ring.Poly, len(g))}
+
+	for j, qi := range ringQ.Modulus {
+		half := scaleUp(0.5, scale, qi)
+		for i := 0; i < ringQ.N; i++ {
+			mvLUT.TestPoly.Coeffs[j][i] = half
+		}
+	}
+	ringQ.NTT(mvLUT.TestPoly, mvLUT.TestPoly)
+
+	// (1 - X) * (1 + X + ... + X^{N-1}) = 1 - X^N = 2 mod X^N + 1
+	oneMinusX := ringQ.NewPoly()
+	for j, qi := range ringQ.Modulus {
+		oneMinusX.Coeffs[j][0] = 1
+		oneMinusX.Coeffs[j][1] = qi - 1
+	}
+	ringQ.NTT(oneMinusX, oneMinusX)
+	ringQ.MForm(oneMinusX, oneMinusX)
+
+	for i := range g {
+		mvLUT.Factors[i] = InitLUT(g[i], 1, ringQ, a, b)
+		ringQ.MulCoeffsMontgomery(mvLUT.Factors[i], oneMinusX, mvLUT.Factors[i])
+		ringQ.MForm(mvLUT.Factors[i], mvLUT.Factors[i])
+	}
+
+	return
+}
@@ -0,0 +1,103 @@
+package lut
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+func BenchmarkLUT(b *testing.B) {
+
+	paramsLUT, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{
+		LogN:     10,
+		LogQ:     []int{40},
+		Pow2Base: 7,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	paramsLWE, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{
+		LogN: 9,
+		Q:    []uint64{0x3001},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	skLWE := rlwe.NewKeyGenerator(paramsLWE).GenSecretKey()
+	skLUT := rlwe.NewKeyGenerator(paramsLUT).GenSecretKey()
+	key := GenEvaluationKey(paramsLUT, skLUT, paramsLWE, skLWE)
+
+	eval := NewEvaluator(paramsLUT, paramsLWE, nil)
+
+	ctLWE := rlwe.NewCiphertextNTT(paramsLWE, 1, paramsLWE.MaxLevel())
+	rlwe.NewEncryptor(paramsLWE, skLWE).Encrypt(rlwe.NewPlaintext(paramsLWE, paramsLWE.MaxLevel()), ctLWE)
+
+	for _, testSet := range []func(eval *Evaluator, ct *rlwe.Ciphertext, key EvaluationKey, b *testing.B){
+		benchMultiValue,
+		benchBatch,
+	} {
+		testSet(eval, ctLWE, key, b)
+		runtime.GC()
+	}
+}
+
+func benchMultiValue(eval *Evaluator, ct *rlwe.Ciphertext, key EvaluationKey, b *testing.B) {
+
+	ringQ := eval.paramsLUT.RingQ()
+
+	g := []func(x float64) float64{sign, sign, sign, sign}
+
+	lutPoly := InitLUT(sign, 1, ringQ, -1, 1)
+	mvLUT := InitMultiValueLUT(g, 1, ringQ, -1, 1)
+
+	// Evaluation of the len(g) LUTs on the first slot of ct
+	lutPolyMap := map[int]*ring.Poly{0: lutPoly}
+	mvLUTMap := map[int]*MultiValueLUT{0: mvLUT}
+
+	b.Run(testString(eval.paramsLUT, "LUT/Evaluate/4xLUT/"), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for range g {
+				eval.Evaluate(ct, lutPolyMap, key)
+			}
+		}
+	})
+
+	b.Run(testString(eval.paramsLUT, "LUT/EvaluateMultiValue/4xLUT/"), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			eval.EvaluateMultiValue(ct, mvLUTMap, key)
+		}
+	})
+}
+
+func benchBatch(eval *Evaluator, ct *rlwe.Ciphertext, key EvaluationKey, b *testing.B) {
+
+	slots := 16
+
+	lutPoly := InitLUT(sign, 1, eval.paramsLUT.RingQ(), -1, 1)
+
+	lutPolyMap := make(map[int]*ring.Poly)
+	cts := make([]*rlwe.LWECiphertext, slots)
+	evalLWE := rlwe.NewEvaluator(eval.paramsLWE, nil)
+	for i := range cts {
+		lutPolyMap[i] = lutPoly
+		cts[i] = evalLWE.SampleExtract(ct, i)
+	}
+
+	b.Run(testString(eval.paramsLUT, "LUT/Evaluate/16xSlot/"), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			eval.Evaluate(ct, lutPolyMap, key)
+		}
+	})
+
+	nbGoRoutines := runtime.NumCPU()
+
+	b.Run(testString(eval.paramsLUT, "LUT/EvaluateLWEBatch/16xSlot/"), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			eval.EvaluateLWEBatch(cts, lutPoly, key, nbGoRoutines)
+		}
+	})
+}
@@ -25,6 +25,7 @@ func testString(params rlwe.Parameters, opname string) string {
 func TestLUT(t *testing.T) {
 	for _, testSet := range []func(t *testing.T){
 		testLUT,
+		testMultiValueLUT,
 	} {
 		testSet(t)
 		runtime.GC()
@@ -148,3 +149,111 @@ func testLUT(t *testing.T) {
 		}
 	})
 }
+
+// testMultiValueLUT tests the multi-value and the batched evaluation of LUTs on small integers
+// encoded as m * Q/16 with m in [-3, 3].
+func testMultiValueLUT(t *testing.T) {
+
+	// The factors of the multi-value LUTs multiply the error of the blind rotation,
+	// which must be small compared to the scale of the LUTs.
+	paramsLUT, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{
+		LogN:     10,
+		LogQ:     []int{40},
+		Pow2Base: 7,
+	})
+	assert.Nil(t, err)
+
+	paramsLWE, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{
+		LogN: 9,
+		Q:    []uint64{0x3001},
+	})
+	assert.Nil(t, err)
+
+	// The normalized input m/4 is mapped back to m by the interval [-4, 4]
+	g := []func(x float64) float64{
+		func(x float64) float64 { return math.Round(x) },
+		func(x float64) float64 { return math.Round(x) * math.Round(x) },
+		func(x float64) float64 { return 2 * sign(math.Round(x)) },
+	}
+
+	scaleLWE := float64(paramsLWE.Q()[0]) / 16.0
+	scaleLUT := float64(paramsLUT.Q()[0]) / 32.0
+
+	mvLUT := InitMultiValueLUT(g, scaleLUT, paramsLUT.RingQ(), -4, 4)
+
+	skLWE := rlwe.NewKeyGenerator(paramsLWE).GenSecretKey()
+	skLUT := rlwe.NewKeyGenerator(paramsLUT).GenSecretKey()
+	key := GenEvaluationKey(paramsLUT, skLUT, paramsLWE, skLWE)
+
+	eval := NewEvaluator(paramsLUT, paramsLWE, nil)
+	evalLWE := rlwe.NewEvaluator(paramsLWE, nil)
+
+	values := []int64{-3, -2, -1, 0, 1, 2, 3}
+
+	ptLWE := rlwe.NewPlaintext(paramsLWE, paramsLWE.MaxLevel())
+	for i, v := range values {
+		ptLWE.Value.Coeffs[0][i] = scaleUp(float64(v), scaleLWE, paramsLWE.Q()[0])
+	}
+	ctLWE := rlwe.NewCiphertextNTT(paramsLWE, 1, paramsLWE.MaxLevel())
+	rlwe.NewEncryptor(paramsLWE, skLWE).Encrypt(ptLWE, ctLWE)
+
+	decryptorLUT := rlwe.NewDecryptor(paramsLUT, skLUT)
+	ptLUT := rlwe.NewPlaintext(paramsLUT, paramsLUT.MaxLevel())
+
+	// decode returns the value of the i-th coefficient of the decryption of ct, divided by scaleLUT
+	decode := func(ct *rlwe.Ciphertext, i int) float64 {
+		decryptorLUT.Decrypt(ct, ptLUT)
+		q := paramsLUT.Q()[0]
+		c := ptLUT.Value.Coeffs[0][i]
+		if c >= q>>1 {
+			return -math.Round(float64(q-c) / scaleLUT)
+		}
+		return math.Round(float64(c) / scaleLUT)
+	}
+
+	t.Run(testString(paramsLUT, "LUT/MultiValue/"), func(t *testing.T) {
+
+		lutMap := make(map[int]*MultiValueLUT)
+		for i := range values {
+			lutMap[i] = mvLUT
+		}
+
+		res := eval.EvaluateMultiValue(ctLWE, lutMap, key)
+
+		for i, v := range values {
+			assert.Equal(t, len(g), len(res[i]))
+			for j := range g {
+				assert.Equal(t, g[j](float64(v)), decode(res[i][j], i), "g_%d(%d)", j, v)
+			}
+		}
+	})
+
+	cts := make([]*rlwe.LWECiphertext, len(values))
+	for i := range values {
+		cts[i] = evalLWE.SampleExtract(ctLWE, i)
+	}
+
+	t.Run(testString(paramsLUT, "LUT/MultiValueLWE/"), func(t *testing.T) {
+		for i, v := range values {
+			res := eval.EvaluateLWEMultiValue(cts[i], mvLUT, key)
+			for j := range g {
+				assert.Equal(t, g[j](float64(v)), decode(res[j], 0), "g_%d(%d)", j, v)
+			}
+		}
+	})
+
+	t.Run(testString(paramsLUT, "LUT/Batch/"), func(t *testing.T) {
+
+		lutPoly := InitLUT(g[1], scaleLUT, paramsLUT.RingQ(), -4, 4)
+
+		for _, nbGoRoutines := range []int{1, 3} {
+			res := eval.EvaluateLWEBatch(cts, lutPoly, key, nbGoRoutines)
+			assert.Equal(t, len(values), len(res))
+			for i, v := range values {
+				assert.Equal(t, g[1](float64(v)), decode(res[i], 0), "g(%d) with %d goroutines", v, nbGoRoutines)
+			}
+		}
+
+		assert.Panics(t, func() { eval.EvaluateLWEBatch([]*rlwe.LWECiphertext{rlwe.NewLWECiphertext(1, 2)}, lutPoly, key, 1) })
+	})
+}
@@ -44,7 +44,7 @@ func scaleUp(value float64, scale float64, Q uint64) (res uint64) {
 
 	res = xInt.Uint64()
 
-	if isNegative {
+	if isNegative && res != 0 {
 		res = Q - res
 	}
 
@@ -0,0 +1,105 @@
+//go:build go1.18
+// +build go1.18
+
+package ring
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// fuzzRing returns a small ring and the encodings of a random polynomial of this ring, used as seed corpus.
+func fuzzRing(f *testing.F) (ringQ *Ring) {
+
+	ringQ, err := NewRing(16, DefaultParams[0].qi)
+	require.NoError(f, err)
+
+	prng, err := utils.NewPRNG()
+	require.NoError(f, err)
+
+	pol := NewUniformSampler(prng, ringQ).ReadNew()
+	pol.IsNTT = true
+
+	data, err := pol.MarshalBinary()
+	require.NoError(f, err)
+	f.Add(data)
+	f.Add(data[:len(data)/2])
+
+	if data, err = pol.MarshalBinaryPacked(); err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+
+	data32 := make([]byte, pol.GetDataLen32(true))
+	_, err = pol.WriteTo32(data32)
+	require.NoError(f, err)
+	f.Add(data32)
+
+	return
+}
+
+func FuzzPolyUnmarshalBinary(f *testing.F) {
+
+	ringQ := fuzzRing(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		pol := new(Poly)
+		if err := pol.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		// the size of the encoding bounds the size of the decoded polynomial
+		require.LessOrEqual(t, len(pol.Coeffs)*pol.N(), 8*len(data))
+
+		if ringQ.Validate(pol) == nil {
+			dataTest, err := pol.MarshalBinary()
+			require.NoError(t, err)
+			require.NoError(t, new(Poly).UnmarshalBinary(dataTest))
+		}
+	})
+}
+
+func FuzzPolyDecodePoly32(f *testing.F) {
+
+	fuzzRing(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		pol := new(Poly)
+		n, err := pol.DecodePoly32(data)
+		if err != nil {
+			return
+		}
+
+		require.LessOrEqual(t, n, len(data))
+		require.LessOrEqual(t, len(pol.Coeffs)*pol.N(), 4*len(data))
+	})
+}
+
+func FuzzPolyReadFrom(f *testing.F) {
+
+	fuzzRing(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		pol := new(Poly)
+		n, err := pol.ReadFrom(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		require.LessOrEqual(t, n, int64(len(data)))
+
+		// ReadFrom and DecodePoly64 agree on the valid encodings
+		polTest := new(Poly)
+		m, err := polTest.DecodePoly64(data)
+		require.NoError(t, err)
+		require.Equal(t, n, int64(m))
+		require.True(t, pol.Equals(polTest))
+	})
+}
@@ -567,3 +567,33 @@ func (r *Ring) EqualLvl(level int, p1, p2 *Poly) bool {
 
 	return true
 }
+
+// Validate checks that pol is a well-formed polynomial of the ring: its degree is N, its level is at most the
+// maximum level of the ring and its coefficients are reduced modulo the moduli of the ring.
+// It should be called on polynomials decoded from untrusted sources before using them.
+func (r *Ring) Validate(pol *Poly) error {
+
+	if pol == nil || len(pol.Coeffs) == 0 {
+		return fmt.Errorf("invalid polynomial: polynomial is empty")
+	}
+
+	if pol.Level() > len(r.Modulus)-1 {
+		return fmt.Errorf("invalid polynomial: level %d is larger than the maximum level %d", pol.Level(), len(r.Modulus)-1)
+	}
+
+	for i, coeffs := range pol.Coeffs {
+
+		if len(coeffs) != r.N {
+			return fmt.Errorf("invalid polynomial: limb %d has %d coefficients instead of %d", i, len(coeffs), r.N)
+		}
+
+		qi := r.Modulus[i]
+		for j, c := range coeffs {
+			if c >= qi {
+				return fmt.Errorf("invalid polynomial: coefficient %d of limb %d is not reduced modulo %d", j, i, qi)
+			}
+		}
+	}
+
+	return nil
+}
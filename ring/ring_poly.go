@@ -3,8 +3,56 @@ package ring
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 )
 
+// MaxDecodeLogN is the log2 of the largest degree of the polynomials read by the decoders of this package.
+// With the checks on the length of the data, it bounds the memory they allocate on untrusted input.
+const MaxDecodeLogN = 20
+
+// errInvalidEncoding is the error returned by the decoders on a malformed polynomial encoding.
+var errInvalidEncoding = errors.New("invalid polynomial encoding")
+
+// decodeHeader decodes the header of a polynomial encoding and sets the flags of the target polynomial.
+// It checks that N is a power of two not larger than 2^MaxDecodeLogN and that the flags are valid.
+func (pol *Poly) decodeHeader(header []byte) (N, Level int, packed bool, err error) {
+
+	if len(header) < 7 {
+		return 0, 0, false, fmt.Errorf("%w: header is truncated", errInvalidEncoding)
+	}
+
+	N = int(binary.BigEndian.Uint32(header))
+	Level = int(header[4])
+
+	if N < 1 || N > 1<<MaxDecodeLogN || N&(N-1) != 0 {
+		return 0, 0, false, fmt.Errorf("%w: N=%d is not a power of two in [1, 2^%d]", errInvalidEncoding, N, MaxDecodeLogN)
+	}
+
+	if header[5]&^(PackedFlag|1) != 0 || header[6] > 1 {
+		return 0, 0, false, fmt.Errorf("%w: invalid flags", errInvalidEncoding)
+	}
+
+	packed = header[5]&PackedFlag != 0
+	pol.IsNTT = header[5]&1 == 1
+	pol.IsMForm = header[6] == 1
+
+	return
+}
+
+// resize reslices the buffer of the target polynomial to N coefficients and Level+1 moduli, and
+// allocates a new one if the buffer does not have the correct size.
+func (pol *Poly) resize(N, Level int) {
+
+	if pol.Buff == nil || len(pol.Buff) != N*(Level+1) {
+		pol.Buff = make([]uint64, N*(Level+1))
+	}
+
+	pol.Coeffs = make([][]uint64, Level+1)
+	for i := 0; i < Level+1; i++ {
+		pol.Coeffs[i] = pol.Buff[i*N : (i+1)*N]
+	}
+}
+
 // Poly is the structure that contains the coefficients of a polynomial.
 type Poly struct {
 	Coeffs  [][]uint64 // Dimension-2 slice of coefficients (re-slice of Buff)
@@ -169,28 +217,17 @@ func (pol *Poly) MarshalBinary() (data []byte, err error) {
 }
 
 // UnmarshalBinary decodes a slice of byte on the target polynomial.
-// Assumes each coefficient is encoded on 8 bytes.
+// Assumes each coefficient is encoded on 8 bytes, unless the PackedFlag is set in the header.
+// It returns an error if the data is not exactly one polynomial encoding.
 func (pol *Poly) UnmarshalBinary(data []byte) (err error) {
 
-	N := int(binary.BigEndian.Uint32(data))
-	Level := int(data[4])
-
-	if data[5] == 1 {
-		pol.IsNTT = true
-	}
-
-	if data[6] == 1 {
-		pol.IsMForm = true
-	}
-
-	pointer := 7
-
-	if ((len(data) - pointer) >> 3) != N*(Level+1) {
-		return errors.New("invalid polynomial encoding")
+	var pointer int
+	if pointer, err = pol.DecodePoly64(data); err != nil {
+		return err
 	}
 
-	if _, err = pol.DecodePoly64(data); err != nil {
-		return err
+	if pointer != len(data) {
+		return fmt.Errorf("%w: %d bytes of remaining data", errInvalidEncoding, len(data)-pointer)
 	}
 
 	return nil
@@ -235,42 +272,39 @@ func WriteCoeffsTo64(pointer int, coeffs []uint64, data []byte) (int, error) {
 // DecodePoly64 decodes a slice of bytes in the target polynomial and returns the number of bytes decoded.
 // The method will first try to write on the buffer. If this step fails, either because the buffer isn't
 // allocated or because it is of the wrong size, the method will allocate the correct buffer.
-// Assumes that each coefficient is encoded on 8 bytes.
+// Assumes that each coefficient is encoded on 8 bytes, unless the PackedFlag is set in the header,
+// in which case the polynomial is decoded as written by WriteToPacked.
+// It returns an error, before allocating, if the header is invalid or if the data is too short.
 func (pol *Poly) DecodePoly64(data []byte) (pointer int, err error) {
 
-	N := int(binary.BigEndian.Uint32(data))
-	Level := int(data[4])
-
-	if data[5] == 1 {
-		pol.IsNTT = true
+	N, Level, packed, err := pol.decodeHeader(data)
+	if err != nil {
+		return 0, err
 	}
 
-	if data[6] == 1 {
-		pol.IsMForm = true
+	if packed {
+		return pol.decodePolyPacked(N, Level, data)
 	}
 
 	pointer = 7
 
-	if pol.Buff == nil || len(pol.Buff) != N*(Level+1) {
-		pol.Buff = make([]uint64, N*(Level+1))
+	if len(data)-pointer < GetDataLen64(N, Level, false) {
+		return 0, fmt.Errorf("%w: data is too short", errInvalidEncoding)
 	}
 
-	if pointer, err = DecodeCoeffs64(pointer, pol.Buff, data); err != nil {
-		return pointer, err
-	}
+	pol.resize(N, Level)
 
-	// Reslice
-	pol.Coeffs = make([][]uint64, Level+1)
-	for i := 0; i < Level+1; i++ {
-		pol.Coeffs[i] = pol.Buff[i*N : (i+1)*N]
-	}
-
-	return pointer, nil
+	return DecodeCoeffs64(pointer, pol.Buff, data)
 }
 
 // DecodeCoeffs64 converts a byte array to a matrix of coefficients.
 // Assumes that each coefficient is encoded on 8 bytes.
 func DecodeCoeffs64(pointer int, coeffs []uint64, data []byte) (int, error) {
+
+	if pointer < 0 || len(data)-pointer < len(coeffs)<<3 {
+		return pointer, fmt.Errorf("%w: data is too short", errInvalidEncoding)
+	}
+
 	for i, j := 0, pointer; i < len(coeffs); i, j = i+1, j+8 {
 		coeffs[i] = binary.BigEndian.Uint64(data[j:])
 	}
@@ -339,42 +373,38 @@ func (pol *Poly) GetDataLen32(WithMetadata bool) (cnt int) {
 // DecodePoly32 decodes a slice of bytes in the target polynomial returns the number of bytes decoded.
 // The method will first try to write on the buffer. If this step fails, either because the buffer isn't
 // allocated or because it is of the wrong size, the method will allocate the correct buffer.
-// Assumes that each coefficient is encoded on 8 bytes.
+// Assumes that each coefficient is encoded on 4 bytes.
+// It returns an error, before allocating, if the header is invalid or if the data is too short.
 func (pol *Poly) DecodePoly32(data []byte) (pointer int, err error) {
 
-	N := int(binary.BigEndian.Uint32(data))
-	Level := int(data[4])
-
-	if data[5] == 1 {
-		pol.IsNTT = true
+	N, Level, packed, err := pol.decodeHeader(data)
+	if err != nil {
+		return 0, err
 	}
 
-	if data[6] == 1 {
-		pol.IsMForm = true
+	if packed {
+		return 0, fmt.Errorf("%w: DecodePoly32 cannot decode the packed encoding", errInvalidEncoding)
 	}
 
 	pointer = 7
 
-	if pol.Buff == nil || len(pol.Buff) != N*(Level+1) {
-		pol.Buff = make([]uint64, N*(Level+1))
-	}
-
-	if pointer, err = DecodeCoeffs32(pointer, pol.Buff, data); err != nil {
-		return pointer, err
+	if len(data)-pointer < GetPolyDataLen32(N, Level, false) {
+		return 0, fmt.Errorf("%w: data is too short", errInvalidEncoding)
 	}
 
-	pol.Coeffs = make([][]uint64, Level+1)
+	pol.resize(N, Level)
 
-	for i := 0; i < Level+1; i++ {
-		pol.Coeffs[i] = pol.Buff[i*N : (i+1)*N]
-	}
-
-	return pointer, nil
+	return DecodeCoeffs32(pointer, pol.Buff, data)
 }
 
 // DecodeCoeffs32 converts a byte array to a matrix of coefficients.
 // Assumes that each coefficient is encoded on 4 bytes.
 func DecodeCoeffs32(pointer int, coeffs []uint64, data []byte) (int, error) {
+
+	if pointer < 0 || len(data)-pointer < len(coeffs)<<2 {
+		return pointer, fmt.Errorf("%w: data is too short", errInvalidEncoding)
+	}
+
 	for i, j := 0, pointer; i < len(coeffs); i, j = i+1, j+4 {
 		coeffs[i] = uint64(binary.BigEndian.Uint32(data[j:]))
 	}
@@ -0,0 +1,220 @@
+package ring
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// PackedFlag is the bit set on the NTT flag byte of the header of the polynomials written with WriteToPacked.
+// DecodePoly64 and UnmarshalBinary check this bit and decode either encoding.
+const PackedFlag = 0x80
+
+// PolyEncoder is the interface of the polynomial types that can be written with the 64-bit and the packed encodings.
+type PolyEncoder interface {
+	GetDataLen64(WithMetadata bool) int
+	WriteTo64(data []byte) (int, error)
+	GetDataLenPacked(WithMetadata bool) int
+	WriteToPacked(data []byte) (int, error)
+}
+
+// GetDataLen returns the number of bytes p will take when written with WriteTo.
+func GetDataLen(p PolyEncoder, WithMetadata, packed bool) int {
+	if packed {
+		return p.GetDataLenPacked(WithMetadata)
+	}
+	return p.GetDataLen64(WithMetadata)
+}
+
+// WriteTo writes p on data with WriteToPacked if packed is true and with WriteTo64 otherwise.
+func WriteTo(p PolyEncoder, data []byte, packed bool) (int, error) {
+	if packed {
+		return p.WriteToPacked(data)
+	}
+	return p.WriteTo64(data)
+}
+
+// widths returns the bit-size of the largest coefficient of each RNS limb of the polynomial, and at least one.
+// As the coefficients are reduced modulo q_i, it is at most ceil(log2(q_i)). Since the bit-sizes are not zero,
+// the size of the encoding bounds the memory allocated by the decoders.
+func (pol *Poly) widths() (w []int) {
+	w = make([]int, len(pol.Coeffs))
+	for i, coeffs := range pol.Coeffs {
+		var max uint64 = 1
+		for _, c := range coeffs {
+			max |= c
+		}
+		w[i] = bits.Len64(max)
+	}
+	return
+}
+
+// GetDataLenPacked returns the number of bytes the polynomial will take when written with WriteToPacked.
+// It can take into account meta data if necessary.
+func (pol *Poly) GetDataLenPacked(WithMetadata bool) (cnt int) {
+
+	if WithMetadata {
+		cnt += 7 + pol.Level() + 1
+	}
+
+	N := pol.N()
+	for _, w := range pol.widths() {
+		cnt += (N*w + 7) >> 3
+	}
+
+	return
+}
+
+// MarshalBinaryPacked encodes the target polynomial on a slice of bytes with WriteToPacked.
+func (pol *Poly) MarshalBinaryPacked() (data []byte, err error) {
+	data = make([]byte, pol.GetDataLenPacked(true))
+	_, err = pol.WriteToPacked(data)
+	return
+}
+
+// WriteToPacked writes the given poly to the data array, using for the coefficients of each RNS limb the bit-size of
+// the largest of them, which is at most ceil(log2(q_i)). The header is the one of WriteTo64 with PackedFlag set,
+// followed by one byte per RNS limb for its bit-size. It returns the number of written bytes, and the corresponding
+// error, if it occurred.
+func (pol *Poly) WriteToPacked(data []byte) (int, error) {
+
+	N := pol.N()
+	Level := pol.Level()
+
+	if len(data) < pol.GetDataLenPacked(true) {
+		// The data is not big enough to write all the information
+		return 0, errors.New("data array is too small to write ring.Poly")
+	}
+
+	binary.BigEndian.PutUint32(data, uint32(N))
+
+	data[4] = uint8(Level)
+
+	data[5] = PackedFlag
+	if pol.IsNTT {
+		data[5] |= 1
+	}
+
+	data[6] = 0
+	if pol.IsMForm {
+		data[6] = 1
+	}
+
+	pointer := 7
+
+	widths := pol.widths()
+	for _, w := range widths {
+		data[pointer] = uint8(w)
+		pointer++
+	}
+
+	for i, coeffs := range pol.Coeffs {
+		pointer += writeCoeffsPacked(coeffs, widths[i], data[pointer:])
+	}
+
+	return pointer, nil
+}
+
+// decodePolyPacked decodes a slice of bytes written with WriteToPacked, whose header was decoded by decodeHeader,
+// in the target polynomial and returns the number of bytes decoded.
+func (pol *Poly) decodePolyPacked(N, Level int, data []byte) (pointer int, err error) {
+
+	pointer = 7
+
+	if len(data) < pointer+Level+1 {
+		return 0, fmt.Errorf("%w: header is truncated", errInvalidEncoding)
+	}
+
+	widths := make([]int, Level+1)
+	size := pointer + Level + 1
+	for i := range widths {
+		if widths[i] = int(data[pointer+i]); widths[i] < 1 || widths[i] > 64 {
+			return 0, fmt.Errorf("%w: invalid bit-size %d", errInvalidEncoding, widths[i])
+		}
+		size += (N*widths[i] + 7) >> 3
+	}
+	pointer += Level + 1
+
+	if len(data) < size {
+		return 0, fmt.Errorf("%w: data is too short", errInvalidEncoding)
+	}
+
+	pol.resize(N, Level)
+
+	for i := range pol.Coeffs {
+		pointer += readCoeffsPacked(pol.Coeffs[i], widths[i], data[pointer:])
+	}
+
+	return pointer, nil
+}
+
+// writeCoeffsPacked writes the w least significant bits of each coefficient on data and returns the number of
+// written bytes. The last byte is padded with zeros.
+func writeCoeffsPacked(coeffs []uint64, w int, data []byte) (pointer int) {
+
+	var acc uint64
+	var n int // number of bits in acc, always smaller than 8 between two coefficients
+
+	for _, c := range coeffs {
+		for rem := w; rem > 0; {
+
+			take := rem
+			if take > 64-n {
+				take = 64 - n
+			}
+
+			acc |= (c & (1<<uint(take) - 1)) << uint(n)
+			c >>= uint(take)
+			n += take
+			rem -= take
+
+			for ; n >= 8; n -= 8 {
+				data[pointer] = byte(acc)
+				acc >>= 8
+				pointer++
+			}
+		}
+	}
+
+	if n > 0 {
+		data[pointer] = byte(acc)
+		pointer++
+	}
+
+	return
+}
+
+// readCoeffsPacked reads coefficients of w bits written by writeCoeffsPacked and returns the number of read bytes.
+func readCoeffsPacked(coeffs []uint64, w int, data []byte) (pointer int) {
+
+	var acc uint64
+	var n int // number of unread bits in acc
+
+	for i := range coeffs {
+
+		var c uint64
+		for got := 0; got < w; {
+
+			if n == 0 {
+				acc = uint64(data[pointer])
+				pointer++
+				n = 8
+			}
+
+			take := w - got
+			if take > n {
+				take = n
+			}
+
+			c |= (acc & (1<<uint(take) - 1)) << uint(got)
+			acc >>= uint(take)
+			n -= take
+			got += take
+		}
+
+		coeffs[i] = c
+	}
+
+	return
+}
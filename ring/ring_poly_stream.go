@@ -0,0 +1,128 @@
+package ring
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// WriteTo writes the polynomial on w with the layout of WriteTo64, one RNS limb at a time, so that at most
+// N*8 bytes are buffered. It returns the number of written bytes and implements the io.WriterTo interface.
+func (pol *Poly) WriteTo(w io.Writer) (n int64, err error) {
+
+	N := pol.N()
+	Level := pol.Level()
+
+	buff := make([]byte, N<<3)
+
+	binary.BigEndian.PutUint32(buff, uint32(N))
+	buff[4] = uint8(Level)
+	buff[5], buff[6] = 0, 0
+	if pol.IsNTT {
+		buff[5] = 1
+	}
+	if pol.IsMForm {
+		buff[6] = 1
+	}
+
+	var inc int
+	if inc, err = w.Write(buff[:7]); err != nil {
+		return n + int64(inc), err
+	}
+	n += int64(inc)
+
+	for _, coeffs := range pol.Coeffs {
+
+		for i, c := range coeffs {
+			binary.BigEndian.PutUint64(buff[i<<3:], c)
+		}
+
+		if inc, err = w.Write(buff); err != nil {
+			return n + int64(inc), err
+		}
+		n += int64(inc)
+	}
+
+	return
+}
+
+// ReadFrom reads on the target polynomial exactly one polynomial written with WriteTo, WriteTo64 or WriteToPacked,
+// one RNS limb at a time. It returns the number of read bytes and implements the io.ReaderFrom interface.
+// The reader should be buffered, as the header is read in several small reads.
+// The coefficients are allocated as they are read, so that a malformed header cannot make ReadFrom allocate
+// much more memory than the number of bytes available on r.
+func (pol *Poly) ReadFrom(r io.Reader) (n int64, err error) {
+
+	header := make([]byte, 7)
+
+	var inc int
+	if inc, err = io.ReadFull(r, header); err != nil {
+		return n + int64(inc), err
+	}
+	n += int64(inc)
+
+	N, Level, packed, err := pol.decodeHeader(header)
+	if err != nil {
+		return n, err
+	}
+
+	widths := make([]int, Level+1)
+	if packed {
+		ws := make([]byte, Level+1)
+		if inc, err = io.ReadFull(r, ws); err != nil {
+			return n + int64(inc), err
+		}
+		n += int64(inc)
+
+		for i := range widths {
+			if widths[i] = int(ws[i]); widths[i] < 1 || widths[i] > 64 {
+				return n, fmt.Errorf("%w: invalid bit-size %d", errInvalidEncoding, widths[i])
+			}
+		}
+	}
+
+	// Reuses the buffer if it has the correct size, else grows a new one limb by limb.
+	coeffs := pol.Buff
+	if len(coeffs) != N*(Level+1) {
+		coeffs = make([]uint64, 0, N)
+	}
+
+	buff := make([]byte, N<<3)
+
+	for i := 0; i < Level+1; i++ {
+
+		size := N << 3
+		if packed {
+			size = (N*widths[i] + 7) >> 3
+		}
+
+		if inc, err = io.ReadFull(r, buff[:size]); err != nil {
+			return n + int64(inc), err
+		}
+		n += int64(inc)
+
+		var limb []uint64
+		if len(coeffs) == N*(Level+1) {
+			limb = coeffs[i*N : (i+1)*N]
+		} else {
+			coeffs = append(coeffs, make([]uint64, N)...)
+			limb = coeffs[i*N:]
+		}
+
+		if packed {
+			readCoeffsPacked(limb, widths[i], buff)
+		} else {
+			for j := range limb {
+				limb[j] = binary.BigEndian.Uint64(buff[j<<3:])
+			}
+		}
+	}
+
+	pol.Buff = coeffs
+	pol.Coeffs = make([][]uint64, Level+1)
+	for i := range pol.Coeffs {
+		pol.Coeffs[i] = pol.Buff[i*N : (i+1)*N]
+	}
+
+	return
+}
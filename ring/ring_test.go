@@ -1,9 +1,12 @@
 package ring
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"math/big"
+	"math/bits"
 	"testing"
 
 	"github.com/tuneinsight/lattigo/v3/utils"
@@ -337,6 +340,125 @@ func testMarshalBinary(tc *testParams, t *testing.T) {
 			require.Equal(t, p.Coeffs[i][:tc.ringQ.N], pTest.Coeffs[i][:tc.ringQ.N])
 		}
 	})
+
+	t.Run(testString("MarshalBinary/Poly/Packed/", tc.ringQ), func(t *testing.T) {
+
+		p := tc.uniformSamplerQ.ReadNew()
+		p.IsNTT = true
+		p.Coeffs[0][0] = tc.ringQ.Modulus[0] - 1
+
+		// a limb of zeros is written on one bit, so that the size of the encoding bounds its decoding
+		if len(p.Coeffs) > 1 {
+			for j := range p.Coeffs[1] {
+				p.Coeffs[1][j] = 0
+			}
+		}
+
+		data, err := p.MarshalBinaryPacked()
+		require.NoError(t, err)
+
+		expected := 7
+		for i, qi := range tc.ringQ.Modulus {
+			w := bits.Len64(qi)
+			if i == 1 {
+				w = 1
+			}
+			expected += 1 + (tc.ringQ.N*w+7)/8
+		}
+		require.Equal(t, expected, len(data))
+
+		pTest := new(Poly)
+		require.NoError(t, pTest.UnmarshalBinary(data))
+		require.True(t, pTest.IsNTT)
+		require.False(t, pTest.IsMForm)
+		require.True(t, tc.ringQ.Equal(p, pTest))
+
+		// DecodePoly64 accepts both encodings
+		pTest = new(Poly)
+		n, err := pTest.DecodePoly64(data)
+		require.NoError(t, err)
+		require.Equal(t, len(data), n)
+		require.True(t, tc.ringQ.Equal(p, pTest))
+
+		require.Error(t, new(Poly).UnmarshalBinary(data[:len(data)-1]))
+	})
+
+	t.Run(testString("MarshalBinary/Poly/Malformed/", tc.ringQ), func(t *testing.T) {
+
+		p := tc.uniformSamplerQ.ReadNew()
+		require.NoError(t, tc.ringQ.Validate(p))
+
+		data, err := p.MarshalBinary()
+		require.NoError(t, err)
+
+		dataPacked, err := p.MarshalBinaryPacked()
+		require.NoError(t, err)
+
+		malformed := func(data []byte, i int, b byte) []byte {
+			data = append([]byte{}, data...)
+			data[i] = b
+			return data
+		}
+
+		for _, data := range [][]byte{
+			data[:6],
+			malformed(data, 3, 3),          // N is not a power of two
+			malformed(data, 1, 0x40),       // N > 2^MaxDecodeLogN
+			malformed(data, 5, 0x80),       // unknown flag
+			malformed(data, 6, 2),          // invalid boolean
+			malformed(data, 4, 0xFF),       // level larger than the data
+			malformed(dataPacked, 7, 0),    // bit-size 0
+			malformed(dataPacked, 7, 65),   // bit-size larger than 64
+			malformed(dataPacked, 4, 0xFF), // level larger than the data
+		} {
+			require.Error(t, new(Poly).UnmarshalBinary(data))
+			_, err = new(Poly).ReadFrom(bytes.NewReader(data[:len(data)-1]))
+			require.Error(t, err)
+		}
+
+		require.Error(t, new(Poly).UnmarshalBinary(append(data, 0))) // trailing data
+
+		// coefficient not reduced modulo q_0
+		p.Coeffs[0][0] = tc.ringQ.Modulus[0]
+		require.Error(t, tc.ringQ.Validate(p))
+
+		// wrong degree and level
+		require.Error(t, tc.ringQ.Validate(NewPoly(tc.ringQ.N/2, 0)))
+		require.Error(t, tc.ringQ.Validate(NewPoly(tc.ringQ.N, len(tc.ringQ.Modulus))))
+		require.Error(t, tc.ringQ.Validate(new(Poly)))
+	})
+
+	t.Run(testString("MarshalBinary/Poly/Stream/", tc.ringQ), func(t *testing.T) {
+
+		p := tc.uniformSamplerQ.ReadNew()
+		p.IsMForm = true
+
+		buf := new(bytes.Buffer)
+		n, err := p.WriteTo(buf)
+		require.NoError(t, err)
+		require.Equal(t, int64(buf.Len()), n)
+
+		data, err := p.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, data, buf.Bytes())
+
+		dataPacked, err := p.MarshalBinaryPacked()
+		require.NoError(t, err)
+
+		// ReadFrom reads exactly one polynomial in either encoding
+		r := bytes.NewReader(append(append(data, dataPacked...), data...))
+		for _, size := range []int{len(data), len(dataPacked), len(data)} {
+			pTest := new(Poly)
+			n, err = pTest.ReadFrom(r)
+			require.NoError(t, err)
+			require.Equal(t, int64(size), n)
+			require.True(t, pTest.IsMForm)
+			require.True(t, tc.ringQ.Equal(p, pTest))
+		}
+
+		_, err = new(Poly).ReadFrom(bytes.NewReader(data[:len(data)-1]))
+		require.Equal(t, io.ErrUnexpectedEOF, err)
+	})
 }
 
 func testUniformSampler(tc *testParams, t *testing.T) {
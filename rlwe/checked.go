@@ -0,0 +1,374 @@
+package rlwe
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// CheckPoly returns an *OperationError wrapping ErrInvalidOperand if p is nil or is not a polynomial
+// of the ring Q of the parameters.
+func CheckPoly(params Parameters, op string, p *ring.Poly) error {
+
+	if p == nil || len(p.Coeffs) == 0 {
+		return NewOperationError(op, ErrInvalidOperand, "polynomial is nil or empty")
+	}
+
+	for i := range p.Coeffs {
+		if len(p.Coeffs[i]) != params.N() {
+			return NewOperationError(op, ErrInvalidOperand, "polynomial has %d coefficients modulo the %d-th prime but the ring has degree %d", len(p.Coeffs[i]), i, params.N())
+		}
+	}
+
+	if p.Level() > params.MaxLevel() {
+		return NewOperationError(op, ErrLevel, "polynomial is at level %d but the maximum level is %d", p.Level(), params.MaxLevel())
+	}
+
+	return nil
+}
+
+// CheckCiphertext returns an *OperationError if ct is nil, has a polynomial that is not in the ring Q of the
+// parameters, has polynomials at different levels or has a degree that is not in [minDegree, maxDegree].
+func CheckCiphertext(params Parameters, op string, ct *Ciphertext, minDegree, maxDegree int) error {
+
+	if ct == nil || len(ct.Value) == 0 {
+		return NewOperationError(op, ErrInvalidOperand, "ciphertext is nil or empty")
+	}
+
+	for _, p := range ct.Value {
+		if err := CheckPoly(params, op, p); err != nil {
+			return err
+		}
+
+		if p.Level() != ct.Value[0].Level() {
+			return NewOperationError(op, ErrLevel, "ciphertext has polynomials at levels %d and %d", ct.Value[0].Level(), p.Level())
+		}
+	}
+
+	if ct.Degree() < minDegree || ct.Degree() > maxDegree {
+		return NewOperationError(op, ErrDegree, "ciphertext has degree %d, it must be in [%d, %d]", ct.Degree(), minDegree, maxDegree)
+	}
+
+	return nil
+}
+
+// CheckPlaintext returns an *OperationError if pt is nil or its polynomial is not in the ring Q of the parameters.
+func CheckPlaintext(params Parameters, op string, pt *Plaintext) error {
+
+	if pt == nil {
+		return NewOperationError(op, ErrInvalidOperand, "plaintext is nil")
+	}
+
+	return CheckPoly(params, op, pt.Value)
+}
+
+// CheckRotationKeys returns an *OperationError wrapping ErrMissingRotationKey if the Evaluator has no
+// rotation key for one of the Galois elements, or wrapping ErrLevel if one of the keys is below level.
+func (eval *Evaluator) CheckRotationKeys(op string, level int, galEls ...uint64) error {
+
+	for _, galEl := range galEls {
+
+		if galEl == 1 {
+			continue
+		}
+
+		var rtk *SwitchingKey
+		var inSet bool
+		switch rtks := eval.Rtks.(type) {
+		case rotationKeyLoader:
+			if !isNilRotationKeys(rtks) {
+				var err error
+				if rtk, inSet, err = rtks.LoadRotationKey(galEl); err != nil {
+					return NewOperationError(op, ErrMissingRotationKey, "galEl key 5^%d cannot be loaded: %s", eval.params.InverseGaloisElement(galEl), err)
+				}
+			}
+		default:
+			if !isNilRotationKeys(rtks) {
+				rtk, inSet = rtks.GetRotationKey(galEl)
+			}
+		}
+
+		if _, indexed := eval.PermuteNTTIndex[galEl]; !inSet || !indexed {
+			return NewOperationError(op, ErrMissingRotationKey, "galEl key 5^%d missing", eval.params.InverseGaloisElement(galEl))
+		}
+
+		if rtk.LevelQ() < level {
+			return NewOperationError(op, ErrLevel, "the input is at level %d but the key of galEl %d is at level %d", level, galEl, rtk.LevelQ())
+		}
+	}
+
+	return nil
+}
+
+// CheckRelinearizationKey returns an *OperationError wrapping ErrMissingRelinearizationKey if the Evaluator
+// cannot relinearize a ciphertext of the given degree, or wrapping ErrLevel if the key is below level.
+func (eval *Evaluator) CheckRelinearizationKey(op string, level, degree int) error {
+
+	if eval.Rlk == nil || len(eval.Rlk.Keys) < degree-1 {
+		return NewOperationError(op, ErrMissingRelinearizationKey, "no relinearization key for a ciphertext of degree %d", degree)
+	}
+
+	for _, key := range eval.Rlk.Keys[:degree-1] {
+		if key.LevelQ() < level {
+			return NewOperationError(op, ErrLevel, "the input is at level %d but the relinearization key is at level %d", level, key.LevelQ())
+		}
+	}
+
+	return nil
+}
+
+// CheckedEvaluator wraps an Evaluator and returns an *OperationError instead of panicking on malformed
+// operands, such as a missing key or operands of invalid degrees or levels.
+type CheckedEvaluator struct {
+	eval *Evaluator
+}
+
+// NewCheckedEvaluator returns a CheckedEvaluator wrapping eval.
+func NewCheckedEvaluator(eval *Evaluator) *CheckedEvaluator {
+	return &CheckedEvaluator{eval: eval}
+}
+
+// Unchecked returns the wrapped Evaluator.
+func (eval *CheckedEvaluator) Unchecked() *Evaluator {
+	return eval.eval
+}
+
+// ShallowCopy returns a CheckedEvaluator wrapping a shallow copy of the wrapped Evaluator.
+func (eval *CheckedEvaluator) ShallowCopy() *CheckedEvaluator {
+	return &CheckedEvaluator{eval: eval.eval.ShallowCopy()}
+}
+
+// WithKey returns a CheckedEvaluator wrapping the wrapped Evaluator with the new EvaluationKey.
+func (eval *CheckedEvaluator) WithKey(evaluationKey *EvaluationKey) *CheckedEvaluator {
+	return &CheckedEvaluator{eval: eval.eval.WithKey(evaluationKey)}
+}
+
+// Automorphism is as Evaluator.Automorphism, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Automorphism(ctIn *Ciphertext, galEl uint64, ctOut *Ciphertext) (err error) {
+
+	defer Recover("Automorphism", &err)
+
+	if err = eval.checkUnary("Automorphism", ctIn, ctOut, 1, 1); err != nil {
+		return
+	}
+
+	if err = eval.eval.CheckRotationKeys("Automorphism", utils.MinInt(ctIn.Level(), ctOut.Level()), galEl); err != nil {
+		return
+	}
+
+	eval.eval.Automorphism(ctIn, galEl, ctOut)
+
+	return
+}
+
+// SwitchKeys is as Evaluator.SwitchKeys, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) SwitchKeys(ctIn *Ciphertext, switchingKey *SwitchingKey, ctOut *Ciphertext) (err error) {
+
+	defer Recover("SwitchKeys", &err)
+
+	if err = eval.checkUnary("SwitchKeys", ctIn, ctOut, 1, 1); err != nil {
+		return
+	}
+
+	if switchingKey == nil || len(switchingKey.Value) == 0 || len(switchingKey.Value[0]) == 0 {
+		return NewOperationError("SwitchKeys", ErrInvalidOperand, "switching key is nil or empty")
+	}
+
+	if level := utils.MinInt(ctIn.Level(), ctOut.Level()); switchingKey.LevelQ() < level {
+		return NewOperationError("SwitchKeys", ErrLevel, "the input is at level %d but the key is at level %d", level, switchingKey.LevelQ())
+	}
+
+	eval.eval.SwitchKeys(ctIn, switchingKey, ctOut)
+
+	return
+}
+
+// Relinearize is as Evaluator.Relinearize, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Relinearize(ctIn *Ciphertext, ctOut *Ciphertext) (err error) {
+
+	defer Recover("Relinearize", &err)
+
+	if err = CheckCiphertext(eval.eval.params, "Relinearize", ctIn, 2, 0xFF); err != nil {
+		return
+	}
+
+	if err = CheckCiphertext(eval.eval.params, "Relinearize", ctOut, 1, 0xFF); err != nil {
+		return
+	}
+
+	if err = eval.eval.CheckRelinearizationKey("Relinearize", utils.MinInt(ctIn.Level(), ctOut.Level()), ctIn.Degree()); err != nil {
+		return
+	}
+
+	eval.eval.Relinearize(ctIn, ctOut)
+
+	return
+}
+
+// Trace is as Evaluator.Trace, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) Trace(ctIn *Ciphertext, logN int, ctOut *Ciphertext) (err error) {
+
+	defer Recover("Trace", &err)
+
+	params := eval.eval.params
+
+	if err = eval.checkUnary("Trace", ctIn, ctOut, 1, 1); err != nil {
+		return
+	}
+
+	if logN < 0 || logN > params.LogN()-1 {
+		return NewOperationError("Trace", ErrInvalidOperand, "logN=%d is not in [0, %d]", logN, params.LogN()-1)
+	}
+
+	if err = eval.eval.CheckRotationKeys("Trace", utils.MinInt(ctIn.Level(), ctOut.Level()), params.GaloisElementsForTrace(logN)...); err != nil {
+		return
+	}
+
+	eval.eval.Trace(ctIn, logN, ctOut)
+
+	return
+}
+
+// ExpandRLWE is as Evaluator.ExpandRLWE, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) ExpandRLWE(ctIn *Ciphertext, logN int) (ctOut []*Ciphertext, err error) {
+
+	defer Recover("ExpandRLWE", &err)
+
+	params := eval.eval.params
+
+	if err = CheckCiphertext(params, "ExpandRLWE", ctIn, 1, 1); err != nil {
+		return
+	}
+
+	if logN < 0 || logN > params.LogN() {
+		return nil, NewOperationError("ExpandRLWE", ErrInvalidOperand, "logN=%d is not in [0, %d]", logN, params.LogN())
+	}
+
+	if err = eval.eval.CheckRotationKeys("ExpandRLWE", ctIn.Level(), params.GaloisElementForExpandRLWE(logN)...); err != nil {
+		return
+	}
+
+	return eval.eval.ExpandRLWE(ctIn, logN), nil
+}
+
+// MergeRLWE is as Evaluator.MergeRLWE, but returns an error instead of panicking.
+func (eval *CheckedEvaluator) MergeRLWE(ctIn map[int]*Ciphertext) (ctOut *Ciphertext, err error) {
+
+	defer Recover("MergeRLWE", &err)
+
+	if len(ctIn) == 0 {
+		return nil, NewOperationError("MergeRLWE", ErrInvalidOperand, "no input ciphertext")
+	}
+
+	level := -1
+	for _, ct := range ctIn {
+
+		if err = CheckCiphertext(eval.eval.params, "MergeRLWE", ct, 1, 1); err != nil {
+			return
+		}
+
+		if level != -1 && ct.Level() != level {
+			return nil, NewOperationError("MergeRLWE", ErrLevel, "input ciphertexts are at levels %d and %d", level, ct.Level())
+		}
+
+		level = ct.Level()
+	}
+
+	return eval.eval.MergeRLWE(ctIn), nil
+}
+
+// checkUnary checks the input and output ciphertexts of an operation.
+func (eval *CheckedEvaluator) checkUnary(op string, ctIn, ctOut *Ciphertext, minDegree, maxDegree int) (err error) {
+
+	if err = CheckCiphertext(eval.eval.params, op, ctIn, minDegree, maxDegree); err != nil {
+		return
+	}
+
+	return CheckCiphertext(eval.eval.params, op, ctOut, minDegree, maxDegree)
+}
+
+// CheckedEncryptor wraps an Encryptor and returns an *OperationError instead of panicking on malformed operands.
+type CheckedEncryptor struct {
+	params Parameters
+	enc    Encryptor
+}
+
+// NewCheckedEncryptor returns a CheckedEncryptor wrapping enc, an Encryptor for the given parameters.
+func NewCheckedEncryptor(params Parameters, enc Encryptor) *CheckedEncryptor {
+	return &CheckedEncryptor{params: params, enc: enc}
+}
+
+// Unchecked returns the wrapped Encryptor.
+func (enc *CheckedEncryptor) Unchecked() Encryptor {
+	return enc.enc
+}
+
+// ShallowCopy returns a CheckedEncryptor wrapping a shallow copy of the wrapped Encryptor.
+func (enc *CheckedEncryptor) ShallowCopy() *CheckedEncryptor {
+	return &CheckedEncryptor{params: enc.params, enc: enc.enc.ShallowCopy()}
+}
+
+// Encrypt is as Encryptor.Encrypt, but returns an error instead of panicking.
+func (enc *CheckedEncryptor) Encrypt(pt *Plaintext, ct interface{}) (err error) {
+
+	defer Recover("Encrypt", &err)
+
+	if pt != nil {
+		if err = CheckPlaintext(enc.params, "Encrypt", pt); err != nil {
+			return
+		}
+	}
+
+	if el, isCiphertext := ct.(*Ciphertext); isCiphertext {
+		if err = CheckCiphertext(enc.params, "Encrypt", el, 1, 1); err != nil {
+			return
+		}
+	}
+
+	enc.enc.Encrypt(pt, ct)
+
+	return
+}
+
+// EncryptZero is as Encryptor.EncryptZero, but returns an error instead of panicking.
+func (enc *CheckedEncryptor) EncryptZero(ct interface{}) (err error) {
+	return enc.Encrypt(nil, ct)
+}
+
+// CheckedDecryptor wraps a Decryptor and returns an *OperationError instead of panicking on malformed operands.
+type CheckedDecryptor struct {
+	params Parameters
+	dec    Decryptor
+}
+
+// NewCheckedDecryptor returns a CheckedDecryptor wrapping dec, a Decryptor for the given parameters.
+func NewCheckedDecryptor(params Parameters, dec Decryptor) *CheckedDecryptor {
+	return &CheckedDecryptor{params: params, dec: dec}
+}
+
+// Unchecked returns the wrapped Decryptor.
+func (dec *CheckedDecryptor) Unchecked() Decryptor {
+	return dec.dec
+}
+
+// ShallowCopy returns a CheckedDecryptor wrapping a shallow copy of the wrapped Decryptor.
+func (dec *CheckedDecryptor) ShallowCopy() *CheckedDecryptor {
+	return &CheckedDecryptor{params: dec.params, dec: dec.dec.ShallowCopy()}
+}
+
+// Decrypt is as Decryptor.Decrypt, but returns an error instead of panicking.
+func (dec *CheckedDecryptor) Decrypt(ct *Ciphertext, pt *Plaintext) (err error) {
+
+	defer Recover("Decrypt", &err)
+
+	if err = CheckCiphertext(dec.params, "Decrypt", ct, 0, 0xFF); err != nil {
+		return
+	}
+
+	if err = CheckPlaintext(dec.params, "Decrypt", pt); err != nil {
+		return
+	}
+
+	dec.dec.Decrypt(ct, pt)
+
+	return
+}
@@ -72,6 +72,12 @@ func newEncryptorBase(params Parameters) *encryptorBase {
 		panic(err)
 	}
 
+	return newEncryptorBaseWithPRNG(params, prng)
+}
+
+// newEncryptorBaseWithPRNG creates a new encryptorBase sampling the errors and the ephemeral keys from prng.
+func newEncryptorBaseWithPRNG(params Parameters, prng utils.PRNG) *encryptorBase {
+
 	var bc *ring.BasisExtender
 	if params.PCount() != 0 {
 		bc = ring.NewBasisExtender(params.RingQ(), params.RingP())
@@ -102,6 +108,19 @@ func newSkEncryptor(params Parameters, key interface{}) (enc *skEncryptor) {
 	return enc
 }
 
+// newSkEncryptorWithPRNG creates a new skEncryptor sampling the errors from prng and the uniform elements from uniformPRNG.
+func newSkEncryptorWithPRNG(params Parameters, key interface{}, prng, uniformPRNG utils.PRNG) (enc *skEncryptor) {
+
+	enc = &skEncryptor{*newEncryptorBaseWithPRNG(params, prng), nil, ringqp.NewUniformSampler(uniformPRNG, *params.RingQP())}
+
+	var err error
+	if enc.sk, err = enc.checkSk(key); err != nil {
+		panic(err)
+	}
+
+	return enc
+}
+
 func newPkEncryptor(params Parameters, key interface{}) (enc *pkEncryptor) {
 	var err error
 	enc = &pkEncryptor{newEncryptorBase(params), nil}
@@ -0,0 +1,225 @@
+package rlwe
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// EnvelopeVersion is the version of the envelope format written by Seal.
+const EnvelopeVersion = 1
+
+// EnvelopeMagic are the bytes at the start of every envelope.
+var EnvelopeMagic = [4]byte{'L', 'T', 'G', 'O'}
+
+// envelopeHeaderLen is the length of the fixed part of the header: magic, version and kind length.
+const envelopeHeaderLen = len(EnvelopeMagic) + 2
+
+// The errors returned by the functions reading envelopes. They are wrapped with the details of the
+// failure and can be tested with errors.Is.
+var (
+	// ErrNotEnveloped is returned when the data does not start with EnvelopeMagic, as the raw layouts of MarshalBinary.
+	ErrNotEnveloped = errors.New("data is not enveloped")
+	// ErrEnvelopeVersion is returned when the envelope was written by an unknown version of the format.
+	ErrEnvelopeVersion = errors.New("unsupported envelope version")
+	// ErrEnvelopeKind is returned when the envelope holds another kind of object than the expected one.
+	ErrEnvelopeKind = errors.New("envelope kind mismatch")
+	// ErrEnvelopeParameters is returned when the envelope holds an object generated under other parameters.
+	ErrEnvelopeParameters = errors.New("envelope parameters mismatch")
+	// ErrEnvelopeCorrupted is returned when the envelope is truncated or has an invalid length.
+	ErrEnvelopeCorrupted = errors.New("corrupted envelope")
+)
+
+// ObjectKind is the type tag of the objects stored in envelopes. It is the package-qualified
+// name of the type of the object.
+type ObjectKind string
+
+// The kinds of the objects of the rlwe package.
+const (
+	KindCiphertext               ObjectKind = "rlwe.Ciphertext"
+	KindSecretKey                ObjectKind = "rlwe.SecretKey"
+	KindPublicKey                ObjectKind = "rlwe.PublicKey"
+	KindSwitchingKey             ObjectKind = "rlwe.SwitchingKey"
+	KindRelinearizationKey       ObjectKind = "rlwe.RelinearizationKey"
+	KindRotationKeySet           ObjectKind = "rlwe.RotationKeySet"
+	KindGadgetCiphertext         ObjectKind = "rlwe.GadgetCiphertext"
+	KindSeededCiphertext         ObjectKind = "rlwe.SeededCiphertext"
+	KindSeededSwitchingKey       ObjectKind = "rlwe.SeededSwitchingKey"
+	KindSeededRelinearizationKey ObjectKind = "rlwe.SeededRelinearizationKey"
+	KindSeededRotationKeySet     ObjectKind = "rlwe.SeededRotationKeySet"
+)
+
+// Fingerprint is the hash of a parameter set stored in the envelopes. The zero Fingerprint is used
+// for the objects that are not bound to a parameter set.
+type Fingerprint [blake2b.Size256]byte
+
+// Fingerprint returns the BLAKE2b-256 hash of the binary encoding of the parameters. It only depends
+// on the ring and the error distributions: the ckks and bfv parameter sets define their own Fingerprint,
+// which also covers their scheme-specific parameters.
+func (p Parameters) Fingerprint() (fp Fingerprint) {
+	data, err := p.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return blake2b.Sum256(data)
+}
+
+// Envelope is a self-describing container of the binary encoding of an object. Its encoding is
+//
+//	magic (4 bytes) | version (1 byte) | len(kind) (1 byte) | kind | fingerprint (32 bytes) | len(payload) (8 bytes) | payload
+type Envelope struct {
+	Version     uint8
+	Kind        ObjectKind
+	Fingerprint Fingerprint
+	Payload     []byte
+}
+
+// IsEnveloped returns true if data starts with EnvelopeMagic.
+func IsEnveloped(data []byte) bool {
+	return len(data) >= len(EnvelopeMagic) && bytes.Equal(data[:len(EnvelopeMagic)], EnvelopeMagic[:])
+}
+
+// GetDataLen returns the length in bytes of the target Envelope.
+func (e *Envelope) GetDataLen() int {
+	return envelopeHeaderLen + len(e.Kind) + len(e.Fingerprint) + 8 + len(e.Payload)
+}
+
+// MarshalBinary encodes the target Envelope on a slice of bytes.
+func (e *Envelope) MarshalBinary() (data []byte, err error) {
+
+	if len(e.Kind) > 0xFF {
+		return nil, errors.New("cannot MarshalBinary: kind is longer than 255 bytes")
+	}
+
+	data = make([]byte, e.GetDataLen())
+	ptr := copy(data, EnvelopeMagic[:])
+	data[ptr] = e.Version
+	data[ptr+1] = uint8(len(e.Kind))
+	ptr += 2
+	ptr += copy(data[ptr:], e.Kind)
+	ptr += copy(data[ptr:], e.Fingerprint[:])
+	binary.BigEndian.PutUint64(data[ptr:], uint64(len(e.Payload)))
+	ptr += 8
+	copy(data[ptr:], e.Payload)
+
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target Envelope.
+// The payload is not copied. It returns ErrNotEnveloped if the data does not start with
+// EnvelopeMagic and ErrEnvelopeVersion if the version is not supported.
+func (e *Envelope) UnmarshalBinary(data []byte) (err error) {
+
+	if !IsEnveloped(data) {
+		return ErrNotEnveloped
+	}
+
+	if len(data) < envelopeHeaderLen {
+		return fmt.Errorf("%w: header is truncated", ErrEnvelopeCorrupted)
+	}
+
+	ptr := len(EnvelopeMagic)
+
+	if e.Version = data[ptr]; e.Version == 0 || e.Version > EnvelopeVersion {
+		return fmt.Errorf("%w: %d, the latest supported version is %d", ErrEnvelopeVersion, e.Version, EnvelopeVersion)
+	}
+
+	kindLen := int(data[ptr+1])
+	ptr += 2
+
+	if len(data) < ptr+kindLen+len(e.Fingerprint)+8 {
+		return fmt.Errorf("%w: header is truncated", ErrEnvelopeCorrupted)
+	}
+
+	e.Kind = ObjectKind(data[ptr : ptr+kindLen])
+	ptr += kindLen
+	ptr += copy(e.Fingerprint[:], data[ptr:])
+
+	payloadLen := binary.BigEndian.Uint64(data[ptr:])
+	ptr += 8
+
+	if uint64(len(data)-ptr) != payloadLen {
+		return fmt.Errorf("%w: payload has %d bytes, expected %d", ErrEnvelopeCorrupted, len(data)-ptr, payloadLen)
+	}
+
+	e.Payload = data[ptr:]
+
+	return
+}
+
+// Verify checks that the target Envelope holds an object of the given kind bound to the given fingerprint.
+func (e *Envelope) Verify(kind ObjectKind, fp Fingerprint) (err error) {
+
+	if e.Kind != kind {
+		return fmt.Errorf("%w: expected %s, got %s", ErrEnvelopeKind, kind, e.Kind)
+	}
+
+	if e.Fingerprint != fp {
+		return fmt.Errorf("%w: expected %x, got %x", ErrEnvelopeParameters, fp[:8], e.Fingerprint[:8])
+	}
+
+	return
+}
+
+// Seal encodes obj with its MarshalBinary method and wraps the result in an envelope of the given kind
+// bound to the given parameter fingerprint.
+func Seal(kind ObjectKind, fp Fingerprint, obj encoding.BinaryMarshaler) (data []byte, err error) {
+
+	e := &Envelope{Version: EnvelopeVersion, Kind: kind, Fingerprint: fp}
+
+	if e.Payload, err = obj.MarshalBinary(); err != nil {
+		return nil, err
+	}
+
+	return e.MarshalBinary()
+}
+
+// Open verifies that data is an envelope of the given kind bound to the given parameter fingerprint
+// and decodes its payload on obj with its UnmarshalBinary method.
+func Open(data []byte, kind ObjectKind, fp Fingerprint, obj encoding.BinaryUnmarshaler) (err error) {
+
+	e := new(Envelope)
+
+	if err = e.UnmarshalBinary(data); err != nil {
+		return
+	}
+
+	if err = e.Verify(kind, fp); err != nil {
+		return
+	}
+
+	return obj.UnmarshalBinary(e.Payload)
+}
+
+// OpenLegacy is as Open, but also accepts the raw layouts written by MarshalBinary before the envelopes
+// were introduced. These are decoded on obj without any verification.
+func OpenLegacy(data []byte, kind ObjectKind, fp Fingerprint, obj encoding.BinaryUnmarshaler) (err error) {
+
+	if !IsEnveloped(data) {
+		return obj.UnmarshalBinary(data)
+	}
+
+	return Open(data, kind, fp, obj)
+}
+
+// Migrate decodes the raw layout data on obj and returns it sealed in an envelope of the given kind
+// bound to the given parameter fingerprint. Data that is already enveloped is verified and returned as is.
+func Migrate(data []byte, kind ObjectKind, fp Fingerprint, obj interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}) ([]byte, error) {
+
+	if err := OpenLegacy(data, kind, fp, obj); err != nil {
+		return nil, err
+	}
+
+	if IsEnveloped(data) {
+		return data, nil
+	}
+
+	return Seal(kind, fp, obj)
+}
@@ -0,0 +1,84 @@
+package rlwe
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// The errors returned by the checked evaluators, encoders, encryptors and decryptors of the rlwe, ckks
+// and bfv packages. They are wrapped in an *OperationError and can be tested with errors.Is.
+var (
+	// ErrMissingRotationKey is returned when the rotation key of a Galois element is not in the evaluation key.
+	ErrMissingRotationKey = errors.New("missing rotation key")
+	// ErrMissingRelinearizationKey is returned when the evaluation key has no relinearization key of a large enough degree.
+	ErrMissingRelinearizationKey = errors.New("missing relinearization key")
+	// ErrLevel is returned when the levels of the operands are invalid or do not match.
+	ErrLevel = errors.New("invalid level")
+	// ErrDegree is returned when the degrees of the operands are invalid or do not match.
+	ErrDegree = errors.New("invalid degree")
+	// ErrRingType is returned when the operation is not supported by the ring type of the parameters.
+	ErrRingType = errors.New("unsupported ring type")
+	// ErrInvalidOperand is returned for all the other malformed operands, such as nil operands or operands of an invalid type.
+	ErrInvalidOperand = errors.New("invalid operand")
+)
+
+// sentinelErrors are the errors kept by Recover when a panic value wraps them.
+var sentinelErrors = []error{ErrMissingRotationKey, ErrMissingRelinearizationKey, ErrLevel, ErrDegree, ErrRingType, ErrInvalidOperand}
+
+// OperationError is the error returned by the checked methods. It records the operation that failed
+// and wraps one of the errors of this package.
+type OperationError struct {
+	Op  string
+	Err error
+	Msg string
+}
+
+// NewOperationError returns a new *OperationError for the operation op wrapping err, with a message
+// formatted from format and args.
+func NewOperationError(op string, err error, format string, args ...interface{}) *OperationError {
+	return &OperationError{Op: op, Err: err, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Error implements the error interface.
+func (e *OperationError) Error() string {
+	if e.Msg == "" {
+		return fmt.Sprintf("cannot %s: %s", e.Op, e.Err)
+	}
+	return fmt.Sprintf("cannot %s: %s: %s", e.Op, e.Err, e.Msg)
+}
+
+// Unwrap returns the error wrapped by the target OperationError.
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// Recover recovers the panic of the calling function, if any, and stores it in *err as an *OperationError
+// for the operation op. It must be deferred directly: defer rlwe.Recover("Rotate", &err).
+// A panic value wrapping one of the errors of this package keeps it, the other panics raised by the
+// validation of the operands are reported as ErrInvalidOperand. Runtime errors, such as out of range
+// indexes or nil pointer dereferences, are bugs and not invalid operands: they are panicked again.
+func Recover(op string, err *error) {
+
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	switch v := r.(type) {
+	case runtime.Error:
+		panic(v)
+	case *OperationError:
+		*err = v
+	case error:
+		*err = NewOperationError(op, ErrInvalidOperand, "%s", v)
+		for _, sentinel := range sentinelErrors {
+			if errors.Is(v, sentinel) {
+				*err = NewOperationError(op, sentinel, "%s", v)
+				break
+			}
+		}
+	default:
+		*err = NewOperationError(op, ErrInvalidOperand, "%v", v)
+	}
+}
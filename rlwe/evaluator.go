@@ -14,7 +14,7 @@ type Evaluator struct {
 	*evaluatorBuffers
 
 	Rlk             *RelinearizationKey
-	Rtks            *RotationKeySet
+	Rtks            RotationKeyProvider
 	PermuteNTTIndex map[uint64][]uint64
 
 	BasisExtender *ring.BasisExtender
@@ -77,7 +77,7 @@ func NewEvaluator(params Parameters, evaluationKey *EvaluationKey) (eval *Evalua
 			eval.Rlk = evaluationKey.Rlk
 		}
 
-		if evaluationKey.Rtks != nil {
+		if evaluationKey.HasRotationKeys() {
 			eval.Rtks = evaluationKey.Rtks
 			eval.PermuteNTTIndex = *eval.permuteNTTIndexesForKey(eval.Rtks)
 		}
@@ -88,12 +88,13 @@ func NewEvaluator(params Parameters, evaluationKey *EvaluationKey) (eval *Evalua
 
 // permuteNTTIndexesForKey generates pemutation indexes for automorphisms for ciphertexts
 // that are given in the NTT domain.
-func (eval *Evaluator) permuteNTTIndexesForKey(rtks *RotationKeySet) *map[uint64][]uint64 {
-	if rtks == nil {
+func (eval *Evaluator) permuteNTTIndexesForKey(rtks RotationKeyProvider) *map[uint64][]uint64 {
+	if isNilRotationKeys(rtks) {
 		return &map[uint64][]uint64{}
 	}
-	permuteNTTIndex := make(map[uint64][]uint64, len(rtks.Keys))
-	for galEl := range rtks.Keys {
+	galEls := rtks.GaloisElements()
+	permuteNTTIndex := make(map[uint64][]uint64, len(galEls))
+	for _, galEl := range galEls {
 		permuteNTTIndex[galEl] = eval.params.RingQ().PermuteNTTIndex(galEl)
 	}
 	return &permuteNTTIndex
@@ -117,11 +118,12 @@ func (eval *Evaluator) ShallowCopy() *Evaluator {
 // WithKey creates a shallow copy of the receiver Evaluator for which the new EvaluationKey is evaluationKey
 // and where the temporary buffers are shared. The receiver and the returned Evaluators cannot be used concurrently.
 func (eval *Evaluator) WithKey(evaluationKey *EvaluationKey) *Evaluator {
+	rtks := normalizeRotationKeys(evaluationKey.Rtks)
 	var indexes map[uint64][]uint64
-	if evaluationKey.Rtks == eval.Rtks {
+	if rtks == eval.Rtks {
 		indexes = eval.PermuteNTTIndex
 	} else {
-		indexes = *eval.permuteNTTIndexesForKey(evaluationKey.Rtks)
+		indexes = *eval.permuteNTTIndexesForKey(rtks)
 	}
 	return &Evaluator{
 		evaluatorBase:    eval.evaluatorBase,
@@ -129,7 +131,7 @@ func (eval *Evaluator) WithKey(evaluationKey *EvaluationKey) *Evaluator {
 		Decomposer:       eval.Decomposer,
 		BasisExtender:    eval.BasisExtender,
 		Rlk:              evaluationKey.Rlk,
-		Rtks:             evaluationKey.Rtks,
+		Rtks:             rtks,
 		PermuteNTTIndex:  indexes,
 	}
 }
@@ -267,6 +269,11 @@ func (eval *Evaluator) mergeRLWERecurse(ciphertexts []*Ciphertext, xPow []*ring.
 		return nil
 	}
 
+	// ctOdd must not be discarded when ctEven is empty
+	if ctEven == nil {
+		ctEven = NewCiphertextNTT(eval.params, 1, ctOdd.Level())
+	}
+
 	var tmpEven *Ciphertext
 	if ctEven != nil {
 		tmpEven = ctEven.CopyNew()
@@ -1,8 +1,6 @@
 package rlwe
 
 import (
-	"fmt"
-
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
 	"github.com/tuneinsight/lattigo/v3/utils"
@@ -14,7 +12,7 @@ import (
 func (eval *Evaluator) Automorphism(ctIn *Ciphertext, galEl uint64, ctOut *Ciphertext) {
 
 	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot apply Automorphism: input and output Ciphertext must be of degree 1")
+		panic(NewOperationError("Automorphism", ErrDegree, "input and output Ciphertext must be of degree 1"))
 	}
 
 	if galEl == 1 {
@@ -26,7 +24,7 @@ func (eval *Evaluator) Automorphism(ctIn *Ciphertext, galEl uint64, ctOut *Ciphe
 
 	rtk, generated := eval.Rtks.GetRotationKey(galEl)
 	if !generated {
-		panic(fmt.Sprintf("galEl key 5^%d missing", eval.params.InverseGaloisElement(galEl)))
+		panic(NewOperationError("Automorphism", ErrMissingRotationKey, "galEl key 5^%d missing", eval.params.InverseGaloisElement(galEl)))
 	}
 
 	level := utils.MinInt(ctIn.Level(), ctOut.Level())
@@ -54,7 +52,7 @@ func (eval *Evaluator) Automorphism(ctIn *Ciphertext, galEl uint64, ctOut *Ciphe
 func (eval *Evaluator) AutomorphismHoisted(level int, ctIn *Ciphertext, c1DecompQP []ringqp.Poly, galEl uint64, ctOut *Ciphertext) {
 
 	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot apply AutomorphismHoisted: input and output Ciphertext must be of degree 1")
+		panic(NewOperationError("AutomorphismHoisted", ErrDegree, "input and output Ciphertext must be of degree 1"))
 	}
 
 	if galEl == 1 {
@@ -66,7 +64,7 @@ func (eval *Evaluator) AutomorphismHoisted(level int, ctIn *Ciphertext, c1Decomp
 
 	rtk, generated := eval.Rtks.GetRotationKey(galEl)
 	if !generated {
-		panic(fmt.Sprintf("galEl key 5^%d missing", eval.params.InverseGaloisElement(galEl)))
+		panic(NewOperationError("AutomorphismHoisted", ErrMissingRotationKey, "galEl key 5^%d missing", eval.params.InverseGaloisElement(galEl)))
 	}
 
 	ringQ := eval.params.RingQ()
@@ -91,7 +89,7 @@ func (eval *Evaluator) AutomorphismHoistedNoModDown(levelQ int, c0 *ring.Poly, c
 
 	rtk, generated := eval.Rtks.GetRotationKey(galEl)
 	if !generated {
-		panic(fmt.Sprintf("cannot AutomorphismHoistedNoModDown: galEl key 5^%d missing", eval.params.InverseGaloisElement(galEl)))
+		panic(NewOperationError("AutomorphismHoistedNoModDown", ErrMissingRotationKey, "galEl key 5^%d missing", eval.params.InverseGaloisElement(galEl)))
 	}
 
 	levelP := rtk.LevelP()
@@ -11,6 +11,7 @@ import (
 // p1 = dot(decomp(cx) * gadget[1]) mod Q
 //
 // Expects the flag IsNTT of cx to correctly reflect the domain of cx.
+// The gadget ciphertext can be at any levelQ larger or equal to the levelQ of cx.
 func (eval *Evaluator) GadgetProduct(levelQ int, cx *ring.Poly, gadgetCt GadgetCiphertext, p0, p1 *ring.Poly) {
 
 	levelP := gadgetCt.LevelP()
@@ -48,6 +49,8 @@ func (eval *Evaluator) GadgetProduct(levelQ int, cx *ring.Poly, gadgetCt GadgetC
 // Expects the flag IsNTT of cx to correctly reflect the domain of cx.
 func (eval *Evaluator) GadgetProductNoModDown(levelQ int, cx *ring.Poly, gadgetCt GadgetCiphertext, p0QP, p1QP ringqp.Poly) {
 
+	checkGadgetLevel("GadgetProductNoModDown", levelQ, gadgetCt)
+
 	ringQ := eval.params.RingQ()
 	ringP := eval.params.RingP()
 	ringQP := eval.params.RingQP()
@@ -120,6 +123,8 @@ func (eval *Evaluator) GadgetProductNoModDown(levelQ int, cx *ring.Poly, gadgetC
 // Expects the flag IsNTT of cx to correctly reflect the domain of cx.
 func (eval *Evaluator) GadgetProductSinglePAndBitDecompNoModDown(levelQ int, cx *ring.Poly, gadgetCt GadgetCiphertext, p0QP, p1QP ringqp.Poly) {
 
+	checkGadgetLevel("GadgetProductSinglePAndBitDecompNoModDown", levelQ, gadgetCt)
+
 	ringQ := eval.params.RingQ()
 	ringP := eval.params.RingP()
 
@@ -209,3 +214,11 @@ func (eval *Evaluator) GadgetProductSinglePAndBitDecompNoModDown(levelQ int, cx
 		ringP.ReduceLvl(levelP, p1QP.P, p1QP.P)
 	}
 }
+
+// checkGadgetLevel panics if the gadget ciphertext cannot be used on a polynomial at levelQ, that is,
+// if its levelQ is smaller than levelQ.
+func checkGadgetLevel(method string, levelQ int, gadgetCt GadgetCiphertext) {
+	if levelQ > gadgetCt.LevelQ() {
+		panic(NewOperationError(method, ErrLevel, "the input is at level %d but the key is at level %d", levelQ, gadgetCt.LevelQ()))
+	}
+}
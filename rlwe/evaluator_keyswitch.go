@@ -13,7 +13,7 @@ import (
 func (eval *Evaluator) SwitchKeys(ctIn *Ciphertext, switchingKey *SwitchingKey, ctOut *Ciphertext) {
 
 	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
-		panic("cannot SwitchKeys: input and output Ciphertext must be of degree 1")
+		panic(NewOperationError("SwitchKeys", ErrDegree, "input and output Ciphertext must be of degree 1"))
 	}
 
 	level := utils.MinInt(ctIn.Level(), ctOut.Level())
@@ -30,7 +30,7 @@ func (eval *Evaluator) SwitchKeys(ctIn *Ciphertext, switchingKey *SwitchingKey,
 // is missing.
 func (eval *Evaluator) Relinearize(ctIn *Ciphertext, ctOut *Ciphertext) {
 	if eval.Rlk == nil || ctIn.Degree()-1 > len(eval.Rlk.Keys) {
-		panic("cannot Relinearize: relinearization key missing (or ciphertext degree is too large)")
+		panic(NewOperationError("Relinearize", ErrMissingRelinearizationKey, "relinearization key missing (or ciphertext degree is too large)"))
 	}
 
 	level := utils.MinInt(ctIn.Level(), ctOut.Level())
@@ -125,8 +125,12 @@ func (eval *Evaluator) KeyswitchHoisted(levelQ int, BuffQPDecompQP []ringqp.Poly
 //
 // BuffQP2 = dot(BuffQPDecompQ||BuffQPDecompP * evakey[0]) mod QP
 // BuffQP3 = dot(BuffQPDecompQ||BuffQPDecompP * evakey[1]) mod QP
+//
+// The decomposition must have been computed with the levelP of evakey, which can be at any levelQ larger or equal to levelQ.
 func (eval *Evaluator) KeyswitchHoistedNoModDown(levelQ int, BuffQPDecompQP []ringqp.Poly, evakey *SwitchingKey, c0Q, c1Q, c0P, c1P *ring.Poly) {
 
+	checkGadgetLevel("KeyswitchHoistedNoModDown", levelQ, evakey.GadgetCiphertext)
+
 	ringQ := eval.params.RingQ()
 	ringP := eval.params.RingP()
 	ringQP := eval.params.RingQP()
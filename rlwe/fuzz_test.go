@@ -0,0 +1,187 @@
+//go:build go1.18
+// +build go1.18
+
+package rlwe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// fuzzParams are small parameters with a gadget decomposition in both the RNS and the power of two bases.
+var fuzzParams = ParametersLiteral{LogN: 5, LogQ: []int{30, 30}, LogP: []int{30}, Pow2Base: 16}
+
+// fuzzObject is the interface of the objects whose decoders are fuzzed.
+type fuzzObject interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	Validator
+}
+
+// fuzzDecoder adds the encodings of seeds to the corpus of f and fuzzes the decoder of the objects returned by
+// newObject: the decoder must not panic and the decoded objects that are valid for the parameters must be
+// re-encoded and decoded again without error.
+func fuzzDecoder(f *testing.F, params Parameters, newObject func() fuzzObject, seeds ...fuzzObject) {
+
+	for _, seed := range seeds {
+		data, err := seed.MarshalBinary()
+		require.NoError(f, err)
+		f.Add(data)
+		f.Add(data[:len(data)/2])
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		obj := newObject()
+		if err := obj.UnmarshalBinary(data); err != nil {
+			return
+		}
+
+		if obj.Validate(params) != nil {
+			return
+		}
+
+		dataTest, err := obj.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, UnmarshalValidated(params, dataTest, newObject()))
+	})
+}
+
+// fuzzKeys returns the parameters and a key generator and a secret key for the fuzz tests.
+func fuzzKeys(f *testing.F) (params Parameters, kgen KeyGenerator, sk *SecretKey) {
+	params, err := NewParametersFromLiteral(fuzzParams)
+	require.NoError(f, err)
+	kgen = NewKeyGenerator(params)
+	return params, kgen, kgen.GenSecretKey()
+}
+
+func FuzzCiphertextUnmarshalBinary(f *testing.F) {
+	params, _, _ := fuzzKeys(f)
+	prng, _ := utils.NewPRNG()
+	fuzzDecoder(f, params, func() fuzzObject { return new(Ciphertext) },
+		NewCiphertextRandom(prng, params, 1, params.MaxLevel()), NewCiphertextRandom(prng, params, 2, 0))
+}
+
+func FuzzSecretKeyUnmarshalBinary(f *testing.F) {
+	params, _, sk := fuzzKeys(f)
+	fuzzDecoder(f, params, func() fuzzObject { return new(SecretKey) }, sk)
+}
+
+func FuzzPublicKeyUnmarshalBinary(f *testing.F) {
+	params, kgen, sk := fuzzKeys(f)
+	fuzzDecoder(f, params, func() fuzzObject { return new(PublicKey) }, kgen.GenPublicKey(sk))
+}
+
+func FuzzSwitchingKeyUnmarshalBinary(f *testing.F) {
+	params, kgen, sk := fuzzKeys(f)
+	fuzzDecoder(f, params, func() fuzzObject { return new(SwitchingKey) }, kgen.GenSwitchingKey(sk, kgen.GenSecretKey()))
+}
+
+func FuzzRelinearizationKeyUnmarshalBinary(f *testing.F) {
+	params, kgen, sk := fuzzKeys(f)
+	fuzzDecoder(f, params, func() fuzzObject { return new(RelinearizationKey) }, kgen.GenRelinearizationKey(sk, 2))
+}
+
+func FuzzRotationKeySetUnmarshalBinary(f *testing.F) {
+	params, kgen, sk := fuzzKeys(f)
+	fuzzDecoder(f, params, func() fuzzObject { return new(RotationKeySet) },
+		kgen.GenRotationKeys([]uint64{params.GaloisElementForColumnRotationBy(1), params.GaloisElementForRowRotation()}, sk))
+}
+
+func FuzzSeededCiphertextUnmarshalBinary(f *testing.F) {
+	params, _, sk := fuzzKeys(f)
+	ct := NewSeededCiphertext(params, params.MaxLevel())
+	NewSeededEncryptor(params, sk).Encrypt(NewPlaintext(params, params.MaxLevel()), ct)
+	fuzzDecoder(f, params, func() fuzzObject { return new(SeededCiphertext) }, ct)
+}
+
+func FuzzSeededSwitchingKeyUnmarshalBinary(f *testing.F) {
+	params, kgen, sk := fuzzKeys(f)
+	fuzzDecoder(f, params, func() fuzzObject { return new(SeededSwitchingKey) }, kgen.GenSeededSwitchingKey(sk, kgen.GenSecretKey()))
+}
+
+func FuzzSeededRelinearizationKeyUnmarshalBinary(f *testing.F) {
+	params, kgen, sk := fuzzKeys(f)
+	fuzzDecoder(f, params, func() fuzzObject { return new(SeededRelinearizationKey) }, kgen.GenSeededRelinearizationKey(sk, 2))
+}
+
+func FuzzSeededRotationKeySetUnmarshalBinary(f *testing.F) {
+	params, kgen, sk := fuzzKeys(f)
+	fuzzDecoder(f, params, func() fuzzObject { return new(SeededRotationKeySet) },
+		kgen.GenSeededRotationKeys([]uint64{params.GaloisElementForColumnRotationBy(1)}, sk))
+}
+
+func FuzzRotationKeySetReadFrom(f *testing.F) {
+
+	params, kgen, sk := fuzzKeys(f)
+
+	data, err := kgen.GenRotationKeys([]uint64{params.GaloisElementForColumnRotationBy(1)}, sk).MarshalBinaryPacked()
+	require.NoError(f, err)
+	f.Add(data)
+	f.Add(data[:len(data)/2])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		rtks := new(RotationKeySet)
+		n, err := rtks.ReadFrom(bufio.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			return
+		}
+
+		// ReadFrom and UnmarshalBinary agree on the valid encodings
+		rtksTest := new(RotationKeySet)
+		require.NoError(t, rtksTest.UnmarshalBinary(data[:n]))
+		require.Equal(t, len(rtks.Keys), len(rtksTest.Keys))
+	})
+}
+
+func FuzzCiphertextReadFrom(f *testing.F) {
+
+	params, _, _ := fuzzKeys(f)
+	prng, _ := utils.NewPRNG()
+
+	data, err := NewCiphertextRandom(prng, params, 1, params.MaxLevel()).MarshalBinary()
+	require.NoError(f, err)
+	f.Add(data)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		ct := new(Ciphertext)
+		n, err := ct.ReadFrom(bufio.NewReader(bytes.NewReader(data)))
+		if err != nil {
+			return
+		}
+
+		require.NoError(t, new(Ciphertext).UnmarshalBinary(data[:n]))
+	})
+}
+
+func FuzzNewLazyRotationKeySet(f *testing.F) {
+
+	params, kgen, sk := fuzzKeys(f)
+
+	data, err := kgen.GenRotationKeys([]uint64{params.GaloisElementForColumnRotationBy(1)}, sk).MarshalBinary()
+	require.NoError(f, err)
+	f.Add(data)
+	f.Add(data[:len(data)/2])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		rtks, err := NewLazyRotationKeySet(bytes.NewReader(data), int64(len(data)), 1)
+		if err != nil {
+			return
+		}
+
+		// the keys are indexed within the encoding
+		for _, galEl := range rtks.GaloisElements() {
+			loc := rtks.index[galEl]
+			require.LessOrEqual(t, loc.offset+loc.size, int64(len(data)))
+		}
+	})
+}
@@ -91,6 +91,10 @@ func (ct *GadgetCiphertext) CopyNew() (ctCopy *GadgetCiphertext) {
 
 // GetDataLen returns the length in bytes of the target Ciphertext.
 func (ct *GadgetCiphertext) GetDataLen(WithMetadata bool) (dataLen int) {
+	return ct.getDataLen(WithMetadata, false)
+}
+
+func (ct *GadgetCiphertext) getDataLen(WithMetadata, packed bool) (dataLen int) {
 
 	if WithMetadata {
 		dataLen += 2
@@ -98,8 +102,8 @@ func (ct *GadgetCiphertext) GetDataLen(WithMetadata bool) (dataLen int) {
 
 	for i := range ct.Value {
 		for _, el := range ct.Value[i] {
-			dataLen += el.Value[0].GetDataLen64(WithMetadata)
-			dataLen += el.Value[1].GetDataLen64(WithMetadata)
+			dataLen += ring.GetDataLen(&el.Value[0], WithMetadata, packed)
+			dataLen += ring.GetDataLen(&el.Value[1], WithMetadata, packed)
 		}
 	}
 
@@ -108,8 +112,18 @@ func (ct *GadgetCiphertext) GetDataLen(WithMetadata bool) (dataLen int) {
 
 // MarshalBinary encodes the target Ciphertext on a slice of bytes.
 func (ct *GadgetCiphertext) MarshalBinary() (data []byte, err error) {
-	data = make([]byte, ct.GetDataLen(true))
-	if _, err = ct.Encode(0, data); err != nil {
+	return ct.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes the target Ciphertext on a slice of bytes with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (ct *GadgetCiphertext) MarshalBinaryPacked() (data []byte, err error) {
+	return ct.marshalBinary(true)
+}
+
+func (ct *GadgetCiphertext) marshalBinary(packed bool) (data []byte, err error) {
+	data = make([]byte, ct.getDataLen(true, packed))
+	if _, err = ct.encode(0, data, packed); err != nil {
 		return
 	}
 
@@ -118,15 +132,25 @@ func (ct *GadgetCiphertext) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary decodes a slice of bytes on the target Ciphertext.
 func (ct *GadgetCiphertext) UnmarshalBinary(data []byte) (err error) {
-	if _, err = ct.Decode(data); err != nil {
+
+	var pointer int
+	if pointer, err = ct.Decode(data); err != nil {
 		return
 	}
 
+	if pointer != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
 	return
 }
 
 // Encode encodes the target ciphertext on a pre-allocated slice of bytes.
 func (ct *GadgetCiphertext) Encode(pointer int, data []byte) (int, error) {
+	return ct.encode(pointer, data, false)
+}
+
+func (ct *GadgetCiphertext) encode(pointer int, data []byte, packed bool) (int, error) {
 
 	var err error
 	var inc int
@@ -139,12 +163,12 @@ func (ct *GadgetCiphertext) Encode(pointer int, data []byte) (int, error) {
 	for i := range ct.Value {
 		for _, el := range ct.Value[i] {
 
-			if inc, err = el.Value[0].WriteTo64(data[pointer:]); err != nil {
+			if inc, err = ring.WriteTo(&el.Value[0], data[pointer:], packed); err != nil {
 				return pointer, err
 			}
 			pointer += inc
 
-			if inc, err = el.Value[1].WriteTo64(data[pointer:]); err != nil {
+			if inc, err = ring.WriteTo(&el.Value[1], data[pointer:], packed); err != nil {
 				return pointer, err
 			}
 			pointer += inc
@@ -157,9 +181,18 @@ func (ct *GadgetCiphertext) Encode(pointer int, data []byte) (int, error) {
 // Decode decodes a slice of bytes on the target ciphertext.
 func (ct *GadgetCiphertext) Decode(data []byte) (pointer int, err error) {
 
+	if len(data) < 2 {
+		return 0, malformed("data is too short")
+	}
+
 	decompRNS := int(data[0])
 	decompBIT := int(data[1])
 
+	// each element is a pair of ringqp.Poly that have at least a header of 2 bytes
+	if decompRNS == 0 || decompBIT == 0 || len(data)-2 < 4*decompRNS*decompBIT {
+		return 0, malformed("invalid decomposition %dx%d", decompRNS, decompBIT)
+	}
+
 	pointer = 2
 
 	ct.Value = make([][]CiphertextQP, decompRNS)
@@ -172,12 +205,12 @@ func (ct *GadgetCiphertext) Decode(data []byte) (pointer int, err error) {
 
 		for j := range ct.Value[i] {
 
-			if inc, err = ct.Value[i][j].Value[0].DecodePoly64(data[pointer:]); err != nil {
+			if inc, err = decodePoly(&ct.Value[i][j].Value[0], data[pointer:]); err != nil {
 				return
 			}
 			pointer += inc
 
-			if inc, err = ct.Value[i][j].Value[1].DecodePoly64(data[pointer:]); err != nil {
+			if inc, err = decodePoly(&ct.Value[i][j].Value[1], data[pointer:]); err != nil {
 				return
 			}
 			pointer += inc
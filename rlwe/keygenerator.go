@@ -1,6 +1,8 @@
 package rlwe
 
 import (
+	"math/bits"
+
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
 	"github.com/tuneinsight/lattigo/v3/utils"
@@ -15,48 +17,93 @@ type KeyGenerator interface {
 	GenPublicKey(sk *SecretKey) (pk *PublicKey)
 	GenKeyPair() (sk *SecretKey, pk *PublicKey)
 	GenRelinearizationKey(sk *SecretKey, maxDegree int) (evk *RelinearizationKey)
+	GenRelinearizationKeyLvl(sk *SecretKey, maxDegree, levelQ, levelP int) (evk *RelinearizationKey)
 	GenSwitchingKey(skInput, skOutput *SecretKey) (newevakey *SwitchingKey)
 	GenSwitchingKeyForGalois(galEl uint64, sk *SecretKey) (swk *SwitchingKey)
+	GenSwitchingKeyForGaloisLvl(galEl uint64, sk *SecretKey, levelQ, levelP int) (swk *SwitchingKey)
 	GenRotationKeys(galEls []uint64, sk *SecretKey) (rks *RotationKeySet)
+	GenRotationKeysLvl(galEls []uint64, sk *SecretKey, levelQ, levelP int) (rks *RotationKeySet)
 	GenSwitchingKeyForRotationBy(k int, sk *SecretKey) (swk *SwitchingKey)
 	GenRotationKeysForRotations(ks []int, inclueSwapRows bool, sk *SecretKey) (rks *RotationKeySet)
 	GenSwitchingKeyForRowRotation(sk *SecretKey) (swk *SwitchingKey)
 	GenRotationKeysForInnerSum(sk *SecretKey) (rks *RotationKeySet)
 	GenSwitchingKeysForRingSwap(skCKKS, skCI *SecretKey) (swkStdToConjugateInvariant, swkConjugateInvariantToStd *SwitchingKey)
+	GenSeededRelinearizationKey(sk *SecretKey, maxDegree int) (evk *SeededRelinearizationKey)
+	GenSeededSwitchingKey(skInput, skOutput *SecretKey) (swk *SeededSwitchingKey)
+	GenSeededRotationKeys(galEls []uint64, sk *SecretKey) (rks *SeededRotationKeySet)
+	GenLWESecretKey(n int) (sk *LWESecretKey)
+	GenLWESwitchingKey(skInput, skOutput *LWESecretKey, Q uint64, logBase int) (swk *LWESwitchingKey)
 }
 
 // KeyGenerator is a structure that stores the elements required to create new keys,
 // as well as a memory buffer for intermediate values.
 type keyGenerator struct {
 	*skEncryptor
+
+	skPRNG            utils.PRNG
+	uniformPRNG       utils.PRNG
+	skTernarySampler  *ring.TernarySampler
+	skGaussianSampler *ring.GaussianSampler
 }
 
+// Domains of the independent streams of randomness of the KeyGenerator.
+const (
+	keyGenDomainSecret  = "rlwe/keygen/secret"
+	keyGenDomainNoise   = "rlwe/keygen/noise"
+	keyGenDomainUniform = "rlwe/keygen/uniform"
+)
+
 // NewKeyGenerator creates a new KeyGenerator, from which the secret and public keys, as well as the evaluation,
 // rotation and switching keys can be generated.
 func NewKeyGenerator(params Parameters) KeyGenerator {
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		panic(err)
+	}
+	return NewKeyGeneratorWithPRNG(params, prng)
+}
+
+// NewKeyGeneratorWithPRNG creates a new KeyGenerator drawing all its randomness from prng. With a utils.KeyedPRNG
+// keyed with a secret seed, the keys are reproducible from the seed.
+//
+// The secret keys, the errors and the uniform elements of the keys are sampled from independent streams derived
+// from prng, so that the secret keys do not depend on the public, evaluation, rotation and switching keys generated
+// in between. All the GenSecretKey* methods and GenLWESecretKey share the stream of the secret keys: a secret key
+// depends on prng and on the sequence of secret keys generated before it by the KeyGenerator.
+func NewKeyGeneratorWithPRNG(params Parameters, prng utils.PRNG) KeyGenerator {
+
+	prngs, err := utils.NewDerivedPRNGs(prng, keyGenDomainSecret, keyGenDomainNoise, keyGenDomainUniform)
+	if err != nil {
+		panic(err)
+	}
+
 	return &keyGenerator{
-		skEncryptor: newSkEncryptor(params, NewSecretKey(params)),
+		skEncryptor:       newSkEncryptorWithPRNG(params, NewSecretKey(params), prngs[1], prngs[2]),
+		skPRNG:            prngs[0],
+		uniformPRNG:       prngs[2],
+		skTernarySampler:  ring.NewTernarySamplerWithHammingWeight(prngs[0], params.RingQ(), params.HammingWeight(), false),
+		skGaussianSampler: ring.NewGaussianSampler(prngs[0], params.RingQ(), params.Sigma(), int(6*params.Sigma())),
 	}
 }
 
 // GenSecretKey generates a new SecretKey with the distribution [1/3, 1/3, 1/3].
 func (keygen *keyGenerator) GenSecretKey() (sk *SecretKey) {
-	return keygen.genSecretKeyFromSampler(keygen.ternarySampler)
+	return keygen.genSecretKeyFromSampler(keygen.skTernarySampler)
 }
 
 // GenSecretKey generates a new SecretKey with the error distribution.
 func (keygen *keyGenerator) GenSecretKeyGaussian() (sk *SecretKey) {
-	return keygen.genSecretKeyFromSampler(keygen.gaussianSampler)
+	return keygen.genSecretKeyFromSampler(keygen.skGaussianSampler)
 }
 
 // GenSecretKeyWithDistrib generates a new SecretKey with the distribution [(p-1)/2, p, (p-1)/2].
 func (keygen *keyGenerator) GenSecretKeyWithDistrib(p float64) (sk *SecretKey) {
-	return keygen.genSecretKeyFromSampler(ring.NewTernarySampler(keygen.prng, keygen.params.RingQ(), p, false))
+	return keygen.genSecretKeyFromSampler(ring.NewTernarySampler(keygen.skPRNG, keygen.params.RingQ(), p, false))
 }
 
 // GenSecretKeyWithHammingWeight generates a new SecretKey with exactly hw non-zero coefficients.
 func (keygen *keyGenerator) GenSecretKeyWithHammingWeight(hw int) (sk *SecretKey) {
-	return keygen.genSecretKeyFromSampler(ring.NewTernarySamplerWithHammingWeight(keygen.prng, keygen.params.RingQ(), hw, false))
+	return keygen.genSecretKeyFromSampler(ring.NewTernarySamplerWithHammingWeight(keygen.skPRNG, keygen.params.RingQ(), hw, false))
 }
 
 // genSecretKeyFromSampler generates a new SecretKey sampled from the provided Sampler.
@@ -92,15 +139,14 @@ func (keygen *keyGenerator) GenKeyPair() (sk *SecretKey, pk *PublicKey) {
 
 // GenRelinKey generates a new EvaluationKey that will be used to relinearize Ciphertexts during multiplication.
 func (keygen *keyGenerator) GenRelinearizationKey(sk *SecretKey, maxDegree int) (evk *RelinearizationKey) {
+	return keygen.GenRelinearizationKeyLvl(sk, maxDegree, keygen.params.QCount()-1, keygen.params.PCount()-1)
+}
 
-	levelQ := keygen.params.QCount() - 1
-	levelP := keygen.params.PCount() - 1
+// GenRelinearizationKeyLvl generates a new EvaluationKey at levels levelQ and levelP, which relinearizes
+// Ciphertexts of level at most levelQ. See NewSwitchingKeyLvl for the valid levels.
+func (keygen *keyGenerator) GenRelinearizationKeyLvl(sk *SecretKey, maxDegree, levelQ, levelP int) (evk *RelinearizationKey) {
 
-	evk = new(RelinearizationKey)
-	evk.Keys = make([]*SwitchingKey, maxDegree)
-	for i := range evk.Keys {
-		evk.Keys[i] = NewSwitchingKey(keygen.params, levelQ, levelP)
-	}
+	evk = NewRelinKeyLvl(keygen.params, maxDegree, levelQ, levelP)
 
 	keygen.buffQP.Q.CopyValues(sk.Value.Q)
 	ringQ := keygen.params.RingQ()
@@ -115,7 +161,13 @@ func (keygen *keyGenerator) GenRelinearizationKey(sk *SecretKey, maxDegree int)
 // GenRotationKeys generates a RotationKeySet from a list of galois element corresponding to the desired rotations
 // See also GenRotationKeysForRotations.
 func (keygen *keyGenerator) GenRotationKeys(galEls []uint64, sk *SecretKey) (rks *RotationKeySet) {
-	rks = NewRotationKeySet(keygen.params, galEls)
+	return keygen.GenRotationKeysLvl(galEls, sk, keygen.params.QCount()-1, keygen.params.PCount()-1)
+}
+
+// GenRotationKeysLvl is as GenRotationKeys, but generates the keys at levels levelQ and levelP. The keys can
+// only be used on Ciphertexts of level at most levelQ. See NewSwitchingKeyLvl for the valid levels.
+func (keygen *keyGenerator) GenRotationKeysLvl(galEls []uint64, sk *SecretKey, levelQ, levelP int) (rks *RotationKeySet) {
+	rks = NewRotationKeySetLvl(keygen.params, galEls, levelQ, levelP)
 	for _, galEl := range galEls {
 		keygen.genrotKey(sk.Value, keygen.params.InverseGaloisElement(galEl), rks.Keys[galEl])
 	}
@@ -150,7 +202,13 @@ func (keygen *keyGenerator) GenSwitchingKeyForRowRotation(sk *SecretKey) (swk *S
 }
 
 func (keygen *keyGenerator) GenSwitchingKeyForGalois(galoisEl uint64, sk *SecretKey) (swk *SwitchingKey) {
-	swk = NewSwitchingKey(keygen.params, keygen.params.QCount()-1, keygen.params.PCount()-1)
+	return keygen.GenSwitchingKeyForGaloisLvl(galoisEl, sk, keygen.params.QCount()-1, keygen.params.PCount()-1)
+}
+
+// GenSwitchingKeyForGaloisLvl generates the switching key for the given galois element at levels levelQ and levelP.
+// See NewSwitchingKeyLvl for the valid levels.
+func (keygen *keyGenerator) GenSwitchingKeyForGaloisLvl(galoisEl uint64, sk *SecretKey, levelQ, levelP int) (swk *SwitchingKey) {
+	swk = NewSwitchingKeyLvl(keygen.params, levelQ, levelP)
 	keygen.genrotKey(sk.Value, keygen.params.InverseGaloisElement(galoisEl), swk)
 	return
 }
@@ -315,3 +373,144 @@ func (keygen *keyGenerator) genSwitchingKey(skIn *ring.Poly, skOut ringqp.Poly,
 	// Adds the plaintext (input-key) to the switching-key.
 	AddPolyTimesGadgetVectorToGadgetCiphertext(skIn, []GadgetCiphertext{swk.GadgetCiphertext}, *keygen.params.RingQP(), keygen.params.Pow2Base(), keygen.buffQ[0])
 }
+
+// GenSeededRelinearizationKey generates a new RelinearizationKey in which the uniform elements of each switching key
+// are generated from a fresh seed. See SeededRelinearizationKey.
+func (keygen *keyGenerator) GenSeededRelinearizationKey(sk *SecretKey, maxDegree int) (evk *SeededRelinearizationKey) {
+
+	levelQ := keygen.params.QCount() - 1
+	levelP := keygen.params.PCount() - 1
+
+	evk = new(SeededRelinearizationKey)
+	evk.Keys = make([]*SeededSwitchingKey, maxDegree)
+
+	keygen.buffQP.Q.CopyValues(sk.Value.Q)
+	ringQ := keygen.params.RingQ()
+	for i := 0; i < maxDegree; i++ {
+		ringQ.MulCoeffsMontgomery(keygen.buffQP.Q, sk.Value.Q, keygen.buffQP.Q)
+		swk := NewSwitchingKey(keygen.params, levelQ, levelP)
+		kgen, seed := keygen.withSeed()
+		kgen.genSwitchingKey(keygen.buffQP.Q, sk.Value, swk)
+		evk.Keys[i] = newSeededSwitchingKey(swk, seed)
+	}
+
+	return
+}
+
+// GenSeededSwitchingKey generates a new key-switching key from skInput to skOutput in which the uniform elements
+// are generated from a fresh seed. See GenSwitchingKey and SeededSwitchingKey.
+func (keygen *keyGenerator) GenSeededSwitchingKey(skInput, skOutput *SecretKey) (swk *SeededSwitchingKey) {
+	kgen, seed := keygen.withSeed()
+	return newSeededSwitchingKey(kgen.GenSwitchingKey(skInput, skOutput), seed)
+}
+
+// GenSeededRotationKeys generates a RotationKeySet from a list of galois element in which the uniform elements
+// of each switching key are generated from a fresh seed. See GenRotationKeys and SeededRotationKeySet.
+func (keygen *keyGenerator) GenSeededRotationKeys(galEls []uint64, sk *SecretKey) (rks *SeededRotationKeySet) {
+	rks = &SeededRotationKeySet{Keys: make(map[uint64]*SeededSwitchingKey, len(galEls))}
+	for _, galEl := range galEls {
+		swk := NewSwitchingKey(keygen.params, keygen.params.QCount()-1, keygen.params.PCount()-1)
+		kgen, seed := keygen.withSeed()
+		kgen.genrotKey(sk.Value, keygen.params.InverseGaloisElement(galEl), swk)
+		rks.Keys[galEl] = newSeededSwitchingKey(swk, seed)
+	}
+	return
+}
+
+// GenLWESecretKey generates a new LWESecretKey of dimension n with the distribution [1/3, 1/3, 1/3].
+func (keygen *keyGenerator) GenLWESecretKey(n int) (sk *LWESecretKey) {
+
+	sk = NewLWESecretKey(n)
+
+	buff := make([]byte, n)
+	for i := 0; i < n; {
+
+		if _, err := keygen.skPRNG.Read(buff); err != nil {
+			panic(err)
+		}
+
+		// rejects 255 so that the bytes are uniform modulo 3
+		for _, b := range buff {
+			if b != 0xff && i < n {
+				sk.Value[i] = int64(b%3) - 1
+				i++
+			}
+		}
+	}
+
+	return
+}
+
+// GenLWESwitchingKey generates a new LWESwitchingKey modulo Q with the gadget base 2^logBase, which switches
+// the LWE ciphertexts encrypted under skInput to LWE ciphertexts encrypted under skOutput.
+// The dimension of skOutput can be smaller than the dimension of skInput.
+func (keygen *keyGenerator) GenLWESwitchingKey(skInput, skOutput *LWESecretKey, Q uint64, logBase int) (swk *LWESwitchingKey) {
+
+	swk = NewLWESwitchingKey(skInput.N(), skOutput.N(), Q, logBase)
+
+	ringQ := keygen.params.RingQ()
+	buffE := ringQ.NewPolyLvl(0)
+	ptr := ringQ.N
+
+	mask := uint64(1)<<bits.Len64(Q-1) - 1
+
+	s := make([]uint64, skOutput.N())
+	for i := range s {
+		s[i] = liftMod(skOutput.Value[i], Q)
+	}
+
+	for i := range swk.Value {
+
+		// s[i] * 2^{j * logBase} mod Q
+		m := liftMod(skInput.Value[i], Q)
+
+		for j, ct := range swk.Value[i] {
+
+			if j > 0 {
+				m = mulMod(m, (uint64(1)<<logBase)%Q, Q)
+			}
+
+			// e is sampled from the error distribution modulo Q[0] and lifted modulo Q
+			if ptr == ringQ.N {
+				keygen.gaussianSampler.ReadLvl(0, buffE)
+				ptr = 0
+			}
+
+			e := buffE.Coeffs[0][ptr]
+			ptr++
+
+			if e >= ringQ.Modulus[0]>>1 {
+				ct.B = liftMod(-int64(ringQ.Modulus[0]-e), Q)
+			} else {
+				ct.B = liftMod(int64(e), Q)
+			}
+
+			// b = -<a, s'> + s[i] * 2^{j * logBase} + e
+			if ct.B += m; ct.B >= Q {
+				ct.B -= Q
+			}
+
+			for k := range ct.A {
+				ct.A[k] = ring.RandUniform(keygen.uniformPRNG, Q, mask)
+				if as := mulMod(ct.A[k], s[k], Q); as != 0 {
+					if ct.B += Q - as; ct.B >= Q {
+						ct.B -= Q
+					}
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// withSeed samples a fresh seed and returns a KeyGenerator sampling the uniform elements of the keys from it.
+// The returned KeyGenerator shares its buffers with the receiver.
+func (keygen *keyGenerator) withSeed() (kgen *keyGenerator, seed []byte) {
+	var prng utils.PRNG
+	seed, prng = newSeed(keygen.prng)
+	kgen = &keyGenerator{}
+	*kgen = *keygen
+	kgen.skEncryptor = keygen.WithPRNG(prng).(*skEncryptor)
+	return kgen, seed
+}
@@ -1,6 +1,9 @@
 package rlwe
 
 import (
+	"fmt"
+	"reflect"
+
 	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
 )
 
@@ -32,11 +35,50 @@ type RotationKeySet struct {
 	Keys map[uint64]*SwitchingKey
 }
 
+// RotationKeyProvider is the interface of the sets of rotation keys from which the Evaluator fetches the
+// keys of the automorphisms. It is implemented by RotationKeySet, which holds all the keys in memory, and by
+// LazyRotationKeySet, which reads them on demand.
+type RotationKeyProvider interface {
+	// GetRotationKey returns the rotation key for the given galois element and true, or nil and false if
+	// the key is not in the set.
+	GetRotationKey(galEl uint64) (*SwitchingKey, bool)
+	// GaloisElements returns the galois elements of the keys of the set.
+	GaloisElements() []uint64
+}
+
 // EvaluationKey is a type for storing generic RLWE public evaluation keys. An evaluation key is a union
 // of a relinearization key and a set of rotation keys.
+// A nil pointer stored in Rtks, such as a nil *RotationKeySet, is not equal to nil: the presence of rotation keys
+// must be tested with HasRotationKeys.
 type EvaluationKey struct {
 	Rlk  *RelinearizationKey
-	Rtks *RotationKeySet
+	Rtks RotationKeyProvider
+}
+
+// HasRotationKeys returns true if the EvaluationKey has a set of rotation keys, that is, if Rtks is neither nil
+// nor a nil pointer.
+func (evk EvaluationKey) HasRotationKeys() bool {
+	return !isNilRotationKeys(evk.Rtks)
+}
+
+// isNilRotationKeys returns true if rtks is nil or stores a nil value of a nillable type.
+func isNilRotationKeys(rtks RotationKeyProvider) bool {
+	if rtks == nil {
+		return true
+	}
+	switch v := reflect.ValueOf(rtks); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// normalizeRotationKeys returns nil if rtks stores a nil pointer, and rtks otherwise.
+func normalizeRotationKeys(rtks RotationKeyProvider) RotationKeyProvider {
+	if isNilRotationKeys(rtks) {
+		return nil
+	}
+	return rtks
 }
 
 // NewSecretKey generates a new SecretKey with zero values.
@@ -96,10 +138,16 @@ func (pk *PublicKey) Equals(other *PublicKey) bool {
 
 // NewRotationKeySet returns a new RotationKeySet with pre-allocated switching keys for each distinct galoisElement value.
 func NewRotationKeySet(params Parameters, galoisElement []uint64) (rotKey *RotationKeySet) {
+	return NewRotationKeySetLvl(params, galoisElement, params.QCount()-1, params.PCount()-1)
+}
+
+// NewRotationKeySetLvl returns a new RotationKeySet with pre-allocated switching keys at levels levelQ and levelP
+// for each distinct galoisElement value. See NewSwitchingKeyLvl for the valid levels.
+func NewRotationKeySetLvl(params Parameters, galoisElement []uint64, levelQ, levelP int) (rotKey *RotationKeySet) {
 	rotKey = new(RotationKeySet)
 	rotKey.Keys = make(map[uint64]*SwitchingKey, len(galoisElement))
 	for _, galEl := range galoisElement {
-		rotKey.Keys[galEl] = NewSwitchingKey(params, params.QCount()-1, params.PCount()-1)
+		rotKey.Keys[galEl] = NewSwitchingKeyLvl(params, levelQ, levelP)
 	}
 	return
 }
@@ -107,13 +155,25 @@ func NewRotationKeySet(params Parameters, galoisElement []uint64) (rotKey *Rotat
 // GetRotationKey return the rotation key for the given galois element or nil if such key is not in the set. The
 // second argument is true  iff the first one is non-nil.
 func (rtks *RotationKeySet) GetRotationKey(galoisEl uint64) (*SwitchingKey, bool) {
-	if rtks.Keys == nil {
+	if rtks == nil || rtks.Keys == nil {
 		return nil, false
 	}
 	rotKey, inSet := rtks.Keys[galoisEl]
 	return rotKey, inSet
 }
 
+// GaloisElements returns the galois elements of the keys of the set.
+func (rtks *RotationKeySet) GaloisElements() (galEls []uint64) {
+	if rtks == nil {
+		return nil
+	}
+	galEls = make([]uint64, 0, len(rtks.Keys))
+	for galEl := range rtks.Keys {
+		galEls = append(galEls, galEl)
+	}
+	return
+}
+
 // NewSwitchingKey returns a new public switching key with pre-allocated zero-value
 func NewSwitchingKey(params Parameters, levelQ, levelP int) *SwitchingKey {
 	return &SwitchingKey{GadgetCiphertext: *NewGadgetCiphertext(
@@ -124,6 +184,26 @@ func NewSwitchingKey(params Parameters, levelQ, levelP int) *SwitchingKey {
 		*params.RingQP())}
 }
 
+// NewSwitchingKeyLvl is as NewSwitchingKey, but panics if the levels are not valid levels of the parameters,
+// that is, if levelQ is not in [0, params.MaxLevel()] or if levelP is not in [0, params.PCount()-1]
+// (levelP must be -1 if the parameters have no modulus P).
+func NewSwitchingKeyLvl(params Parameters, levelQ, levelP int) *SwitchingKey {
+
+	if levelQ < 0 || levelQ > params.MaxLevel() {
+		panic(fmt.Sprintf("cannot NewSwitchingKeyLvl: levelQ=%d is not in [0, %d]", levelQ, params.MaxLevel()))
+	}
+
+	if params.PCount() == 0 && levelP != -1 {
+		panic("cannot NewSwitchingKeyLvl: levelP must be -1 for parameters without modulus P")
+	}
+
+	if params.PCount() != 0 && (levelP < 0 || levelP > params.PCount()-1) {
+		panic(fmt.Sprintf("cannot NewSwitchingKeyLvl: levelP=%d is not in [0, %d]", levelP, params.PCount()-1))
+	}
+
+	return NewSwitchingKey(params, levelQ, levelP)
+}
+
 // Equals checks two SwitchingKeys for equality.
 func (swk *SwitchingKey) Equals(other *SwitchingKey) bool {
 	return swk.GadgetCiphertext.Equals(&other.GadgetCiphertext)
@@ -136,10 +216,16 @@ func (swk *SwitchingKey) CopyNew() *SwitchingKey {
 
 // NewRelinKey creates a new EvaluationKey with zero values.
 func NewRelinKey(params Parameters, maxRelinDegree int) (evakey *RelinearizationKey) {
+	return NewRelinKeyLvl(params, maxRelinDegree, params.QCount()-1, params.PCount()-1)
+}
+
+// NewRelinKeyLvl creates a new EvaluationKey with zero values at levels levelQ and levelP.
+// See NewSwitchingKeyLvl for the valid levels.
+func NewRelinKeyLvl(params Parameters, maxRelinDegree, levelQ, levelP int) (evakey *RelinearizationKey) {
 	evakey = new(RelinearizationKey)
 	evakey.Keys = make([]*SwitchingKey, maxRelinDegree)
 	for d := 0; d < maxRelinDegree; d++ {
-		evakey.Keys[d] = NewSwitchingKey(params, params.QCount()-1, params.PCount()-1)
+		evakey.Keys[d] = NewSwitchingKeyLvl(params, levelQ, levelP)
 	}
 
 	return
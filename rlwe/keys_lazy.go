@@ -0,0 +1,297 @@
+package rlwe
+
+import (
+	"bufio"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// LazyRotationKeySet is a RotationKeyProvider that reads the rotation keys on demand from the encoding of a
+// RotationKeySet, as written by RotationKeySet.WriteTo or RotationKeySet.MarshalBinary, and keeps the most
+// recently used ones in a LRU cache. Only the location of each key in the encoding is kept in memory.
+//
+// The encoding is read through an io.ReaderAt, such as an *os.File, or a bytes.Reader on a memory-mapped file.
+// A LazyRotationKeySet can be used concurrently, for example by the shallow copies of an Evaluator.
+type LazyRotationKeySet struct {
+	r      io.ReaderAt
+	closer io.Closer
+
+	capacity int
+	index    map[uint64]keyLocation
+	galEls   []uint64
+
+	mu    sync.Mutex
+	lru   *list.List // of *lazyKey, the most recently used first
+	cache map[uint64]*list.Element
+}
+
+type keyLocation struct {
+	offset, size int64
+}
+
+type lazyKey struct {
+	galEl uint64
+	swk   *SwitchingKey
+}
+
+// NewLazyRotationKeySet creates a new LazyRotationKeySet reading the size bytes of the encoding of a
+// RotationKeySet from r and caching at most capacity keys. It reads the headers of the keys to index them,
+// but not their coefficients.
+func NewLazyRotationKeySet(r io.ReaderAt, size int64, capacity int) (rtks *LazyRotationKeySet, err error) {
+
+	if capacity < 1 {
+		return nil, errors.New("cannot NewLazyRotationKeySet: capacity must be at least 1")
+	}
+
+	rtks = &LazyRotationKeySet{
+		r:        r,
+		capacity: capacity,
+		index:    make(map[uint64]keyLocation),
+		lru:      list.New(),
+		cache:    make(map[uint64]*list.Element),
+	}
+
+	buff := make([]byte, 8)
+
+	if size < 8 {
+		return nil, errors.New("cannot NewLazyRotationKeySet: truncated encoding")
+	}
+
+	if _, err = r.ReadAt(buff, 0); err != nil {
+		return nil, fmt.Errorf("cannot NewLazyRotationKeySet: %w", err)
+	}
+	nbKeys := binary.BigEndian.Uint64(buff)
+
+	offset := int64(8)
+
+	for i := uint64(0); i < nbKeys; i++ {
+
+		if offset+8 > size {
+			return nil, errors.New("cannot NewLazyRotationKeySet: truncated encoding")
+		}
+
+		if _, err = r.ReadAt(buff, offset); err != nil {
+			return nil, fmt.Errorf("cannot NewLazyRotationKeySet: %w", err)
+		}
+		galEl := binary.BigEndian.Uint64(buff)
+		offset += 8
+
+		var keySize int64
+		if keySize, err = gadgetCiphertextDataLen(r, offset); err != nil {
+			return nil, fmt.Errorf("cannot NewLazyRotationKeySet: %w", err)
+		}
+
+		if _, inSet := rtks.index[galEl]; inSet {
+			return nil, fmt.Errorf("cannot NewLazyRotationKeySet: duplicate rotation key for galois element %d", galEl)
+		}
+
+		rtks.galEls = append(rtks.galEls, galEl)
+		rtks.index[galEl] = keyLocation{offset: offset, size: keySize}
+		offset += keySize
+
+		if offset > size {
+			return nil, errors.New("cannot NewLazyRotationKeySet: truncated encoding")
+		}
+	}
+
+	if offset != size {
+		return nil, fmt.Errorf("cannot NewLazyRotationKeySet: %d trailing bytes", size-offset)
+	}
+
+	return
+}
+
+// OpenLazyRotationKeySet opens the file at path, which stores the encoding of a RotationKeySet, and
+// returns a LazyRotationKeySet reading the keys from it. The file is closed by Close.
+func OpenLazyRotationKeySet(path string, capacity int) (rtks *LazyRotationKeySet, err error) {
+
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return nil, err
+	}
+
+	var info os.FileInfo
+	if info, err = f.Stat(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	if rtks, err = NewLazyRotationKeySet(f, info.Size(), capacity); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	rtks.closer = f
+
+	return
+}
+
+// Close closes the file opened by OpenLazyRotationKeySet, if any, and empties the cache.
+func (rtks *LazyRotationKeySet) Close() (err error) {
+
+	rtks.mu.Lock()
+	defer rtks.mu.Unlock()
+
+	rtks.lru.Init()
+	rtks.cache = make(map[uint64]*list.Element)
+
+	if rtks.closer != nil {
+		err = rtks.closer.Close()
+		rtks.closer = nil
+	}
+
+	return
+}
+
+// GaloisElements returns the galois elements of the keys of the set, in the order of the encoding,
+// which is sorted for the encodings written by RotationKeySet.WriteTo and RotationKeySet.MarshalBinary.
+func (rtks *LazyRotationKeySet) GaloisElements() []uint64 {
+	if rtks == nil {
+		return nil
+	}
+	return append([]uint64{}, rtks.galEls...)
+}
+
+// rotationKeyLoader is implemented by the RotationKeyProviders that may fail to fetch a key, such as
+// LazyRotationKeySet. The checked methods of the Evaluator fetch the keys through it to return the errors.
+type rotationKeyLoader interface {
+	RotationKeyProvider
+	LoadRotationKey(galEl uint64) (swk *SwitchingKey, inSet bool, err error)
+}
+
+// GetRotationKey returns the rotation key for the given galois element and true, or nil and false if the key
+// is not in the set. The key is read from the encoding if it is not in the cache, in which case the least
+// recently used key is evicted if the cache is full. The method panics if the key cannot be read: LoadRotationKey
+// returns the error instead, and the checked methods of the Evaluator load the keys with it.
+func (rtks *LazyRotationKeySet) GetRotationKey(galEl uint64) (*SwitchingKey, bool) {
+	swk, inSet, err := rtks.LoadRotationKey(galEl)
+	if err != nil {
+		panic(err)
+	}
+	return swk, inSet
+}
+
+// LoadRotationKey returns the rotation key for the given galois element and true, or nil and false if the key
+// is not in the set, as GetRotationKey. It returns an error if the key cannot be read or decoded.
+func (rtks *LazyRotationKeySet) LoadRotationKey(galEl uint64) (swk *SwitchingKey, inSet bool, err error) {
+
+	if rtks == nil {
+		return nil, false, nil
+	}
+
+	loc, inSet := rtks.index[galEl]
+	if !inSet {
+		return nil, false, nil
+	}
+
+	rtks.mu.Lock()
+	if e, cached := rtks.cache[galEl]; cached {
+		rtks.lru.MoveToFront(e)
+		rtks.mu.Unlock()
+		return e.Value.(*lazyKey).swk, true, nil
+	}
+	rtks.mu.Unlock()
+
+	// the key is read without holding the lock, so that the cached keys can be fetched concurrently
+	swk = new(SwitchingKey)
+	if _, err = swk.ReadFrom(bufio.NewReader(io.NewSectionReader(rtks.r, loc.offset, loc.size))); err != nil {
+		return nil, true, fmt.Errorf("cannot LoadRotationKey: %w", err)
+	}
+
+	rtks.mu.Lock()
+	defer rtks.mu.Unlock()
+
+	// another goroutine may have read the key in the meantime
+	if e, cached := rtks.cache[galEl]; cached {
+		rtks.lru.MoveToFront(e)
+		return e.Value.(*lazyKey).swk, true, nil
+	}
+
+	rtks.cache[galEl] = rtks.lru.PushFront(&lazyKey{galEl: galEl, swk: swk})
+
+	for rtks.lru.Len() > rtks.capacity {
+		e := rtks.lru.Back()
+		delete(rtks.cache, e.Value.(*lazyKey).galEl)
+		rtks.lru.Remove(e)
+	}
+
+	return swk, true, nil
+}
+
+// gadgetCiphertextDataLen returns the length of the encoding of the GadgetCiphertext at the given offset
+// of r, by reading the headers of its polynomials.
+func gadgetCiphertextDataLen(r io.ReaderAt, offset int64) (size int64, err error) {
+
+	decomp := make([]byte, 2)
+	if _, err = r.ReadAt(decomp, offset); err != nil {
+		return
+	}
+	size = 2
+
+	if decomp[0] == 0 || decomp[1] == 0 {
+		return 0, malformed("invalid decomposition %dx%d", decomp[0], decomp[1])
+	}
+
+	flags := make([]byte, 2)
+
+	// each element of the gadget ciphertext is a pair of ringqp.Poly
+	for i := 0; i < 2*int(decomp[0])*int(decomp[1]); i++ {
+
+		if _, err = r.ReadAt(flags, offset+size); err != nil {
+			return
+		}
+		size += 2
+
+		if flags[0] > 1 || flags[1] > 1 {
+			return 0, malformed("invalid flags %v", flags)
+		}
+
+		for _, flag := range flags {
+			if flag == 1 {
+				var inc int64
+				if inc, err = polyDataLen(r, offset+size); err != nil {
+					return
+				}
+				size += inc
+			}
+		}
+	}
+
+	return
+}
+
+// polyDataLen returns the length of the encoding of the ring.Poly at the given offset of r,
+// written with either WriteTo64 or WriteToPacked.
+func polyDataLen(r io.ReaderAt, offset int64) (size int64, err error) {
+
+	header := make([]byte, 7)
+	if _, err = r.ReadAt(header, offset); err != nil {
+		return
+	}
+
+	N := int64(binary.BigEndian.Uint32(header))
+	limbs := int64(header[4]) + 1
+
+	if header[5]&ring.PackedFlag == 0 {
+		return 7 + N*limbs*8, nil
+	}
+
+	widths := make([]byte, limbs)
+	if _, err = r.ReadAt(widths, offset+7); err != nil {
+		return
+	}
+
+	size = 7 + limbs
+	for _, w := range widths {
+		size += (N*int64(w) + 7) >> 3
+	}
+
+	return
+}
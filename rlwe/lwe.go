@@ -0,0 +1,496 @@
+package rlwe
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// LWECiphertext is a LWE ciphertext (b, a) of dimension len(A) modulo Q, which decrypts
+// under the secret s to the phase b + <a, s> mod Q.
+type LWECiphertext struct {
+	B uint64
+	A []uint64
+	Q uint64
+}
+
+// NewLWECiphertext creates a new LWECiphertext of dimension n modulo Q.
+func NewLWECiphertext(n int, Q uint64) *LWECiphertext {
+	return &LWECiphertext{A: make([]uint64, n), Q: Q}
+}
+
+// N returns the dimension of the target LWECiphertext.
+func (ct *LWECiphertext) N() int {
+	return len(ct.A)
+}
+
+// CopyNew creates a deep copy of the target LWECiphertext.
+func (ct *LWECiphertext) CopyNew() *LWECiphertext {
+	A := make([]uint64, len(ct.A))
+	copy(A, ct.A)
+	return &LWECiphertext{B: ct.B, A: A, Q: ct.Q}
+}
+
+// ModSwitch switches the modulus of the target LWECiphertext from ct.Q to Q, applying round(x * Q / ct.Q)
+// to each of its coefficients, and writes the result on ctOut, which can be the target LWECiphertext.
+func (ct *LWECiphertext) ModSwitch(Q uint64, ctOut *LWECiphertext) {
+
+	if len(ctOut.A) != len(ct.A) {
+		ctOut.A = make([]uint64, len(ct.A))
+	}
+
+	ctOut.B = modSwitch(ct.B, ct.Q, Q)
+	for i, a := range ct.A {
+		ctOut.A[i] = modSwitch(a, ct.Q, Q)
+	}
+
+	ctOut.Q = Q
+}
+
+// ModSwitchNew switches the modulus of the target LWECiphertext from ct.Q to Q and returns the result
+// on a new LWECiphertext.
+func (ct *LWECiphertext) ModSwitchNew(Q uint64) (ctOut *LWECiphertext) {
+	ctOut = NewLWECiphertext(len(ct.A), Q)
+	ct.ModSwitch(Q, ctOut)
+	return
+}
+
+// GetDataLen returns the length in bytes of the target LWECiphertext.
+func (ct *LWECiphertext) GetDataLen() int {
+	return 20 + 8*len(ct.A)
+}
+
+// MarshalBinary encodes the target LWECiphertext on a slice of bytes.
+func (ct *LWECiphertext) MarshalBinary() (data []byte, err error) {
+	data = make([]byte, ct.GetDataLen())
+	binary.BigEndian.PutUint64(data[0:], ct.Q)
+	binary.BigEndian.PutUint32(data[8:], uint32(len(ct.A)))
+	binary.BigEndian.PutUint64(data[12:], ct.B)
+	for i, a := range ct.A {
+		binary.BigEndian.PutUint64(data[20+8*i:], a)
+	}
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target LWECiphertext.
+func (ct *LWECiphertext) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 20 {
+		return malformed("data is too short")
+	}
+
+	Q := binary.BigEndian.Uint64(data[0:])
+	n := int(binary.BigEndian.Uint32(data[8:]))
+
+	if Q < 2 {
+		return malformed("invalid modulus %d", Q)
+	}
+
+	if (len(data)-20)/8 != n || (len(data)-20)%8 != 0 {
+		return malformed("dimension %d does not match the size of the data", n)
+	}
+
+	ct.Q = Q
+
+	if ct.B = binary.BigEndian.Uint64(data[12:]); ct.B >= Q {
+		return malformed("coefficient is not reduced modulo %d", Q)
+	}
+
+	ct.A = make([]uint64, n)
+	for i := range ct.A {
+		if ct.A[i] = binary.BigEndian.Uint64(data[20+8*i:]); ct.A[i] >= Q {
+			return malformed("coefficient is not reduced modulo %d", Q)
+		}
+	}
+
+	return
+}
+
+// LWESecretKey is a LWE secret key, stored as a vector of small signed integers
+// so that it can be used with any modulus.
+type LWESecretKey struct {
+	Value []int64
+}
+
+// NewLWESecretKey creates a new zero LWESecretKey of dimension n.
+func NewLWESecretKey(n int) *LWESecretKey {
+	return &LWESecretKey{Value: make([]int64, n)}
+}
+
+// NewLWESecretKeyFromSecretKey returns the LWESecretKey of dimension N under which the LWE ciphertexts
+// extracted from the ciphertexts encrypted under sk decrypt, that is, the coefficients of sk.
+func NewLWESecretKeyFromSecretKey(params Parameters, sk *SecretKey) (skLWE *LWESecretKey) {
+
+	ringQ := params.RingQ()
+
+	pol := ringQ.NewPolyLvl(0)
+	ring.CopyLvl(0, sk.Value.Q, pol)
+	ringQ.InvNTTLvl(0, pol, pol)
+	ringQ.InvMFormLvl(0, pol, pol)
+
+	Q := ringQ.Modulus[0]
+
+	skLWE = NewLWESecretKey(ringQ.N)
+	for i, c := range pol.Coeffs[0] {
+		if c >= Q>>1 {
+			skLWE.Value[i] = -int64(Q - c)
+		} else {
+			skLWE.Value[i] = int64(c)
+		}
+	}
+
+	return
+}
+
+// N returns the dimension of the target LWESecretKey.
+func (sk *LWESecretKey) N() int {
+	return len(sk.Value)
+}
+
+// Decrypt returns the phase b + <a, s> mod Q of ct under the target LWESecretKey.
+func (sk *LWESecretKey) Decrypt(ct *LWECiphertext) (phase uint64) {
+
+	if len(ct.A) != len(sk.Value) {
+		panic(NewOperationError("Decrypt", ErrInvalidOperand, "ciphertext has dimension %d but secret key has dimension %d", len(ct.A), len(sk.Value)))
+	}
+
+	Q := ct.Q
+
+	phase = ct.B
+	for i, a := range ct.A {
+		if phase += mulMod(a, liftMod(sk.Value[i], Q), Q); phase >= Q {
+			phase -= Q
+		}
+	}
+
+	return
+}
+
+// LWESwitchingKey is a structure that stores the LWE encryptions, under an output secret s',
+// of the coefficients s[i] of an input secret multiplied by the powers of 2^LogBase.
+type LWESwitchingKey struct {
+	Value   [][]*LWECiphertext
+	LogBase int
+}
+
+// NewLWESwitchingKey creates a new empty LWESwitchingKey modulo Q from dimension nIn to dimension nOut,
+// with the gadget base 2^logBase.
+func NewLWESwitchingKey(nIn, nOut int, Q uint64, logBase int) (swk *LWESwitchingKey) {
+
+	decomp := lweDecomposition(Q, logBase)
+
+	swk = &LWESwitchingKey{Value: make([][]*LWECiphertext, nIn), LogBase: logBase}
+	for i := range swk.Value {
+		swk.Value[i] = make([]*LWECiphertext, decomp)
+		for j := range swk.Value[i] {
+			swk.Value[i][j] = NewLWECiphertext(nOut, Q)
+		}
+	}
+
+	return
+}
+
+// Q returns the modulus of the target LWESwitchingKey.
+func (swk *LWESwitchingKey) Q() uint64 {
+	return swk.Value[0][0].Q
+}
+
+// GetDataLen returns the length in bytes of the target LWESwitchingKey.
+func (swk *LWESwitchingKey) GetDataLen() int {
+	return 10 + len(swk.Value)*len(swk.Value[0])*swk.Value[0][0].GetDataLen()
+}
+
+// MarshalBinary encodes the target LWESwitchingKey on a slice of bytes.
+func (swk *LWESwitchingKey) MarshalBinary() (data []byte, err error) {
+
+	data = make([]byte, swk.GetDataLen())
+	data[0] = uint8(swk.LogBase)
+	binary.BigEndian.PutUint32(data[1:], uint32(len(swk.Value)))
+	data[5] = uint8(len(swk.Value[0]))
+	binary.BigEndian.PutUint32(data[6:], uint32(swk.Value[0][0].N()))
+
+	pointer := 10
+	for i := range swk.Value {
+		for _, ct := range swk.Value[i] {
+
+			var dataCt []byte
+			if dataCt, err = ct.MarshalBinary(); err != nil {
+				return nil, err
+			}
+
+			pointer += copy(data[pointer:], dataCt)
+		}
+	}
+
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes generated by MarshalBinary on the target LWESwitchingKey.
+func (swk *LWESwitchingKey) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 10 {
+		return malformed("data is too short")
+	}
+
+	logBase := int(data[0])
+	nIn := int(binary.BigEndian.Uint32(data[1:]))
+	decomp := int(data[5])
+	nOut := int(binary.BigEndian.Uint32(data[6:]))
+
+	if logBase < 1 || logBase > 32 || nIn == 0 || decomp == 0 {
+		return malformed("invalid switching key dimensions")
+	}
+
+	size := 20 + 8*nOut
+	if (len(data)-10)/size/decomp != nIn || (len(data)-10)%(size*decomp) != 0 {
+		return malformed("dimensions do not match the size of the data")
+	}
+
+	swk.LogBase = logBase
+	swk.Value = make([][]*LWECiphertext, nIn)
+
+	pointer := 10
+	for i := range swk.Value {
+		swk.Value[i] = make([]*LWECiphertext, decomp)
+		for j := range swk.Value[i] {
+
+			swk.Value[i][j] = new(LWECiphertext)
+			if err = swk.Value[i][j].UnmarshalBinary(data[pointer : pointer+size]); err != nil {
+				return
+			}
+
+			if swk.Value[i][j].Q != swk.Value[0][0].Q {
+				return malformed("ciphertexts of the switching key have different moduli")
+			}
+
+			pointer += size
+		}
+	}
+
+	if decomp != lweDecomposition(swk.Q(), logBase) {
+		return malformed("decomposition %d does not match the modulus and the base", decomp)
+	}
+
+	return
+}
+
+// SampleExtract returns the LWE ciphertext of dimension N modulo Q[0] encrypting the index-th coefficient of
+// the plaintext of ctIn, which must be a ciphertext of degree one at level zero. The LWE ciphertext decrypts
+// under the LWESecretKey returned by NewLWESecretKeyFromSecretKey.
+func (eval *Evaluator) SampleExtract(ctIn *Ciphertext, index int) (ctOut *LWECiphertext) {
+
+	params := eval.params
+	ringQ := params.RingQ()
+
+	if params.RingType() != ring.Standard {
+		panic(NewOperationError("SampleExtract", ErrRingType, "sample extraction is only supported in the Standard ring"))
+	}
+
+	if ctIn.Degree() != 1 {
+		panic(NewOperationError("SampleExtract", ErrDegree, "input ciphertext has degree %d instead of 1", ctIn.Degree()))
+	}
+
+	if ctIn.Level() != 0 {
+		panic(NewOperationError("SampleExtract", ErrLevel, "input ciphertext is at level %d instead of 0", ctIn.Level()))
+	}
+
+	N := ringQ.N
+
+	if index < 0 || index >= N {
+		panic(NewOperationError("SampleExtract", ErrInvalidOperand, "index %d is not in [0, %d)", index, N))
+	}
+
+	Q := ringQ.Modulus[0]
+
+	ctOut = NewLWECiphertext(N, Q)
+
+	c0, c1 := ctIn.Value[0], ctIn.Value[1]
+	if ctIn.Value[0].IsNTT {
+		c0, c1 = eval.BuffQP[3].Q, eval.BuffQP[4].Q
+		ringQ.InvNTTLvl(0, ctIn.Value[0], c0)
+		ringQ.InvNTTLvl(0, ctIn.Value[1], c1)
+	}
+
+	ctOut.B = c0.Coeffs[0][index]
+
+	// The index-th coefficient of c1 * s is sum_{j<=index} c1[index-j] * s[j] - sum_{j>index} c1[N+index-j] * s[j]
+	coeffs := c1.Coeffs[0]
+	for j := 0; j <= index; j++ {
+		ctOut.A[j] = coeffs[index-j]
+	}
+
+	for j := index + 1; j < N; j++ {
+		if c := coeffs[N+index-j]; c != 0 {
+			ctOut.A[j] = Q - c
+		}
+	}
+
+	return
+}
+
+// SwitchKeysLWE switches the key of the LWE ciphertext ctIn, encrypted under the input secret of swk,
+// to the output secret of swk, and writes the result on ctOut, which can be ctIn. The dimension of the
+// output secret can be smaller than the dimension of the input secret.
+func (eval *Evaluator) SwitchKeysLWE(ctIn *LWECiphertext, swk *LWESwitchingKey, ctOut *LWECiphertext) {
+
+	Q := swk.Q()
+
+	if ctIn.Q != Q {
+		panic(NewOperationError("SwitchKeysLWE", ErrInvalidOperand, "ciphertext is modulo %d but switching key is modulo %d", ctIn.Q, Q))
+	}
+
+	if len(ctIn.A) != len(swk.Value) {
+		panic(NewOperationError("SwitchKeysLWE", ErrInvalidOperand, "ciphertext has dimension %d but switching key has input dimension %d", len(ctIn.A), len(swk.Value)))
+	}
+
+	nOut := swk.Value[0][0].N()
+	mask := uint64(1)<<swk.LogBase - 1
+
+	// Accumulates on 128 bits the products of the digits of a[i] with the switching key and reduces once at the end:
+	// the sum of len(ctIn.A) * decomposition products of at most 2^LogBase * Q does not overflow.
+	hi := make([]uint64, nOut+1)
+	lo := make([]uint64, nOut+1)
+
+	var h, l, carry uint64
+	for i, a := range ctIn.A {
+		for j, ct := range swk.Value[i] {
+
+			d := (a >> (uint(j * swk.LogBase))) & mask
+
+			if d == 0 {
+				continue
+			}
+
+			h, l = bits.Mul64(d, ct.B)
+			lo[0], carry = bits.Add64(lo[0], l, 0)
+			hi[0] += h + carry
+
+			for k, c := range ct.A {
+				h, l = bits.Mul64(d, c)
+				lo[k+1], carry = bits.Add64(lo[k+1], l, 0)
+				hi[k+1] += h + carry
+			}
+		}
+	}
+
+	B := ctIn.B
+
+	if len(ctOut.A) != nOut {
+		ctOut.A = make([]uint64, nOut)
+	}
+
+	_, r := bits.Div64(hi[0]%Q, lo[0], Q)
+	if ctOut.B = B + r; ctOut.B >= Q {
+		ctOut.B -= Q
+	}
+
+	for k := range ctOut.A {
+		_, ctOut.A[k] = bits.Div64(hi[k+1]%Q, lo[k+1], Q)
+	}
+
+	ctOut.Q = Q
+}
+
+// LWEToRLWE returns a RLWE ciphertext at level zero in the NTT domain encrypting the plaintext of ct in
+// its constant coefficient and zero in all the others. The LWE ciphertext must be of dimension N modulo Q[0].
+// The method requires the rotation keys for the Galois elements returned by GaloisElementsForTrace(0).
+func (eval *Evaluator) LWEToRLWE(ct *LWECiphertext) (ctOut *Ciphertext) {
+	ctOut = eval.lweToRLWE("LWEToRLWE", ct)
+	eval.Trace(ctOut, 0, ctOut)
+	return
+}
+
+// PackLWE packs a batch of LWE ciphertexts into a single RLWE ciphertext at level zero in the NTT domain, the
+// plaintext of each LWE ciphertext being packed in the coefficient of the RLWE plaintext given by its key in ctIn.
+// The LWE ciphertexts must be of dimension N modulo Q[0], see SampleExtract.
+// The method requires the rotation keys for the Galois elements returned by GaloisElementsForMergeRLWE.
+func (eval *Evaluator) PackLWE(ctIn map[int]*LWECiphertext) (ctOut *Ciphertext) {
+
+	ciphertexts := make(map[int]*Ciphertext)
+	for i, ct := range ctIn {
+
+		if i < 0 || i >= eval.params.N() {
+			panic(NewOperationError("PackLWE", ErrInvalidOperand, "index %d is not in [0, %d)", i, eval.params.N()))
+		}
+
+		ciphertexts[i] = eval.lweToRLWE("PackLWE", ct)
+	}
+
+	return eval.MergeRLWE(ciphertexts)
+}
+
+// lweToRLWE returns a RLWE ciphertext at level zero in the NTT domain whose plaintext has the
+// plaintext of ct as constant coefficient.
+func (eval *Evaluator) lweToRLWE(op string, ct *LWECiphertext) (ctOut *Ciphertext) {
+
+	params := eval.params
+	ringQ := params.RingQ()
+
+	if params.RingType() != ring.Standard {
+		panic(NewOperationError(op, ErrRingType, "LWE ciphertexts can only be packed in the Standard ring"))
+	}
+
+	N := ringQ.N
+	Q := ringQ.Modulus[0]
+
+	if ct.Q != Q || len(ct.A) != N {
+		panic(NewOperationError(op, ErrInvalidOperand, "LWE ciphertext of dimension %d modulo %d is not a sample of dimension %d modulo %d", len(ct.A), ct.Q, N, Q))
+	}
+
+	ctOut = NewCiphertextNTT(params, 1, 0)
+
+	// Inverse of the sample extraction at index zero: c1 = a[0] - a[1] X^{N-1} - ... - a[N-1] X
+	ctOut.Value[0].Coeffs[0][0] = ct.B
+	c1 := ctOut.Value[1].Coeffs[0]
+	c1[0] = ct.A[0]
+	for j := 1; j < N; j++ {
+		if ct.A[j] != 0 {
+			c1[N-j] = Q - ct.A[j]
+		}
+	}
+
+	ringQ.NTTLvl(0, ctOut.Value[0], ctOut.Value[0])
+	ringQ.NTTLvl(0, ctOut.Value[1], ctOut.Value[1])
+
+	return
+}
+
+// lweDecomposition returns the number of digits in base 2^logBase of the integers modulo Q.
+func lweDecomposition(Q uint64, logBase int) int {
+
+	if logBase < 1 || logBase > 32 {
+		panic(NewOperationError("NewLWESwitchingKey", ErrInvalidOperand, "logBase=%d is not in [1, 32]", logBase))
+	}
+
+	return (bits.Len64(Q-1) + logBase - 1) / logBase
+}
+
+// modSwitch returns round(x * qOut / q) mod qOut.
+func modSwitch(x, q, qOut uint64) uint64 {
+	hi, lo := bits.Mul64(x, qOut)
+	lo, carry := bits.Add64(lo, q>>1, 0)
+	quo, _ := bits.Div64(hi+carry, lo, q)
+	if quo >= qOut {
+		quo -= qOut
+	}
+	return quo
+}
+
+// mulMod returns x * y mod q for x, y < q.
+func mulMod(x, y, q uint64) uint64 {
+	hi, lo := bits.Mul64(x, y)
+	_, r := bits.Div64(hi, lo, q)
+	return r
+}
+
+// liftMod returns x mod q for a small signed integer x.
+func liftMod(x int64, q uint64) uint64 {
+	if x < 0 {
+		if r := uint64(-x) % q; r != 0 {
+			return q - r
+		}
+		return 0
+	}
+	return uint64(x) % q
+}
@@ -2,7 +2,7 @@ package rlwe
 
 import (
 	"encoding/binary"
-	"errors"
+	"sort"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 )
@@ -25,8 +25,23 @@ func (el *Ciphertext) GetDataLen(WithMetaData bool) (dataLen int) {
 // MarshalBinary encodes a Ciphertext on a byte slice. The total size
 // in byte is 4 + 8* N * numberModuliQ * (degree + 1).
 func (el *Ciphertext) MarshalBinary() (data []byte, err error) {
+	return el.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes a Ciphertext on a byte slice, writing the coefficients of each RNS limb
+// on the bit-size of its modulus (see ring.Poly.WriteToPacked). It is decoded by UnmarshalBinary.
+func (el *Ciphertext) MarshalBinaryPacked() (data []byte, err error) {
+	return el.marshalBinary(true)
+}
+
+func (el *Ciphertext) marshalBinary(packed bool) (data []byte, err error) {
 
-	data = make([]byte, el.GetDataLen(true))
+	dataLen := 1
+	for _, p := range el.Value {
+		dataLen += ring.GetDataLen(p, true, packed)
+	}
+
+	data = make([]byte, dataLen)
 
 	data[0] = uint8(el.Degree() + 1)
 
@@ -36,7 +51,7 @@ func (el *Ciphertext) MarshalBinary() (data []byte, err error) {
 
 	for _, el := range el.Value {
 
-		if inc, err = el.WriteTo64(data[pointer:]); err != nil {
+		if inc, err = ring.WriteTo(el, data[pointer:], packed); err != nil {
 			return nil, err
 		}
 
@@ -48,11 +63,17 @@ func (el *Ciphertext) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary decodes a previously marshaled Ciphertext on the target Ciphertext.
 func (el *Ciphertext) UnmarshalBinary(data []byte) (err error) {
-	if len(data) < 10 { // cf. Ciphertext.GetDataLen()
-		return errors.New("too small bytearray")
+
+	if len(data) < 1 {
+		return malformed("data is too short")
 	}
 
-	el.Value = make([]*ring.Poly, uint8(data[0]))
+	// each element has at least a header of 7 bytes, cf. ring.Poly.GetDataLen64
+	if data[0] == 0 || len(data)-1 < 7*int(data[0]) {
+		return malformed("invalid number of elements %d", data[0])
+	}
+
+	el.Value = make([]*ring.Poly, data[0])
 
 	var pointer, inc int
 	pointer = 1
@@ -61,7 +82,7 @@ func (el *Ciphertext) UnmarshalBinary(data []byte) (err error) {
 
 		el.Value[i] = new(ring.Poly)
 
-		if inc, err = el.Value[i].DecodePoly64(data[pointer:]); err != nil {
+		if inc, err = decodePoly(el.Value[i], data[pointer:]); err != nil {
 			return err
 		}
 
@@ -69,7 +90,7 @@ func (el *Ciphertext) UnmarshalBinary(data []byte) (err error) {
 	}
 
 	if pointer != len(data) {
-		return errors.New("remaining unparsed data")
+		return malformed("remaining unparsed data")
 	}
 
 	return nil
@@ -83,8 +104,18 @@ func (sk *SecretKey) GetDataLen64(WithMetadata bool) (dataLen int) {
 
 // MarshalBinary encodes a secret key in a byte slice.
 func (sk *SecretKey) MarshalBinary() (data []byte, err error) {
-	data = make([]byte, sk.GetDataLen64(true))
-	if _, err = sk.Value.WriteTo64(data); err != nil {
+	return sk.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes a secret key in a byte slice with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (sk *SecretKey) MarshalBinaryPacked() (data []byte, err error) {
+	return sk.marshalBinary(true)
+}
+
+func (sk *SecretKey) marshalBinary(packed bool) (data []byte, err error) {
+	data = make([]byte, ring.GetDataLen(&sk.Value, true, packed))
+	if _, err = ring.WriteTo(&sk.Value, data, packed); err != nil {
 		return nil, err
 	}
 	return
@@ -92,7 +123,16 @@ func (sk *SecretKey) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary decodes a previously marshaled SecretKey in the target SecretKey.
 func (sk *SecretKey) UnmarshalBinary(data []byte) (err error) {
-	_, err = sk.Value.DecodePoly64(data)
+
+	var pointer int
+	if pointer, err = decodePoly(&sk.Value, data); err != nil {
+		return
+	}
+
+	if pointer != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
 	return
 }
 
@@ -103,14 +143,24 @@ func (pk *PublicKey) GetDataLen64(WithMetadata bool) (dataLen int) {
 
 // MarshalBinary encodes a PublicKey in a byte slice.
 func (pk *PublicKey) MarshalBinary() (data []byte, err error) {
-	data = make([]byte, pk.GetDataLen64(true))
+	return pk.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes a PublicKey in a byte slice with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (pk *PublicKey) MarshalBinaryPacked() (data []byte, err error) {
+	return pk.marshalBinary(true)
+}
+
+func (pk *PublicKey) marshalBinary(packed bool) (data []byte, err error) {
+	data = make([]byte, ring.GetDataLen(&pk.Value[0], true, packed)+ring.GetDataLen(&pk.Value[1], true, packed))
 	var inc, pt int
-	if inc, err = pk.Value[0].WriteTo64(data[pt:]); err != nil {
+	if inc, err = ring.WriteTo(&pk.Value[0], data[pt:], packed); err != nil {
 		return nil, err
 	}
 	pt += inc
 
-	if _, err = pk.Value[1].WriteTo64(data[pt:]); err != nil {
+	if _, err = ring.WriteTo(&pk.Value[1], data[pt:], packed); err != nil {
 		return nil, err
 	}
 
@@ -121,13 +171,15 @@ func (pk *PublicKey) MarshalBinary() (data []byte, err error) {
 func (pk *PublicKey) UnmarshalBinary(data []byte) (err error) {
 
 	var pt, inc int
-	if inc, err = pk.Value[0].DecodePoly64(data[pt:]); err != nil {
-		return
+	for i := range pk.Value {
+		if inc, err = decodePoly(&pk.Value[i], data[pt:]); err != nil {
+			return
+		}
+		pt += inc
 	}
-	pt += inc
 
-	if _, err = pk.Value[1].DecodePoly64(data[pt:]); err != nil {
-		return
+	if pt != len(data) {
+		return malformed("remaining unparsed data")
 	}
 
 	return
@@ -138,6 +190,12 @@ func (swk *SwitchingKey) MarshalBinary() (data []byte, err error) {
 	return swk.GadgetCiphertext.MarshalBinary()
 }
 
+// MarshalBinaryPacked encodes the target SwitchingKey on a slice of bytes with the packed encoding of the
+// polynomials. It is decoded by UnmarshalBinary.
+func (swk *SwitchingKey) MarshalBinaryPacked() (data []byte, err error) {
+	return swk.GadgetCiphertext.MarshalBinaryPacked()
+}
+
 // UnmarshalBinary decodes a slice of bytes on the target SwitchingKey.
 func (swk *SwitchingKey) UnmarshalBinary(data []byte) (err error) {
 	return swk.GadgetCiphertext.UnmarshalBinary(data)
@@ -155,10 +213,23 @@ func (rlk *RelinearizationKey) GetDataLen(WithMetadata bool) (dataLen int) {
 
 // MarshalBinary encodes an EvaluationKey key in a byte slice.
 func (rlk *RelinearizationKey) MarshalBinary() (data []byte, err error) {
+	return rlk.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes an EvaluationKey key in a byte slice with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (rlk *RelinearizationKey) MarshalBinaryPacked() (data []byte, err error) {
+	return rlk.marshalBinary(true)
+}
+
+func (rlk *RelinearizationKey) marshalBinary(packed bool) (data []byte, err error) {
 
 	var pointer int
 
-	dataLen := rlk.GetDataLen(true)
+	dataLen := 1
+	for _, evakey := range rlk.Keys {
+		dataLen += evakey.getDataLen(true, packed)
+	}
 
 	data = make([]byte, dataLen)
 
@@ -168,7 +239,7 @@ func (rlk *RelinearizationKey) MarshalBinary() (data []byte, err error) {
 
 	for _, evakey := range rlk.Keys {
 
-		if pointer, err = evakey.Encode(pointer, data); err != nil {
+		if pointer, err = evakey.encode(pointer, data, packed); err != nil {
 			return nil, err
 		}
 	}
@@ -179,13 +250,15 @@ func (rlk *RelinearizationKey) MarshalBinary() (data []byte, err error) {
 // UnmarshalBinary decodes a previously marshaled EvaluationKey in the target EvaluationKey.
 func (rlk *RelinearizationKey) UnmarshalBinary(data []byte) (err error) {
 
-	deg := int(data[0])
+	if len(data) < 1 || data[0] == 0 {
+		return malformed("relinearization key is empty")
+	}
 
-	rlk.Keys = make([]*SwitchingKey, deg)
+	rlk.Keys = make([]*SwitchingKey, data[0])
 
 	pointer := 1
 	var inc int
-	for i := 0; i < deg; i++ {
+	for i := range rlk.Keys {
 		rlk.Keys[i] = new(SwitchingKey)
 		if inc, err = rlk.Keys[i].Decode(data[pointer:]); err != nil {
 			return err
@@ -193,11 +266,18 @@ func (rlk *RelinearizationKey) UnmarshalBinary(data []byte) (err error) {
 		pointer += inc
 	}
 
+	if pointer != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
 	return nil
 }
 
 // GetDataLen returns the length in bytes of the target RotationKeys.
 func (rtks *RotationKeySet) GetDataLen(WithMetaData bool) (dataLen int) {
+	if WithMetaData {
+		dataLen += 8
+	}
 	for _, k := range rtks.Keys {
 		if WithMetaData {
 			dataLen += 8
@@ -207,19 +287,39 @@ func (rtks *RotationKeySet) GetDataLen(WithMetaData bool) (dataLen int) {
 	return
 }
 
-// MarshalBinary encodes a RotationKeys struct in a byte slice.
+// MarshalBinary encodes a RotationKeys struct in a byte slice: the number of keys followed by the pairs
+// (galois element, key) sorted by galois element.
 func (rtks *RotationKeySet) MarshalBinary() (data []byte, err error) {
+	return rtks.marshalBinary(false)
+}
 
-	data = make([]byte, rtks.GetDataLen(true))
+// MarshalBinaryPacked encodes a RotationKeys struct in a byte slice with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (rtks *RotationKeySet) MarshalBinaryPacked() (data []byte, err error) {
+	return rtks.marshalBinary(true)
+}
+
+func (rtks *RotationKeySet) marshalBinary(packed bool) (data []byte, err error) {
+
+	dataLen := 8
+	for _, key := range rtks.Keys {
+		dataLen += 8 + key.getDataLen(true, packed)
+	}
 
-	pointer := int(0)
+	data = make([]byte, dataLen)
+
+	galEls := rtks.GaloisElements()
+	sort.Slice(galEls, func(i, j int) bool { return galEls[i] < galEls[j] })
 
-	for galEL, key := range rtks.Keys {
+	binary.BigEndian.PutUint64(data, uint64(len(galEls)))
+	pointer := 8
 
-		binary.BigEndian.PutUint64(data[pointer:pointer+8], galEL)
+	for _, galEl := range galEls {
+
+		binary.BigEndian.PutUint64(data[pointer:pointer+8], galEl)
 		pointer += 8
 
-		if pointer, err = key.Encode(pointer, data); err != nil {
+		if pointer, err = rtks.Keys[galEl].encode(pointer, data, packed); err != nil {
 			return nil, err
 		}
 	}
@@ -230,13 +330,28 @@ func (rtks *RotationKeySet) MarshalBinary() (data []byte, err error) {
 // UnmarshalBinary decodes a previously marshaled RotationKeys in the target RotationKeys.
 func (rtks *RotationKeySet) UnmarshalBinary(data []byte) (err error) {
 
+	if len(data) < 8 {
+		return malformed("data is too short")
+	}
+
+	nbKeys := binary.BigEndian.Uint64(data)
+	data = data[8:]
+
 	rtks.Keys = make(map[uint64]*SwitchingKey)
 
-	for len(data) > 0 {
+	for i := uint64(0); i < nbKeys; i++ {
+
+		if len(data) < 8 {
+			return malformed("data is too short")
+		}
 
 		galEl := binary.BigEndian.Uint64(data)
 		data = data[8:]
 
+		if _, inSet := rtks.Keys[galEl]; inSet {
+			return malformed("duplicate rotation key for galois element %d", galEl)
+		}
+
 		swk := new(SwitchingKey)
 		var inc int
 		if inc, err = swk.Decode(data); err != nil {
@@ -244,7 +359,10 @@ func (rtks *RotationKeySet) UnmarshalBinary(data []byte) (err error) {
 		}
 		data = data[inc:]
 		rtks.Keys[galEl] = swk
+	}
 
+	if len(data) != 0 {
+		return malformed("%d trailing bytes", len(data))
 	}
 
 	return nil
@@ -385,7 +385,7 @@ func (p Parameters) GaloisElementForColumnRotationBy(k int) uint64 {
 // rotation automorphism
 func (p Parameters) GaloisElementForRowRotation() uint64 {
 	if p.ringType == ring.ConjugateInvariant {
-		panic("Cannot generate GaloisElementForRowRotation if ringType is ConjugateInvariant")
+		panic(NewOperationError("GaloisElementForRowRotation", ErrRingType, "the row rotation is undefined in ConjugateInvariant Ring"))
 	}
 	return p.ringQ.NthRoot - 1
 }
@@ -404,9 +404,9 @@ func (p Parameters) GaloisElementsForTrace(logN int) (galEls []uint64) {
 		case ring.Standard:
 			galEls = append(galEls, p.GaloisElementForRowRotation())
 		case ring.ConjugateInvariant:
-			panic("cannot GaloisElementsForTrace: Galois element 5^-1 is undefined in ConjugateInvariant Ring")
+			panic(NewOperationError("GaloisElementsForTrace", ErrRingType, "Galois element 5^-1 is undefined in ConjugateInvariant Ring"))
 		default:
-			panic("cannot GaloisElementsForTrace: invalid ring type")
+			panic(NewOperationError("GaloisElementsForTrace", ErrRingType, "invalid ring type"))
 		}
 	}
 
@@ -428,7 +428,7 @@ func (p Parameters) GaloisElementsForRowInnerSum() (galEls []uint64) {
 	case ring.ConjugateInvariant:
 		galEls[p.logN-1] = p.GaloisElementForColumnRotationBy(1 << (p.logN - 1))
 	default:
-		panic("cannot GaloisElementsForRowInnerSum: invalid ring type")
+		panic(NewOperationError("GaloisElementsForRowInnerSum", ErrRingType, "invalid ring type"))
 	}
 
 	return galEls
@@ -516,7 +516,7 @@ func (p Parameters) MarshalBinary() ([]byte, error) {
 
 // UnmarshalBinary decodes a []byte into a parameter set struct.
 func (p *Parameters) UnmarshalBinary(data []byte) error {
-	if len(data) < 11 {
+	if len(data) < 21 {
 		return fmt.Errorf("invalid rlwe.Parameter serialization")
 	}
 	b := utils.NewBuffer(data)
@@ -532,6 +532,10 @@ func (p *Parameters) UnmarshalBinary(data []byte) error {
 		return err
 	}
 
+	if len(data) < 21+(lenQ+lenP)<<3 {
+		return fmt.Errorf("invalid rlwe.Parameter serialization")
+	}
+
 	qi := make([]uint64, lenQ)
 	pi := make([]uint64, lenP)
 	b.ReadUint64Slice(qi)
@@ -2,6 +2,10 @@
 package ringqp
 
 import (
+	"errors"
+	"fmt"
+	"io"
+
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/utils"
 )
@@ -287,6 +291,17 @@ func (r *Ring) MulCoeffsMontgomeryAndAddLvl(levelQ, levelP int, p1, p2, p3 Poly)
 	}
 }
 
+// MulScalarLvl multiplies p1 by scalar and returns the result in p2.
+// The operation is performed at levelQ for the ringQ and levelP for the ringP.
+func (r *Ring) MulScalarLvl(levelQ, levelP int, p1 Poly, scalar uint64, p2 Poly) {
+	if r.RingQ != nil {
+		r.RingQ.MulScalarLvl(levelQ, p1.Q, scalar, p2.Q)
+	}
+	if r.RingP != nil {
+		r.RingP.MulScalarLvl(levelP, p1.P, scalar, p2.P)
+	}
+}
+
 // ReduceLvl applies the modular reduction on the coefficients of p1 and returns the result on p2.
 // The operation is performed at levelQ for the ringQ and levelP for the ringP.
 func (r *Ring) ReduceLvl(levelQ, levelP int, p1, p2 Poly) {
@@ -362,6 +377,27 @@ func (r *Ring) ExtendBasisSmallNormAndCenter(polyInQ *ring.Poly, levelP int, pol
 	}
 }
 
+// Validate checks that p is a well-formed polynomial of the ring, see ring.Ring.Validate.
+// The polynomials modulo Q and P must be present if and only if the ring has the respective moduli.
+func (r *Ring) Validate(p Poly) (err error) {
+
+	if (p.Q != nil) != (r.RingQ != nil) || (p.P != nil) != (r.RingP != nil) {
+		return fmt.Errorf("invalid ringqp.Poly: moduli do not match the ring")
+	}
+
+	if p.Q != nil {
+		if err = r.RingQ.Validate(p.Q); err != nil {
+			return
+		}
+	}
+
+	if p.P != nil {
+		return r.RingP.Validate(p.P)
+	}
+
+	return
+}
+
 // Copy copies the input Poly on the target Poly.
 func (p *Poly) Copy(polFrom Poly) {
 	if polFrom.Q != nil {
@@ -420,11 +456,68 @@ func (p *Poly) WriteTo64(data []byte) (pt int, err error) {
 	return
 }
 
+// GetDataLenPacked returns the length in byte of the target Poly when written with WriteToPacked.
+func (p *Poly) GetDataLenPacked(WithMetadata bool) (dataLen int) {
+	if WithMetadata {
+		dataLen = 2
+	}
+	if p.Q != nil {
+		dataLen += p.Q.GetDataLenPacked(WithMetadata)
+	}
+	if p.P != nil {
+		dataLen += p.P.GetDataLenPacked(WithMetadata)
+	}
+
+	return
+}
+
+// WriteToPacked writes a Poly on the input data.
+// Encodes the coefficients of each RNS limb on the bit-size of the modulus, see ring.Poly.WriteToPacked.
+func (p *Poly) WriteToPacked(data []byte) (pt int, err error) {
+	var inc int
+
+	data[0], data[1] = 0, 0
+
+	if p.Q != nil {
+		data[0] = 1
+	}
+
+	if p.P != nil {
+		data[1] = 1
+	}
+
+	pt = 2
+
+	if data[0] == 1 {
+		if inc, err = p.Q.WriteToPacked(data[pt:]); err != nil {
+			return
+		}
+		pt += inc
+	}
+
+	if data[1] == 1 {
+		if inc, err = p.P.WriteToPacked(data[pt:]); err != nil {
+			return
+		}
+		pt += inc
+	}
+
+	return
+}
+
 // DecodePoly64 decodes the input bytes on the target Poly.
 // Writes on pre-allocated coefficients.
-// Assumes that each coefficient is encoded on 8 bytes.
+// Assumes that each coefficient is encoded on 8 bytes, unless the polynomials were written with WriteToPacked.
 func (p *Poly) DecodePoly64(data []byte) (pt int, err error) {
 
+	if len(data) < 2 {
+		return 0, errInvalidEncoding
+	}
+
+	if err = checkFlags(data[:2]); err != nil {
+		return 0, err
+	}
+
 	var inc int
 	pt = 2
 
@@ -455,6 +548,88 @@ func (p *Poly) DecodePoly64(data []byte) (pt int, err error) {
 	return
 }
 
+var errInvalidEncoding = errors.New("invalid ringqp.Poly encoding: data is too short")
+
+// checkFlags checks that the two flags announcing the polynomials modulo Q and P are either 0 or 1.
+func checkFlags(flags []byte) error {
+	if flags[0] > 1 || flags[1] > 1 {
+		return fmt.Errorf("invalid ringqp.Poly encoding: invalid flags %v", flags)
+	}
+	return nil
+}
+
+// WriteTo writes the Poly on w with the layout of WriteTo64, see ring.Poly.WriteTo.
+// It returns the number of written bytes and implements the io.WriterTo interface.
+func (p *Poly) WriteTo(w io.Writer) (n int64, err error) {
+
+	flags := []byte{0, 0}
+	if p.Q != nil {
+		flags[0] = 1
+	}
+	if p.P != nil {
+		flags[1] = 1
+	}
+
+	var inc int
+	if inc, err = w.Write(flags); err != nil {
+		return n + int64(inc), err
+	}
+	n += int64(inc)
+
+	var inc64 int64
+	for _, pol := range []*ring.Poly{p.Q, p.P} {
+		if pol != nil {
+			if inc64, err = pol.WriteTo(w); err != nil {
+				return n + inc64, err
+			}
+			n += inc64
+		}
+	}
+
+	return
+}
+
+// ReadFrom reads on the target Poly exactly one Poly written with WriteTo, WriteTo64 or WriteToPacked.
+// It returns the number of read bytes and implements the io.ReaderFrom interface.
+func (p *Poly) ReadFrom(r io.Reader) (n int64, err error) {
+
+	flags := []byte{0, 0}
+
+	var inc int
+	if inc, err = io.ReadFull(r, flags); err != nil {
+		return n + int64(inc), err
+	}
+	n += int64(inc)
+
+	if err = checkFlags(flags); err != nil {
+		return n, err
+	}
+
+	var inc64 int64
+
+	if flags[0] == 1 {
+		if p.Q == nil {
+			p.Q = new(ring.Poly)
+		}
+		if inc64, err = p.Q.ReadFrom(r); err != nil {
+			return n + inc64, err
+		}
+		n += inc64
+	}
+
+	if flags[1] == 1 {
+		if p.P == nil {
+			p.P = new(ring.Poly)
+		}
+		if inc64, err = p.P.ReadFrom(r); err != nil {
+			return n + inc64, err
+		}
+		n += inc64
+	}
+
+	return
+}
+
 // UniformSampler is a type for sampling polynomials in Ring.
 type UniformSampler struct {
 	samplerQ, samplerP *ring.UniformSampler
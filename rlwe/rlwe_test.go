@@ -1,13 +1,21 @@
 package rlwe
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math"
 	"math/big"
 	"math/bits"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -66,7 +74,17 @@ func TestRLWE(t *testing.T) {
 			testKeySwitchDimension,
 			testMergeRLWE,
 			testExpandRLWE,
+			testSeeded,
 			testMarshaller,
+			testEnvelope,
+			testLazyRotationKeySet,
+			testLevelKeys,
+			testChecked,
+			testValidate,
+			testSecurity,
+			testKeyGeneratorWithPRNG,
+			testSealedKey,
+			testLWE,
 		} {
 			testSet(kgen, t)
 			runtime.GC()
@@ -662,6 +680,130 @@ func testExpandRLWE(kgen KeyGenerator, t *testing.T) {
 	})
 }
 
+func testSeeded(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	sk := kgen.GenSecretKey()
+	ringQ := params.RingQ()
+
+	for _, isNTT := range []bool{true, false} {
+
+		t.Run(testString(params, fmt.Sprintf("Seeded/Encrypt/NTT=%t", isNTT)), func(t *testing.T) {
+
+			encryptor := NewSeededEncryptor(params, sk)
+			decryptor := NewDecryptor(params, sk)
+
+			plaintext := NewPlaintext(params, params.MaxLevel())
+			plaintext.Value.IsNTT = isNTT
+
+			seeded := NewSeededCiphertext(params, plaintext.Level())
+			seeded.Value.IsNTT = isNTT
+			encryptor.ShallowCopy().Encrypt(plaintext, seeded)
+
+			data, err := seeded.MarshalBinary()
+			require.NoError(t, err)
+
+			seededTest := new(SeededCiphertext)
+			require.NoError(t, seededTest.UnmarshalBinary(data))
+
+			ciphertext := seededTest.Expand(params)
+			require.Equal(t, plaintext.Level(), ciphertext.Level())
+			require.Equal(t, isNTT, ciphertext.Value[1].IsNTT)
+			require.Less(t, len(data), ciphertext.GetDataLen(true)/2+SeedSize+64)
+
+			decryptor.Decrypt(ciphertext, plaintext)
+			if isNTT {
+				ringQ.InvNTTLvl(plaintext.Level(), plaintext.Value, plaintext.Value)
+			}
+			require.GreaterOrEqual(t, 5+params.LogN(), log2OfInnerSum(plaintext.Level(), ringQ, plaintext.Value))
+		})
+	}
+
+	// Checks that Dec(KS(Enc(ct, sk), skOut), skOut) has a small norm
+	keySwitch := func(t *testing.T, swk *SwitchingKey, skIn, skOut *SecretKey) {
+
+		eval := NewEvaluator(params, nil)
+
+		plaintext := NewPlaintext(params, params.MaxLevel())
+		plaintext.Value.IsNTT = true
+		ciphertext := NewCiphertextNTT(params, 1, plaintext.Level())
+		NewEncryptor(params, skIn).Encrypt(plaintext, ciphertext)
+
+		eval.GadgetProduct(ciphertext.Value[1].Level(), ciphertext.Value[1], swk.GadgetCiphertext, eval.BuffQP[1].Q, eval.BuffQP[2].Q)
+		ringQ.Add(ciphertext.Value[0], eval.BuffQP[1].Q, ciphertext.Value[0])
+		ring.CopyValues(eval.BuffQP[2].Q, ciphertext.Value[1])
+		ringQ.MulCoeffsMontgomeryAndAddLvl(ciphertext.Level(), ciphertext.Value[1], skOut.Value.Q, ciphertext.Value[0])
+		ringQ.InvNTTLvl(ciphertext.Level(), ciphertext.Value[0], ciphertext.Value[0])
+		require.GreaterOrEqual(t, 11+params.LogN(), log2OfInnerSum(ciphertext.Level(), ringQ, ciphertext.Value[0]))
+	}
+
+	t.Run(testString(params, "Seeded/SwitchingKey"), func(t *testing.T) {
+
+		skOut := kgen.GenSecretKey()
+
+		seeded := kgen.GenSeededSwitchingKey(sk, skOut)
+
+		data, err := seeded.MarshalBinary()
+		require.NoError(t, err)
+
+		seededTest := new(SeededSwitchingKey)
+		require.NoError(t, seededTest.UnmarshalBinary(data))
+
+		swk := seededTest.Expand(params)
+		require.Less(t, len(data), swk.GetDataLen(true)/2+SeedSize+64)
+
+		keySwitch(t, swk, sk, skOut)
+	})
+
+	t.Run(testString(params, "Seeded/RelinearizationKey"), func(t *testing.T) {
+
+		seeded := kgen.GenSeededRelinearizationKey(sk, 2)
+
+		data, err := seeded.MarshalBinary()
+		require.NoError(t, err)
+
+		seededTest := new(SeededRelinearizationKey)
+		require.NoError(t, seededTest.UnmarshalBinary(data))
+
+		rlk := seededTest.Expand(params)
+		require.Len(t, rlk.Keys, 2)
+
+		// The key of degree i switches from sk^(i+2) to sk
+		skIn := sk.CopyNew()
+		for i := range rlk.Keys {
+			params.RingQP().MulCoeffsMontgomeryLvl(params.QCount()-1, params.PCount()-1, skIn.Value, sk.Value, skIn.Value)
+			keySwitch(t, rlk.Keys[i], skIn, sk)
+		}
+	})
+
+	t.Run(testString(params, "Seeded/RotationKeySet"), func(t *testing.T) {
+
+		galEls := []uint64{params.GaloisElementForColumnRotationBy(1), params.GaloisElementForRowRotation()}
+
+		seeded := kgen.GenSeededRotationKeys(galEls, sk)
+
+		data, err := seeded.MarshalBinary()
+		require.NoError(t, err)
+
+		seededTest := new(SeededRotationKeySet)
+		require.NoError(t, seededTest.UnmarshalBinary(data))
+
+		rtks := seededTest.Expand(params)
+		require.Len(t, rtks.Keys, len(galEls))
+
+		// The key of galEl switches from sk to sk(X^(galEl^-1))
+		for _, galEl := range galEls {
+			skOut := NewSecretKey(params)
+			index := ringQ.PermuteNTTIndex(params.InverseGaloisElement(galEl))
+			ringQ.PermuteNTTWithIndexLvl(params.QCount()-1, sk.Value.Q, index, skOut.Value.Q)
+			swk, ok := rtks.GetRotationKey(galEl)
+			require.True(t, ok)
+			keySwitch(t, swk, sk, skOut)
+		}
+	})
+}
+
 func testMarshaller(kgen KeyGenerator, t *testing.T) {
 
 	params := kgen.(*keyGenerator).params
@@ -790,4 +932,929 @@ func testMarshaller(kgen KeyGenerator, t *testing.T) {
 
 		rotationKey.Equals(resRotationKey)
 	})
+
+	t.Run(testString(params, "Marshaller/Packed"), func(t *testing.T) {
+
+		type marshaler interface {
+			MarshalBinary() ([]byte, error)
+			MarshalBinaryPacked() ([]byte, error)
+			UnmarshalBinary([]byte) error
+		}
+
+		// Checks that the packed encoding is smaller than the 64-bit one and
+		// decodes to the same object with UnmarshalBinary.
+		checkPacked := func(t *testing.T, want, have marshaler) {
+			data, err := want.MarshalBinary()
+			require.NoError(t, err)
+			dataPacked, err := want.MarshalBinaryPacked()
+			require.NoError(t, err)
+			require.Less(t, len(dataPacked), len(data))
+			require.NoError(t, have.UnmarshalBinary(dataPacked))
+			dataHave, err := have.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, data, dataHave)
+		}
+
+		prng, _ := utils.NewPRNG()
+
+		checkPacked(t, NewCiphertextRandom(prng, params, 1, params.MaxLevel()), new(Ciphertext))
+		checkPacked(t, sk, new(SecretKey))
+		checkPacked(t, pk, new(PublicKey))
+		checkPacked(t, kgen.GenRelinearizationKey(sk, 2), new(RelinearizationKey))
+		checkPacked(t, kgen.GenSwitchingKey(sk, kgen.GenSecretKey()), new(SwitchingKey))
+		checkPacked(t, kgen.GenSeededSwitchingKey(sk, kgen.GenSecretKey()), new(SeededSwitchingKey))
+
+		galEl := params.GaloisElementForColumnRotationBy(1)
+		checkPacked(t, &RotationKeySet{Keys: kgen.GenRotationKeys([]uint64{galEl}, sk).Keys}, new(RotationKeySet))
+	})
+
+	t.Run(testString(params, "Marshaller/Stream"), func(t *testing.T) {
+
+		type streamer interface {
+			MarshalBinary() ([]byte, error)
+			io.WriterTo
+			io.ReaderFrom
+		}
+
+		// Checks that WriteTo writes the bytes of MarshalBinary and that ReadFrom
+		// reads exactly one object, leaving the trailing data unread.
+		checkStream := func(t *testing.T, want, have streamer) {
+			data, err := want.MarshalBinary()
+			require.NoError(t, err)
+
+			buf := new(bytes.Buffer)
+			n, err := want.WriteTo(buf)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(data)), n)
+			require.Equal(t, data, buf.Bytes())
+
+			buf.WriteByte(0xFF)
+			r := bufio.NewReader(buf)
+			n, err = have.ReadFrom(r)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(data)), n)
+
+			dataHave, err := have.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, data, dataHave)
+
+			b, err := r.ReadByte()
+			require.NoError(t, err)
+			require.Equal(t, byte(0xFF), b)
+		}
+
+		prng, _ := utils.NewPRNG()
+
+		checkStream(t, NewCiphertextRandom(prng, params, 2, params.MaxLevel()), new(Ciphertext))
+		checkStream(t, sk, new(SecretKey))
+		checkStream(t, pk, new(PublicKey))
+		checkStream(t, kgen.GenRelinearizationKey(sk, 2), new(RelinearizationKey))
+		checkStream(t, kgen.GenSwitchingKey(sk, kgen.GenSecretKey()), new(SwitchingKey))
+
+		// RotationKeySet.ReadFrom reads the number of keys written in the header
+		galEls := []uint64{params.GaloisElementForColumnRotationBy(1), params.GaloisElementForRowRotation()}
+		rtks := kgen.GenRotationKeys(galEls, sk)
+
+		buf := new(bytes.Buffer)
+		n, err := rtks.WriteTo(buf)
+		require.NoError(t, err)
+		require.Equal(t, int64(rtks.GetDataLen(true)), n)
+
+		rtksTest := new(RotationKeySet)
+		n, err = rtksTest.ReadFrom(bufio.NewReader(buf))
+		require.NoError(t, err)
+		require.Equal(t, int64(rtks.GetDataLen(true)), n)
+		require.Len(t, rtksTest.Keys, len(galEls))
+		for _, galEl := range galEls {
+			dataWant, err := rtks.Keys[galEl].MarshalBinary()
+			require.NoError(t, err)
+			dataHave, err := rtksTest.Keys[galEl].MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, dataWant, dataHave)
+		}
+
+		// the keys are written sorted by galois element, so that the encoding is deterministic
+		data, err := rtks.MarshalBinary()
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			buf.Reset()
+			_, err = rtks.WriteTo(buf)
+			require.NoError(t, err)
+			require.Equal(t, data, buf.Bytes())
+		}
+
+		_, err = new(RotationKeySet).ReadFrom(bytes.NewReader(data[:len(data)-1]))
+		require.Equal(t, io.ErrUnexpectedEOF, err)
+
+		// a stream cut at a key boundary is not read as a smaller set
+		_, err = new(RotationKeySet).ReadFrom(bytes.NewReader(data[:len(data)-rtks.Keys[galEls[0]].GetDataLen(true)-8]))
+		require.Equal(t, io.ErrUnexpectedEOF, err)
+		require.True(t, errors.Is(new(RotationKeySet).UnmarshalBinary(data[:len(data)-rtks.Keys[galEls[0]].GetDataLen(true)-8]), ErrMalformed))
+
+		// the data following the keys is left unread
+		buf = bytes.NewBuffer(append(append([]byte{}, data...), 0xFF))
+		n, err = new(RotationKeySet).ReadFrom(buf)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(data)), n)
+		require.Equal(t, []byte{0xFF}, buf.Bytes())
+	})
+}
+
+func testEnvelope(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	prng, _ := utils.NewPRNG()
+	ct := NewCiphertextRandom(prng, params, 1, params.MaxLevel())
+	fp := params.Fingerprint()
+
+	t.Run(testString(params, "Envelope/Fingerprint"), func(t *testing.T) {
+		paramsOther, err := NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: params.Q(), P: params.P(), H: params.HammingWeight() + 1, Sigma: params.Sigma()})
+		require.NoError(t, err)
+		require.Equal(t, fp, params.Fingerprint())
+		require.NotEqual(t, fp, paramsOther.Fingerprint())
+	})
+
+	t.Run(testString(params, "Envelope/Seal"), func(t *testing.T) {
+		data, err := Seal(KindCiphertext, fp, ct)
+		require.NoError(t, err)
+		require.True(t, IsEnveloped(data))
+
+		ctTest := new(Ciphertext)
+		require.NoError(t, Open(data, KindCiphertext, fp, ctTest))
+		require.True(t, ct.Value[0].Equals(ctTest.Value[0]) && ct.Value[1].Equals(ctTest.Value[1]))
+
+		require.True(t, errors.Is(Open(data, KindPublicKey, fp, new(PublicKey)), ErrEnvelopeKind))
+		require.True(t, errors.Is(Open(data, KindCiphertext, Fingerprint{}, ctTest), ErrEnvelopeParameters))
+		require.True(t, errors.Is(Open(data[:len(data)-1], KindCiphertext, fp, ctTest), ErrEnvelopeCorrupted))
+		require.True(t, errors.Is(Open(data[:envelopeHeaderLen-1], KindCiphertext, fp, ctTest), ErrEnvelopeCorrupted))
+
+		dataNext := append([]byte{}, data...)
+		dataNext[len(EnvelopeMagic)] = EnvelopeVersion + 1
+		require.True(t, errors.Is(Open(dataNext, KindCiphertext, fp, ctTest), ErrEnvelopeVersion))
+	})
+
+	t.Run(testString(params, "Envelope/Legacy"), func(t *testing.T) {
+		raw, err := ct.MarshalBinary()
+		require.NoError(t, err)
+		require.False(t, IsEnveloped(raw))
+
+		ctTest := new(Ciphertext)
+		require.True(t, errors.Is(Open(raw, KindCiphertext, fp, ctTest), ErrNotEnveloped))
+		require.NoError(t, OpenLegacy(raw, KindCiphertext, fp, ctTest))
+		require.True(t, ct.Value[0].Equals(ctTest.Value[0]) && ct.Value[1].Equals(ctTest.Value[1]))
+
+		data, err := Migrate(raw, KindCiphertext, fp, new(Ciphertext))
+		require.NoError(t, err)
+		sealed, err := Seal(KindCiphertext, fp, ct)
+		require.NoError(t, err)
+		require.Equal(t, sealed, data)
+
+		data, err = Migrate(sealed, KindCiphertext, fp, new(Ciphertext))
+		require.NoError(t, err)
+		require.Equal(t, sealed, data)
+	})
+}
+
+func testLazyRotationKeySet(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	sk := kgen.GenSecretKey()
+
+	galEls := []uint64{params.GaloisElementForColumnRotationBy(1), params.GaloisElementForColumnRotationBy(2), params.GaloisElementForRowRotation()}
+	rtks := kgen.GenRotationKeys(galEls, sk)
+
+	data, err := rtks.MarshalBinary()
+	require.NoError(t, err)
+
+	t.Run(testString(params, "LazyRotationKeySet/Cache"), func(t *testing.T) {
+
+		lazy, err := NewLazyRotationKeySet(bytes.NewReader(data), int64(len(data)), 2)
+		require.NoError(t, err)
+		require.ElementsMatch(t, galEls, lazy.GaloisElements())
+
+		_, inSet := lazy.GetRotationKey(params.GaloisElementForColumnRotationBy(3))
+		require.False(t, inSet)
+
+		for _, galEl := range append(galEls, galEls[0]) {
+			rtk, inSet := lazy.GetRotationKey(galEl)
+			require.True(t, inSet)
+			require.True(t, rtks.Keys[galEl].Equals(rtk))
+
+			// the cached key is returned until it is evicted
+			rtkCached, _ := lazy.GetRotationKey(galEl)
+			require.True(t, rtk == rtkCached)
+		}
+
+		// galEls[1] is the least recently used key
+		require.Equal(t, 2, lazy.lru.Len())
+		_, cached := lazy.cache[galEls[1]]
+		require.False(t, cached)
+		_, cached = lazy.cache[galEls[0]]
+		require.True(t, cached)
+
+		_, err = NewLazyRotationKeySet(bytes.NewReader(data), int64(len(data)-1), 2)
+		require.Error(t, err)
+
+		// a LazyRotationKeySet is written with the layout of the RotationKeySet it was read from
+		buf := new(bytes.Buffer)
+		_, err = WriteRotationKeys(buf, lazy)
+		require.NoError(t, err)
+		require.Equal(t, data, buf.Bytes())
+	})
+
+	t.Run(testString(params, "LazyRotationKeySet/Errors"), func(t *testing.T) {
+
+		dir, err := ioutil.TempDir("", "rtks")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "rtks")
+		require.NoError(t, ioutil.WriteFile(path, data, 0600))
+
+		lazy, err := OpenLazyRotationKeySet(path, 1)
+		require.NoError(t, err)
+		eval := NewEvaluator(params, &EvaluationKey{Rtks: lazy})
+
+		// the keys cannot be read once the file is closed
+		require.NoError(t, lazy.Close())
+
+		_, inSet, err := lazy.LoadRotationKey(galEls[0])
+		require.True(t, inSet)
+		require.Error(t, err)
+		require.Panics(t, func() { lazy.GetRotationKey(galEls[0]) })
+
+		err = eval.CheckRotationKeys("Automorphism", params.MaxLevel(), galEls[0])
+		require.True(t, errors.Is(err, ErrMissingRotationKey))
+	})
+
+	t.Run(testString(params, "LazyRotationKeySet/Nil"), func(t *testing.T) {
+
+		// nil pointers stored in the interface are not rotation keys
+		for _, rtks := range []RotationKeyProvider{nil, (*RotationKeySet)(nil), (*LazyRotationKeySet)(nil)} {
+			evk := &EvaluationKey{Rtks: rtks}
+			require.False(t, evk.HasRotationKeys())
+
+			eval := NewEvaluator(params, evk)
+			require.Nil(t, eval.Rtks)
+			require.Nil(t, eval.WithKey(evk).Rtks)
+
+			err := eval.CheckRotationKeys("Automorphism", params.MaxLevel(), galEls[0])
+			require.True(t, errors.Is(err, ErrMissingRotationKey))
+		}
+
+		require.True(t, (&EvaluationKey{Rtks: rtks}).HasRotationKeys())
+	})
+
+	t.Run(testString(params, "LazyRotationKeySet/Evaluator"), func(t *testing.T) {
+
+		dataPacked, err := rtks.MarshalBinaryPacked()
+		require.NoError(t, err)
+
+		dir, err := ioutil.TempDir("", "rtks")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "rtks")
+		require.NoError(t, ioutil.WriteFile(path, dataPacked, 0600))
+
+		lazy, err := OpenLazyRotationKeySet(path, 1)
+		require.NoError(t, err)
+		defer lazy.Close()
+
+		eval := NewEvaluator(params, &EvaluationKey{Rtks: rtks})
+		evalLazy := NewEvaluator(params, &EvaluationKey{Rtks: lazy})
+
+		prng, _ := utils.NewPRNG()
+		ct := NewCiphertextRandom(prng, params, 1, params.MaxLevel())
+		ct.Value[0].IsNTT, ct.Value[1].IsNTT = true, true
+
+		for _, galEl := range galEls {
+			ctWant := NewCiphertextNTT(params, 1, params.MaxLevel())
+			ctHave := NewCiphertextNTT(params, 1, params.MaxLevel())
+			eval.Automorphism(ct, galEl, ctWant)
+			evalLazy.ShallowCopy().Automorphism(ct, galEl, ctHave)
+			require.True(t, ctWant.Value[0].Equals(ctHave.Value[0]) && ctWant.Value[1].Equals(ctHave.Value[1]))
+		}
+	})
+}
+
+func testLevelKeys(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	ringQ := params.RingQ()
+
+	levelQ := params.MaxLevel() / 2
+	levelP := params.PCount() - 1
+
+	sk := kgen.GenSecretKey()
+	encryptor := NewEncryptor(params, sk)
+
+	t.Run(testString(params, "LevelKeys/Automorphism"), func(t *testing.T) {
+
+		galEl := params.GaloisElementForColumnRotationBy(1)
+
+		rtks := kgen.GenRotationKeysLvl([]uint64{galEl}, sk, levelQ, levelP)
+		rtk := rtks.Keys[galEl]
+		require.Equal(t, levelQ, rtk.LevelQ())
+		require.Equal(t, levelP, rtk.LevelP())
+
+		if levelQ < params.MaxLevel() {
+			require.Less(t, rtks.GetDataLen(true), kgen.GenRotationKeys([]uint64{galEl}, sk).GetDataLen(true))
+		}
+
+		eval := NewEvaluator(params, &EvaluationKey{Rtks: rtks})
+
+		// Test that Dec(Automorphism(Enc(0, sk)), sk) has a small norm
+		plaintext := NewPlaintext(params, levelQ)
+		plaintext.Value.IsNTT = true
+		ciphertext := NewCiphertextNTT(params, 1, levelQ)
+		encryptor.Encrypt(plaintext, ciphertext)
+
+		eval.Automorphism(ciphertext, galEl, ciphertext)
+		require.Equal(t, levelQ, ciphertext.Level())
+
+		ringQ.MulCoeffsMontgomeryAndAddLvl(levelQ, ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+		ringQ.InvNTTLvl(levelQ, ciphertext.Value[0], ciphertext.Value[0])
+		require.GreaterOrEqual(t, 11+params.LogN(), log2OfInnerSum(levelQ, ringQ, ciphertext.Value[0]))
+
+		if levelQ < params.MaxLevel() {
+			ciphertext = NewCiphertextNTT(params, 1, levelQ+1)
+			require.Panics(t, func() { eval.Automorphism(ciphertext, galEl, ciphertext) })
+		}
+	})
+
+	t.Run(testString(params, "LevelKeys/Relinearize"), func(t *testing.T) {
+
+		rlk := kgen.GenRelinearizationKeyLvl(sk, 1, levelQ, levelP)
+		require.Equal(t, levelQ, rlk.Keys[0].LevelQ())
+		require.Equal(t, levelP, rlk.Keys[0].LevelP())
+
+		eval := NewEvaluator(params, &EvaluationKey{Rlk: rlk})
+
+		// Dec((c0 - c2*s^2, c1, c2), sk) = Dec((c0, c1), sk) = 0 + e
+		plaintext := NewPlaintext(params, levelQ)
+		plaintext.Value.IsNTT = true
+		ciphertext := NewCiphertextNTT(params, 2, levelQ)
+		encryptor.Encrypt(plaintext, &Ciphertext{Value: ciphertext.Value[:2]})
+
+		prng, _ := utils.NewPRNG()
+		ring.NewUniformSampler(prng, ringQ).ReadLvl(levelQ, ciphertext.Value[2])
+
+		sk2 := ringQ.NewPolyLvl(levelQ)
+		ringQ.MulCoeffsMontgomeryLvl(levelQ, sk.Value.Q, sk.Value.Q, sk2)
+		ringQ.MulCoeffsMontgomeryAndSubLvl(levelQ, ciphertext.Value[2], sk2, ciphertext.Value[0])
+
+		eval.Relinearize(ciphertext, ciphertext)
+		require.Equal(t, 1, ciphertext.Degree())
+
+		ringQ.MulCoeffsMontgomeryAndAddLvl(levelQ, ciphertext.Value[1], sk.Value.Q, ciphertext.Value[0])
+		ringQ.InvNTTLvl(levelQ, ciphertext.Value[0], ciphertext.Value[0])
+		require.GreaterOrEqual(t, 11+params.LogN(), log2OfInnerSum(levelQ, ringQ, ciphertext.Value[0]))
+	})
+
+	t.Run(testString(params, "LevelKeys/InvalidLevels"), func(t *testing.T) {
+		require.Panics(t, func() {
+			kgen.GenRotationKeysLvl([]uint64{params.GaloisElementForRowRotation()}, sk, params.MaxLevel()+1, levelP)
+		})
+		require.Panics(t, func() { kgen.GenRelinearizationKeyLvl(sk, 1, levelQ, params.PCount()) })
+	})
+}
+
+func testChecked(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	sk := kgen.GenSecretKey()
+	encryptor := NewCheckedEncryptor(params, NewEncryptor(params, sk))
+	decryptor := NewCheckedDecryptor(params, NewDecryptor(params, sk))
+
+	galEl := params.GaloisElementForColumnRotationBy(1)
+
+	eval := NewCheckedEvaluator(NewEvaluator(params, &EvaluationKey{}))
+
+	plaintext := NewPlaintext(params, params.MaxLevel())
+	plaintext.Value.IsNTT = true
+	ciphertext := NewCiphertextNTT(params, 1, params.MaxLevel())
+	require.NoError(t, encryptor.Encrypt(plaintext, ciphertext))
+
+	t.Run(testString(params, "Checked/MissingRotationKey"), func(t *testing.T) {
+		var err error
+		require.NotPanics(t, func() { err = eval.Automorphism(ciphertext, galEl, ciphertext) })
+		require.True(t, errors.Is(err, ErrMissingRotationKey))
+
+		var opErr *OperationError
+		require.True(t, errors.As(err, &opErr))
+		require.Equal(t, "Automorphism", opErr.Op)
+
+		require.True(t, errors.Is(eval.Trace(ciphertext, 0, ciphertext), ErrMissingRotationKey))
+	})
+
+	t.Run(testString(params, "Checked/MissingRelinearizationKey"), func(t *testing.T) {
+		ct2 := NewCiphertextNTT(params, 2, params.MaxLevel())
+		require.True(t, errors.Is(eval.Relinearize(ct2, ciphertext), ErrMissingRelinearizationKey))
+	})
+
+	t.Run(testString(params, "Checked/Recover"), func(t *testing.T) {
+		recovered := func(f func()) (err error) {
+			defer Recover("Test", &err)
+			f()
+			return
+		}
+		require.True(t, errors.Is(recovered(func() { panic(NewOperationError("Test", ErrLevel, "")) }), ErrLevel))
+		require.True(t, errors.Is(recovered(func() { panic(fmt.Errorf("wrapped: %w", ErrDegree)) }), ErrDegree))
+		require.True(t, errors.Is(recovered(func() { panic("invalid input") }), ErrInvalidOperand))
+
+		// Runtime errors are not converted
+		var ct *Ciphertext
+		require.Panics(t, func() { _ = recovered(func() { _ = ct.Value }) })
+	})
+
+	t.Run(testString(params, "Checked/InvalidOperands"), func(t *testing.T) {
+		require.True(t, errors.Is(eval.Automorphism(nil, galEl, ciphertext), ErrInvalidOperand))
+		require.True(t, errors.Is(eval.Automorphism(NewCiphertextNTT(params, 2, params.MaxLevel()), galEl, ciphertext), ErrDegree))
+		require.True(t, errors.Is(eval.Trace(ciphertext, params.LogN(), ciphertext), ErrInvalidOperand))
+		require.True(t, errors.Is(encryptor.Encrypt(plaintext, nil), ErrInvalidOperand))
+		require.True(t, errors.Is(decryptor.Decrypt(ciphertext, nil), ErrInvalidOperand))
+
+		ctBadLevels := NewCiphertextNTT(params, 1, params.MaxLevel())
+		ctBadLevels.Value[1] = ring.NewPoly(params.N(), params.MaxLevel()+1)
+		require.True(t, errors.Is(decryptor.Decrypt(ctBadLevels, plaintext), ErrLevel))
+
+		ctTruncated := NewCiphertextNTT(params, 1, params.MaxLevel())
+		ctTruncated.Value[1].Coeffs[params.MaxLevel()] = ctTruncated.Value[1].Coeffs[params.MaxLevel()][:10]
+		require.True(t, errors.Is(CheckCiphertext(params, "Test", ctTruncated, 1, 1), ErrInvalidOperand))
+		require.True(t, errors.Is(decryptor.Decrypt(ctTruncated, plaintext), ErrInvalidOperand))
+	})
+
+	t.Run(testString(params, "Checked/Automorphism"), func(t *testing.T) {
+		evalRot := eval.WithKey(&EvaluationKey{Rtks: kgen.GenRotationKeys([]uint64{galEl}, sk)})
+		ctOut := NewCiphertextNTT(params, 1, params.MaxLevel())
+		require.NoError(t, evalRot.Automorphism(ciphertext, galEl, ctOut))
+
+		ptOut := NewPlaintext(params, params.MaxLevel())
+		require.NoError(t, decryptor.Decrypt(ctOut, ptOut))
+		require.GreaterOrEqual(t, 11+params.LogN(), log2OfInnerSum(ptOut.Level(), params.RingQ(), ptOut.Value))
+	})
+}
+
+func testValidate(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	sk, pk := kgen.GenKeyPair()
+	galEl := params.GaloisElementForColumnRotationBy(1)
+
+	prng, _ := utils.NewPRNG()
+
+	ciphertext := NewCiphertextRandom(prng, params, 1, params.MaxLevel())
+	seeded := NewSeededCiphertext(params, params.MaxLevel())
+	NewSeededEncryptor(params, sk).Encrypt(NewPlaintext(params, params.MaxLevel()), seeded)
+
+	objects := map[string]interface {
+		MarshalBinary() ([]byte, error)
+		Validator
+	}{
+		"Ciphertext":               ciphertext,
+		"SecretKey":                sk,
+		"PublicKey":                pk,
+		"SwitchingKey":             kgen.GenSwitchingKey(sk, kgen.GenSecretKey()),
+		"RelinearizationKey":       kgen.GenRelinearizationKey(sk, 2),
+		"RotationKeySet":           kgen.GenRotationKeys([]uint64{galEl}, sk),
+		"RotationKeySet/Lvl":       kgen.GenRotationKeysLvl([]uint64{galEl}, sk, params.MaxLevel()/2, params.PCount()-1),
+		"SeededCiphertext":         seeded,
+		"SeededSwitchingKey":       kgen.GenSeededSwitchingKey(sk, kgen.GenSecretKey()),
+		"SeededRelinearizationKey": kgen.GenSeededRelinearizationKey(sk, 1),
+		"SeededRotationKeySet":     kgen.GenSeededRotationKeys([]uint64{galEl}, sk),
+	}
+
+	type unmarshaler interface {
+		UnmarshalBinary([]byte) error
+		Validator
+	}
+
+	newObject := map[string]func() unmarshaler{
+		"Ciphertext":               func() unmarshaler { return new(Ciphertext) },
+		"SecretKey":                func() unmarshaler { return new(SecretKey) },
+		"PublicKey":                func() unmarshaler { return new(PublicKey) },
+		"SwitchingKey":             func() unmarshaler { return new(SwitchingKey) },
+		"RelinearizationKey":       func() unmarshaler { return new(RelinearizationKey) },
+		"RotationKeySet":           func() unmarshaler { return new(RotationKeySet) },
+		"RotationKeySet/Lvl":       func() unmarshaler { return new(RotationKeySet) },
+		"SeededCiphertext":         func() unmarshaler { return new(SeededCiphertext) },
+		"SeededSwitchingKey":       func() unmarshaler { return new(SeededSwitchingKey) },
+		"SeededRelinearizationKey": func() unmarshaler { return new(SeededRelinearizationKey) },
+		"SeededRotationKeySet":     func() unmarshaler { return new(SeededRotationKeySet) },
+	}
+
+	// parameters with one modulus less, under which the objects are invalid
+	paramsSmall, err := NewParametersFromLiteral(ParametersLiteral{LogN: params.LogN(), Q: params.Q()[:1], P: params.P(), Pow2Base: params.Pow2Base(), RingType: params.RingType()})
+	require.NoError(t, err)
+
+	for name, obj := range objects {
+
+		t.Run(testString(params, "Validate/"+name), func(t *testing.T) {
+
+			require.NoError(t, obj.Validate(params))
+
+			data, err := obj.MarshalBinary()
+			require.NoError(t, err)
+			require.NoError(t, UnmarshalValidated(params, data, newObject[name]()))
+
+			// the truncated encodings and the encodings with trailing data are rejected,
+			// except the empty encoding of the rotation key sets
+			for _, size := range []int{0, 1, 2, 7, 8, 9, len(data) / 2, len(data) - 1} {
+				if err := newObject[name]().UnmarshalBinary(data[:size]); size > 0 || !strings.Contains(name, "RotationKeySet") {
+					require.Error(t, err)
+				}
+			}
+			require.Error(t, newObject[name]().UnmarshalBinary(append(data, 0)))
+
+			if params.MaxLevel() > 0 && name != "RotationKeySet/Lvl" {
+				require.True(t, errors.Is(obj.Validate(paramsSmall), ErrMalformed))
+			}
+		})
+	}
+
+	t.Run(testString(params, "Validate/Malformed"), func(t *testing.T) {
+
+		// coefficient not reduced modulo q_0
+		ct := ciphertext.CopyNew()
+		ct.Value[1].Coeffs[0][0] = params.Q()[0]
+		data, err := ct.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, new(Ciphertext).UnmarshalBinary(data))
+		require.True(t, errors.Is(UnmarshalValidated(params, data, new(Ciphertext)), ErrMalformed))
+
+		// elements of different levels
+		if params.MaxLevel() > 0 {
+			ct = ciphertext.CopyNew()
+			ct.Value[1].Coeffs = ct.Value[1].Coeffs[:1]
+			require.True(t, errors.Is(ct.Validate(params), ErrMalformed))
+		}
+
+		// empty ciphertext
+		require.True(t, errors.Is(new(Ciphertext).UnmarshalBinary([]byte{0}), ErrMalformed))
+
+		// invalid Galois elements
+		rtks := objects["RotationKeySet"].(*RotationKeySet)
+		for _, invalid := range []uint64{galEl + 1, galEl + params.RingQ().NthRoot} {
+			require.True(t, errors.Is((&RotationKeySet{Keys: map[uint64]*SwitchingKey{invalid: rtks.Keys[galEl]}}).Validate(params), ErrMalformed))
+		}
+
+		// invalid seed size
+		seededTest := &SeededCiphertext{Value: seeded.Value, Seed: seeded.Seed[:SeedSize-1]}
+		require.True(t, errors.Is(seededTest.Validate(params), ErrMalformed))
+
+		// gadget decomposition of a wrong size
+		swk := objects["SwitchingKey"].(*SwitchingKey)
+		swkTest := &SwitchingKey{GadgetCiphertext{Value: swk.Value[:len(swk.Value)-1]}}
+		if len(swkTest.Value) > 0 {
+			require.True(t, errors.Is(swkTest.Validate(params), ErrMalformed))
+		}
+
+		// decomposition of size zero and oversized decomposition
+		require.True(t, errors.Is(new(SwitchingKey).UnmarshalBinary([]byte{0, 1}), ErrMalformed))
+		require.True(t, errors.Is(new(SwitchingKey).UnmarshalBinary([]byte{0xFF, 0xFF, 0, 0}), ErrMalformed))
+	})
+}
+
+func testSecurity(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	t.Run(testString(params, "Security/Parameters"), func(t *testing.T) {
+
+		inst := params.SecurityInstance()
+		require.Equal(t, UniformTernary, inst.Secret)
+		require.LessOrEqual(t, inst.LogQP, float64(params.LogQP()))
+		require.Greater(t, inst.LogQP, float64(params.LogQP()-1))
+
+		est, err := params.EstimateSecurity()
+		require.NoError(t, err)
+
+		// the test parameters are within the bounds of the HE standard for 128-bit security
+		require.NotEqual(t, 0, est.Standard)
+		require.GreaterOrEqual(t, est.Bits(), 125.0)
+	})
+
+	t.Run(testString(params, "Security/Instances"), func(t *testing.T) {
+
+		inst := SecurityInstance{LogN: params.LogN(), LogQP: float64(params.LogQP()), Sigma: DefaultSigma}
+
+		ternary, err := EstimateSecurity(inst)
+		require.NoError(t, err)
+
+		// a larger modulus and a sparser secret decrease the security
+		inst.LogQP *= 2
+		larger, err := EstimateSecurity(inst)
+		require.NoError(t, err)
+		require.Less(t, larger.Bits(), ternary.Bits())
+
+		inst.LogQP /= 2
+		inst.Secret, inst.H = SparseTernary, 64
+		sparse, err := EstimateSecurity(inst)
+		require.NoError(t, err)
+		require.Less(t, sparse.Bits(), ternary.Bits())
+		require.Equal(t, -1, sparse.Standard)
+
+		// the secrets of very small weight are found by exhaustive search
+		inst.H = 2
+		sparse, err = EstimateSecurity(inst)
+		require.NoError(t, err)
+		require.Less(t, sparse.Bits(), float64(2*params.LogN()))
+
+		inst.Secret = GaussianSecret
+		gaussian, err := EstimateSecurity(inst)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, gaussian.Bits(), ternary.Bits())
+
+		inst.Secret, inst.H = SparseTernary, 0
+		_, err = EstimateSecurity(inst)
+		require.Error(t, err)
+
+		inst.Secret, inst.LogN = UniformTernary, MaxLogN+1
+		_, err = EstimateSecurity(inst)
+		require.Error(t, err)
+	})
+
+	if i := params.LogN() - heStandardMinLogN; i >= 0 && i < len(heStandardTernary) {
+
+		t.Run(testString(params, "Security/HEStandard"), func(t *testing.T) {
+
+			for j, logQP := range heStandardTernary[i] {
+
+				inst := SecurityInstance{LogN: params.LogN(), LogQP: logQP, Sigma: DefaultSigma}
+
+				est, err := EstimateSecurity(inst)
+				require.NoError(t, err)
+				require.Equal(t, heStandardLevels[j], est.Standard)
+
+				// the attack model is consistent with the tables of the HE standard
+				require.InDelta(t, float64(heStandardLevels[j]), est.Bits(), 10)
+			}
+
+			est, err := EstimateSecurity(SecurityInstance{LogN: params.LogN(), LogQP: heStandardTernary[i][0] + 1, Sigma: DefaultSigma})
+			require.NoError(t, err)
+			require.Equal(t, 0, est.Standard)
+		})
+	}
+}
+
+func testKeyGeneratorWithPRNG(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	newKeyGenerator := func(seed byte) KeyGenerator {
+		prng, err := utils.NewKeyedPRNG([]byte{seed})
+		require.NoError(t, err)
+		return NewKeyGeneratorWithPRNG(params, prng)
+	}
+
+	t.Run(testString(params, "KeyGeneratorWithPRNG/Reproducible"), func(t *testing.T) {
+
+		kgenA, kgenB := newKeyGenerator('a'), newKeyGenerator('a')
+
+		skA, pkA := kgenA.GenKeyPair()
+		skB, pkB := kgenB.GenKeyPair()
+		require.True(t, skA.Value.Equals(skB.Value))
+		require.True(t, pkA.Equals(pkB))
+
+		rlkA := kgenA.GenRelinearizationKey(skA, 1)
+		rlkB := kgenB.GenRelinearizationKey(skB, 1)
+		require.True(t, rlkA.Equals(rlkB))
+
+		galEls := []uint64{params.GaloisElementForColumnRotationBy(1)}
+		require.True(t, kgenA.GenRotationKeys(galEls, skA).Equals(kgenB.GenRotationKeys(galEls, skB)))
+
+		seededA := kgenA.GenSeededRotationKeys(galEls, skA)
+		seededB := kgenB.GenSeededRotationKeys(galEls, skB)
+		dataA, err := seededA.MarshalBinary()
+		require.NoError(t, err)
+		dataB, err := seededB.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, dataA, dataB)
+
+		skC := newKeyGenerator('c').GenSecretKey()
+		require.False(t, skA.Value.Equals(skC.Value))
+	})
+
+	t.Run(testString(params, "KeyGeneratorWithPRNG/DomainSeparation"), func(t *testing.T) {
+
+		kgenA, kgenB := newKeyGenerator('a'), newKeyGenerator('a')
+
+		// the secret keys do not depend on the other keys generated before
+		kgenB.GenPublicKey(kgen.GenSecretKey())
+		kgenB.GenRelinearizationKey(kgen.GenSecretKey(), 1)
+
+		require.True(t, kgenA.GenSecretKey().Value.Equals(kgenB.GenSecretKey().Value))
+		require.True(t, kgenA.GenSecretKeyGaussian().Value.Equals(kgenB.GenSecretKeyGaussian().Value))
+	})
+}
+
+// testKDFParameters are cheap parameters of the KDF for the tests.
+var testKDFParameters = KDFParameters{Time: 1, Memory: 64, Threads: 1}
+
+func testSealedKey(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+	sk := kgen.GenSecretKey()
+	password := []byte("password")
+
+	data, err := SealSecretKey(params, sk, password, testKDFParameters)
+	require.NoError(t, err)
+
+	t.Run(testString(params, "SealedKey/Open"), func(t *testing.T) {
+
+		paramsOpen, skOpen, err := OpenSecretKey(data, password)
+		require.NoError(t, err)
+		require.True(t, params.Equals(paramsOpen))
+		require.True(t, sk.Value.Equals(skOpen.Value))
+
+		// the key material is not in the clear
+		skData, err := sk.MarshalBinary()
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(data, skData[len(skData)-64:]))
+
+		// two sealings of the same key differ
+		dataTest, err := SealSecretKey(params, sk, password, testKDFParameters)
+		require.NoError(t, err)
+		require.NotEqual(t, data, dataTest)
+	})
+
+	t.Run(testString(params, "SealedKey/WrongPassword"), func(t *testing.T) {
+		_, _, err := OpenSecretKey(data, []byte("passw0rd"))
+		require.True(t, errors.Is(err, ErrWrongPassword))
+	})
+
+	t.Run(testString(params, "SealedKey/Tampered"), func(t *testing.T) {
+
+		// offset of the parameters, after the header, the purpose, the KDF parameters, the salt, the 24-byte nonce and the size
+		offset := len(sealedKeyMagic) + 2 + len(SealedSecretKeyPurpose) + 9 + sealedKeySaltLen + 24 + 4
+
+		// the parameters, the salt and the ciphertext are authenticated
+		for _, i := range []int{offset + 4, offset - 30, len(data) - 1, len(data) / 2} {
+			tampered := append([]byte{}, data...)
+			tampered[i] ^= 1
+			_, _, err := OpenSecretKey(tampered, password)
+			require.True(t, errors.Is(err, ErrWrongPassword))
+		}
+
+		// truncated and forged headers
+		for _, n := range []int{0, 10, 30, 100} {
+			_, _, err := OpenSecretKey(data[:n], password)
+			require.True(t, errors.Is(err, ErrMalformed))
+		}
+
+		_, _, err := OpenKey("another purpose", data, password)
+		require.True(t, errors.Is(err, ErrMalformed))
+	})
+
+	t.Run(testString(params, "SealedKey/InvalidArguments"), func(t *testing.T) {
+
+		_, err := SealSecretKey(params, sk, password, KDFParameters{})
+		require.Error(t, err)
+
+		_, err = SealKey("", params, sk, password, testKDFParameters)
+		require.Error(t, err)
+
+		_, err = SealSecretKey(params, NewSecretKey(params), password, testKDFParameters)
+		require.NoError(t, err)
+
+		_, err = SealSecretKey(params, &SecretKey{}, password, testKDFParameters)
+		require.Error(t, err)
+	})
+}
+
+func testLWE(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	if params.RingType() != ring.Standard {
+		return
+	}
+
+	sk := kgen.GenSecretKey()
+	skLWE := NewLWESecretKeyFromSecretKey(params, sk)
+
+	Q := params.RingQ().Modulus[0]
+	N := params.N()
+
+	// plaintext with the coefficients (j mod 4) * Q/4
+	pt := NewPlaintext(params, 0)
+	for j := range pt.Value.Coeffs[0] {
+		pt.Value.Coeffs[0][j] = uint64(j&3) * (Q >> 2)
+	}
+
+	ct := NewCiphertext(params, 1, 0)
+	NewEncryptor(params, sk).Encrypt(pt, ct)
+
+	indexes := []int{0, 1, 2, N/2 + 3, N - 1}
+
+	// requireMessage checks that the phase is close to m * Q/4.
+	requireMessage := func(t *testing.T, phase, Q uint64, m int) {
+		diff := phase - uint64(m)*(Q>>2)
+		if phase < uint64(m)*(Q>>2) {
+			diff = uint64(m)*(Q>>2) - phase
+		}
+		if diff > Q>>1 {
+			diff = Q - diff
+		}
+		require.Less(t, diff, Q>>4)
+	}
+
+	rtks := kgen.GenRotationKeys(params.GaloisElementsForMergeRLWE(), sk)
+	eval := NewEvaluator(params, &EvaluationKey{Rtks: rtks})
+
+	t.Run(testString(params, "LWE/SampleExtract"), func(t *testing.T) {
+
+		ctNTT := ct.CopyNew()
+		params.RingQ().NTTLvl(0, ctNTT.Value[0], ctNTT.Value[0])
+		params.RingQ().NTTLvl(0, ctNTT.Value[1], ctNTT.Value[1])
+		ctNTT.Value[0].IsNTT, ctNTT.Value[1].IsNTT = true, true
+
+		for _, index := range indexes {
+			requireMessage(t, skLWE.Decrypt(eval.SampleExtract(ct, index)), Q, index&3)
+			requireMessage(t, skLWE.Decrypt(eval.SampleExtract(ctNTT, index)), Q, index&3)
+		}
+
+		require.Panics(t, func() { eval.SampleExtract(ct, N) })
+		require.Panics(t, func() { eval.SampleExtract(NewCiphertext(params, 2, 0), 0) })
+	})
+
+	t.Run(testString(params, "LWE/ModSwitch"), func(t *testing.T) {
+		QOut := uint64(1) << 20
+		for _, index := range indexes {
+			ctLWE := eval.SampleExtract(ct, index).ModSwitchNew(QOut)
+			require.Equal(t, QOut, ctLWE.Q)
+			requireMessage(t, skLWE.Decrypt(ctLWE), QOut, index&3)
+		}
+	})
+
+	t.Run(testString(params, "LWE/SwitchKeys"), func(t *testing.T) {
+
+		if N > 1<<11 {
+			t.Skip("switching key is too large for the ring degree")
+		}
+
+		skOut := kgen.GenLWESecretKey(N / 2)
+		swk := kgen.GenLWESwitchingKey(skLWE, skOut, Q, 7)
+
+		for _, index := range indexes {
+
+			ctLWE := eval.SampleExtract(ct, index)
+			eval.SwitchKeysLWE(ctLWE, swk, ctLWE)
+
+			require.Equal(t, N/2, ctLWE.N())
+			requireMessage(t, skOut.Decrypt(ctLWE), Q, index&3)
+
+			// switches to the modulus 2N, as for the evaluation of a LUT
+			requireMessage(t, skOut.Decrypt(ctLWE.ModSwitchNew(uint64(2*N))), uint64(2*N), index&3)
+		}
+
+		require.Panics(t, func() { eval.SwitchKeysLWE(NewLWECiphertext(N/2, Q), swk, NewLWECiphertext(N/2, Q)) })
+	})
+
+	t.Run(testString(params, "LWE/Pack"), func(t *testing.T) {
+
+		decryptor := NewDecryptor(params, sk)
+		ptOut := NewPlaintext(params, 0)
+
+		ciphertexts := make(map[int]*LWECiphertext)
+		for _, index := range indexes {
+			ciphertexts[index] = eval.SampleExtract(ct, index)
+		}
+
+		// the plaintexts are packed in the coefficients of the indexes and the other coefficients are zero
+		decryptor.Decrypt(eval.PackLWE(ciphertexts), ptOut)
+		for j, c := range ptOut.Value.Coeffs[0] {
+			if _, ok := ciphertexts[j]; ok {
+				requireMessage(t, c, Q, j&3)
+			} else {
+				requireMessage(t, c, Q, 0)
+			}
+		}
+
+		decryptor.Decrypt(eval.LWEToRLWE(eval.SampleExtract(ct, 3)), ptOut)
+		requireMessage(t, ptOut.Value.Coeffs[0][0], Q, 3)
+		for _, c := range ptOut.Value.Coeffs[0][1:] {
+			requireMessage(t, c, Q, 0)
+		}
+	})
+
+	t.Run(testString(params, "LWE/Marshaller"), func(t *testing.T) {
+
+		ctLWE := eval.SampleExtract(ct, 1)
+
+		data, err := ctLWE.MarshalBinary()
+		require.NoError(t, err)
+
+		ctTest := new(LWECiphertext)
+		require.NoError(t, ctTest.UnmarshalBinary(data))
+		require.Equal(t, ctLWE, ctTest)
+
+		require.ErrorIs(t, ctTest.UnmarshalBinary(data[:len(data)-1]), ErrMalformed)
+	})
 }
@@ -0,0 +1,237 @@
+package rlwe
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// ErrWrongPassword is returned when a sealed key cannot be opened, either because the password is wrong or
+// because the sealed key has been tampered with.
+var ErrWrongPassword = errors.New("wrong password or corrupted sealed key")
+
+// KDFParameters are the parameters of the Argon2id password-based key derivation function of the sealed keys.
+type KDFParameters struct {
+	Time    uint32 // number of passes over the memory
+	Memory  uint32 // size of the memory in KiB
+	Threads uint8  // degree of parallelism
+}
+
+// DefaultKDFParameters are the parameters of Argon2id recommended by RFC 9106 for memory-constrained
+// environments: three passes over 64 MiB with four lanes.
+var DefaultKDFParameters = KDFParameters{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// Bounds on the parameters of the KDF accepted by OpenKey, so that a forged sealed key cannot make its opening
+// arbitrarily expensive.
+const (
+	maxKDFTime   = 64
+	maxKDFMemory = 4 * 1024 * 1024 // 4 GiB
+)
+
+// The purposes of the keys sealed by the rlwe package.
+const (
+	// SealedSecretKeyPurpose is the purpose of the secret keys sealed by SealSecretKey.
+	SealedSecretKeyPurpose = "rlwe/secret-key"
+)
+
+const (
+	sealedKeyMagic   = "LATTIGO-SEALED-KEY"
+	sealedKeyVersion = 1
+	sealedKeySaltLen = 16
+)
+
+// SealSecretKey encrypts sk under password with SealKey for the purpose SealedSecretKeyPurpose.
+func SealSecretKey(params Parameters, sk *SecretKey, password []byte, kdf KDFParameters) ([]byte, error) {
+	return SealKey(SealedSecretKeyPurpose, params, sk, password, kdf)
+}
+
+// OpenSecretKey decrypts the secret key sealed by SealSecretKey with password. See OpenKey.
+func OpenSecretKey(data, password []byte) (Parameters, *SecretKey, error) {
+	return OpenKey(SealedSecretKeyPurpose, data, password)
+}
+
+// SealKey encrypts the secret key material sk of the parameters params under password and returns the sealed key.
+// The purpose distinguishes the different kinds of keys and is checked by OpenKey.
+//
+// The sealed key carries the purpose, the parameters of the KDF, a random salt and nonce and the parameters params in
+// the clear, followed by the encryption of sk with XChaCha20-Poly1305 under a key derived from password with Argon2id.
+// The data in the clear are authenticated along with sk. The encoding of sk is erased from memory once encrypted.
+func SealKey(purpose string, params Parameters, sk *SecretKey, password []byte, kdf KDFParameters) (data []byte, err error) {
+
+	if len(purpose) == 0 || len(purpose) > 0xFF {
+		return nil, fmt.Errorf("cannot SealKey: invalid purpose")
+	}
+
+	if err = checkKDFParameters(kdf); err != nil {
+		return nil, fmt.Errorf("cannot SealKey: %w", err)
+	}
+
+	if err = sk.Validate(params); err != nil {
+		return nil, fmt.Errorf("cannot SealKey: %w", err)
+	}
+
+	paramsData, err := params.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	// header
+	b := new(bytes.Buffer)
+	b.WriteString(sealedKeyMagic)
+	b.WriteByte(sealedKeyVersion)
+	b.WriteByte(uint8(len(purpose)))
+	b.WriteString(purpose)
+
+	var kdfData [9]byte
+	binary.BigEndian.PutUint32(kdfData[0:], kdf.Time)
+	binary.BigEndian.PutUint32(kdfData[4:], kdf.Memory)
+	kdfData[8] = kdf.Threads
+	b.Write(kdfData[:])
+
+	salt := make([]byte, sealedKeySaltLen)
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	b.Write(salt)
+	b.Write(nonce)
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(paramsData)))
+	b.Write(size[:])
+	b.Write(paramsData)
+
+	header := b.Bytes()
+
+	plaintext, err := sk.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	defer erase(plaintext)
+
+	aead, key, err := newSealingAEAD(password, salt, kdf)
+	if err != nil {
+		return nil, err
+	}
+	defer erase(key)
+
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// OpenKey decrypts the key sealed by SealKey for the given purpose with password, and returns the parameters and
+// the secret key material of the sealed key. The secret key is checked against the parameters.
+//
+// It returns an error wrapping ErrMalformed if data is not a well-formed sealed key or has another purpose, and
+// ErrWrongPassword if the authentication of the sealed key fails.
+func OpenKey(purpose string, data, password []byte) (params Parameters, sk *SecretKey, err error) {
+
+	r := bytes.NewReader(data)
+	next := func(n int) []byte {
+		if err != nil || r.Len() < n {
+			err = malformed("sealed key is too short")
+			return nil
+		}
+		buf := make([]byte, n)
+		r.Read(buf)
+		return buf
+	}
+
+	magic := next(len(sealedKeyMagic) + 1)
+	if err == nil && (string(magic[:len(sealedKeyMagic)]) != sealedKeyMagic || magic[len(sealedKeyMagic)] != sealedKeyVersion) {
+		return params, nil, malformed("not a sealed key of version %d", sealedKeyVersion)
+	}
+
+	var purposeData []byte
+	if size := next(1); err == nil {
+		purposeData = next(int(size[0]))
+	}
+
+	if err == nil && string(purposeData) != purpose {
+		return params, nil, malformed("sealed key has purpose %q instead of %q", purposeData, purpose)
+	}
+
+	var kdf KDFParameters
+	if kdfData := next(9); err == nil {
+		kdf = KDFParameters{Time: binary.BigEndian.Uint32(kdfData[0:]), Memory: binary.BigEndian.Uint32(kdfData[4:]), Threads: kdfData[8]}
+	}
+
+	salt := next(sealedKeySaltLen)
+	nonce := next(chacha20poly1305.NonceSizeX)
+
+	var paramsData []byte
+	if size := next(4); err == nil {
+		paramsData = next(int(binary.BigEndian.Uint32(size)))
+	}
+
+	if err != nil {
+		return
+	}
+
+	if err = checkKDFParameters(kdf); err != nil {
+		return params, nil, fmt.Errorf("%w: %s", ErrMalformed, err)
+	}
+
+	header := data[:len(data)-r.Len()]
+	ciphertext := data[len(header):]
+
+	aead, key, err := newSealingAEAD(password, salt, kdf)
+	if err != nil {
+		return
+	}
+	defer erase(key)
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return params, nil, ErrWrongPassword
+	}
+	defer erase(plaintext)
+
+	if err = params.UnmarshalBinary(paramsData); err != nil {
+		return params, nil, fmt.Errorf("%w: %s", ErrMalformed, err)
+	}
+
+	sk = new(SecretKey)
+	if err = UnmarshalValidated(params, plaintext, sk); err != nil {
+		return Parameters{}, nil, err
+	}
+
+	return
+}
+
+// checkKDFParameters checks that kdf are valid parameters for Argon2id within the bounds accepted by OpenKey.
+func checkKDFParameters(kdf KDFParameters) error {
+	if kdf.Time == 0 || kdf.Time > maxKDFTime || kdf.Threads == 0 || kdf.Memory < 8*uint32(kdf.Threads) || kdf.Memory > maxKDFMemory {
+		return fmt.Errorf("invalid KDF parameters %+v", kdf)
+	}
+	return nil
+}
+
+// newSealingAEAD returns the authenticated encryption scheme keyed with the key derived from password and salt,
+// along with the key.
+func newSealingAEAD(password, salt []byte, kdf KDFParameters) (aead cipher.AEAD, key []byte, err error) {
+
+	key = argon2.IDKey(password, salt, kdf.Time, kdf.Memory, kdf.Threads, chacha20poly1305.KeySize)
+
+	if aead, err = chacha20poly1305.NewX(key); err != nil {
+		erase(key)
+		return nil, nil, err
+	}
+
+	return
+}
+
+// erase overwrites b with zeros.
+func erase(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,288 @@
+package rlwe
+
+import (
+	"fmt"
+	"math"
+)
+
+// SecretDistribution is the distribution of the secret of an RLWE instance.
+type SecretDistribution int
+
+const (
+	// UniformTernary is the uniform distribution over {-1, 0, 1}^N.
+	UniformTernary = SecretDistribution(iota)
+	// SparseTernary is the uniform distribution over the vectors of {-1, 0, 1}^N of Hamming weight H.
+	SparseTernary
+	// GaussianSecret is the error distribution.
+	GaussianSecret
+)
+
+func (s SecretDistribution) String() string {
+	switch s {
+	case UniformTernary:
+		return "UniformTernary"
+	case SparseTernary:
+		return "SparseTernary"
+	case GaussianSecret:
+		return "Gaussian"
+	default:
+		return fmt.Sprintf("SecretDistribution(%d)", int(s))
+	}
+}
+
+// SecurityInstance is an RLWE instance of which the security can be estimated.
+type SecurityInstance struct {
+	LogN   int
+	LogQP  float64
+	Sigma  float64
+	Secret SecretDistribution
+	H      int // Hamming weight of the secret, only used by SparseTernary
+}
+
+// SecurityEstimate is the estimated security of an RLWE instance. The costs of the attacks are in bits.
+type SecurityEstimate struct {
+	SecurityInstance
+
+	// Primal is the cost of the primal (uSVP) attack.
+	Primal float64
+	// Dual is the cost of the dual (distinguishing) attack.
+	Dual float64
+	// Search is the cost of the meet-in-the-middle exhaustive search of the secret.
+	Search float64
+	// Standard is the largest security level among 128, 192 and 256 bits for which the instance is within the bounds
+	// of the tables of the HE standard, 0 if the instance is below the 128-bit bound and -1 if the tables do not cover
+	// the instance.
+	Standard int
+}
+
+// Bits returns the estimated bit security of the instance, that is, the cost of the cheapest attack.
+func (e SecurityEstimate) Bits() float64 {
+	return math.Min(math.Min(e.Primal, e.Dual), e.Search)
+}
+
+// heStandardLevels are the security levels of the columns of the tables of the HE standard.
+var heStandardLevels = [3]int{128, 192, 256}
+
+// heStandardMinLogN is the LogN of the first rows of the tables of the HE standard.
+const heStandardMinLogN = 10
+
+// heStandardGaussian are the largest log2(QP) for which an instance with a secret sampled from the error distribution
+// reaches 128, 192 and 256 bit classical security, for LogN from 10 to 15 (HE standard, table of the error distribution,
+// of which the classical bounds are also those of the uniform secret).
+var heStandardGaussian = [][3]float64{
+	{29, 21, 16},
+	{56, 39, 31},
+	{111, 77, 60},
+	{220, 154, 120},
+	{440, 307, 239},
+	{880, 612, 478},
+}
+
+// heStandardTernary are the largest log2(QP) for which an instance with a uniform ternary secret reaches 128, 192 and
+// 256 bit classical security, for LogN from 10 to 15 (HE standard, table of the ternary distribution).
+var heStandardTernary = [][3]float64{
+	{27, 19, 14},
+	{54, 37, 29},
+	{109, 75, 58},
+	{218, 152, 118},
+	{438, 305, 237},
+	{881, 611, 476},
+}
+
+// heStandardMinSigma is the standard deviation of the error assumed by the tables of the HE standard, 8/sqrt(2*pi)
+// rounded up to DefaultSigma.
+const heStandardMinSigma = 3.2
+
+// EstimateSecurity estimates the classical bit security of the RLWE instance inst.
+//
+// The estimate is the cost of the cheapest of the primal (uSVP) and dual attacks, both adapted to small secrets by
+// rescaling the secret to the size of the error, with the number of samples and the block size of BKZ optimized
+// for each attack and the cost of BKZ in dimension d with block size b estimated by the core-SVP sieving model
+// 0.292b + 16.4 + log2(8d), and of the meet-in-the-middle search of the secret. The model does not account for the
+// hybrid attacks that combine lattice reduction with a partial search of the secret, which are more effective on
+// sparse secrets: the estimates for sparse secrets are upper bounds that barely depend on H and must not be used to
+// select parameters with a sparse secret.
+//
+// The result also reports the security level guaranteed by the tables of the HE standard, when they cover the instance
+// (LogN between 10 and 15, Sigma at least 3.2 and a uniform ternary or Gaussian secret).
+func EstimateSecurity(inst SecurityInstance) (est SecurityEstimate, err error) {
+
+	if inst.LogN < MinLogN || inst.LogN > MaxLogN {
+		return est, fmt.Errorf("invalid LogN: %d is not in [%d, %d]", inst.LogN, MinLogN, MaxLogN)
+	}
+
+	if !(inst.LogQP > 0) || !(inst.Sigma > 0) {
+		return est, fmt.Errorf("invalid instance: LogQP and Sigma must be positive")
+	}
+
+	n := float64(int(1) << inst.LogN)
+
+	// standard deviation and entropy in bits of the secret
+	var sigmaS, entropy float64
+	switch inst.Secret {
+	case UniformTernary:
+		sigmaS = math.Sqrt(2.0 / 3.0)
+		entropy = n * math.Log2(3)
+	case SparseTernary:
+		if inst.H <= 0 || inst.H > 1<<inst.LogN {
+			return est, fmt.Errorf("invalid Hamming weight: %d is not in [1, %d]", inst.H, 1<<inst.LogN)
+		}
+		h := float64(inst.H)
+		sigmaS = math.Sqrt(h / n)
+		entropy = (logGamma(n+1)-logGamma(h+1)-logGamma(n-h+1))/math.Ln2 + h
+	case GaussianSecret:
+		sigmaS = inst.Sigma
+		entropy = n * math.Log2(inst.Sigma*math.Sqrt(2*math.Pi*math.E))
+	default:
+		return est, fmt.Errorf("invalid secret distribution: %s", inst.Secret)
+	}
+
+	est.SecurityInstance = inst
+	est.Primal = primalCost(n, inst.LogQP, inst.Sigma, sigmaS)
+	est.Dual = dualCost(n, inst.LogQP, inst.Sigma, sigmaS)
+	est.Search = entropy / 2
+	est.Standard = heStandardLevel(inst)
+
+	return
+}
+
+// heStandardLevel returns the security level guaranteed by the tables of the HE standard for inst, or -1 if the tables
+// do not cover inst.
+func heStandardLevel(inst SecurityInstance) (level int) {
+
+	var table [][3]float64
+	switch inst.Secret {
+	case UniformTernary:
+		table = heStandardTernary
+	case GaussianSecret:
+		table = heStandardGaussian
+	default:
+		return -1
+	}
+
+	if inst.LogN < heStandardMinLogN || inst.LogN >= heStandardMinLogN+len(table) || inst.Sigma < heStandardMinSigma {
+		return -1
+	}
+
+	for i, logQP := range table[inst.LogN-heStandardMinLogN] {
+		if inst.LogQP <= logQP {
+			level = heStandardLevels[i]
+		}
+	}
+
+	return
+}
+
+// logGamma returns the natural logarithm of Gamma(x) for x > 0.
+func logGamma(x float64) float64 {
+	lg, _ := math.Lgamma(x)
+	return lg
+}
+
+// logRootHermite returns log2 of the root Hermite factor reached by BKZ with block size beta.
+func logRootHermite(beta float64) float64 {
+	return math.Log2(math.Pow(math.Pi*beta, 1/beta)*beta/(2*math.Pi*math.E)) / (2 * (beta - 1))
+}
+
+// bkzCost returns the cost in bits of BKZ with block size beta in dimension d, in the core-SVP sieving model.
+func bkzCost(beta, d float64) float64 {
+	return 0.292*beta + 16.4 + math.Log2(8*d)
+}
+
+// minBlockSize is the smallest block size considered by the attacks, below which the model of BKZ is not accurate.
+const minBlockSize = 50
+
+// samplesSteps is the number of values of the number of samples tried by the attacks for each block size.
+const samplesSteps = 64
+
+// primalCost returns the cost in bits of the primal (uSVP) attack on the RLWE instance of dimension n and modulus 2^logq,
+// with a secret of standard deviation sigmaS and an error of standard deviation sigma, using up to 2n samples.
+// The attack succeeds with block size beta if the projection of the error vector on the last beta vectors of the
+// basis is shorter than the norm of the beta-th Gram-Schmidt vector [ADPS16].
+func primalCost(n, logq, sigma, sigmaS float64) float64 {
+
+	// The coordinates of the secret are scaled by nu to have the same size as the error.
+	logNu := math.Max(0, math.Log2(sigma/sigmaS))
+
+	step := math.Max(1, math.Floor(2*n/samplesSteps))
+
+	for beta := float64(minBlockSize); beta < 2*n; beta++ {
+
+		lhs := math.Log2(sigma) + 0.5*math.Log2(beta)
+		logDelta := logRootHermite(beta)
+
+		for m := step; m <= 2*n; m += step {
+			d := m + n + 1
+			if beta < d && lhs <= (2*beta-d)*logDelta+(m*logq+n*logNu)/d {
+				return bkzCost(beta, d)
+			}
+		}
+	}
+
+	return bkzCost(3*n, 3*n+1)
+}
+
+// dualCost returns the cost in bits of the dual attack on the RLWE instance of dimension n and modulus 2^logq, with a
+// secret of standard deviation sigmaS and an error of standard deviation sigma, using up to 2n samples.
+// The attack finds with BKZ a short vector (x, y) of the lattice {(x, c*y) : x^T A = y mod q}, where c scales the
+// secret to the size of the error, and distinguishes <x, b> mod q from uniform with advantage
+// eps = exp(-2 pi^2 (sigma |(x, y)| / q)^2). It needs 1/eps^2 such vectors, of which a run of sieving outputs 2^(0.2075 beta).
+func dualCost(n, logq, sigma, sigmaS float64) float64 {
+
+	logC := math.Min(0, math.Log2(sigmaS/sigma))
+
+	step := math.Max(1, math.Floor(2*n/samplesSteps))
+
+	best := math.Inf(1)
+	for beta := float64(minBlockSize); beta < 3*n && bkzCost(beta, n) < best; beta++ {
+
+		logDelta := logRootHermite(beta)
+
+		for m := step; m <= 2*n; m += step {
+
+			d := m + n
+			if beta >= d {
+				continue
+			}
+
+			logNorm := d*logDelta + n*(logq+logC)/d
+
+			// log2 of 1/eps^2
+			logRepetitions := 4 * math.Pi * math.Pi * math.Exp2(2*(math.Log2(sigma)+logNorm-logq)) / math.Ln2
+
+			if cost := bkzCost(beta, d) + math.Max(0, logRepetitions-0.2075*beta); cost < best {
+				best = cost
+			}
+		}
+	}
+
+	return best
+}
+
+// SecurityInstance returns the RLWE instance of the receiver parameters. The secrets of Hamming weight at least N/2,
+// which includes the default weight, are considered to be uniform ternary, as in the HE standard.
+func (p Parameters) SecurityInstance() SecurityInstance {
+
+	logQP := 0.0
+	for _, qi := range p.qi {
+		logQP += math.Log2(float64(qi))
+	}
+	for _, pi := range p.pi {
+		logQP += math.Log2(float64(pi))
+	}
+
+	inst := SecurityInstance{LogN: p.logN, LogQP: logQP, Sigma: p.sigma, H: p.h}
+
+	if p.h >= p.N()>>1 {
+		inst.Secret = UniformTernary
+	} else {
+		inst.Secret = SparseTernary
+	}
+
+	return inst
+}
+
+// EstimateSecurity estimates the classical bit security of the receiver parameters, see rlwe.EstimateSecurity.
+func (p Parameters) EstimateSecurity() (SecurityEstimate, error) {
+	return EstimateSecurity(p.SecurityInstance())
+}
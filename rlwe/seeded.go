@@ -0,0 +1,538 @@
+package rlwe
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// SeedSize is the size in bytes of the seeds from which the uniform elements of the seeded ciphertexts
+// and keys are expanded.
+const SeedSize = 32
+
+// SeededCiphertext is a degree one Ciphertext encrypted under a secret key, in which the uniform element
+// c1 is replaced by the seed of the PRNG from which it was sampled. Its serialization is about half the
+// size of the one of the corresponding Ciphertext.
+type SeededCiphertext struct {
+	Value *ring.Poly
+	Seed  []byte
+}
+
+// NewSeededCiphertext returns a new SeededCiphertext with zero values at the given level.
+func NewSeededCiphertext(params Parameters, level int) *SeededCiphertext {
+	return &SeededCiphertext{Value: ring.NewPoly(params.N(), level), Seed: make([]byte, SeedSize)}
+}
+
+// Level returns the level of the target SeededCiphertext.
+func (ct *SeededCiphertext) Level() int {
+	return ct.Value.Level()
+}
+
+// Expand re-generates the uniform element c1 from the seed and returns the corresponding Ciphertext.
+// The element c0 of the returned Ciphertext shares its backing array with the receiver.
+func (ct *SeededCiphertext) Expand(params Parameters) (ctOut *Ciphertext) {
+
+	sampler := newSeededSampler(params, ct.Seed)
+
+	level := ct.Level()
+
+	c1 := ring.NewPoly(params.N(), level)
+	sampler.ReadLvl(level, -1, ringqp.Poly{Q: c1})
+
+	if !ct.Value.IsNTT {
+		params.RingQ().InvNTTLvl(level, c1, c1)
+	}
+
+	c1.IsNTT = ct.Value.IsNTT
+
+	return &Ciphertext{Value: []*ring.Poly{ct.Value, c1}}
+}
+
+// GetDataLen returns the length in bytes of the target SeededCiphertext.
+func (ct *SeededCiphertext) GetDataLen(WithMetaData bool) (dataLen int) {
+	return SeedSize + ct.Value.GetDataLen64(WithMetaData)
+}
+
+// MarshalBinary encodes a SeededCiphertext on a byte slice.
+func (ct *SeededCiphertext) MarshalBinary() (data []byte, err error) {
+	return ct.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes a SeededCiphertext on a byte slice with the packed encoding of the polynomials.
+// It is decoded by UnmarshalBinary.
+func (ct *SeededCiphertext) MarshalBinaryPacked() (data []byte, err error) {
+	return ct.marshalBinary(true)
+}
+
+func (ct *SeededCiphertext) marshalBinary(packed bool) (data []byte, err error) {
+
+	if len(ct.Seed) != SeedSize {
+		return nil, errors.New("cannot MarshalBinary: invalid seed size")
+	}
+
+	data = make([]byte, SeedSize+ring.GetDataLen(ct.Value, true, packed))
+	copy(data, ct.Seed)
+
+	if _, err = ring.WriteTo(ct.Value, data[SeedSize:], packed); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a previously marshaled SeededCiphertext on the target SeededCiphertext.
+func (ct *SeededCiphertext) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < SeedSize+7 {
+		return malformed("data is too short")
+	}
+
+	ct.Seed = make([]byte, SeedSize)
+	copy(ct.Seed, data)
+
+	ct.Value = new(ring.Poly)
+
+	var inc int
+	if inc, err = decodePoly(ct.Value, data[SeedSize:]); err != nil {
+		return err
+	}
+
+	if SeedSize+inc != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
+	return nil
+}
+
+// SeededEncryptor is an interface for the encryption of SeededCiphertexts under a secret key.
+type SeededEncryptor interface {
+	Encrypt(pt *Plaintext, ct *SeededCiphertext)
+	EncryptZero(ct *SeededCiphertext)
+	ShallowCopy() SeededEncryptor
+}
+
+type seededEncryptor struct {
+	*skEncryptor
+}
+
+// NewSeededEncryptor creates a new SeededEncryptor from a secret key.
+// Each encryption samples a fresh seed from which the uniform element is generated.
+func NewSeededEncryptor(params Parameters, sk *SecretKey) SeededEncryptor {
+	return &seededEncryptor{newSkEncryptor(params, sk)}
+}
+
+// ShallowCopy creates a shallow copy of this SeededEncryptor in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// SeededEncryptor can be used concurrently.
+func (enc *seededEncryptor) ShallowCopy() SeededEncryptor {
+	return NewSeededEncryptor(enc.params, enc.sk)
+}
+
+// Encrypt encrypts the input plaintext under a fresh seed and writes the result on ct.
+// The level of ct is set to the minimum between the level of pt and the level of ct.
+func (enc *seededEncryptor) Encrypt(pt *Plaintext, ct *SeededCiphertext) {
+	ct.Value.Resize(utils.MinInt(pt.Level(), ct.Level()))
+	enc.withSeed(ct).Encrypt(pt, ct.Value)
+}
+
+// EncryptZero generates an encryption of zero under a fresh seed and writes the result on ct.
+func (enc *seededEncryptor) EncryptZero(ct *SeededCiphertext) {
+	enc.withSeed(ct).EncryptZero(ct.Value)
+}
+
+// withSeed samples a fresh seed, writes it on ct and returns an encryptor generating the uniform element from it.
+func (enc *seededEncryptor) withSeed(ct *SeededCiphertext) PRNGEncryptor {
+	var prng utils.PRNG
+	ct.Seed, prng = newSeed(enc.prng)
+	return enc.WithPRNG(prng)
+}
+
+// SeededSwitchingKey is a SwitchingKey in which the uniform elements of the gadget ciphertexts are replaced by
+// the seed of the PRNG from which they were sampled. Its serialization is about half the size of the one of the
+// corresponding SwitchingKey.
+type SeededSwitchingKey struct {
+	Value [][]ringqp.Poly
+	Seed  []byte
+}
+
+// SeededRelinearizationKey is a RelinearizationKey made of SeededSwitchingKeys.
+type SeededRelinearizationKey struct {
+	Keys []*SeededSwitchingKey
+}
+
+// SeededRotationKeySet is a RotationKeySet made of SeededSwitchingKeys.
+type SeededRotationKeySet struct {
+	Keys map[uint64]*SeededSwitchingKey
+}
+
+// newSeededSwitchingKey strips the uniform elements of a SwitchingKey generated from the given seed.
+// The returned SeededSwitchingKey shares its backing arrays with swk.
+func newSeededSwitchingKey(swk *SwitchingKey, seed []byte) *SeededSwitchingKey {
+	Value := make([][]ringqp.Poly, len(swk.Value))
+	for i := range swk.Value {
+		Value[i] = make([]ringqp.Poly, len(swk.Value[i]))
+		for j := range swk.Value[i] {
+			Value[i][j] = swk.Value[i][j].Value[0]
+		}
+	}
+	return &SeededSwitchingKey{Value: Value, Seed: seed}
+}
+
+// LevelQ returns the level of the modulus Q of the target SeededSwitchingKey.
+func (swk *SeededSwitchingKey) LevelQ() int {
+	return swk.Value[0][0].Q.Level()
+}
+
+// LevelP returns the level of the modulus P of the target SeededSwitchingKey.
+// Returns -1 if P is absent.
+func (swk *SeededSwitchingKey) LevelP() int {
+	if swk.Value[0][0].P != nil {
+		return swk.Value[0][0].P.Level()
+	}
+	return -1
+}
+
+// Expand re-generates the uniform elements from the seed and returns the corresponding SwitchingKey.
+// The returned SwitchingKey shares its first elements with the receiver.
+func (swk *SeededSwitchingKey) Expand(params Parameters) (swkOut *SwitchingKey) {
+
+	sampler := newSeededSampler(params, swk.Seed)
+	ringQP := params.RingQP()
+
+	levelQ, levelP := swk.LevelQ(), swk.LevelP()
+
+	swkOut = &SwitchingKey{GadgetCiphertext{Value: make([][]CiphertextQP, len(swk.Value))}}
+	for i := range swk.Value {
+		swkOut.Value[i] = make([]CiphertextQP, len(swk.Value[i]))
+		for j := range swk.Value[i] {
+
+			a := ringQP.NewPolyLvl(levelQ, levelP)
+
+			// Same sampling order as the encryptions of zero of the KeyGenerator
+			sampler.ReadLvl(levelQ, levelP, a)
+
+			a.Q.IsNTT = swk.Value[i][j].Q.IsNTT
+			if levelP > -1 {
+				a.P.IsNTT = swk.Value[i][j].P.IsNTT
+			}
+
+			swkOut.Value[i][j] = CiphertextQP{Value: [2]ringqp.Poly{swk.Value[i][j], a}}
+		}
+	}
+
+	return
+}
+
+// Expand re-generates the uniform elements of each key and returns the corresponding RelinearizationKey.
+func (rlk *SeededRelinearizationKey) Expand(params Parameters) (rlkOut *RelinearizationKey) {
+	rlkOut = &RelinearizationKey{Keys: make([]*SwitchingKey, len(rlk.Keys))}
+	for i := range rlk.Keys {
+		rlkOut.Keys[i] = rlk.Keys[i].Expand(params)
+	}
+	return
+}
+
+// Expand re-generates the uniform elements of each key and returns the corresponding RotationKeySet.
+func (rtks *SeededRotationKeySet) Expand(params Parameters) (rtksOut *RotationKeySet) {
+	rtksOut = &RotationKeySet{Keys: make(map[uint64]*SwitchingKey, len(rtks.Keys))}
+	for galEl, swk := range rtks.Keys {
+		rtksOut.Keys[galEl] = swk.Expand(params)
+	}
+	return
+}
+
+// GetDataLen returns the length in bytes of the target SeededSwitchingKey.
+func (swk *SeededSwitchingKey) GetDataLen(WithMetadata bool) (dataLen int) {
+	return swk.getDataLen(WithMetadata, false)
+}
+
+func (swk *SeededSwitchingKey) getDataLen(WithMetadata, packed bool) (dataLen int) {
+
+	if WithMetadata {
+		dataLen += 2
+	}
+
+	dataLen += SeedSize
+
+	for i := range swk.Value {
+		for _, el := range swk.Value[i] {
+			dataLen += ring.GetDataLen(&el, WithMetadata, packed)
+		}
+	}
+
+	return
+}
+
+// MarshalBinary encodes the target SeededSwitchingKey on a slice of bytes.
+func (swk *SeededSwitchingKey) MarshalBinary() (data []byte, err error) {
+	return swk.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes the target SeededSwitchingKey on a slice of bytes with the packed encoding of the
+// polynomials. It is decoded by UnmarshalBinary.
+func (swk *SeededSwitchingKey) MarshalBinaryPacked() (data []byte, err error) {
+	return swk.marshalBinary(true)
+}
+
+func (swk *SeededSwitchingKey) marshalBinary(packed bool) (data []byte, err error) {
+	data = make([]byte, swk.getDataLen(true, packed))
+	if _, err = swk.encode(0, data, packed); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target SeededSwitchingKey.
+func (swk *SeededSwitchingKey) UnmarshalBinary(data []byte) (err error) {
+
+	var pointer int
+	if pointer, err = swk.Decode(data); err != nil {
+		return
+	}
+
+	if pointer != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
+	return
+}
+
+// Encode encodes the target SeededSwitchingKey on a pre-allocated slice of bytes.
+func (swk *SeededSwitchingKey) Encode(pointer int, data []byte) (int, error) {
+	return swk.encode(pointer, data, false)
+}
+
+func (swk *SeededSwitchingKey) encode(pointer int, data []byte, packed bool) (int, error) {
+
+	var err error
+	var inc int
+
+	if len(swk.Value) > 0xFF || len(swk.Value[0]) > 0xFF {
+		return pointer, errors.New("cannot Encode: uint8 overflow on length")
+	}
+
+	if len(swk.Seed) != SeedSize {
+		return pointer, errors.New("cannot Encode: invalid seed size")
+	}
+
+	data[pointer] = uint8(len(swk.Value))
+	pointer++
+	data[pointer] = uint8(len(swk.Value[0]))
+	pointer++
+
+	pointer += copy(data[pointer:], swk.Seed)
+
+	for i := range swk.Value {
+		for _, el := range swk.Value[i] {
+			if inc, err = ring.WriteTo(&el, data[pointer:], packed); err != nil {
+				return pointer, err
+			}
+			pointer += inc
+		}
+	}
+
+	return pointer, nil
+}
+
+// Decode decodes a slice of bytes on the target SeededSwitchingKey and returns the number of bytes read.
+func (swk *SeededSwitchingKey) Decode(data []byte) (pointer int, err error) {
+
+	if len(data) < 2+SeedSize {
+		return 0, malformed("data is too short")
+	}
+
+	decompRNS := int(data[0])
+	decompBIT := int(data[1])
+
+	// each element is a ringqp.Poly that has at least a header of 2 bytes
+	if decompRNS == 0 || decompBIT == 0 || len(data)-2-SeedSize < 2*decompRNS*decompBIT {
+		return 0, malformed("invalid decomposition %dx%d", decompRNS, decompBIT)
+	}
+
+	pointer = 2
+
+	swk.Seed = make([]byte, SeedSize)
+	pointer += copy(swk.Seed, data[pointer:])
+
+	swk.Value = make([][]ringqp.Poly, decompRNS)
+
+	var inc int
+	for i := range swk.Value {
+		swk.Value[i] = make([]ringqp.Poly, decompBIT)
+		for j := range swk.Value[i] {
+			if inc, err = decodePoly(&swk.Value[i][j], data[pointer:]); err != nil {
+				return
+			}
+			pointer += inc
+		}
+	}
+
+	return
+}
+
+// GetDataLen returns the length in bytes of the target SeededRelinearizationKey.
+func (rlk *SeededRelinearizationKey) GetDataLen(WithMetadata bool) (dataLen int) {
+
+	if WithMetadata {
+		dataLen++
+	}
+
+	for _, swk := range rlk.Keys {
+		dataLen += swk.GetDataLen(WithMetadata)
+	}
+
+	return
+}
+
+// MarshalBinary encodes the target SeededRelinearizationKey on a slice of bytes.
+func (rlk *SeededRelinearizationKey) MarshalBinary() (data []byte, err error) {
+	return rlk.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes the target SeededRelinearizationKey on a slice of bytes with the packed encoding
+// of the polynomials. It is decoded by UnmarshalBinary.
+func (rlk *SeededRelinearizationKey) MarshalBinaryPacked() (data []byte, err error) {
+	return rlk.marshalBinary(true)
+}
+
+func (rlk *SeededRelinearizationKey) marshalBinary(packed bool) (data []byte, err error) {
+
+	dataLen := 1
+	for _, swk := range rlk.Keys {
+		dataLen += swk.getDataLen(true, packed)
+	}
+
+	data = make([]byte, dataLen)
+	data[0] = uint8(len(rlk.Keys))
+
+	pointer := 1
+	for _, swk := range rlk.Keys {
+		if pointer, err = swk.encode(pointer, data, packed); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target SeededRelinearizationKey.
+func (rlk *SeededRelinearizationKey) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 1 || data[0] == 0 {
+		return malformed("seeded relinearization key is empty")
+	}
+
+	rlk.Keys = make([]*SeededSwitchingKey, data[0])
+
+	pointer := 1
+	var inc int
+	for i := range rlk.Keys {
+		rlk.Keys[i] = new(SeededSwitchingKey)
+		if inc, err = rlk.Keys[i].Decode(data[pointer:]); err != nil {
+			return err
+		}
+		pointer += inc
+	}
+
+	if pointer != len(data) {
+		return malformed("remaining unparsed data")
+	}
+
+	return nil
+}
+
+// GetDataLen returns the length in bytes of the target SeededRotationKeySet.
+func (rtks *SeededRotationKeySet) GetDataLen(WithMetaData bool) (dataLen int) {
+	for _, swk := range rtks.Keys {
+		if WithMetaData {
+			dataLen += 8
+		}
+		dataLen += swk.GetDataLen(WithMetaData)
+	}
+	return
+}
+
+// MarshalBinary encodes the target SeededRotationKeySet on a slice of bytes.
+func (rtks *SeededRotationKeySet) MarshalBinary() (data []byte, err error) {
+	return rtks.marshalBinary(false)
+}
+
+// MarshalBinaryPacked encodes the target SeededRotationKeySet on a slice of bytes with the packed encoding
+// of the polynomials. It is decoded by UnmarshalBinary.
+func (rtks *SeededRotationKeySet) MarshalBinaryPacked() (data []byte, err error) {
+	return rtks.marshalBinary(true)
+}
+
+func (rtks *SeededRotationKeySet) marshalBinary(packed bool) (data []byte, err error) {
+
+	var dataLen int
+	for _, swk := range rtks.Keys {
+		dataLen += 8 + swk.getDataLen(true, packed)
+	}
+
+	data = make([]byte, dataLen)
+
+	var pointer int
+	for galEl, swk := range rtks.Keys {
+
+		binary.BigEndian.PutUint64(data[pointer:pointer+8], galEl)
+		pointer += 8
+
+		if pointer, err = swk.encode(pointer, data, packed); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target SeededRotationKeySet.
+func (rtks *SeededRotationKeySet) UnmarshalBinary(data []byte) (err error) {
+
+	rtks.Keys = make(map[uint64]*SeededSwitchingKey)
+
+	for len(data) > 0 {
+
+		if len(data) < 8 {
+			return malformed("data is too short")
+		}
+
+		galEl := binary.BigEndian.Uint64(data)
+		data = data[8:]
+
+		swk := new(SeededSwitchingKey)
+		var inc int
+		if inc, err = swk.Decode(data); err != nil {
+			return err
+		}
+		data = data[inc:]
+		rtks.Keys[galEl] = swk
+	}
+
+	return nil
+}
+
+// newSeed samples a new seed from the given PRNG and returns it along with the PRNG keyed with it.
+func newSeed(prng utils.PRNG) (seed []byte, keyed utils.PRNG) {
+	seed = make([]byte, SeedSize)
+	if _, err := prng.Read(seed); err != nil {
+		panic(err)
+	}
+	var err error
+	if keyed, err = utils.NewKeyedPRNG(seed); err != nil {
+		panic(err)
+	}
+	return
+}
+
+// newSeededSampler returns a uniform sampler in the ring QP of params keyed with the given seed.
+func newSeededSampler(params Parameters, seed []byte) ringqp.UniformSampler {
+	prng, err := utils.NewKeyedPRNG(seed)
+	if err != nil {
+		panic(err)
+	}
+	return ringqp.NewUniformSampler(prng, *params.RingQP())
+}
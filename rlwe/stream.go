@@ -0,0 +1,289 @@
+package rlwe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// The WriteTo and ReadFrom methods of this file implement the io.WriterTo and io.ReaderFrom interfaces with
+// the same layouts as MarshalBinary and UnmarshalBinary. The objects are written and read one polynomial at
+// a time, so that the memory used by the streaming is bounded by the size of one RNS limb of a polynomial.
+// The readers should be buffered, e.g. with bufio.NewReader, and ReadFrom reads exactly one object.
+
+// writeBytes writes b on w and adds the number of written bytes to n.
+func writeBytes(w io.Writer, b []byte, n *int64) (err error) {
+	inc, err := w.Write(b)
+	*n += int64(inc)
+	return
+}
+
+// readBytes fills b from r and adds the number of read bytes to n.
+func readBytes(r io.Reader, b []byte, n *int64) (err error) {
+	inc, err := io.ReadFull(r, b)
+	*n += int64(inc)
+	return
+}
+
+// writeObject writes obj on w and adds the number of written bytes to n.
+func writeObject(w io.Writer, obj io.WriterTo, n *int64) (err error) {
+	inc, err := obj.WriteTo(w)
+	*n += inc
+	return
+}
+
+// readObject reads obj from r and adds the number of read bytes to n.
+func readObject(r io.Reader, obj io.ReaderFrom, n *int64) (err error) {
+	inc, err := obj.ReadFrom(r)
+	*n += inc
+	return
+}
+
+// WriteTo writes the target Ciphertext on w.
+func (el *Ciphertext) WriteTo(w io.Writer) (n int64, err error) {
+
+	if err = writeBytes(w, []byte{uint8(el.Degree() + 1)}, &n); err != nil {
+		return
+	}
+
+	for _, p := range el.Value {
+		if err = writeObject(w, p, &n); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// ReadFrom reads a Ciphertext from r on the target Ciphertext.
+func (el *Ciphertext) ReadFrom(r io.Reader) (n int64, err error) {
+
+	degree := []byte{0}
+	if err = readBytes(r, degree, &n); err != nil {
+		return
+	}
+
+	if degree[0] == 0 {
+		return n, malformed("ciphertext has no element")
+	}
+
+	el.Value = make([]*ring.Poly, degree[0])
+
+	for i := range el.Value {
+		el.Value[i] = new(ring.Poly)
+		if err = readObject(r, el.Value[i], &n); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// WriteTo writes the target SecretKey on w.
+func (sk *SecretKey) WriteTo(w io.Writer) (n int64, err error) {
+	return sk.Value.WriteTo(w)
+}
+
+// ReadFrom reads a SecretKey from r on the target SecretKey.
+func (sk *SecretKey) ReadFrom(r io.Reader) (n int64, err error) {
+	return sk.Value.ReadFrom(r)
+}
+
+// WriteTo writes the target PublicKey on w.
+func (pk *PublicKey) WriteTo(w io.Writer) (n int64, err error) {
+	for i := range pk.Value {
+		if err = writeObject(w, &pk.Value[i], &n); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ReadFrom reads a PublicKey from r on the target PublicKey.
+func (pk *PublicKey) ReadFrom(r io.Reader) (n int64, err error) {
+	for i := range pk.Value {
+		if err = readObject(r, &pk.Value[i], &n); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// WriteTo writes the target GadgetCiphertext on w.
+func (ct *GadgetCiphertext) WriteTo(w io.Writer) (n int64, err error) {
+
+	if err = writeBytes(w, []byte{uint8(len(ct.Value)), uint8(len(ct.Value[0]))}, &n); err != nil {
+		return
+	}
+
+	for i := range ct.Value {
+		for j := range ct.Value[i] {
+			for k := range ct.Value[i][j].Value {
+				if err = writeObject(w, &ct.Value[i][j].Value[k], &n); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// ReadFrom reads a GadgetCiphertext from r on the target GadgetCiphertext.
+func (ct *GadgetCiphertext) ReadFrom(r io.Reader) (n int64, err error) {
+
+	decomp := []byte{0, 0}
+	if err = readBytes(r, decomp, &n); err != nil {
+		return
+	}
+
+	if decomp[0] == 0 || decomp[1] == 0 {
+		return n, malformed("invalid decomposition %dx%d", decomp[0], decomp[1])
+	}
+
+	ct.Value = make([][]CiphertextQP, decomp[0])
+
+	for i := range ct.Value {
+
+		ct.Value[i] = make([]CiphertextQP, decomp[1])
+
+		for j := range ct.Value[i] {
+			for k := range ct.Value[i][j].Value {
+				if err = readObject(r, &ct.Value[i][j].Value[k], &n); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// WriteTo writes the target RelinearizationKey on w.
+func (rlk *RelinearizationKey) WriteTo(w io.Writer) (n int64, err error) {
+
+	if err = writeBytes(w, []byte{uint8(len(rlk.Keys))}, &n); err != nil {
+		return
+	}
+
+	for _, swk := range rlk.Keys {
+		if err = writeObject(w, swk, &n); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// ReadFrom reads a RelinearizationKey from r on the target RelinearizationKey.
+func (rlk *RelinearizationKey) ReadFrom(r io.Reader) (n int64, err error) {
+
+	deg := []byte{0}
+	if err = readBytes(r, deg, &n); err != nil {
+		return
+	}
+
+	if deg[0] == 0 {
+		return n, malformed("relinearization key is empty")
+	}
+
+	rlk.Keys = make([]*SwitchingKey, deg[0])
+
+	for i := range rlk.Keys {
+		rlk.Keys[i] = new(SwitchingKey)
+		if err = readObject(r, rlk.Keys[i], &n); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// WriteTo writes the target RotationKeySet on w.
+func (rtks *RotationKeySet) WriteTo(w io.Writer) (n int64, err error) {
+	return WriteRotationKeys(w, rtks)
+}
+
+// WriteRotationKeys writes the keys of rtks on w with the layout of RotationKeySet.MarshalBinary, that is
+// the number of keys followed by the pairs (galois element, key) sorted by galois element. The keys are
+// fetched one at a time, so that a LazyRotationKeySet does not load all its keys at once.
+func WriteRotationKeys(w io.Writer, rtks RotationKeyProvider) (n int64, err error) {
+
+	galEls := append([]uint64{}, rtks.GaloisElements()...)
+	sort.Slice(galEls, func(i, j int) bool { return galEls[i] < galEls[j] })
+
+	buff := make([]byte, 8)
+	binary.BigEndian.PutUint64(buff, uint64(len(galEls)))
+
+	if err = writeBytes(w, buff, &n); err != nil {
+		return
+	}
+
+	for _, galEl := range galEls {
+
+		swk, inSet := rtks.GetRotationKey(galEl)
+		if !inSet {
+			return n, fmt.Errorf("cannot WriteRotationKeys: missing rotation key for galois element %d", galEl)
+		}
+
+		binary.BigEndian.PutUint64(buff, galEl)
+
+		if err = writeBytes(w, buff, &n); err != nil {
+			return
+		}
+
+		if err = writeObject(w, swk, &n); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// ReadFrom reads a RotationKeySet written by WriteTo from r on the target RotationKeySet.
+// It reads exactly the number of keys written in the header and returns io.ErrUnexpectedEOF if r
+// ends before the last key.
+func (rtks *RotationKeySet) ReadFrom(r io.Reader) (n int64, err error) {
+
+	buff := make([]byte, 8)
+
+	if err = readBytes(r, buff, &n); err != nil {
+		return
+	}
+
+	nbKeys := binary.BigEndian.Uint64(buff)
+
+	rtks.Keys = make(map[uint64]*SwitchingKey)
+
+	for i := uint64(0); i < nbKeys; i++ {
+
+		if err = readBytes(r, buff, &n); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return
+		}
+
+		galEl := binary.BigEndian.Uint64(buff)
+
+		if _, inSet := rtks.Keys[galEl]; inSet {
+			return n, malformed("duplicate rotation key for galois element %d", galEl)
+		}
+
+		swk := new(SwitchingKey)
+		if err = readObject(r, swk, &n); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return
+		}
+
+		rtks.Keys[galEl] = swk
+	}
+
+	return
+}
@@ -0,0 +1,314 @@
+package rlwe
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe/ringqp"
+)
+
+// ErrMalformed is returned by the decoders and by the Validate methods of this package when an encoding
+// or an object is malformed. It is wrapped with the details of the failure and can be tested with errors.Is.
+//
+// The decoders only check that the encoding is structurally sound, i.e., that its lengths are consistent
+// with the size of the data, so that they never panic nor allocate much more memory than the size of the data.
+// The objects decoded from untrusted sources must then be checked against the expected parameters with Validate,
+// or be decoded with UnmarshalValidated.
+var ErrMalformed = errors.New("malformed object")
+
+// Validator is the interface of the objects that can be checked against a set of parameters.
+type Validator interface {
+	Validate(params Parameters) error
+}
+
+// UnmarshalValidated decodes data on obj and checks the decoded object against params.
+func UnmarshalValidated(params Parameters, data []byte, obj interface {
+	encoding.BinaryUnmarshaler
+	Validator
+}) (err error) {
+
+	if err = obj.UnmarshalBinary(data); err != nil {
+		return
+	}
+
+	return obj.Validate(params)
+}
+
+// malformed returns a new error wrapping ErrMalformed with a message formatted from format and args.
+func malformed(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrMalformed, fmt.Sprintf(format, args...))
+}
+
+// decodePoly decodes data on p and wraps the decoding errors with ErrMalformed.
+func decodePoly(p interface{ DecodePoly64([]byte) (int, error) }, data []byte) (n int, err error) {
+	if n, err = p.DecodePoly64(data); err != nil {
+		err = fmt.Errorf("%w: %s", ErrMalformed, err)
+	}
+	return
+}
+
+// validatePoly checks that pol is a well-formed polynomial of ringQ.
+func validatePoly(ringQ *ring.Ring, pol *ring.Poly) (err error) {
+	if err = ringQ.Validate(pol); err != nil {
+		return fmt.Errorf("%w: %s", ErrMalformed, err)
+	}
+	return
+}
+
+// validatePolyQP checks that p is a well-formed polynomial of ringQP at the given levels.
+func validatePolyQP(ringQP *ringqp.Ring, levelQ, levelP int, p ringqp.Poly) (err error) {
+
+	if err = ringQP.Validate(p); err != nil {
+		return fmt.Errorf("%w: %s", ErrMalformed, err)
+	}
+
+	if p.LevelQ() != levelQ || p.LevelP() != levelP {
+		return malformed("polynomial has levels (%d, %d) instead of (%d, %d)", p.LevelQ(), p.LevelP(), levelQ, levelP)
+	}
+
+	return
+}
+
+// validateDecomposition checks that a gadget decomposition of the given dimensions matches the one of params
+// at the given levels.
+func validateDecomposition(params Parameters, levelQ, levelP, decompRNS, decompPw2 int) error {
+
+	if levelQ < 0 || levelQ > params.MaxLevel() || levelP > params.PCount()-1 {
+		return malformed("levels (%d, %d) are not supported by the parameters", levelQ, levelP)
+	}
+
+	if decompRNS != params.DecompRNS(levelQ, levelP) || decompPw2 != params.DecompPw2(levelQ, levelP) {
+		return malformed("decomposition %dx%d does not match the decomposition %dx%d of the parameters",
+			decompRNS, decompPw2, params.DecompRNS(levelQ, levelP), params.DecompPw2(levelQ, levelP))
+	}
+
+	return nil
+}
+
+// validateGaloisElement checks that galEl is a Galois element of the ring of params.
+func validateGaloisElement(params Parameters, galEl uint64) error {
+	if galEl&1 == 0 || galEl >= params.RingQ().NthRoot {
+		return malformed("invalid Galois element %d", galEl)
+	}
+	return nil
+}
+
+// Validate checks that the target Ciphertext is a well-formed ciphertext of params: its elements have the
+// same level and domain, and their coefficients are reduced modulo the moduli of the parameters.
+func (el *Ciphertext) Validate(params Parameters) (err error) {
+
+	if el == nil || len(el.Value) == 0 {
+		return malformed("ciphertext is empty")
+	}
+
+	for _, pol := range el.Value {
+
+		if err = validatePoly(params.RingQ(), pol); err != nil {
+			return
+		}
+
+		if pol.Level() != el.Value[0].Level() || pol.IsNTT != el.Value[0].IsNTT {
+			return malformed("elements of the ciphertext have different levels or domains")
+		}
+	}
+
+	return
+}
+
+// Validate checks that the target SecretKey is a well-formed secret key of params.
+func (sk *SecretKey) Validate(params Parameters) error {
+
+	if sk == nil {
+		return malformed("secret key is nil")
+	}
+
+	return validatePolyQP(params.RingQP(), params.QCount()-1, params.PCount()-1, sk.Value)
+}
+
+// Validate checks that the target PublicKey is a well-formed public key of params.
+func (pk *PublicKey) Validate(params Parameters) (err error) {
+
+	if pk == nil {
+		return malformed("public key is nil")
+	}
+
+	for i := range pk.Value {
+		if err = validatePolyQP(params.RingQP(), params.QCount()-1, params.PCount()-1, pk.Value[i]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Validate checks that the target GadgetCiphertext is a well-formed gadget ciphertext of params: its
+// decomposition matches the one of the parameters at its levels and the coefficients of its elements are
+// reduced modulo the moduli of the parameters.
+func (ct *GadgetCiphertext) Validate(params Parameters) (err error) {
+
+	if ct == nil || len(ct.Value) == 0 || len(ct.Value[0]) == 0 || ct.Value[0][0].Value[0].Q == nil {
+		return malformed("gadget ciphertext is empty")
+	}
+
+	levelQ, levelP := ct.LevelQ(), ct.LevelP()
+
+	for i := range ct.Value {
+		if len(ct.Value[i]) != len(ct.Value[0]) {
+			return malformed("gadget ciphertext is not rectangular")
+		}
+	}
+
+	if err = validateDecomposition(params, levelQ, levelP, len(ct.Value), len(ct.Value[0])); err != nil {
+		return
+	}
+
+	ringQP := params.RingQP()
+	for i := range ct.Value {
+		for j := range ct.Value[i] {
+			for _, p := range ct.Value[i][j].Value {
+				if err = validatePolyQP(ringQP, levelQ, levelP, p); err != nil {
+					return
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// Validate checks that the target RelinearizationKey is a well-formed relinearization key of params.
+func (rlk *RelinearizationKey) Validate(params Parameters) (err error) {
+
+	if rlk == nil || len(rlk.Keys) == 0 {
+		return malformed("relinearization key is empty")
+	}
+
+	for _, swk := range rlk.Keys {
+
+		if swk == nil {
+			return malformed("switching key is nil")
+		}
+
+		if err = swk.Validate(params); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Validate checks that the target RotationKeySet is a well-formed rotation key set of params, indexed
+// by valid Galois elements.
+func (rtks *RotationKeySet) Validate(params Parameters) (err error) {
+
+	if rtks == nil {
+		return malformed("rotation key set is nil")
+	}
+
+	for galEl, swk := range rtks.Keys {
+
+		if err = validateGaloisElement(params, galEl); err != nil {
+			return
+		}
+
+		if swk == nil {
+			return malformed("switching key is nil")
+		}
+
+		if err = swk.Validate(params); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Validate checks that the target SeededCiphertext is a well-formed seeded ciphertext of params.
+func (ct *SeededCiphertext) Validate(params Parameters) (err error) {
+
+	if ct == nil {
+		return malformed("seeded ciphertext is nil")
+	}
+
+	if len(ct.Seed) != SeedSize {
+		return malformed("seed has %d bytes instead of %d", len(ct.Seed), SeedSize)
+	}
+
+	return validatePoly(params.RingQ(), ct.Value)
+}
+
+// Validate checks that the target SeededSwitchingKey is a well-formed seeded switching key of params, see
+// GadgetCiphertext.Validate.
+func (swk *SeededSwitchingKey) Validate(params Parameters) (err error) {
+
+	if swk == nil || len(swk.Value) == 0 || len(swk.Value[0]) == 0 || swk.Value[0][0].Q == nil {
+		return malformed("seeded switching key is empty")
+	}
+
+	if len(swk.Seed) != SeedSize {
+		return malformed("seed has %d bytes instead of %d", len(swk.Seed), SeedSize)
+	}
+
+	levelQ, levelP := swk.Value[0][0].LevelQ(), swk.Value[0][0].LevelP()
+
+	for i := range swk.Value {
+		if len(swk.Value[i]) != len(swk.Value[0]) {
+			return malformed("seeded switching key is not rectangular")
+		}
+	}
+
+	if err = validateDecomposition(params, levelQ, levelP, len(swk.Value), len(swk.Value[0])); err != nil {
+		return
+	}
+
+	ringQP := params.RingQP()
+	for i := range swk.Value {
+		for _, p := range swk.Value[i] {
+			if err = validatePolyQP(ringQP, levelQ, levelP, p); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// Validate checks that the target SeededRelinearizationKey is a well-formed seeded relinearization key of params.
+func (rlk *SeededRelinearizationKey) Validate(params Parameters) (err error) {
+
+	if rlk == nil || len(rlk.Keys) == 0 {
+		return malformed("seeded relinearization key is empty")
+	}
+
+	for _, swk := range rlk.Keys {
+		if err = swk.Validate(params); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Validate checks that the target SeededRotationKeySet is a well-formed seeded rotation key set of params,
+// indexed by valid Galois elements.
+func (rtks *SeededRotationKeySet) Validate(params Parameters) (err error) {
+
+	if rtks == nil {
+		return malformed("seeded rotation key set is nil")
+	}
+
+	for galEl, swk := range rtks.Keys {
+
+		if err = validateGaloisElement(params, galEl); err != nil {
+			return
+		}
+
+		if err = swk.Validate(params); err != nil {
+			return
+		}
+	}
+
+	return
+}
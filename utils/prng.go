@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"io"
 
 	"golang.org/x/crypto/blake2b"
@@ -56,3 +57,37 @@ func (prng *KeyedPRNG) Read(sum []byte) (n int, err error) {
 func (prng *KeyedPRNG) Reset() {
 	prng.xof.Reset()
 }
+
+// DeriveKey derives from key the 64-byte key of the given domain. The keys derived from the same key for
+// different domains are independent, so that the KeyedPRNGs keyed with them generate independent sequences.
+func DeriveKey(key []byte, domain string) []byte {
+	h, err := blake2b.New512(nil)
+	if err != nil {
+		panic(err)
+	}
+	var size [8]byte
+	binary.BigEndian.PutUint64(size[:], uint64(len(domain)))
+	h.Write(size[:])
+	h.Write([]byte(domain))
+	h.Write(key)
+	return h.Sum(nil)
+}
+
+// NewDerivedPRNGs reads a 64-byte key from prng and returns, for each of the given domains, a new KeyedPRNG
+// keyed with the key derived for this domain (see DeriveKey).
+func NewDerivedPRNGs(prng PRNG, domains ...string) (prngs []*KeyedPRNG, err error) {
+
+	key := make([]byte, 64)
+	if _, err = io.ReadFull(prng, key); err != nil {
+		return nil, err
+	}
+
+	prngs = make([]*KeyedPRNG, len(domains))
+	for i, domain := range domains {
+		if prngs[i], err = NewKeyedPRNG(DeriveKey(key, domain)); err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
@@ -31,4 +31,30 @@ func Test_PRNG(t *testing.T) {
 		require.Equal(t, sum0, sum1)
 	})
 
+	t.Run("DerivedPRNGs", func(t *testing.T) {
+
+		key := []byte{0x49, 0x0a, 0x42, 0x3d}
+
+		Ha, _ := NewKeyedPRNG(key)
+		Hb, _ := NewKeyedPRNG(key)
+
+		prngsA, err := NewDerivedPRNGs(Ha, "a", "b")
+		require.NoError(t, err)
+		prngsB, err := NewDerivedPRNGs(Hb, "b", "a")
+		require.NoError(t, err)
+
+		sum0 := make([]byte, 64)
+		sum1 := make([]byte, 64)
+
+		// the sequences depend only on the key and the domain
+		prngsA[0].Read(sum0)
+		prngsB[1].Read(sum1)
+		require.Equal(t, sum0, sum1)
+
+		prngsA[1].Read(sum1)
+		require.NotEqual(t, sum0, sum1)
+
+		require.NotEqual(t, DeriveKey(key, "a"), DeriveKey(key, "b"))
+		require.NotEqual(t, DeriveKey(key, "ab"), DeriveKey(append(key, 'a'), "b"))
+	})
 }